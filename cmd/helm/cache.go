@@ -0,0 +1,155 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/gosuri/uitable"
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v3/cmd/helm/require"
+	"helm.sh/helm/v3/pkg/cli/output"
+	"helm.sh/helm/v3/pkg/downloader"
+)
+
+const cacheHelp = `
+This command consists of multiple subcommands to manage the chart cache.
+
+Chart archives downloaded by 'helm pull', 'helm install', 'helm upgrade' and
+'helm dependency build' are kept in the chart cache, keyed by the digest listed
+in the repository index or by the digest of the OCI manifest. A chart that is
+already cached is not downloaded again, and the --offline flag of these commands
+only takes charts from the cache.
+
+The chart cache is stored in $HELM_CHART_CACHE.
+`
+
+const cachePruneDesc = `
+Remove the charts of the chart cache that were not used for some time.
+
+With --older-than, only the charts that were not downloaded or used during that
+duration are removed. Otherwise, every chart is removed.
+
+    $ helm cache prune --older-than 720h
+`
+
+func newCacheCmd(out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache list|prune",
+		Short: "manage the chart cache",
+		Long:  cacheHelp,
+		Args:  require.NoArgs,
+	}
+
+	cmd.AddCommand(newCacheListCmd(out))
+	cmd.AddCommand(newCachePruneCmd(out))
+
+	return cmd
+}
+
+func newCacheListCmd(out io.Writer) *cobra.Command {
+	var outfmt output.Format
+	cmd := &cobra.Command{
+		Use:               "list",
+		Aliases:           []string{"ls"},
+		Short:             "list the charts in the chart cache",
+		Args:              require.NoArgs,
+		ValidArgsFunction: noMoreArgsCompFunc,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			entries, err := downloader.NewChartCache(settings.ChartCache).List()
+			if err != nil {
+				return err
+			}
+			return outfmt.Write(out, &cacheListWriter{entries})
+		},
+	}
+
+	bindOutputFlag(cmd, &outfmt)
+
+	return cmd
+}
+
+func newCachePruneCmd(out io.Writer) *cobra.Command {
+	var olderThan time.Duration
+	cmd := &cobra.Command{
+		Use:               "prune",
+		Short:             "remove charts from the chart cache",
+		Long:              cachePruneDesc,
+		Args:              require.NoArgs,
+		ValidArgsFunction: noMoreArgsCompFunc,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			pruned, err := downloader.NewChartCache(settings.ChartCache).Prune(time.Now().Add(-olderThan))
+			var size int64
+			for _, e := range pruned {
+				size += e.Size
+			}
+			fmt.Fprintf(out, "Removed %d charts (%s) from the chart cache\n", len(pruned), formatSize(size))
+			return err
+		},
+	}
+
+	cmd.Flags().DurationVar(&olderThan, "older-than", 0, "only remove the charts not used for this duration (e.g. 720h)")
+
+	return cmd
+}
+
+type cacheListWriter struct {
+	entries []downloader.CacheEntry
+}
+
+func (w *cacheListWriter) WriteTable(out io.Writer) error {
+	table := uitable.New()
+	table.AddRow("NAME", "DIGEST", "SIZE", "LAST USED")
+	for _, e := range w.entries {
+		table.AddRow(e.Name, e.Digest, formatSize(e.Size), e.LastUsed.Format(time.RFC3339))
+	}
+	return output.EncodeTable(out, table)
+}
+
+func (w *cacheListWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, w.list())
+}
+
+func (w *cacheListWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, w.list())
+}
+
+// list returns the entries, as an empty list rather than null when the cache
+// is empty.
+func (w *cacheListWriter) list() []downloader.CacheEntry {
+	if w.entries == nil {
+		return []downloader.CacheEntry{}
+	}
+	return w.entries
+}
+
+// formatSize formats a number of bytes with a binary unit.
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
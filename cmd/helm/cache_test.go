@@ -0,0 +1,89 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"helm.sh/helm/v3/pkg/downloader"
+)
+
+func TestCacheCmd(t *testing.T) {
+	defer resetEnv()()
+
+	settings.ChartCache = t.TempDir()
+	cache := downloader.NewChartCache(settings.ChartCache)
+	if _, err := cache.Put("sha256:515c58e5f79d8b2913a10cb400ebb6fa9c77fe813287afbacf1a0b897cd78727", "alpine-0.2.0.tgz", []byte("alpine")); err != nil {
+		t.Fatal(err)
+	}
+	old, err := cache.Put("sha256:0e6661f193211d7a5206918d42f5c2a9470b737d0e6661f193211d7a5206918d", "foo-0.1.0.tgz", []byte("foo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lastUsed := time.Now().Add(-48 * time.Hour)
+	if err := os.Chtimes(old, lastUsed, lastUsed); err != nil {
+		t.Fatal(err)
+	}
+
+	_, out, err := executeActionCommand("cache list")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expect := range []string{"alpine-0.2.0.tgz", "foo-0.1.0.tgz", "sha256:515c58e5", "6 B"} {
+		if !strings.Contains(out, expect) {
+			t.Errorf("expected %q in the output, got:\n%s", expect, out)
+		}
+	}
+
+	_, out, err = executeActionCommand("cache prune --older-than 24h")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := "Removed 1 charts (3 B) from the chart cache\n"; out != expect {
+		t.Errorf("expected %q, got %q", expect, out)
+	}
+
+	_, out, err = executeActionCommand("cache list")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "alpine-0.2.0.tgz") || strings.Contains(out, "foo-0.1.0.tgz") {
+		t.Errorf("expected only alpine to be cached, got:\n%s", out)
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	tests := map[int64]string{
+		0:               "0 B",
+		1023:            "1023 B",
+		1024:            "1.0 KiB",
+		1536:            "1.5 KiB",
+		5 * 1024 * 1024: "5.0 MiB",
+	}
+	for size, expect := range tests {
+		if got := formatSize(size); got != expect {
+			t.Errorf("formatSize(%d) = %q, expected %q", size, got, expect)
+		}
+	}
+}
+
+func TestCacheListOutputCompletion(t *testing.T) {
+	outputFlagCompletionTest(t, "cache list")
+}
@@ -39,6 +39,10 @@ does.
 
 If no lock file is found, 'helm dependency build' will mirror the behavior
 of 'helm dependency update'.
+
+Downloaded dependencies are kept in the chart cache. With --offline, the
+dependencies are only taken from the chart cache, so that a chart can be built
+without network access once its dependencies were downloaded.
 `
 
 func newDependencyBuildCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
@@ -55,15 +59,19 @@ func newDependencyBuildCmd(cfg *action.Configuration, out io.Writer) *cobra.Comm
 				chartpath = filepath.Clean(args[0])
 			}
 			man := &downloader.Manager{
-				Out:              out,
-				ChartPath:        chartpath,
-				Keyring:          client.Keyring,
-				SkipUpdate:       client.SkipRefresh,
-				Getters:          getter.All(settings),
-				RegistryClient:   cfg.RegistryClient,
-				RepositoryConfig: settings.RepositoryConfig,
-				RepositoryCache:  settings.RepositoryCache,
-				Debug:            settings.Debug,
+				Out:               out,
+				ChartPath:         chartpath,
+				Keyring:           client.Keyring,
+				SkipUpdate:        client.SkipRefresh,
+				Getters:           getter.All(settings),
+				RegistryClient:    cfg.RegistryClient,
+				RepositoryConfig:  settings.RepositoryConfig,
+				RepositoryCache:   settings.RepositoryCache,
+				TrustPolicyConfig: settings.TrustPolicyConfig,
+				RegistryConfig:    settings.RegistryConfig,
+				ChartCache:        settings.ChartCache,
+				Offline:           client.Offline,
+				Debug:             settings.Debug,
 			}
 			if client.Verify {
 				man.Verify = downloader.VerifyIfPossible
@@ -80,6 +88,7 @@ func newDependencyBuildCmd(cfg *action.Configuration, out io.Writer) *cobra.Comm
 	f.BoolVar(&client.Verify, "verify", false, "verify the packages against signatures")
 	f.StringVar(&client.Keyring, "keyring", defaultKeyring(), "keyring containing public keys")
 	f.BoolVar(&client.SkipRefresh, "skip-refresh", false, "do not refresh the local repository cache")
+	f.BoolVar(&client.Offline, "offline", false, "only use dependencies from the chart cache, without refreshing the repository cache or downloading charts")
 
 	return cmd
 }
@@ -175,8 +175,11 @@ func TestDependencyUpdateCmd_DoNotDeleteOldChartsOnError(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	// Chart repo is down
+	// Chart repo is down, and the charts are not in the chart cache
 	srv.Stop()
+	chartCache := settings.ChartCache
+	t.Cleanup(func() { settings.ChartCache = chartCache })
+	settings.ChartCache = t.TempDir()
 
 	_, output, err = executeActionCommand(fmt.Sprintf("dependency update %s --repository-config %s --repository-cache %s", dir(chartname), dir("repositories.yaml"), dir()))
 	if err == nil {
@@ -82,6 +82,9 @@ func (w metadataWriter) WriteTable(out io.Writer) error {
 	_, _ = fmt.Fprintf(out, "REVISION: %v\n", w.metadata.Revision)
 	_, _ = fmt.Fprintf(out, "STATUS: %v\n", w.metadata.Status)
 	_, _ = fmt.Fprintf(out, "DEPLOYED_AT: %v\n", w.metadata.DeployedAt)
+	if w.metadata.ChartDigest != "" {
+		_, _ = fmt.Fprintf(out, "CHART_DIGEST: %v\n", w.metadata.ChartDigest)
+	}
 	return nil
 }
 
@@ -77,6 +77,7 @@ func main() {
 		if err := actionConfig.Init(settings.RESTClientGetter(), settings.Namespace(), helmDriver, debug); err != nil {
 			log.Fatal(err)
 		}
+		actionConfig.Releases.LockTimeout = settings.LockTimeout
 		if helmDriver == "memory" {
 			loadReleasesInMemory(actionConfig)
 		}
@@ -48,11 +48,17 @@ The historical release set is printed as a formatted table, e.g:
     2           Mon Oct 3 10:15:13 2016     superseded      alpine-0.1.0      1.0             Upgraded successfully
     3           Mon Oct 3 10:15:13 2016     superseded      alpine-0.1.0      1.0             Rolled back to 2
     4           Mon Oct 3 10:15:13 2016     deployed        alpine-0.1.0      1.0             Upgraded successfully
+
+Each revision records who made it, from where and why: the Kubernetes user,
+the hostname, the Helm version, where the chart came from and the cause given
+with '--change-cause'. The '--show-origin' flag shows them in the table instead
+of the chart and description. They are always included in JSON and YAML output.
 `
 
 func newHistoryCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	client := action.NewHistory(cfg)
 	var outfmt output.Format
+	var showOrigin bool
 
 	cmd := &cobra.Command{
 		Use:     "history RELEASE_NAME",
@@ -72,12 +78,16 @@ func newHistoryCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 				return err
 			}
 
+			if showOrigin {
+				return outfmt.Write(out, releaseOriginHistory(history))
+			}
 			return outfmt.Write(out, history)
 		},
 	}
 
 	f := cmd.Flags()
 	f.IntVar(&client.Max, "max", 256, "maximum number of revision to include in history")
+	f.BoolVar(&showOrigin, "show-origin", false, "if set, show who made each revision, from where and why, instead of its chart and description")
 	bindOutputFlag(cmd, &outfmt)
 
 	return cmd
@@ -90,6 +100,8 @@ type releaseInfo struct {
 	Chart       string        `json:"chart"`
 	AppVersion  string        `json:"app_version"`
 	Description string        `json:"description"`
+	// Origin records who made the revision, from where and why
+	Origin *release.Origin `json:"origin,omitempty"`
 }
 
 type releaseHistory []releaseInfo
@@ -111,6 +123,31 @@ func (r releaseHistory) WriteTable(out io.Writer) error {
 	return output.EncodeTable(out, tbl)
 }
 
+// releaseOriginHistory is a release history whose table shows who made each
+// revision, from where and why.
+type releaseOriginHistory releaseHistory
+
+func (r releaseOriginHistory) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, r)
+}
+
+func (r releaseOriginHistory) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, r)
+}
+
+func (r releaseOriginHistory) WriteTable(out io.Writer) error {
+	tbl := uitable.New()
+	tbl.AddRow("REVISION", "UPDATED", "STATUS", "DEPLOYED BY", "HOSTNAME", "HELM VERSION", "CHART SOURCE", "CHANGE CAUSE")
+	for _, item := range r {
+		origin := item.Origin
+		if origin == nil {
+			origin = &release.Origin{}
+		}
+		tbl.AddRow(item.Revision, item.Updated.Format(time.ANSIC), item.Status, origin.User, origin.Hostname, origin.HelmVersion, origin.ChartSource, origin.ChangeCause)
+	}
+	return output.EncodeTable(out, tbl)
+}
+
 func getHistory(client *action.History, name string) (releaseHistory, error) {
 	hist, err := client.Run(name)
 	if err != nil {
@@ -148,6 +185,7 @@ func getReleaseHistory(rls []*release.Release) (history releaseHistory) {
 			Chart:       c,
 			AppVersion:  a,
 			Description: d,
+			Origin:      r.Info.Origin,
 		}
 		if !r.Info.LastDeployed.IsZero() {
 			rInfo.Updated = r.Info.LastDeployed
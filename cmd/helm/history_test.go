@@ -66,10 +66,33 @@ func TestHistoryCmd(t *testing.T) {
 			mk("angry-bird", 3, release.StatusSuperseded),
 		},
 		golden: "output/history.json",
+	}, {
+		name:   "get history with origin",
+		cmd:    "history angry-bird --show-origin",
+		rels:   originHistory(),
+		golden: "output/history-origin.txt",
+	}, {
+		name:   "get history with origin in json",
+		cmd:    "history angry-bird --output json",
+		rels:   originHistory(),
+		golden: "output/history-origin.json",
 	}}
 	runTestCmd(t, tests)
 }
 
+func originHistory() []*release.Release {
+	first := release.Mock(&release.MockReleaseOptions{Name: "angry-bird", Version: 1, Status: release.StatusSuperseded})
+	second := release.Mock(&release.MockReleaseOptions{Name: "angry-bird", Version: 2, Status: release.StatusDeployed})
+	second.Info.Origin = &release.Origin{
+		User:        "jane",
+		Hostname:    "laptop",
+		HelmVersion: "v3.17.0",
+		ChartSource: "https://charts.example.com/foo-0.1.0-beta.1.tgz",
+		ChangeCause: "more replicas",
+	}
+	return []*release.Release{second, first}
+}
+
 func TestHistoryOutputCompletion(t *testing.T) {
 	outputFlagCompletionTest(t, "history")
 }
@@ -124,12 +124,45 @@ supply a version number with the '--version' flag.
 
 To see the list of chart repositories, use 'helm repo list'. To search for
 charts in a repository, use 'helm search'.
+
+WAITING FOR RESOURCES
+
+The '--plan' flag shows what the install would do without doing it: the
+resources that would be created or adopted, and the hooks that would run in
+each phase, in order. Use '--output json' to save the plan.
+
+With '--wait', the readiness of each resource of the release is shown on stderr
+while Helm waits, and the resources that were not ready are listed if the wait
+times out.
+
+Custom resources are ready once their 'Ready' status condition, or else their
+'Available' condition, is True, and their status has caught up with their
+latest generation. Annotate a resource to wait for another condition or for a
+JSONPath expression instead:
+
+    metadata:
+      annotations:
+        helm.sh/ready-condition: Synced
+        # or
+        helm.sh/ready-jsonpath: '{.status.phase}=Running'
+
+Resources can be rolled out in waves with the 'helm.sh/wave' annotation, whose
+value is an integer; resources without it are in wave 0. Waves are applied in
+increasing order by install, upgrade and rollback, and with '--wait', each wave
+must be ready before the next one is applied. '--timeout' applies to each wave.
+If a wave fails, the later waves are not applied. The waves are recorded in the
+release.
+
+    metadata:
+      annotations:
+        helm.sh/wave: "-1"
 `
 
 func newInstallCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	client := action.NewInstall(cfg)
 	valueOpts := &values.Options{}
 	var outfmt output.Format
+	var plan bool
 
 	cmd := &cobra.Command{
 		Use:   "install [NAME] [CHART]",
@@ -153,12 +186,21 @@ func newInstallCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 			if client.DryRunOption == "" {
 				client.DryRunOption = "none"
 			}
+			if plan {
+				p, err := runInstallPlan(args, client, valueOpts, out)
+				if err != nil {
+					return errors.Wrap(err, "INSTALLATION PLAN FAILED")
+				}
+				return outfmt.Write(out, &planWriter{p})
+			}
+			done := reportWaitProgress(cfg, client.Wait || client.Atomic)
 			rel, err := runInstall(args, client, valueOpts, out)
+			done(err)
 			if err != nil {
 				return errors.Wrap(err, "INSTALLATION FAILED")
 			}
 
-			return outfmt.Write(out, &statusPrinter{rel, settings.Debug, false, false, false, client.HideNotes})
+			return outfmt.Write(out, &statusPrinter{rel, settings.Debug, false, false, false, client.HideNotes, false})
 		},
 	}
 
@@ -167,6 +209,7 @@ func newInstallCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	// it is added separately
 	f := cmd.Flags()
 	f.BoolVar(&client.HideSecret, "hide-secret", false, "hide Kubernetes Secrets when also using the --dry-run flag")
+	f.BoolVar(&plan, "plan", false, "show what the install would do to each resource and which hooks would run, without installing")
 	bindOutputFlag(cmd, &outfmt)
 	bindPostRenderFlag(cmd, &client.PostRenderer)
 
@@ -186,11 +229,12 @@ func addInstallFlags(cmd *cobra.Command, f *pflag.FlagSet, client *action.Instal
 	f.BoolVar(&client.DisableHooks, "no-hooks", false, "prevent hooks from running during install")
 	f.BoolVar(&client.Replace, "replace", false, "re-use the given name, only if that name is a deleted release which remains in the history. This is unsafe in production")
 	f.DurationVar(&client.Timeout, "timeout", 300*time.Second, "time to wait for any individual Kubernetes operation (like Jobs for hooks)")
-	f.BoolVar(&client.Wait, "wait", false, "if set, will wait until all Pods, PVCs, Services, and minimum number of Pods of a Deployment, StatefulSet, or ReplicaSet are in a ready state before marking the release as successful. It will wait for as long as --timeout")
+	f.BoolVar(&client.Wait, "wait", false, "if set, will wait until all Pods, PVCs, Services, custom resources, and minimum number of Pods of a Deployment, StatefulSet, or ReplicaSet are in a ready state before marking the release as successful. It will wait for as long as --timeout")
 	f.BoolVar(&client.WaitForJobs, "wait-for-jobs", false, "if set and --wait enabled, will wait until all Jobs have been completed before marking the release as successful. It will wait for as long as --timeout")
 	f.BoolVarP(&client.GenerateName, "generate-name", "g", false, "generate the name (and omit the NAME parameter)")
 	f.StringVar(&client.NameTemplate, "name-template", "", "specify template used to name the release")
 	f.StringVar(&client.Description, "description", "", "add a custom description")
+	f.StringVar(&client.ChangeCause, "change-cause", "", "record why the release is installed in its revision")
 	f.BoolVar(&client.Devel, "devel", false, "use development versions, too. Equivalent to version '>0.0.0-0'. If --version is set, this is ignored")
 	f.BoolVar(&client.DependencyUpdate, "dependency-update", false, "update dependencies if they are missing before installing the chart")
 	f.BoolVar(&client.DisableOpenAPIValidation, "disable-openapi-validation", false, "if set, the installation process will not validate rendered templates against the Kubernetes OpenAPI Schema")
@@ -221,6 +265,41 @@ func addInstallFlags(cmd *cobra.Command, f *pflag.FlagSet, client *action.Instal
 }
 
 func runInstall(args []string, client *action.Install, valueOpts *values.Options, out io.Writer) (*release.Release, error) {
+	chartRequested, vals, err := loadInstallChart(args, client, valueOpts, out)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create context and prepare the handle of SIGTERM
+	ctx := context.Background()
+	ctx, cancel := context.WithCancel(ctx)
+
+	// Set up channel on which to send signal notifications.
+	// We must use a buffered channel or risk missing the signal
+	// if we're not ready to receive when the signal is sent.
+	cSignal := make(chan os.Signal, 2)
+	signal.Notify(cSignal, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-cSignal
+		fmt.Fprintf(out, "Release %s has been cancelled.\n", args[0])
+		cancel()
+	}()
+
+	return client.RunWithContext(ctx, chartRequested, vals)
+}
+
+// runInstallPlan returns what installing the chart would do.
+func runInstallPlan(args []string, client *action.Install, valueOpts *values.Options, out io.Writer) (*release.Plan, error) {
+	chartRequested, vals, err := loadInstallChart(args, client, valueOpts, out)
+	if err != nil {
+		return nil, err
+	}
+	return client.Plan(chartRequested, vals)
+}
+
+// loadInstallChart locates and loads the chart to install, with its
+// dependencies, and merges the values to install it with.
+func loadInstallChart(args []string, client *action.Install, valueOpts *values.Options, out io.Writer) (*chart.Chart, map[string]interface{}, error) {
 	debug("Original chart version: %q", client.Version)
 	if client.Version == "" && client.Devel {
 		debug("setting version to >0.0.0-0")
@@ -229,13 +308,13 @@ func runInstall(args []string, client *action.Install, valueOpts *values.Options
 
 	name, chart, err := client.NameAndChart(args)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	client.ReleaseName = name
 
 	cp, err := client.ChartPathOptions.LocateChart(chart, settings)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	debug("CHART PATH: %s\n", cp)
@@ -243,17 +322,17 @@ func runInstall(args []string, client *action.Install, valueOpts *values.Options
 	p := getter.All(settings)
 	vals, err := valueOpts.MergeValues(p)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Check chart dependencies to make sure all are present in /charts
 	chartRequested, err := loader.Load(cp)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	if err := checkIfInstallable(chartRequested); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	if chartRequested.Metadata.Deprecated {
@@ -268,25 +347,29 @@ func runInstall(args []string, client *action.Install, valueOpts *values.Options
 			err = errors.Wrap(err, "An error occurred while checking for chart dependencies. You may need to run `helm dependency build` to fetch missing dependencies")
 			if client.DependencyUpdate {
 				man := &downloader.Manager{
-					Out:              out,
-					ChartPath:        cp,
-					Keyring:          client.ChartPathOptions.Keyring,
-					SkipUpdate:       false,
-					Getters:          p,
-					RepositoryConfig: settings.RepositoryConfig,
-					RepositoryCache:  settings.RepositoryCache,
-					Debug:            settings.Debug,
-					RegistryClient:   client.GetRegistryClient(),
+					Out:               out,
+					ChartPath:         cp,
+					Keyring:           client.ChartPathOptions.Keyring,
+					SkipUpdate:        false,
+					Getters:           p,
+					RepositoryConfig:  settings.RepositoryConfig,
+					RepositoryCache:   settings.RepositoryCache,
+					TrustPolicyConfig: settings.TrustPolicyConfig,
+					RegistryConfig:    settings.RegistryConfig,
+					ChartCache:        settings.ChartCache,
+					Offline:           client.Offline,
+					Debug:             settings.Debug,
+					RegistryClient:    client.GetRegistryClient(),
 				}
 				if err := man.Update(); err != nil {
-					return nil, err
+					return nil, nil, err
 				}
 				// Reload the chart with the updated Chart.lock file.
 				if chartRequested, err = loader.Load(cp); err != nil {
-					return nil, errors.Wrap(err, "failed reloading chart after repo update")
+					return nil, nil, errors.Wrap(err, "failed reloading chart after repo update")
 				}
 			} else {
-				return nil, err
+				return nil, nil, err
 			}
 		}
 	}
@@ -295,25 +378,10 @@ func runInstall(args []string, client *action.Install, valueOpts *values.Options
 
 	// Validate DryRunOption member is one of the allowed values
 	if err := validateDryRunOptionFlag(client.DryRunOption); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	// Create context and prepare the handle of SIGTERM
-	ctx := context.Background()
-	ctx, cancel := context.WithCancel(ctx)
-
-	// Set up channel on which to send signal notifications.
-	// We must use a buffered channel or risk missing the signal
-	// if we're not ready to receive when the signal is sent.
-	cSignal := make(chan os.Signal, 2)
-	signal.Notify(cSignal, os.Interrupt, syscall.SIGTERM)
-	go func() {
-		<-cSignal
-		fmt.Fprintf(out, "Release %s has been cancelled.\n", args[0])
-		cancel()
-	}()
-
-	return client.RunWithContext(ctx, chartRequested, vals)
+	return chartRequested, vals, nil
 }
 
 // checkIfInstallable validates if a chart can be installed
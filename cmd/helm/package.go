@@ -21,6 +21,8 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strconv"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -29,6 +31,7 @@ import (
 	"helm.sh/helm/v3/pkg/cli/values"
 	"helm.sh/helm/v3/pkg/downloader"
 	"helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/provenance"
 )
 
 const packageDesc = `
@@ -45,11 +48,50 @@ provide '--keyring path/to/secret/keys' and '--key keyname'.
 
 If '--keyring' is not specified, Helm usually defaults to the public keyring
 unless your environment is otherwise configured.
+
+Charts can also be signed with an ed25519, SSH or X.509 key instead of PGP,
+using '--sign-scheme'. In that case '--key' is the path of the private key:
+
+  $ helm package --sign ./mychart --sign-scheme ssh --key ~/.ssh/id_ed25519
+
+With the x509 scheme, the key file must also hold the certificate chain of the
+key, leaf first. 'helm verify' then expects '--keyring' to be a file of trusted
+ed25519 public keys, an SSH allowed_signers file or a CA bundle respectively.
+
+To get the same archive each time a chart is packaged, on any machine, give a
+timestamp with '--timestamp' or the SOURCE_DATE_EPOCH environment variable.
+Every file of the archive is then stamped with that time, in seconds since the
+Unix epoch or in RFC 3339 format, and is written in a fixed order with
+normalized permissions:
+
+  $ SOURCE_DATE_EPOCH=$(git log -1 --format=%ct) helm package ./mychart
 `
 
+// reproducibleTimestamp returns the time reproducible archives are stamped
+// with: the --timestamp flag if set, or the SOURCE_DATE_EPOCH environment
+// variable. It returns the zero time if neither is set.
+func reproducibleTimestamp(flag string) (time.Time, error) {
+	value, source := flag, "--timestamp"
+	if value == "" {
+		value, source = os.Getenv("SOURCE_DATE_EPOCH"), "SOURCE_DATE_EPOCH"
+	}
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0).UTC(), nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, errors.Errorf("invalid %s %q: expected seconds since the Unix epoch or an RFC 3339 time", source, value)
+	}
+	return t.UTC(), nil
+}
+
 func newPackageCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	client := action.NewPackage()
 	valueOpts := &values.Options{}
+	var timestamp string
 
 	cmd := &cobra.Command{
 		Use:   "package [CHART_PATH] [...]",
@@ -63,12 +105,17 @@ func newPackageCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 				if client.Key == "" {
 					return errors.New("--key is required for signing a package")
 				}
-				if client.Keyring == "" {
+				if client.Keyring == "" && (client.SignScheme == "" || client.SignScheme == provenance.SchemePGP) {
 					return errors.New("--keyring is required for signing a package")
 				}
 			}
 			client.RepositoryConfig = settings.RepositoryConfig
 			client.RepositoryCache = settings.RepositoryCache
+			timestamp, err := reproducibleTimestamp(timestamp)
+			if err != nil {
+				return err
+			}
+			client.Timestamp = timestamp
 			p := getter.All(settings)
 			vals, err := valueOpts.MergeValues(p)
 			if err != nil {
@@ -86,14 +133,15 @@ func newPackageCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 
 				if client.DependencyUpdate {
 					downloadManager := &downloader.Manager{
-						Out:              io.Discard,
-						ChartPath:        path,
-						Keyring:          client.Keyring,
-						Getters:          p,
-						Debug:            settings.Debug,
-						RegistryClient:   cfg.RegistryClient,
-						RepositoryConfig: settings.RepositoryConfig,
-						RepositoryCache:  settings.RepositoryCache,
+						Out:               io.Discard,
+						ChartPath:         path,
+						Keyring:           client.Keyring,
+						Getters:           p,
+						Debug:             settings.Debug,
+						RegistryClient:    cfg.RegistryClient,
+						RepositoryConfig:  settings.RepositoryConfig,
+						RepositoryCache:   settings.RepositoryCache,
+						TrustPolicyConfig: settings.TrustPolicyConfig,
 					}
 
 					if err := downloadManager.Update(); err != nil {
@@ -111,14 +159,16 @@ func newPackageCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	}
 
 	f := cmd.Flags()
-	f.BoolVar(&client.Sign, "sign", false, "use a PGP private key to sign this package")
-	f.StringVar(&client.Key, "key", "", "name of the key to use when signing. Used if --sign is true")
+	f.BoolVar(&client.Sign, "sign", false, "use a private key to sign this package")
+	f.StringVar(&client.SignScheme, "sign-scheme", provenance.SchemePGP, "signing scheme to use if --sign is true: pgp, ed25519, ssh or x509")
+	f.StringVar(&client.Key, "key", "", "name of the key to use when signing, or path of the key for schemes other than pgp. Used if --sign is true")
 	f.StringVar(&client.Keyring, "keyring", defaultKeyring(), "location of a public keyring")
 	f.StringVar(&client.PassphraseFile, "passphrase-file", "", `location of a file which contains the passphrase for the signing key. Use "-" in order to read from stdin.`)
 	f.StringVar(&client.Version, "version", "", "set the version on the chart to this semver version")
 	f.StringVar(&client.AppVersion, "app-version", "", "set the appVersion on the chart to this version")
 	f.StringVarP(&client.Destination, "destination", "d", ".", "location to write the chart.")
 	f.BoolVarP(&client.DependencyUpdate, "dependency-update", "u", false, `update dependencies from "Chart.yaml" to dir "charts/" before packaging`)
+	f.StringVar(&timestamp, "timestamp", "", "make the archive reproducible, stamping its files with this time in seconds since the Unix epoch or in RFC 3339 format. Defaults to $SOURCE_DATE_EPOCH")
 
 	return cmd
 }
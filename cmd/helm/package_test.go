@@ -16,12 +16,14 @@ limitations under the License.
 package main
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
 	"testing"
+	"time"
 
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/chart/loader"
@@ -101,6 +103,19 @@ func TestPackage(t *testing.T) {
 			args: []string{"testdata/testcharts/chart-bad-type"},
 			err:  true,
 		},
+		{
+			name:    "package --timestamp testdata/testcharts/alpine",
+			args:    []string{"testdata/testcharts/alpine"},
+			flags:   map[string]string{"timestamp": "1672628645"},
+			hasfile: "alpine-0.1.0.tgz",
+		},
+		{
+			name:   "package --timestamp invalid",
+			args:   []string{"testdata/testcharts/alpine"},
+			flags:  map[string]string{"timestamp": "yesterday"},
+			expect: `invalid --timestamp "yesterday"`,
+			err:    true,
+		},
 	}
 
 	origDir, err := os.Getwd()
@@ -165,6 +180,36 @@ func TestPackage(t *testing.T) {
 	}
 }
 
+func TestPackageReproducible(t *testing.T) {
+	chartToPackage := "testdata/testcharts/alpine"
+
+	packageChart := func(flags string) []byte {
+		t.Helper()
+		dir := t.TempDir()
+		if _, _, err := executeActionCommand(fmt.Sprintf("package %s --destination=%s %s", chartToPackage, dir, flags)); err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(filepath.Join(dir, "alpine-0.1.0.tgz"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+
+	t.Setenv("SOURCE_DATE_EPOCH", "1672628645")
+	first := packageChart("")
+	time.Sleep(time.Second)
+	if second := packageChart(""); !bytes.Equal(first, second) {
+		t.Error("expected packaging with the same SOURCE_DATE_EPOCH to give the same archive")
+	}
+	if rfc3339 := packageChart("--timestamp=2023-01-02T03:04:05Z"); !bytes.Equal(first, rfc3339) {
+		t.Error("expected --timestamp in RFC 3339 format to give the same archive as SOURCE_DATE_EPOCH")
+	}
+	if other := packageChart("--timestamp=0"); bytes.Equal(first, other) {
+		t.Error("expected --timestamp to override SOURCE_DATE_EPOCH")
+	}
+}
+
 func TestSetAppVersion(t *testing.T) {
 	var ch *chart.Chart
 	expectedAppVersion := "app-version-foo"
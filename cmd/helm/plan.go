@@ -0,0 +1,92 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/gosuri/uitable"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v3/pkg/cli/output"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// planWriter writes the plan of an install or upgrade.
+type planWriter struct {
+	plan *release.Plan
+}
+
+func (w *planWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, w.plan)
+}
+
+func (w *planWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, w.plan)
+}
+
+func (w *planWriter) WriteTable(out io.Writer) error {
+	plan := w.plan
+	fmt.Fprintf(out, "RELEASE: %s\n", plan.Name)
+	fmt.Fprintf(out, "NAMESPACE: %s\n", plan.Namespace)
+	fmt.Fprintf(out, "REVISION: %d\n", plan.Revision)
+	fmt.Fprintf(out, "CHART: %s\n", plan.Chart)
+	if len(plan.Namespaces) > 0 {
+		fmt.Fprintf(out, "AFFECTED NAMESPACES: %s\n", strings.Join(plan.Namespaces, ", "))
+	}
+
+	fmt.Fprintln(out, "\nRESOURCES (in order):")
+	if len(plan.Changes) == 0 {
+		fmt.Fprintln(out, "none")
+	} else {
+		tbl := uitable.New()
+		tbl.AddRow("OPERATION", "KIND", "NAME", "NAMESPACE")
+		for _, c := range plan.Changes {
+			tbl.AddRow(c.Operation, c.Kind, c.Name, c.Namespace)
+		}
+		if err := output.EncodeTable(out, tbl); err != nil {
+			return err
+		}
+	}
+	if len(plan.Orphaned) > 0 {
+		fmt.Fprintln(out, "\nORPHANED RESOURCES (helm.sh/resource-policy: keep):")
+		if err := writePlannedResources(out, plan.Orphaned); err != nil {
+			return err
+		}
+	}
+	return writePlannedHooks(out, plan.Hooks)
+}
+
+// loadPlan reads a plan written in JSON or YAML by --plan.
+func loadPlan(path string) (*release.Plan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	plan := &release.Plan{}
+	if err := yaml.Unmarshal(data, plan); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse plan %s", path)
+	}
+	if plan.Name == "" {
+		return nil, errors.Errorf("%s is not a plan", path)
+	}
+	return plan, nil
+}
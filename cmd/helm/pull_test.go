@@ -40,6 +40,11 @@ func TestPullCmd(t *testing.T) {
 	}
 	ociSrv.Run(t)
 
+	ociDigest, err := ociSrv.Client.Resolve(fmt.Sprintf("%s/u/ocitestuser/oci-dependent-chart:0.1.0", ociSrv.RegistryURL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
 	if err := srv.LinkIndices(); err != nil {
 		t.Fatal(err)
 	}
@@ -170,6 +175,23 @@ func TestPullCmd(t *testing.T) {
 			wantError:    true,
 			wantErrorMsg: fmt.Sprintf("failed to untar: a file or directory with the name %s already exists", filepath.Join(srv.Root(), "ocitest2")),
 		},
+		{
+			name:       "Fetch OCI Chart pinned to a digest",
+			args:       fmt.Sprintf("oci://%s/u/ocitestuser/oci-dependent-chart@%s", ociSrv.RegistryURL, ociDigest),
+			expectFile: "./oci-dependent-chart-0.1.0.tgz",
+		},
+		{
+			name:       "Fetch OCI Chart pinned to a version and digest",
+			args:       fmt.Sprintf("oci://%s/u/ocitestuser/oci-dependent-chart:0.1.0@%s --untar --untardir ocitest3", ociSrv.RegistryURL, ociDigest),
+			expectFile: "./ocitest3",
+			expectDir:  true,
+		},
+		{
+			name:       "Fail fetching OCI chart pinned to a different digest",
+			args:       fmt.Sprintf("oci://%s/u/ocitestuser/oci-dependent-chart@sha256:%064d", ociSrv.RegistryURL, 0),
+			failExpect: "Failed to fetch",
+			wantError:  true,
+		},
 		{
 			name:       "Fail fetching non-existent OCI chart",
 			args:       fmt.Sprintf("oci://%s/u/ocitestuser/nosuchthing --version 0.1.0", ociSrv.RegistryURL),
@@ -183,15 +205,20 @@ func TestPullCmd(t *testing.T) {
 			wantError:    true,
 		},
 		{
-			name:         "Fail fetching OCI chart without version specified",
-			args:         fmt.Sprintf("oci://%s/u/ocitestuser/oci-dependent-chart:0.1.0", ociSrv.RegistryURL),
-			wantErrorMsg: "Error: --version flag is explicitly required for OCI registries",
-			wantError:    true,
+			name:       "Fetch OCI chart with the tag in the reference",
+			args:       fmt.Sprintf("oci://%s/u/ocitestuser/oci-dependent-chart:0.1.0", ociSrv.RegistryURL),
+			expectFile: "./oci-dependent-chart-0.1.0.tgz",
 		},
 		{
-			name:      "Fail fetching OCI chart without version specified",
-			args:      fmt.Sprintf("oci://%s/u/ocitestuser/oci-dependent-chart:0.1.0 --version 0.1.0", ociSrv.RegistryURL),
-			wantError: true,
+			name:       "Fetch OCI chart with the tag in the reference and the same version",
+			args:       fmt.Sprintf("oci://%s/u/ocitestuser/oci-dependent-chart:0.1.0 --version 0.1.0", ociSrv.RegistryURL),
+			expectFile: "./oci-dependent-chart-0.1.0.tgz",
+		},
+		{
+			name:         "Fail fetching OCI chart with the tag in the reference and another version",
+			args:         fmt.Sprintf("oci://%s/u/ocitestuser/oci-dependent-chart:0.1.0 --version 0.2.0", ociSrv.RegistryURL),
+			wantErrorMsg: fmt.Sprintf("Error: chart reference %s/u/ocitestuser/oci-dependent-chart:0.1.0 does not match version 0.2.0", ociSrv.RegistryURL),
+			wantError:    true,
 		},
 	}
 
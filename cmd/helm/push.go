@@ -17,8 +17,10 @@ limitations under the License.
 package main
 
 import (
+	"errors"
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/spf13/cobra"
 
@@ -28,10 +30,28 @@ import (
 )
 
 const pushDesc = `
-Upload a chart to a registry.
+Upload a chart to a registry or to an HTTP chart repository.
 
 If the chart has an associated provenance file,
 it will also be uploaded.
+
+Charts are uploaded to HTTP(S) chart repositories with the ChartMuseum API by
+default, or with a PUT request next to the repository index using
+'--upload-api put'. The credentials and TLS settings of a repository added with
+'helm repo add' are used for its URL. A chart version that already exists in
+the repository is only overwritten with '--force'.
+
+  $ helm push mychart-0.1.0.tgz https://charts.example.com
+
+When pushing to an OCI registry, the '--sign' flag signs the chart manifest
+with a PGP key and attaches the signature to it as an OCI referrer. Other
+artifacts, such as an SBOM or a provenance statement, can be attached with
+'--attestation TYPE=FILE'. Use 'helm verify oci://...' to check them.
+
+The creation time recorded in the manifest of a chart pushed to an OCI registry
+is the time the chart archive was created. Set it with '--timestamp' or the
+SOURCE_DATE_EPOCH environment variable to push a reproducible archive with a
+stable manifest digest.
 `
 
 type registryPushOptions struct {
@@ -40,6 +60,14 @@ type registryPushOptions struct {
 	caFile                string
 	insecureSkipTLSverify bool
 	plainHTTP             bool
+	sign                  bool
+	key                   string
+	keyring               string
+	passphraseFile        string
+	attestations          []string
+	force                 bool
+	uploadAPI             string
+	timestamp             string
 }
 
 func newPushCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
@@ -73,15 +101,42 @@ func newPushCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 				return fmt.Errorf("missing registry client: %w", err)
 			}
 			cfg.RegistryClient = registryClient
+			timestamp, err := reproducibleTimestamp(o.timestamp)
+			if err != nil {
+				return err
+			}
 			chartRef := args[0]
 			remote := args[1]
-			client := action.NewPushWithOpts(action.WithPushConfig(cfg),
+			opts := []action.PushOpt{action.WithPushConfig(cfg),
 				action.WithTLSClientConfig(o.certFile, o.keyFile, o.caFile),
 				action.WithInsecureSkipTLSVerify(o.insecureSkipTLSverify),
 				action.WithPlainHTTP(o.plainHTTP),
-				action.WithPushOptWriter(out))
+				action.WithPushForce(o.force),
+				action.WithPushUploadAPI(o.uploadAPI),
+				action.WithPushCreationTime(timestamp),
+				action.WithPushOptWriter(out)}
+			if o.sign {
+				if o.key == "" {
+					return fmt.Errorf("--key is required for signing a chart")
+				}
+				if o.keyring == "" {
+					return fmt.Errorf("--keyring is required for signing a chart")
+				}
+				opts = append(opts, action.WithPushSign(o.key, o.keyring, o.passphraseFile))
+			}
+			for _, a := range o.attestations {
+				artifactType, file, ok := strings.Cut(a, "=")
+				if !ok || artifactType == "" || file == "" {
+					return fmt.Errorf("invalid attestation %q, expected TYPE=FILE", a)
+				}
+				opts = append(opts, action.WithPushAttestation(artifactType, file))
+			}
+			client := action.NewPushWithOpts(opts...)
 			client.Settings = settings
 			output, err := client.Run(chartRef, remote)
+			if errors.Is(err, pusher.ErrChartExists) {
+				return fmt.Errorf("%w (use --force to overwrite it)", err)
+			}
 			if err != nil {
 				return err
 			}
@@ -96,6 +151,14 @@ func newPushCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	f.StringVar(&o.caFile, "ca-file", "", "verify certificates of HTTPS-enabled servers using this CA bundle")
 	f.BoolVar(&o.insecureSkipTLSverify, "insecure-skip-tls-verify", false, "skip tls certificate checks for the chart upload")
 	f.BoolVar(&o.plainHTTP, "plain-http", false, "use insecure HTTP connections for the chart upload")
+	f.BoolVar(&o.sign, "sign", false, "use a PGP private key to sign the pushed chart")
+	f.StringVar(&o.key, "key", "", "name of the key to use when signing. Used if --sign is true")
+	f.StringVar(&o.keyring, "keyring", defaultKeyring(), "location of a public keyring")
+	f.StringVar(&o.passphraseFile, "passphrase-file", "", `location of a file which contains the passphrase for the signing key. Use "-" in order to read from stdin.`)
+	f.BoolVar(&o.force, "force", false, "overwrite the chart version if it already exists in an HTTP chart repository")
+	f.StringVar(&o.uploadAPI, "upload-api", pusher.UploadChartMuseum, "API used to upload charts to HTTP chart repositories: chartmuseum or put")
+	f.StringVar(&o.timestamp, "timestamp", "", "creation time recorded in the manifest of the chart pushed to an OCI registry, in seconds since the Unix epoch or in RFC 3339 format. Defaults to $SOURCE_DATE_EPOCH")
+	f.StringArrayVar(&o.attestations, "attestation", []string{}, "attach a file to the pushed chart as an attestation of the given artifact type (can specify multiple): TYPE=FILE")
 
 	return cmd
 }
@@ -17,7 +17,14 @@ limitations under the License.
 package main
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"helm.sh/helm/v3/pkg/repo"
+	"helm.sh/helm/v3/pkg/repo/repotest"
 )
 
 func TestPushFileCompletion(t *testing.T) {
@@ -25,3 +32,36 @@ func TestPushFileCompletion(t *testing.T) {
 	checkFileCompletion(t, "push package.tgz", false)
 	checkFileCompletion(t, "push package.tgz oci://localhost:5000", false)
 }
+
+func TestPushHTTPCmd(t *testing.T) {
+	srv := repotest.NewTempServerWithCleanupAndBasicAuth(t, "")
+	defer srv.Stop()
+
+	// The credentials of the repository come from the repositories file.
+	repoFile := filepath.Join(srv.Root(), "repositories.yaml")
+	rf := repo.NewFile()
+	rf.Add(&repo.Entry{Name: "test", URL: srv.URL(), Username: "username", Password: "password"})
+	if err := rf.WriteFile(repoFile, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	chart := "testdata/testcharts/compressedchart-0.1.0.tgz"
+	cmd := fmt.Sprintf("push %s %s --repository-config %s", chart, srv.URL(), repoFile)
+	if _, _, err := executeActionCommand(cmd); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(srv.Root(), "compressedchart-0.1.0.tgz")); err != nil {
+		t.Errorf("expected the chart to be uploaded: %s", err)
+	}
+
+	_, _, err := executeActionCommand(cmd)
+	if err == nil || !strings.Contains(err.Error(), "chart version already exists (use --force to overwrite it)") {
+		t.Errorf("expected a conflict, got %v", err)
+	}
+	if _, _, err := executeActionCommand(cmd + " --force"); err != nil {
+		t.Errorf("expected a forced push to succeed, got %s", err)
+	}
+	if _, _, err := executeActionCommand(cmd + " --upload-api put --force"); err != nil {
+		t.Errorf("expected a forced PUT to succeed, got %s", err)
+	}
+}
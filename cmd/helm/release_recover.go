@@ -0,0 +1,99 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v3/cmd/helm/require"
+	"helm.sh/helm/v3/pkg/action"
+)
+
+const releaseRecoverDesc = `
+This command recovers a release whose last operation was interrupted, for
+example when Helm was killed during an install, upgrade or rollback.
+
+Such a release is left with its last revision in a pending state, and Helm
+refuses to run another operation on it. This command marks that revision as
+failed, so that the release can be upgraded or rolled back again. With
+--rollback, the release is then rolled back to its last deployed revision.
+
+The lock of a release is held by the operation running on it, so a release
+whose operation is still running is never recovered. Operations that do not
+lock releases, such as the ones run by older versions of Helm, are detected by
+the age of the pending revision instead: a revision that has been pending for
+less than --min-age is assumed to belong to an operation that is still running,
+and is not recovered unless --force is set.
+
+To see the state of the revisions of a release, run 'helm history RELEASE'.
+`
+
+func newReleaseRecoverCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	client := action.NewRecover(cfg)
+
+	cmd := &cobra.Command{
+		Use:   "recover RELEASE",
+		Short: "recover a release stuck in a pending state",
+		Long:  releaseRecoverDesc,
+		Args:  require.ExactArgs(1),
+		ValidArgsFunction: func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return noMoreArgsComp()
+			}
+			return compListReleases(toComplete, args, cfg)
+		},
+		RunE: func(_ *cobra.Command, args []string) error {
+			done := reportWaitProgress(cfg, client.Rollback && client.Wait)
+			res, err := client.Run(args[0])
+			done(err)
+			if res != nil {
+				if client.DryRun {
+					fmt.Fprintf(out, "Revision %d of release %q would be marked as failed (was %s)\n", res.Release.Version, res.Release.Name, res.PendingStatus)
+				} else {
+					fmt.Fprintf(out, "Revision %d of release %q was marked as failed (was %s)\n", res.Release.Version, res.Release.Name, res.PendingStatus)
+				}
+			}
+			if err != nil {
+				return err
+			}
+			if res.RolledBackTo != 0 {
+				if client.DryRun {
+					fmt.Fprintf(out, "Release %q would be rolled back to revision %d\n", res.Release.Name, res.RolledBackTo)
+				} else {
+					fmt.Fprintf(out, "Release %q was rolled back to revision %d\n", res.Release.Name, res.RolledBackTo)
+				}
+			}
+			return nil
+		},
+	}
+
+	f := cmd.Flags()
+	f.DurationVar(&client.MinAge, "min-age", 10*time.Minute, "how long the last revision must have been pending before it is considered stuck")
+	f.BoolVar(&client.Force, "force", false, "recover the release even if its last revision has been pending for less than --min-age")
+	f.BoolVar(&client.Rollback, "rollback", false, "roll the release back to its last deployed revision once the pending revision is marked as failed")
+	f.BoolVar(&client.DryRun, "dry-run", false, "simulate a recovery")
+	f.BoolVar(&client.DisableHooks, "no-hooks", false, "prevent hooks from running during the rollback")
+	f.DurationVar(&client.Timeout, "timeout", 300*time.Second, "time to wait for any individual Kubernetes operation (like Jobs for hooks) during the rollback")
+	f.BoolVar(&client.Wait, "wait", false, "if set with --rollback, will wait until all Pods, PVCs, Services, custom resources, and minimum number of Pods of a Deployment, StatefulSet, or ReplicaSet are in a ready state before marking the rollback as successful. It will wait for as long as --timeout")
+	f.BoolVar(&client.WaitForJobs, "wait-for-jobs", false, "if set and --wait enabled, will wait until all Jobs have been completed before marking the rollback as successful. It will wait for as long as --timeout")
+
+	return cmd
+}
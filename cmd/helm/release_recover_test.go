@@ -0,0 +1,83 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"testing"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+	helmtime "helm.sh/helm/v3/pkg/time"
+)
+
+func TestReleaseRecoverCmd(t *testing.T) {
+	stuck := func(status release.Status) []*release.Release {
+		return []*release.Release{
+			{
+				Name:    "funny-honey",
+				Info:    &release.Info{Status: release.StatusDeployed, LastDeployed: helmtime.Unix(1242129600, 0)},
+				Chart:   &chart.Chart{},
+				Version: 1,
+			},
+			{
+				Name:    "funny-honey",
+				Info:    &release.Info{Status: status, LastDeployed: helmtime.Now()},
+				Chart:   &chart.Chart{},
+				Version: 2,
+			},
+		}
+	}
+
+	tests := []cmdTestCase{{
+		name:   "recover a stuck release",
+		cmd:    "release recover funny-honey --min-age 0s",
+		golden: "output/release-recover.txt",
+		rels:   stuck(release.StatusPendingUpgrade),
+	}, {
+		name:   "recover and roll back a stuck release",
+		cmd:    "release recover funny-honey --min-age 0s --rollback",
+		golden: "output/release-recover-rollback.txt",
+		rels:   stuck(release.StatusPendingUpgrade),
+	}, {
+		name:   "recover a stuck release in a dry run",
+		cmd:    "release recover funny-honey --min-age 0s --rollback --dry-run",
+		golden: "output/release-recover-dry-run.txt",
+		rels:   stuck(release.StatusPendingUpgrade),
+	}, {
+		name:      "recover a release that may still be running",
+		cmd:       "release recover funny-honey --min-age 1h",
+		golden:    "output/release-recover-too-young.txt",
+		rels:      stuck(release.StatusPendingUpgrade),
+		wantError: true,
+	}, {
+		name:      "recover a release that is not stuck",
+		cmd:       "release recover funny-honey",
+		golden:    "output/release-recover-not-stuck.txt",
+		rels:      stuck(release.StatusDeployed),
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
+
+func TestReleaseRecoverCompletion(t *testing.T) {
+	checkReleaseCompletion(t, "release recover", false)
+}
+
+func TestReleaseRecoverFileCompletion(t *testing.T) {
+	checkFileCompletion(t, "release recover", false)
+	checkFileCompletion(t, "release recover myrelease", false)
+}
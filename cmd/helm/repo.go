@@ -30,6 +30,45 @@ var repoHelm = `
 This command consists of multiple subcommands to interact with chart repositories.
 
 It can be used to add, remove, list, and index chart repositories.
+
+A repository can list mirrors, which are tried in order when it cannot be
+reached. The repositories file can also hold rules rewriting the URLs of
+repositories, for example to send requests to an internal proxy. The rules also
+apply to the repositories of chart dependencies. A rule ending with '*' matches
+every URL with that prefix. The credentials of a repository are only sent to
+the host of its URL, not to its mirrors or to the targets of rewrite rules,
+unless it passes its credentials to all domains (--pass-credentials):
+
+    repositories:
+    - name: example
+      url: https://charts.example.com
+      mirrors:
+      - https://charts-mirror.example.com
+    rewrites:
+    - from: https://charts.example.com/*
+      to: https://proxy.example.internal/charts/*
+
+Instead of a username and a password, a repository can get its credentials
+when they are needed, so that no secret is stored in the repositories file:
+from a credential helper command following the protocol of Docker credential
+helpers (--credential-helper), or from the credentials stored for a server in
+the registry credentials file by 'helm registry login' (--registry-credentials).
+
+Repositories behind token-authenticated gateways can be given a bearer token,
+static headers such as API keys, or an OAuth2 client credentials flow whose
+tokens are requested from the token endpoint and reused until they expire:
+
+    repositories:
+    - name: internal
+      url: https://charts.example.internal
+      headers:
+        X-Api-Key: ...
+      oauth2:
+        tokenURL: https://auth.example.internal/oauth2/token
+        clientID: helm
+        clientSecret: ...
+        scopes:
+        - charts:read
 `
 
 func newRepoCmd(out io.Writer) *cobra.Command {
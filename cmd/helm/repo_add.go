@@ -22,6 +22,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"time"
 
@@ -56,6 +57,10 @@ type repoAddOptions struct {
 	keyFile               string
 	caFile                string
 	insecureSkipTLSverify bool
+	mirrors               []string
+	credentialHelper      string
+	registryCredentials   string
+	jsonIndex             bool
 
 	repoFile  string
 	repoCache string
@@ -99,6 +104,10 @@ func newRepoAddCmd(out io.Writer) *cobra.Command {
 	f.BoolVar(&o.insecureSkipTLSverify, "insecure-skip-tls-verify", false, "skip tls certificate checks for the repository")
 	f.BoolVar(&o.allowDeprecatedRepos, "allow-deprecated-repos", false, "by default, this command will not allow adding official repos that have been permanently deleted. This disables that behavior")
 	f.BoolVar(&o.passCredentialsAll, "pass-credentials", false, "pass credentials to all domains")
+	f.StringVar(&o.credentialHelper, "credential-helper", "", "command providing the repository credentials when they are needed, following the protocol of Docker credential helpers")
+	f.StringVar(&o.registryCredentials, "registry-credentials", "", "use the credentials stored for this server in the registry credentials file")
+	f.BoolVar(&o.jsonIndex, "json-index", false, "download the compact JSON index of the repository, index.json, when it serves one")
+	f.StringArrayVar(&o.mirrors, "mirror", nil, "URL of a mirror of the repository, used when the repository cannot be reached. Can be specified multiple times, mirrors are tried in order")
 
 	return cmd
 }
@@ -180,6 +189,16 @@ func (o *repoAddOptions) run(out io.Writer) error {
 		CAFile:                o.caFile,
 		InsecureSkipTLSverify: o.insecureSkipTLSverify,
 	}
+	if len(o.mirrors) > 0 {
+		c.Mirrors = o.mirrors
+	}
+	c.CredentialHelper = o.credentialHelper
+	c.RegistryCredentials = o.registryCredentials
+	c.JSONIndex = o.jsonIndex
+
+	if c.CredentialHelper != "" && c.RegistryCredentials != "" {
+		return errors.New("--credential-helper and --registry-credentials cannot be used together")
+	}
 
 	// Check if the repo name is legal
 	if strings.Contains(o.name, "/") {
@@ -191,7 +210,7 @@ func (o *repoAddOptions) run(out io.Writer) error {
 	// 2. When the config is different require --force-update
 	if !o.forceUpdate && f.Has(o.name) {
 		existing := f.Get(o.name)
-		if c != *existing {
+		if !reflect.DeepEqual(c, *existing) {
 
 			// The input coming in for the name is different from what is already
 			// configured. Return an error.
@@ -211,6 +230,8 @@ func (o *repoAddOptions) run(out io.Writer) error {
 	if o.repoCache != "" {
 		r.CachePath = o.repoCache
 	}
+	r.Rewrites = f.Rewrites
+	r.RegistryConfig = settings.RegistryConfig
 	if _, err := r.DownloadIndexFile(); err != nil {
 		return errors.Wrapf(err, "looks like %q is not a valid chart repository or cannot be reached", o.url)
 	}
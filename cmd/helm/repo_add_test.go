@@ -134,6 +134,88 @@ func TestRepoAdd(t *testing.T) {
 	}
 }
 
+func TestRepoAddWithMirrors(t *testing.T) {
+	ts, err := repotest.NewTempServerWithCleanup(t, "testdata/testserver/*.*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ts.Stop()
+
+	rootDir := t.TempDir()
+	repoFile := filepath.Join(rootDir, "repositories.yaml")
+	os.Setenv(xdg.CacheHomeEnvVar, rootDir)
+
+	// The repository is down, so the index comes from the mirror.
+	o := &repoAddOptions{
+		name:     "mirrored",
+		url:      "http://127.0.0.1:1",
+		mirrors:  []string{ts.URL()},
+		repoFile: repoFile,
+	}
+	if err := o.run(io.Discard); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := repo.LoadFile(repoFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := f.Get("mirrored")
+	if entry == nil || len(entry.Mirrors) != 1 || entry.Mirrors[0] != ts.URL() {
+		t.Fatalf("expected the mirror to be saved, got %v", entry)
+	}
+
+	// Adding the same repository again is idempotent.
+	if err := o.run(io.Discard); err != nil {
+		t.Errorf("expected adding the same repository to succeed, got %s", err)
+	}
+	o.mirrors = nil
+	if err := o.run(io.Discard); err == nil {
+		t.Error("expected adding the repository without its mirrors to fail")
+	}
+}
+
+func TestRepoAddWithRegistryCredentials(t *testing.T) {
+	defer resetEnv()()
+
+	ts := repotest.NewTempServerWithCleanupAndBasicAuth(t, "testdata/testserver/*.*")
+	defer ts.Stop()
+
+	rootDir := t.TempDir()
+	repoFile := filepath.Join(rootDir, "repositories.yaml")
+	os.Setenv(xdg.CacheHomeEnvVar, rootDir)
+
+	settings.RegistryConfig = filepath.Join(rootDir, "config.json")
+	config := `{"auths":{"charts.example.com":{"username":"username","password":"password"}}}`
+	if err := os.WriteFile(settings.RegistryConfig, []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	o := &repoAddOptions{
+		name:                "authenticated",
+		url:                 ts.URL(),
+		registryCredentials: "charts.example.com",
+		repoFile:            repoFile,
+	}
+	if err := o.run(io.Discard); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := repo.LoadFile(repoFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry := f.Get("authenticated"); entry == nil || entry.RegistryCredentials != "charts.example.com" || entry.Password != "" {
+		t.Fatalf("expected a reference to the registry credentials to be saved, got %v", entry)
+	}
+
+	o.name = "both"
+	o.credentialHelper = "docker-credential-example"
+	if err := o.run(io.Discard); err == nil {
+		t.Error("expected an error when both a credential helper and registry credentials are given")
+	}
+}
+
 func TestRepoAddCheckLegalName(t *testing.T) {
 	ts, err := repotest.NewTempServerWithCleanup(t, "testdata/testserver/*.*")
 	if err != nil {
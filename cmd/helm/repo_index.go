@@ -39,13 +39,25 @@ To merge the generated index with an existing index file, use the '--merge'
 flag. In this case, the charts found in the current directory will be merged
 into the index passed in with --merge, with local charts taking priority over
 existing charts.
+
+To speed up indexing large directories, use the '--incremental' flag. The
+entries of the existing 'index.yaml' are then reused for the charts whose
+archive size and modification time did not change since the last indexing,
+which are recorded in '.index-state.json' next to the index. As the creation
+time of an entry is the modification time of its archive, the result is the
+same as indexing all the charts.
 `
 
+// indexStateFile is the name of the file recording the state of the archives
+// indexed by 'helm repo index --incremental'.
+const indexStateFile = ".index-state.json"
+
 type repoIndexOptions struct {
-	dir   string
-	url   string
-	merge string
-	json  bool
+	dir         string
+	url         string
+	merge       string
+	json        bool
+	incremental bool
 }
 
 func newRepoIndexCmd(out io.Writer) *cobra.Command {
@@ -74,6 +86,7 @@ func newRepoIndexCmd(out io.Writer) *cobra.Command {
 	f.StringVar(&o.url, "url", "", "url of chart repository")
 	f.StringVar(&o.merge, "merge", "", "merge the generated index into the given index")
 	f.BoolVar(&o.json, "json", false, "output in JSON format")
+	f.BoolVar(&o.incremental, "incremental", false, "only load the charts that changed since the last indexing of the directory")
 
 	return cmd
 }
@@ -84,16 +97,45 @@ func (i *repoIndexOptions) run(_ io.Writer) error {
 		return err
 	}
 
+	if i.incremental {
+		return indexIncremental(path, i.url, i.merge, i.json)
+	}
 	return index(path, i.url, i.merge, i.json)
 }
 
 func index(dir, url, mergeTo string, json bool) error {
-	out := filepath.Join(dir, "index.yaml")
-
 	i, err := repo.IndexDirectory(dir, url)
 	if err != nil {
 		return err
 	}
+	return writeIndex(i, dir, mergeTo, json)
+}
+
+func indexIncremental(dir, url, mergeTo string, json bool) error {
+	var previous *repo.IndexFile
+	if _, err := os.Stat(filepath.Join(dir, "index.yaml")); err == nil {
+		if previous, err = repo.LoadIndexFile(filepath.Join(dir, "index.yaml")); err != nil {
+			return errors.Wrap(err, "failed to load the existing index")
+		}
+	}
+	stateFile := filepath.Join(dir, indexStateFile)
+	state, err := repo.LoadIndexState(stateFile)
+	if err != nil {
+		return err
+	}
+
+	i, state, err := repo.IndexDirectoryIncremental(dir, url, previous, state)
+	if err != nil {
+		return err
+	}
+	if err := writeIndex(i, dir, mergeTo, json); err != nil {
+		return err
+	}
+	return state.WriteFile(stateFile, 0644)
+}
+
+func writeIndex(i *repo.IndexFile, dir, mergeTo string, json bool) error {
+	out := filepath.Join(dir, "index.yaml")
 	if mergeTo != "" {
 		// if index.yaml is missing then create an empty one to merge into
 		var i2 *repo.IndexFile
@@ -22,7 +22,9 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"helm.sh/helm/v3/pkg/repo"
 )
@@ -162,6 +164,71 @@ func TestRepoIndexCmd(t *testing.T) {
 	}
 }
 
+func TestRepoIndexCmdIncremental(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"compressedchart-0.1.0.tgz", "compressedchart-0.2.0.tgz", "reqtest-0.1.0.tgz"} {
+		if err := copyFile(filepath.Join("testdata/testcharts", name), filepath.Join(dir, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	destIndex := filepath.Join(dir, "index.yaml")
+
+	// readIndex reads the index, without its generation time.
+	readIndex := func() string {
+		t.Helper()
+		b, err := os.ReadFile(destIndex)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var lines []string
+		for _, line := range strings.Split(string(b), "\n") {
+			if !strings.HasPrefix(line, "generated:") {
+				lines = append(lines, line)
+			}
+		}
+		return strings.Join(lines, "\n")
+	}
+	run := func(args ...string) string {
+		t.Helper()
+		c := newRepoIndexCmd(io.Discard)
+		if err := c.ParseFlags(args); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.RunE(c, []string{dir}); err != nil {
+			t.Fatal(err)
+		}
+		return readIndex()
+	}
+
+	first := run("--incremental", "--url", "https://charts.example.com")
+	if _, err := os.Stat(filepath.Join(dir, indexStateFile)); err != nil {
+		t.Fatalf("expected the index state to be written: %s", err)
+	}
+	if full := run("--url", "https://charts.example.com"); full != first {
+		t.Errorf("expected the incremental index to match a full index:\n%s\ngot:\n%s", full, first)
+	}
+
+	// Add, remove and modify archives.
+	if err := os.Remove(filepath.Join(dir, "compressedchart-0.1.0.tgz")); err != nil {
+		t.Fatal(err)
+	}
+	if err := copyFile("testdata/testcharts/compressedchart-0.3.0.tgz", filepath.Join(dir, "compressedchart-0.3.0.tgz")); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, "reqtest-0.1.0.tgz"), later, later); err != nil {
+		t.Fatal(err)
+	}
+
+	incremental := run("--incremental", "--url", "https://charts.example.com")
+	if full := run("--url", "https://charts.example.com"); full != incremental {
+		t.Errorf("expected the incremental index to match a full index:\n%s\ngot:\n%s", full, incremental)
+	}
+	if incremental == first || strings.Contains(incremental, "compressedchart-0.1.0.tgz") {
+		t.Errorf("expected the index to be updated, got:\n%s", incremental)
+	}
+}
+
 func linkOrCopy(old, new string) error {
 	if err := os.Link(old, new); err != nil {
 		return copyFile(old, new)
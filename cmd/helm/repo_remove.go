@@ -86,6 +86,11 @@ func removeRepoCache(root, name string) error {
 		os.Remove(idx)
 	}
 
+	idx = filepath.Join(root, helmpath.CacheIndexValidatorsFile(name))
+	if _, err := os.Stat(idx); err == nil {
+		os.Remove(idx)
+	}
+
 	idx = filepath.Join(root, helmpath.CacheIndexFile(name))
 	if _, err := os.Stat(idx); os.IsNotExist(err) {
 		return nil
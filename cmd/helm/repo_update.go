@@ -32,6 +32,12 @@ import (
 const updateDesc = `
 Update gets the latest information about charts from the respective chart repositories.
 Information is cached locally, where it is used by commands like 'helm search'.
+Repositories whose index has not changed since the last update, according to
+the HTTP ETag or Last-Modified headers of the index, are not downloaded again.
+A repository served over HTTP may provide a compact JSON index as 'index.json'.
+It is downloaded instead of 'index.yaml' for repositories added with
+'--json-index', and for repositories that served it on the previous update.
+'index.yaml' is only downloaded when 'index.json' is not found.
 
 You can optionally specify a list of repositories you want to update.
 	$ helm repo update <repo_name> ...
@@ -107,6 +113,8 @@ func (o *repoUpdateOptions) run(out io.Writer) error {
 			if o.repoCache != "" {
 				r.CachePath = o.repoCache
 			}
+			r.Rewrites = f.Rewrites
+			r.RegistryConfig = settings.RegistryConfig
 			repos = append(repos, r)
 		}
 	}
@@ -122,11 +130,13 @@ func updateCharts(repos []*repo.ChartRepository, out io.Writer, failOnRepoUpdate
 		wg.Add(1)
 		go func(re *repo.ChartRepository) {
 			defer wg.Done()
-			if _, err := re.DownloadIndexFile(); err != nil {
+			if _, changed, err := re.UpdateIndexFile(); err != nil {
 				fmt.Fprintf(out, "...Unable to get an update from the %q chart repository (%s):\n\t%s\n", re.Config.Name, re.Config.URL, err)
 				repoFailList = append(repoFailList, re.Config.URL)
-			} else {
+			} else if changed {
 				fmt.Fprintf(out, "...Successfully got an update from the %q chart repository\n", re.Config.Name)
+			} else {
+				fmt.Fprintf(out, "...The %q chart repository is unchanged\n", re.Config.Name)
 			}
 		}(re)
 	}
@@ -156,6 +156,39 @@ func TestUpdateCharts(t *testing.T) {
 	}
 }
 
+func TestUpdateChartsUnchanged(t *testing.T) {
+	defer resetEnv()()
+	ensure.HelmHome(t)
+
+	ts, err := repotest.NewTempServerWithCleanup(t, "testdata/testserver/*.*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ts.Stop()
+
+	r, err := repo.NewChartRepository(&repo.Entry{
+		Name: "charts",
+		URL:  ts.URL(),
+	}, getter.All(settings))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The test server answers If-Modified-Since with the modification time
+	// of the index file.
+	b := bytes.NewBuffer(nil)
+	updateCharts([]*repo.ChartRepository{r}, b, false)
+	if got := b.String(); !strings.Contains(got, `...Successfully got an update from the "charts" chart repository`) {
+		t.Errorf("Expected the repository to be updated, got %q", got)
+	}
+
+	b.Reset()
+	updateCharts([]*repo.ChartRepository{r}, b, false)
+	if got := b.String(); !strings.Contains(got, `...The "charts" chart repository is unchanged`) {
+		t.Errorf("Expected the repository to be unchanged, got %q", got)
+	}
+}
+
 func TestRepoUpdateFileCompletion(t *testing.T) {
 	checkFileCompletion(t, "repo update", false)
 	checkFileCompletion(t, "repo update repo1", false)
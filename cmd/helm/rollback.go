@@ -20,12 +20,16 @@ import (
 	"fmt"
 	"io"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/pmezard/go-difflib/difflib"
 	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
 
 	"helm.sh/helm/v3/cmd/helm/require"
 	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/release"
 )
 
 const rollbackDesc = `
@@ -36,10 +40,24 @@ second is a revision (version) number. If this argument is omitted or set to
 0, it will roll back to the previous release.
 
 To see revision numbers, run 'helm history RELEASE'.
+
+Instead of a revision number, the revision to roll back to can be chosen by
+what it matches, with the '--to-status', '--to-label', '--to-chart-version'
+and '--to-app-version' flags. The release is rolled back to the last revision
+before the current one that matches all of them. As a deployed revision is
+superseded once it is replaced, '--to-status deployed' also matches superseded
+revisions:
+
+    $ helm rollback web --to-status deployed
+    $ helm rollback web --to-label approved=true
+
+With '--dry-run', the changes the rollback would make to the chart, values and
+manifests of the release are shown as a diff, without rolling back.
 `
 
 func newRollbackCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	client := action.NewRollback(cfg)
+	var toStatus string
 
 	cmd := &cobra.Command{
 		Use:   "rollback <RELEASE> [REVISION]",
@@ -65,8 +83,20 @@ func newRollbackCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 				}
 				client.Version = ver
 			}
+			client.ToStatus = release.Status(toStatus)
+
+			if client.DryRun {
+				current, previous, err := client.Preview(args[0])
+				if err != nil {
+					return err
+				}
+				return writeRollbackDiff(out, current, previous)
+			}
 
-			if err := client.Run(args[0]); err != nil {
+			done := reportWaitProgress(cfg, client.Wait)
+			err := client.Run(args[0])
+			done(err)
+			if err != nil {
 				return err
 			}
 
@@ -81,10 +111,62 @@ func newRollbackCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	f.BoolVar(&client.Force, "force", false, "force resource update through delete/recreate if needed")
 	f.BoolVar(&client.DisableHooks, "no-hooks", false, "prevent hooks from running during rollback")
 	f.DurationVar(&client.Timeout, "timeout", 300*time.Second, "time to wait for any individual Kubernetes operation (like Jobs for hooks)")
-	f.BoolVar(&client.Wait, "wait", false, "if set, will wait until all Pods, PVCs, Services, and minimum number of Pods of a Deployment, StatefulSet, or ReplicaSet are in a ready state before marking the release as successful. It will wait for as long as --timeout")
+	f.BoolVar(&client.Wait, "wait", false, "if set, will wait until all Pods, PVCs, Services, custom resources, and minimum number of Pods of a Deployment, StatefulSet, or ReplicaSet are in a ready state before marking the release as successful. It will wait for as long as --timeout")
 	f.BoolVar(&client.WaitForJobs, "wait-for-jobs", false, "if set and --wait enabled, will wait until all Jobs have been completed before marking the release as successful. It will wait for as long as --timeout")
 	f.BoolVar(&client.CleanupOnFail, "cleanup-on-fail", false, "allow deletion of new resources created in this rollback when rollback fails")
 	f.IntVar(&client.MaxHistory, "history-max", settings.MaxHistory, "limit the maximum number of revisions saved per release. Use 0 for no limit")
+	f.StringVar(&toStatus, "to-status", "", "roll back to the last revision with this status. 'deployed' also matches superseded revisions")
+	f.StringToStringVar(&client.ToLabels, "to-label", nil, "roll back to the last revision with this label (can specify multiple or separate values with commas: key1=val1,key2=val2)")
+	f.StringVar(&client.ToChartVersion, "to-chart-version", "", "roll back to the last revision made with this version of the chart")
+	f.StringVar(&client.ChangeCause, "change-cause", "", "record why the release is rolled back in its revision")
+	f.StringVar(&client.ToAppVersion, "to-app-version", "", "roll back to the last revision made with a chart of this app version")
 
 	return cmd
 }
+
+// writeRollbackDiff writes what rolling back from the current release to a
+// previous revision would change in its chart, values and manifests.
+func writeRollbackDiff(out io.Writer, current, previous *release.Release) error {
+	fmt.Fprintf(out, "Rolling back %s from revision %d to revision %d would make the following changes.\n", current.Name, current.Version, previous.Version)
+
+	from, to := formatChartName(current.Chart), formatChartName(previous.Chart)
+	if from == to {
+		fmt.Fprintf(out, "\nCHART: %s (no changes)\n", from)
+	} else {
+		fmt.Fprintf(out, "\nCHART: %s -> %s\n", from, to)
+	}
+
+	currentValues, err := yaml.Marshal(current.Config)
+	if err != nil {
+		return err
+	}
+	previousValues, err := yaml.Marshal(previous.Config)
+	if err != nil {
+		return err
+	}
+	if err := writeRevisionDiff(out, "VALUES", string(currentValues), string(previousValues), current.Version, previous.Version); err != nil {
+		return err
+	}
+	return writeRevisionDiff(out, "MANIFEST", current.Manifest, previous.Manifest, current.Version, previous.Version)
+}
+
+// writeRevisionDiff writes a unified diff of a part of two revisions of a
+// release.
+func writeRevisionDiff(out io.Writer, title, from, to string, fromRevision, toRevision int) error {
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(strings.TrimSuffix(from, "\n")),
+		B:        difflib.SplitLines(strings.TrimSuffix(to, "\n")),
+		FromFile: fmt.Sprintf("revision %d", fromRevision),
+		ToFile:   fmt.Sprintf("revision %d", toRevision),
+		Context:  3,
+	})
+	if err != nil {
+		return err
+	}
+	if diff == "" {
+		fmt.Fprintf(out, "\n%s: no changes\n", title)
+		return nil
+	}
+	fmt.Fprintf(out, "\n%s:\n%s", title, diff)
+	return nil
+}
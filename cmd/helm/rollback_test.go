@@ -41,6 +41,37 @@ func TestRollbackCmd(t *testing.T) {
 		},
 	}
 
+	chartVersion := func(version, appVersion string) *chart.Chart {
+		return &chart.Chart{Metadata: &chart.Metadata{Name: "web", Version: version, AppVersion: appVersion}}
+	}
+	history := []*release.Release{
+		{
+			Name:     "web",
+			Info:     &release.Info{Status: release.StatusSuperseded},
+			Chart:    chartVersion("0.1.0", "1.0"),
+			Config:   map[string]interface{}{"replicas": 2, "image": "web:1.0"},
+			Manifest: "kind: Deployment\nmetadata:\n  name: web\nspec:\n  replicas: 2\n",
+			Labels:   map[string]string{"approved": "true"},
+			Version:  1,
+		},
+		{
+			Name:     "web",
+			Info:     &release.Info{Status: release.StatusFailed},
+			Chart:    chartVersion("0.2.0", "1.1"),
+			Config:   map[string]interface{}{"replicas": 3, "image": "web:1.1"},
+			Manifest: "kind: Deployment\nmetadata:\n  name: web\nspec:\n  replicas: 3\n",
+			Version:  2,
+		},
+		{
+			Name:     "web",
+			Info:     &release.Info{Status: release.StatusDeployed},
+			Chart:    chartVersion("0.2.0", "1.1"),
+			Config:   map[string]interface{}{"replicas": 3, "image": "web:1.1"},
+			Manifest: "kind: Deployment\nmetadata:\n  name: web\nspec:\n  replicas: 3\n",
+			Version:  3,
+		},
+	}
+
 	tests := []cmdTestCase{{
 		name:   "rollback a release",
 		cmd:    "rollback funny-honey 1",
@@ -72,6 +103,27 @@ func TestRollbackCmd(t *testing.T) {
 		golden:    "output/rollback-non-existent-version.txt",
 		rels:      rels,
 		wantError: true,
+	}, {
+		name:   "rollback a release to the last deployed revision",
+		cmd:    "rollback web --to-status deployed",
+		golden: "output/rollback.txt",
+		rels:   history,
+	}, {
+		name:      "rollback a release to a revision with a missing label",
+		cmd:       "rollback web --to-label approved=false",
+		golden:    "output/rollback-no-matching-revision.txt",
+		rels:      history,
+		wantError: true,
+	}, {
+		name:   "preview the rollback of a release to a labelled revision",
+		cmd:    "rollback web --to-label approved=true --dry-run",
+		golden: "output/rollback-dry-run.txt",
+		rels:   history,
+	}, {
+		name:   "preview the rollback of a release without changes",
+		cmd:    "rollback web --to-chart-version 0.2.0 --dry-run",
+		golden: "output/rollback-dry-run-no-changes.txt",
+		rels:   history,
 	}, {
 		name:      "rollback a release without release name",
 		cmd:       "rollback",
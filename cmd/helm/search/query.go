@@ -0,0 +1,189 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package search
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+// Facets that can be used in a query, as facet:value.
+const (
+	// FacetKeyword matches charts with a keyword.
+	FacetKeyword = "keyword"
+	// FacetMaintainer matches charts with a maintainer whose name or email
+	// contains the value.
+	FacetMaintainer = "maintainer"
+	// FacetAnnotation matches charts with an annotation, given as key=value,
+	// or with an annotation key, given as key.
+	FacetAnnotation = "annotation"
+	// FacetAppVersion matches charts whose app version satisfies a semantic
+	// version constraint, such as >=2.0.
+	FacetAppVersion = "appVersion"
+	// FacetDeprecated matches deprecated charts if true, and other charts if
+	// false.
+	FacetDeprecated = "deprecated"
+	// FacetKubeVersion matches charts compatible with a Kubernetes version.
+	FacetKubeVersion = "kubeVersion"
+)
+
+var facets = []string{FacetKeyword, FacetMaintainer, FacetAnnotation, FacetAppVersion, FacetDeprecated, FacetKubeVersion}
+
+// Query is a search query, made of terms matched against the search index
+// and facets matched against the metadata of charts.
+//
+// For example, the query "postgres keyword:database appVersion:>=15" searches
+// for "postgres" in charts with the "database" keyword and an app version of
+// at least 15.
+type Query struct {
+	// Terms are the terms of the query that are not facets.
+	Terms []string
+	// Facets are the facets of the query, in the order they were given.
+	Facets []Facet
+}
+
+// Facet is a condition on the metadata of a chart.
+type Facet struct {
+	Name  string
+	Value string
+
+	constraint *semver.Constraints
+}
+
+// ParseQuery parses the arguments of a search into a query. An argument is a
+// facet if it starts with the name of a facet followed by a colon. Facet names
+// are not case sensitive.
+func ParseQuery(args []string) (*Query, error) {
+	q := &Query{}
+	for _, arg := range args {
+		var terms []string
+		hasFacets := false
+		for _, term := range strings.Fields(arg) {
+			f, ok, err := parseFacet(term)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				q.Facets = append(q.Facets, f)
+				hasFacets = true
+			} else {
+				terms = append(terms, term)
+			}
+		}
+		// Keep arguments without facets intact, as they may be regular
+		// expressions.
+		if !hasFacets {
+			terms = []string{arg}
+		}
+		q.Terms = append(q.Terms, terms...)
+	}
+	return q, nil
+}
+
+func parseFacet(term string) (Facet, bool, error) {
+	name, value, ok := strings.Cut(term, ":")
+	if !ok {
+		return Facet{}, false, nil
+	}
+	for _, facet := range facets {
+		if !strings.EqualFold(name, facet) {
+			continue
+		}
+		f := Facet{Name: facet, Value: value}
+		if value == "" {
+			return f, false, errors.Errorf("the %s facet requires a value", facet)
+		}
+		switch facet {
+		case FacetAppVersion:
+			c, err := semver.NewConstraint(value)
+			if err != nil {
+				return f, false, errors.Wrapf(err, "invalid %s constraint %q", facet, value)
+			}
+			f.constraint = c
+		case FacetDeprecated:
+			if _, err := strconv.ParseBool(value); err != nil {
+				return f, false, errors.Errorf("invalid %s value %q, expected true or false", facet, value)
+			}
+		case FacetKubeVersion:
+			if _, err := semver.NewVersion(value); err != nil {
+				return f, false, errors.Wrapf(err, "invalid %s %q", facet, value)
+			}
+		}
+		return f, true, nil
+	}
+	// Not a facet, so the colon is part of the term.
+	return Facet{}, false, nil
+}
+
+// Text returns the terms of the query, to search the index with.
+func (q *Query) Text() string {
+	return strings.Join(q.Terms, " ")
+}
+
+// Match checks that chart metadata satisfies all the facets of the query.
+func (q *Query) Match(md *chart.Metadata) bool {
+	for _, f := range q.Facets {
+		if !f.Match(md) {
+			return false
+		}
+	}
+	return true
+}
+
+// Match checks that chart metadata satisfies the facet.
+func (f Facet) Match(md *chart.Metadata) bool {
+	if md == nil {
+		return false
+	}
+	switch f.Name {
+	case FacetKeyword:
+		for _, k := range md.Keywords {
+			if strings.EqualFold(k, f.Value) {
+				return true
+			}
+		}
+	case FacetMaintainer:
+		value := strings.ToLower(f.Value)
+		for _, m := range md.Maintainers {
+			if m == nil {
+				continue
+			}
+			if strings.Contains(strings.ToLower(m.Name), value) || strings.Contains(strings.ToLower(m.Email), value) {
+				return true
+			}
+		}
+	case FacetAnnotation:
+		key, value, hasValue := strings.Cut(f.Value, "=")
+		v, ok := md.Annotations[key]
+		return ok && (!hasValue || v == value)
+	case FacetAppVersion:
+		v, err := semver.NewVersion(md.AppVersion)
+		return err == nil && f.constraint.Check(v)
+	case FacetDeprecated:
+		deprecated, _ := strconv.ParseBool(f.Value)
+		return md.Deprecated == deprecated
+	case FacetKubeVersion:
+		return md.KubeVersion == "" || chartutil.IsCompatibleRange(md.KubeVersion, f.Value)
+	}
+	return false
+}
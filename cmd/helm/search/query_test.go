@@ -0,0 +1,127 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package search
+
+import (
+	"testing"
+
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		args   []string
+		text   string
+		facets []string
+		err    bool
+	}{
+		{args: []string{"nginx"}, text: "nginx"},
+		{args: []string{"nginx", "keyword:web"}, text: "nginx", facets: []string{"keyword=web"}},
+		{args: []string{"nginx keyword:web AppVersion:>=1.0"}, text: "nginx", facets: []string{"keyword=web", "appVersion=>=1.0"}},
+		{args: []string{"annotation:category=storage"}, facets: []string{"annotation=category=storage"}},
+		// Arguments without facets are kept intact for regular expressions.
+		{args: []string{"a  b"}, text: "a  b"},
+		// Colons are kept in terms that are not facets.
+		{args: []string{"foo:bar"}, text: "foo:bar"},
+		{args: []string{"keyword:"}, err: true},
+		{args: []string{"appVersion:foo"}, err: true},
+		{args: []string{"deprecated:maybe"}, err: true},
+		{args: []string{"kubeVersion:latest"}, err: true},
+	}
+
+	for _, tt := range tests {
+		q, err := ParseQuery(tt.args)
+		if tt.err {
+			if err == nil {
+				t.Errorf("%v: expected an error", tt.args)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%v: unexpected error: %s", tt.args, err)
+			continue
+		}
+		if q.Text() != tt.text {
+			t.Errorf("%v: expected text %q, got %q", tt.args, tt.text, q.Text())
+		}
+		var facets []string
+		for _, f := range q.Facets {
+			facets = append(facets, f.Name+"="+f.Value)
+		}
+		if len(facets) != len(tt.facets) {
+			t.Errorf("%v: expected facets %v, got %v", tt.args, tt.facets, facets)
+			continue
+		}
+		for i := range facets {
+			if facets[i] != tt.facets[i] {
+				t.Errorf("%v: expected facets %v, got %v", tt.args, tt.facets, facets)
+			}
+		}
+	}
+}
+
+func TestQueryMatch(t *testing.T) {
+	md := &chart.Metadata{
+		Name:        "postgres",
+		Version:     "1.2.3",
+		AppVersion:  "15.2.0",
+		Keywords:    []string{"database", "SQL"},
+		Maintainers: []*chart.Maintainer{{Name: "Alice", Email: "alice@example.com"}},
+		Annotations: map[string]string{"category": "storage"},
+		KubeVersion: ">=1.25.0-0",
+	}
+
+	tests := []struct {
+		query string
+		match bool
+	}{
+		{"keyword:database", true},
+		{"keyword:sql", true},
+		{"keyword:web", false},
+		{"maintainer:alice", true},
+		{"maintainer:example.com", true},
+		{"maintainer:bob", false},
+		{"annotation:category=storage", true},
+		{"annotation:category", true},
+		{"annotation:category=network", false},
+		{"annotation:tier", false},
+		{"appVersion:>=15", true},
+		{"appVersion:<15", false},
+		{"deprecated:false", true},
+		{"deprecated:true", false},
+		{"kubeVersion:1.28.0", true},
+		{"kubeVersion:v1.24.3", false},
+		{"keyword:database maintainer:alice appVersion:^15", true},
+		{"keyword:database maintainer:bob", false},
+	}
+
+	for _, tt := range tests {
+		q, err := ParseQuery([]string{tt.query})
+		if err != nil {
+			t.Fatalf("%s: %s", tt.query, err)
+		}
+		if got := q.Match(md); got != tt.match {
+			t.Errorf("%s: expected match to be %t", tt.query, tt.match)
+		}
+	}
+
+	// Charts without a Kubernetes version constraint are compatible with any version.
+	q, _ := ParseQuery([]string{"kubeVersion:1.20.0"})
+	if !q.Match(&chart.Metadata{Name: "any"}) {
+		t.Error("expected a chart without kubeVersion to match")
+	}
+}
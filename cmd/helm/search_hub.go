@@ -19,13 +19,15 @@ package main
 import (
 	"fmt"
 	"io"
-	"strings"
+	"sync"
 
 	"github.com/gosuri/uitable"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
+	"helm.sh/helm/v3/cmd/helm/search"
 	"helm.sh/helm/v3/internal/monocular"
+	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/cli/output"
 )
 
@@ -47,6 +49,16 @@ Monocular search API. Similarly, when setting the 'endpoint' flag, the specified
 endpoint must also be implement a Monocular compatible search API endpoint.
 Note that when specifying a Monocular instance as the 'endpoint', rich queries
 are not supported. For API details, see https://github.com/helm/monocular
+
+Results can be narrowed down with the facets of 'helm search repo', which are
+matched against the latest version of each chart found:
+
+    $ helm search hub postgres maintainer:bitnami appVersion:'>=15'
+
+The hub search API does not return the annotations, deprecation or Kubernetes
+version constraint of charts, so the annotation:, deprecated: and kubeVersion:
+facets download the archive of each chart found to read them. Charts whose
+archive cannot be downloaded do not match these facets.
 `
 
 type searchHubOptions struct {
@@ -86,16 +98,74 @@ func (o *searchHubOptions) run(out io.Writer, args []string) error {
 		return errors.Wrap(err, fmt.Sprintf("unable to create connection to %q", o.searchEndpoint))
 	}
 
-	q := strings.Join(args, " ")
-	results, err := c.Search(q)
+	query, err := search.ParseQuery(args)
+	if err != nil {
+		return err
+	}
+
+	results, err := c.Search(query.Text())
 	if err != nil {
 		debug("%s", err)
 		return fmt.Errorf("unable to perform search against %q", o.searchEndpoint)
 	}
+	if len(query.Facets) > 0 {
+		metadata := hubMetadata(c, results, needsArchive(query))
+		matches := results[:0]
+		for i, r := range results {
+			if query.Match(metadata[i]) {
+				matches = append(matches, r)
+			}
+		}
+		results = matches
+	}
 
 	return o.outputFormat.Write(out, newHubSearchWriter(results, o.searchEndpoint, o.maxColWidth, o.listRepoURL, o.failOnNoResult))
 }
 
+// needsArchive reports whether the query has facets on metadata that the hub
+// search API does not return.
+func needsArchive(query *search.Query) bool {
+	for _, f := range query.Facets {
+		switch f.Name {
+		case search.FacetAnnotation, search.FacetDeprecated, search.FacetKubeVersion:
+			return true
+		}
+	}
+	return false
+}
+
+// hubMetadata returns the metadata of the latest version of each search
+// result. When fromArchive is set, it is read from the chart archives, a few
+// at a time. A result whose archive cannot be read has no metadata, so that it
+// matches no facet.
+func hubMetadata(c *monocular.Client, results []monocular.SearchResult, fromArchive bool) []*chart.Metadata {
+	metadata := make([]*chart.Metadata, len(results))
+	if !fromArchive {
+		for i, r := range results {
+			metadata[i] = r.Metadata()
+		}
+		return metadata
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, 8)
+	for i, r := range results {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, r monocular.SearchResult) {
+			defer func() { <-sem; wg.Done() }()
+			md, err := c.ChartMetadata(r)
+			if err != nil {
+				debug("unable to read the metadata of %s: %s", r.ID, err)
+				return
+			}
+			metadata[i] = md
+		}(i, r)
+	}
+	wg.Wait()
+	return metadata
+}
+
 type hubChartRepo struct {
 	URL  string `json:"url"`
 	Name string `json:"name"`
@@ -20,7 +20,12 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
 )
 
 func TestSearchHubCmd(t *testing.T) {
@@ -185,3 +190,83 @@ func TestSearchHubCmd_FailOnNoResponseTests(t *testing.T) {
 		})
 	}
 }
+
+func TestSearchHubFacetCmd(t *testing.T) {
+
+	// Setup a mock search service
+	var searchResult = `{"data":[{"id":"stable/phpmyadmin","type":"chart","attributes":{"name":"phpmyadmin","repo":{"name":"stable","url":"https://charts.helm.sh/stable"},"description":"phpMyAdmin is an mysql administration frontend","keywords":["mariadb","mysql","phpmyadmin"],"maintainers":[{"name":"Bitnami","email":"containers@bitnami.com"}]},"relationships":{"latestChartVersion":{"data":{"version":"3.0.0","app_version":"4.9.0-1"}}}},{"id":"stable/mariadb","type":"chart","attributes":{"name":"mariadb","repo":{"name":"stable","url":"https://charts.helm.sh/stable"},"description":"Fast, reliable, scalable, and easy to use open-source relational database system","keywords":["mariadb","mysql","database"],"maintainers":[{"name":"Someone","email":"someone@example.com"}]},"relationships":{"latestChartVersion":{"data":{"version":"7.0.0","app_version":"10.3.18"}}}}]}`
+	var query string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query().Get("q")
+		fmt.Fprintln(w, searchResult)
+	}))
+	defer ts.Close()
+
+	tests := []string{
+		"maria keyword:database",
+		"maria maintainer:example.com",
+		"maria appVersion:'>=10'",
+	}
+	for _, tt := range tests {
+		testcmd := "search hub --endpoint " + ts.URL + " " + tt
+		_, out, err := executeActionCommandC(storageFixture(), testcmd)
+		if err != nil {
+			t.Errorf("%s: unexpected error, %s", tt, err)
+		}
+		if !strings.Contains(out, ts.URL+"/charts/stable/mariadb") || strings.Contains(out, "phpmyadmin") {
+			t.Errorf("%s: expected only stable/mariadb to match, got %s", tt, out)
+		}
+		if query != "maria" {
+			t.Errorf("%s: expected the facets not to be sent to the hub, got %q", tt, query)
+		}
+	}
+
+}
+
+func TestSearchHubArchiveFacetCmd(t *testing.T) {
+	// The hub does not return annotations, deprecation or Kubernetes version
+	// constraints, which are read from the chart archives.
+	dir := t.TempDir()
+	for _, md := range []*chart.Metadata{
+		{Name: "phpmyadmin", Version: "3.0.0", Deprecated: true, KubeVersion: "<1.20.0"},
+		{Name: "mariadb", Version: "7.0.0", KubeVersion: ">=1.20.0", Annotations: map[string]string{"category": "Database"}},
+	} {
+		md.APIVersion = chart.APIVersionV2
+		if _, err := chartutil.Save(&chart.Chart{Metadata: md}, dir); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var searchResult = `{"data":[{"id":"stable/phpmyadmin","type":"chart","attributes":{"name":"phpmyadmin","repo":{"name":"stable","url":"{{URL}}/stable"},"description":"phpMyAdmin is an mysql administration frontend"},"relationships":{"latestChartVersion":{"data":{"version":"3.0.0","urls":["{{URL}}/archives/phpmyadmin-3.0.0.tgz"]}}}},{"id":"stable/mariadb","type":"chart","attributes":{"name":"mariadb","repo":{"name":"stable","url":"{{URL}}/archives"},"description":"Fast, reliable, scalable, and easy to use open-source relational database system"},"relationships":{"latestChartVersion":{"data":{"version":"7.0.0","urls":["mariadb-7.0.0.tgz"]}}}},{"id":"stable/missing","type":"chart","attributes":{"name":"missing","repo":{"name":"stable","url":"{{URL}}/archives"},"description":"A chart without an archive"},"relationships":{"latestChartVersion":{"data":{"version":"1.0.0","urls":["missing-1.0.0.tgz"]}}}}]}`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if name, ok := strings.CutPrefix(r.URL.Path, "/archives/"); ok {
+			http.ServeFile(w, r, filepath.Join(dir, filepath.Base(name)))
+			return
+		}
+		fmt.Fprintln(w, strings.ReplaceAll(searchResult, "{{URL}}", "http://"+r.Host))
+	}))
+	defer ts.Close()
+
+	tests := []struct {
+		query  string
+		expect string
+	}{
+		{"maria annotation:category=Database", "stable/mariadb"},
+		{"maria deprecated:true", "stable/phpmyadmin"},
+		{"maria deprecated:false", "stable/mariadb"},
+		{"maria kubeVersion:1.28.0", "stable/mariadb"},
+		{"maria kubeVersion:1.18.0", "stable/phpmyadmin"},
+	}
+	for _, tt := range tests {
+		testcmd := "search hub --endpoint " + ts.URL + " " + tt.query
+		_, out, err := executeActionCommandC(storageFixture(), testcmd)
+		if err != nil {
+			t.Errorf("%s: unexpected error, %s", tt.query, err)
+		}
+		for _, id := range []string{"stable/phpmyadmin", "stable/mariadb", "stable/missing"} {
+			if strings.Contains(out, id) != (id == tt.expect) {
+				t.Errorf("%s: expected only %s to match, got %s", tt.query, tt.expect, out)
+			}
+		}
+	}
+}
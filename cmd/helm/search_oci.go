@@ -0,0 +1,276 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v3/cmd/helm/require"
+	"helm.sh/helm/v3/cmd/helm/search"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/helmpath"
+	"helm.sh/helm/v3/pkg/registry"
+	"helm.sh/helm/v3/pkg/repo"
+)
+
+const searchOCIDesc = `
+Search for Helm charts stored in an OCI registry.
+
+The repositories under the given registry path are listed with the catalog API
+of the registry, and their tags with the tag list API. The description and the
+other metadata of a chart are read from the config of its manifest, without
+downloading the chart. Registries that do not offer the catalog API can still
+be searched by giving the full path of a chart repository.
+
+It will display the latest stable versions of the charts found. If you
+specify the --devel flag, the output will include pre-release versions.
+If you want to search using a version constraint, use --version.
+
+Chart metadata is cached by manifest digest in the repository cache, so that
+later searches only fetch the charts that changed.
+
+Examples:
+
+    # List the latest stable versions of the charts under an organization
+    $ helm search oci oci://registry.example.com/org
+
+    # Search for charts matching the keyword "nginx", including pre-release versions
+    $ helm search oci oci://registry.example.com/org nginx --devel
+
+    # List every version of a chart with a major version of 1
+    $ helm search oci oci://registry.example.com/org/nginx --versions --version ^1.0.0
+
+The same facets as 'helm search repo' can be used to narrow down the search.
+`
+
+type searchOCIOptions struct {
+	searchRepoOptions
+	certFile              string
+	keyFile               string
+	caFile                string
+	insecureSkipTLSverify bool
+	plainHTTP             bool
+}
+
+func newSearchOCICmd(out io.Writer) *cobra.Command {
+	o := &searchOCIOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "oci [registry] [keyword]",
+		Short: "search an OCI registry for a keyword in charts",
+		Long:  searchOCIDesc,
+		Args:  require.MinimumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			o.repoCacheDir = settings.RepositoryCache
+			return o.run(out, args[0], args[1:])
+		},
+	}
+
+	f := cmd.Flags()
+	f.BoolVarP(&o.regexp, "regexp", "r", false, "use regular expressions for searching charts")
+	f.BoolVarP(&o.versions, "versions", "l", false, "show the long listing, with each version of each chart on its own line")
+	f.BoolVar(&o.devel, "devel", false, "use development versions (alpha, beta, and release candidate releases), too. Equivalent to version '>0.0.0-0'. If --version is set, this is ignored")
+	f.StringVar(&o.version, "version", "", "search using semantic versioning constraints")
+	f.UintVar(&o.maxColWidth, "max-col-width", 50, "maximum column width for output table")
+	f.BoolVar(&o.failOnNoResult, "fail-on-no-result", false, "search fails if no results are found")
+	f.StringVar(&o.certFile, "cert-file", "", "identify registry client using this SSL certificate file")
+	f.StringVar(&o.keyFile, "key-file", "", "identify registry client using this SSL key file")
+	f.StringVar(&o.caFile, "ca-file", "", "verify certificates of HTTPS-enabled servers using this CA bundle")
+	f.BoolVar(&o.insecureSkipTLSverify, "insecure-skip-tls-verify", false, "skip tls certificate checks for the registry")
+	f.BoolVar(&o.plainHTTP, "plain-http", false, "use insecure HTTP connections for the registry")
+
+	bindOutputFlag(cmd, &o.outputFormat)
+
+	return cmd
+}
+
+func (o *searchOCIOptions) run(out io.Writer, remote string, args []string) error {
+	if !registry.IsOCI(remote) {
+		return errors.Errorf("invalid registry %q, expected an oci:// reference", remote)
+	}
+	host, root, _ := strings.Cut(strings.Trim(strings.TrimPrefix(remote, fmt.Sprintf("%s://", registry.OCIScheme)), "/"), "/")
+	if host == "" {
+		return errors.Errorf("invalid registry %q, expected an oci:// reference", remote)
+	}
+
+	o.setupSearchedVersion()
+	constraint, err := semver.NewConstraint(o.version)
+	if err != nil {
+		return errors.Wrap(err, "an invalid version/constraint format")
+	}
+
+	query, err := search.ParseQuery(args)
+	if err != nil {
+		return err
+	}
+
+	client, err := newRegistryClient(o.certFile, o.keyFile, o.caFile, o.insecureSkipTLSverify, o.plainHTTP)
+	if err != nil {
+		return fmt.Errorf("missing registry client: %w", err)
+	}
+
+	repositories, err := ociRepositories(client, host, root)
+	if err != nil {
+		return err
+	}
+
+	cacheFile := filepath.Join(o.repoCacheDir, helmpath.CacheOCIMetadataFile(host))
+	cache := loadOCIMetadataCache(cacheFile)
+	cached := len(cache)
+
+	ind := repo.NewIndexFile()
+	for _, repository := range repositories {
+		ref := fmt.Sprintf("%s/%s", host, repository)
+		tags, err := client.Tags(ref)
+		if err != nil {
+			warning("failed to list the tags of %s: %s", ref, err)
+			continue
+		}
+		// Tags are sorted from the newest version, so the latest matching
+		// version comes first. The facets are matched here, so that an older
+		// version is found when the latest one does not match them.
+		for _, tag := range tags {
+			v, err := semver.NewVersion(tag)
+			if err != nil || !constraint.Check(v) {
+				continue
+			}
+			cv, err := ociChartVersion(client, cache, ref, tag)
+			if err != nil {
+				warning("failed to read the metadata of %s:%s: %s", ref, tag, err)
+				continue
+			}
+			if !query.Match(cv.Metadata) {
+				continue
+			}
+			ind.Entries[repository] = append(ind.Entries[repository], cv)
+			if !o.versions {
+				break
+			}
+		}
+	}
+
+	if len(cache) != cached {
+		if err := writeOCIMetadataCache(cacheFile, cache); err != nil {
+			warning("failed to cache chart metadata: %s", err)
+		}
+	}
+
+	index := search.NewIndex()
+	index.AddRepo(host, ind, true)
+
+	var res []*search.Result
+	if len(query.Terms) == 0 {
+		res = index.All()
+	} else {
+		res, err = index.Search(query.Text(), searchMaxScore, o.regexp)
+		if err != nil {
+			return err
+		}
+	}
+	for _, r := range res {
+		r.Name = fmt.Sprintf("%s://%s", registry.OCIScheme, r.Name)
+	}
+
+	search.SortScore(res)
+	data, err := o.applyConstraint(res)
+	if err != nil {
+		return err
+	}
+
+	return o.outputFormat.Write(out, &repoSearchWriter{data, o.maxColWidth, o.failOnNoResult})
+}
+
+// ociRepositories returns the repositories of a registry under the root path.
+// When the registry does not offer the catalog API, the root path is
+// searched as a repository.
+func ociRepositories(client *registry.Client, host, root string) ([]string, error) {
+	catalog, err := client.Repositories(host)
+	if errors.Is(err, registry.ErrCatalogUnsupported) && root != "" {
+		debug("%s, searching %s as a repository", err, root)
+		return []string{root}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if root == "" {
+		return catalog, nil
+	}
+
+	var repositories []string
+	for _, repository := range catalog {
+		if repository == root || strings.HasPrefix(repository, root+"/") {
+			repositories = append(repositories, repository)
+		}
+	}
+	return repositories, nil
+}
+
+// ociChartVersion returns the chart version of a tag, with the metadata from
+// the cache if the tag still points to the same manifest.
+func ociChartVersion(client *registry.Client, cache map[string]*chart.Metadata, ref, tag string) (*repo.ChartVersion, error) {
+	digest, err := client.Resolve(fmt.Sprintf("%s:%s", ref, tag))
+	if err != nil {
+		return nil, err
+	}
+	meta, ok := cache[digest]
+	if !ok {
+		if _, meta, err = client.ChartMetadata(fmt.Sprintf("%s@%s", ref, digest)); err != nil {
+			return nil, err
+		}
+		cache[digest] = meta
+	}
+	return &repo.ChartVersion{
+		Metadata: meta,
+		URLs:     []string{fmt.Sprintf("%s://%s:%s", registry.OCIScheme, ref, tag)},
+		Digest:   digest,
+	}, nil
+}
+
+// loadOCIMetadataCache loads the chart metadata cached by manifest digest. A
+// missing or corrupt cache is treated as empty.
+func loadOCIMetadataCache(path string) map[string]*chart.Metadata {
+	cache := map[string]*chart.Metadata{}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cache
+	}
+	if err := json.Unmarshal(data, &cache); err != nil {
+		debug("ignoring corrupt chart metadata cache %s: %s", path, err)
+		return map[string]*chart.Metadata{}
+	}
+	return cache
+}
+
+func writeOCIMetadataCache(path string, cache map[string]*chart.Metadata) error {
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,175 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/helmpath"
+	"helm.sh/helm/v3/pkg/repo/repotest"
+)
+
+func TestSearchOCICmd(t *testing.T) {
+	srv, err := repotest.NewTempServerWithCleanup(t, "testdata/testcharts/*.tgz*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Stop()
+
+	ociSrv, err := repotest.NewOCIServer(t, srv.Root())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ociSrv.Run(t)
+
+	cacheDir := t.TempDir()
+	flags := fmt.Sprintf("--plain-http --registry-config %s --repository-cache %s", filepath.Join(srv.Root(), "config.json"), cacheDir)
+	remote := fmt.Sprintf("oci://%s/u/ocitestuser", ociSrv.RegistryURL)
+
+	var charts []string
+	for _, name := range []string{"signtest-0.1.0.tgz", "reqtest-0.1.0.tgz", "compressedchart-0.1.0.tgz", "compressedchart-0.2.0.tgz", "compressedchart-0.3.0.tgz"} {
+		charts = append(charts, filepath.Join("testdata/testcharts", name))
+	}
+	// The latest version of a chart is deprecated, but not an older one.
+	for _, md := range []*chart.Metadata{
+		{Name: "facetchart", Version: "1.0.0"},
+		{Name: "facetchart", Version: "2.0.0", Deprecated: true},
+	} {
+		md.APIVersion = chart.APIVersionV2
+		archive, err := chartutil.Save(&chart.Chart{Metadata: md}, t.TempDir())
+		if err != nil {
+			t.Fatal(err)
+		}
+		charts = append(charts, archive)
+	}
+	for _, archive := range charts {
+		push := fmt.Sprintf("push %s %s %s", archive, remote, flags)
+		if _, _, err := executeActionCommand(push); err != nil {
+			t.Fatalf("failed to push %s: %s", archive, err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		cmd       string
+		expect    []string
+		reject    []string
+		wantError bool
+	}{
+		{
+			name: "search lists the latest version of every chart",
+			cmd:  "search oci " + remote,
+			expect: []string{
+				remote + "/compressedchart\t0.3.0",
+				remote + "/oci-dependent-chart\t0.1.0",
+				remote + "/signtest\t0.1.0",
+				"A Helm chart for Kubernetes",
+			},
+			reject: []string{"0.2.0"},
+		},
+		{
+			name:   "search with a keyword",
+			cmd:    "search oci " + remote + " compressed",
+			expect: []string{remote + "/compressedchart"},
+			reject: []string{"signtest"},
+		},
+		{
+			name:   "search all versions matching a constraint",
+			cmd:    "search oci " + remote + "/compressedchart --versions --version '>=0.2.0'",
+			expect: []string{"0.2.0", "0.3.0"},
+			reject: []string{"0.1.0"},
+		},
+		{
+			name:   "search a single repository",
+			cmd:    "search oci " + remote + "/signtest",
+			expect: []string{remote + "/signtest\t0.1.0"},
+			reject: []string{"compressedchart"},
+		},
+		{
+			name:   "search with a facet",
+			cmd:    "search oci " + remote + " deprecated:true",
+			expect: []string{remote + "/facetchart\t2.0.0"},
+			reject: []string{"compressedchart", "signtest"},
+		},
+		{
+			name:   "search the latest version matching a facet",
+			cmd:    "search oci " + remote + "/facetchart deprecated:false",
+			expect: []string{remote + "/facetchart\t1.0.0"},
+			reject: []string{"2.0.0"},
+		},
+		{
+			name:   "search with json output",
+			cmd:    "search oci " + remote + "/reqtest --output json",
+			expect: []string{`[{"name":"` + remote + `/reqtest","version":"0.1.0"`},
+		},
+		{
+			name:      "search fails for a non OCI reference",
+			cmd:       "search oci https://example.com/charts",
+			expect:    []string{"expected an oci:// reference"},
+			wantError: true,
+		},
+		{
+			name:      "search fails with an invalid constraint",
+			cmd:       "search oci " + remote + " --version nope",
+			expect:    []string{"an invalid version/constraint format"},
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, out, err := executeActionCommand(tt.cmd + " --max-col-width 200 " + flags)
+			if tt.wantError {
+				if err == nil {
+					t.Fatalf("expected an error, got %q", out)
+				}
+				for _, e := range tt.expect {
+					if !strings.Contains(err.Error(), e) {
+						t.Errorf("expected error containing %q, got %q", e, err)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			// Columns are padded, so compare without padding.
+			normalized := strings.Join(strings.Fields(strings.ReplaceAll(out, "\t", " \t ")), " ")
+			for _, e := range tt.expect {
+				e = strings.Join(strings.Fields(strings.ReplaceAll(e, "\t", " \t ")), " ")
+				if !strings.Contains(normalized, e) {
+					t.Errorf("expected %q in %q", e, out)
+				}
+			}
+			for _, r := range tt.reject {
+				if strings.Contains(out, r) {
+					t.Errorf("did not expect %q in %q", r, out)
+				}
+			}
+		})
+	}
+
+	if _, err := os.Stat(filepath.Join(cacheDir, helmpath.CacheOCIMetadataFile(ociSrv.RegistryURL))); err != nil {
+		t.Errorf("expected chart metadata to be cached: %s", err)
+	}
+}
@@ -56,6 +56,19 @@ Examples:
     # Search for the latest stable release for nginx-ingress with a major version of 1
     $ helm search repo nginx-ingress --version ^1.0.0
 
+    # Search for charts with the "database" keyword and an app version of at least 2.0
+    $ helm search repo keyword:database appVersion:'>=2.0'
+
+Besides keywords, the search can be narrowed down with facets on the metadata of
+charts, which are combined with the other search terms and '--version':
+
+    keyword:KEYWORD          charts with the given keyword
+    maintainer:NAME          charts with a maintainer whose name or email contains NAME
+    annotation:KEY[=VALUE]   charts with the given annotation
+    appVersion:CONSTRAINT    charts whose app version satisfies a semver constraint
+    deprecated:true|false    deprecated charts, or charts that are not deprecated
+    kubeVersion:VERSION      charts compatible with the given Kubernetes version
+
 Repositories are managed with 'helm repo' commands.
 `
 
@@ -109,16 +122,21 @@ func (o *searchRepoOptions) run(out io.Writer, args []string) error {
 		return err
 	}
 
+	query, err := search.ParseQuery(args)
+	if err != nil {
+		return err
+	}
+
 	var res []*search.Result
-	if len(args) == 0 {
+	if len(query.Terms) == 0 {
 		res = index.All()
 	} else {
-		q := strings.Join(args, " ")
-		res, err = index.Search(q, searchMaxScore, o.regexp)
+		res, err = index.Search(query.Text(), searchMaxScore, o.regexp)
 		if err != nil {
 			return err
 		}
 	}
+	res = filterFacets(res, query)
 
 	search.SortScore(res)
 	data, err := o.applyConstraint(res)
@@ -129,6 +147,20 @@ func (o *searchRepoOptions) run(out io.Writer, args []string) error {
 	return o.outputFormat.Write(out, &repoSearchWriter{data, o.maxColWidth, o.failOnNoResult})
 }
 
+// filterFacets keeps the results whose chart satisfies the facets of the query.
+func filterFacets(res []*search.Result, query *search.Query) []*search.Result {
+	if len(query.Facets) == 0 {
+		return res
+	}
+	data := res[:0]
+	for _, r := range res {
+		if query.Match(r.Chart.Metadata) {
+			data = append(data, r)
+		}
+	}
+	return data
+}
+
 func (o *searchRepoOptions) setupSearchedVersion() {
 	debug("Original chart version: %q", o.version)
 
@@ -86,6 +86,30 @@ func TestSearchRepositoriesCmd(t *testing.T) {
 		name:   "search for 'alpine', expect valid yaml output",
 		cmd:    "search repo alpine --output yaml",
 		golden: "output/search-output-yaml.txt",
+	}, {
+		name:   "search for charts with the 'database' keyword, expect one match",
+		cmd:    "search repo keyword:database",
+		golden: "output/search-facet-keyword.txt",
+	}, {
+		name:   "search for charts maintained by 'bitnami', expect one match",
+		cmd:    "search repo maintainer:bitnami",
+		golden: "output/search-facet-keyword.txt",
+	}, {
+		name:   "search for deprecated 'alpine' versions, expect one match with version 0.1.0",
+		cmd:    "search repo alpine deprecated:true --versions",
+		golden: "output/search-facet-deprecated.txt",
+	}, {
+		name:   "search for 'alpine' with an app version constraint and --devel, expect one match with version 0.3.0-rc.1",
+		cmd:    "search repo alpine appVersion:'>=3.0' --devel",
+		golden: "output/search-facet-app-version.txt",
+	}, {
+		name:   "search for 'alpine' with an app version constraint and no matching stable version, expect no matches",
+		cmd:    "search repo alpine appVersion:'>=3.0'",
+		golden: "output/search-not-found.txt",
+	}, {
+		name:      "search with an invalid app version constraint, expect failure",
+		cmd:       "search repo appVersion:foo",
+		wantError: true,
 	}}
 
 	settings.Debug = true
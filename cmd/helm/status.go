@@ -44,6 +44,7 @@ The status consists of:
 - state of the release (can be: unknown, deployed, uninstalled, superseded, failed, uninstalling, pending-install, pending-upgrade or pending-rollback)
 - revision of the release
 - description of the release (can be completion message or error message, need to enable --show-desc)
+- who made the revision, from where and why, if recorded
 - list of resources that this release consists of (need to enable --show-resources)
 - details on last test suite run, if applicable
 - additional notes provided by the chart
@@ -80,7 +81,7 @@ func newStatusCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 			// strip chart metadata from the output
 			rel.Chart = nil
 
-			return outfmt.Write(out, &statusPrinter{rel, false, client.ShowDescription, client.ShowResources, false, false})
+			return outfmt.Write(out, &statusPrinter{rel, false, client.ShowDescription, client.ShowResources, false, false, true})
 		},
 	}
 
@@ -114,6 +115,7 @@ type statusPrinter struct {
 	showResources   bool
 	showMetadata    bool
 	hideNotes       bool
+	showOrigin      bool
 }
 
 func (s statusPrinter) WriteJSON(out io.Writer) error {
@@ -143,6 +145,19 @@ func (s statusPrinter) WriteTable(out io.Writer) error {
 	if s.showDescription {
 		_, _ = fmt.Fprintf(out, "DESCRIPTION: %s\n", s.release.Info.Description)
 	}
+	if origin := s.release.Info.Origin; s.showOrigin && origin != nil {
+		for _, field := range []struct{ name, value string }{
+			{"DEPLOYED BY", origin.User},
+			{"HOSTNAME", origin.Hostname},
+			{"HELM VERSION", origin.HelmVersion},
+			{"CHART SOURCE", origin.ChartSource},
+			{"CHANGE CAUSE", origin.ChangeCause},
+		} {
+			if field.value != "" {
+				_, _ = fmt.Fprintf(out, "%s: %s\n", field.name, field.value)
+			}
+		}
+	}
 
 	if s.showResources && s.release.Info.Resources != nil && len(s.release.Info.Resources) > 0 {
 		buf := new(bytes.Buffer)
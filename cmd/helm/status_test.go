@@ -52,6 +52,20 @@ func TestStatusCmd(t *testing.T) {
 			Status:      release.StatusDeployed,
 			Description: "Mock description",
 		}),
+	}, {
+		name:   "get status of a deployed release, with origin",
+		cmd:    "status flummoxed-chickadee",
+		golden: "output/status-with-origin.txt",
+		rels: releasesMockWithStatus(&release.Info{
+			Status: release.StatusDeployed,
+			Origin: &release.Origin{
+				User:        "system:serviceaccount:ci:deployer",
+				Hostname:    "ci-runner-7",
+				HelmVersion: "v3.17.0",
+				ChartSource: "oci://registry.example.com/charts/name@sha256:0123456789abcdef",
+				ChangeCause: "roll out 3.2.1",
+			},
+		}),
 	}, {
 		name:   "get status of a deployed release with notes",
 		cmd:    "status flummoxed-chickadee",
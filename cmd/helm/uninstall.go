@@ -19,12 +19,16 @@ package main
 import (
 	"fmt"
 	"io"
+	"strings"
 	"time"
 
+	"github.com/gosuri/uitable"
 	"github.com/spf13/cobra"
 
 	"helm.sh/helm/v3/cmd/helm/require"
 	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/cli/output"
+	"helm.sh/helm/v3/pkg/release"
 )
 
 const uninstallDesc = `
@@ -34,11 +38,16 @@ It removes all of the resources associated with the last release of the chart
 as well as the release history, freeing it up for future use.
 
 Use the '--dry-run' flag to see which releases will be uninstalled without actually
-uninstalling them.
+uninstalling them. It prints the plan of each release: the resources that
+would be deleted, in deletion order, the resources that would be kept because
+of their 'helm.sh/resource-policy: keep' annotation, the hooks that would run
+and the namespaces affected. Use '--output json' or '--output yaml' to get the
+plans in a machine-readable format.
 `
 
 func newUninstallCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	client := action.NewUninstall(cfg)
+	var outfmt output.Format
 
 	cmd := &cobra.Command{
 		Use:        "uninstall RELEASE_NAME [...]",
@@ -55,18 +64,28 @@ func newUninstallCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 			if validationErr != nil {
 				return validationErr
 			}
+			var plans uninstallPlans
 			for i := 0; i < len(args); i++ {
 
 				res, err := client.Run(args[i])
 				if err != nil {
 					return err
 				}
+				if client.DryRun {
+					if res != nil && res.Plan != nil {
+						plans = append(plans, res.Plan)
+					}
+					continue
+				}
 				if res != nil && res.Info != "" {
 					fmt.Fprintln(out, res.Info)
 				}
 
 				fmt.Fprintf(out, "release \"%s\" uninstalled\n", args[i])
 			}
+			if client.DryRun {
+				return outfmt.Write(out, plans)
+			}
 			return nil
 		},
 	}
@@ -80,10 +99,76 @@ func newUninstallCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	f.StringVar(&client.DeletionPropagation, "cascade", "background", "Must be \"background\", \"orphan\", or \"foreground\". Selects the deletion cascading strategy for the dependents. Defaults to background.")
 	f.DurationVar(&client.Timeout, "timeout", 300*time.Second, "time to wait for any individual Kubernetes operation (like Jobs for hooks)")
 	f.StringVar(&client.Description, "description", "", "add a custom description")
+	bindOutputFlag(cmd, &outfmt)
 
 	return cmd
 }
 
+// uninstallPlans are the plans of the releases uninstalled in a dry run.
+type uninstallPlans []*release.UninstallPlan
+
+func (p uninstallPlans) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, p)
+}
+
+func (p uninstallPlans) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, p)
+}
+
+func (p uninstallPlans) WriteTable(out io.Writer) error {
+	for i, plan := range p {
+		if i > 0 {
+			fmt.Fprintln(out)
+		}
+		fmt.Fprintf(out, "RELEASE: %s\n", plan.Name)
+		fmt.Fprintf(out, "NAMESPACE: %s\n", plan.Namespace)
+		if len(plan.Namespaces) > 0 {
+			fmt.Fprintf(out, "AFFECTED NAMESPACES: %s\n", strings.Join(plan.Namespaces, ", "))
+		}
+
+		fmt.Fprintln(out, "\nRESOURCES TO DELETE (in order):")
+		if err := writePlannedResources(out, plan.Delete); err != nil {
+			return err
+		}
+		if len(plan.Keep) > 0 {
+			fmt.Fprintln(out, "\nRESOURCES KEPT (helm.sh/resource-policy: keep):")
+			if err := writePlannedResources(out, plan.Keep); err != nil {
+				return err
+			}
+		}
+		if err := writePlannedHooks(out, plan.Hooks); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writePlannedResources(out io.Writer, resources []release.PlannedResource) error {
+	if len(resources) == 0 {
+		fmt.Fprintln(out, "none")
+		return nil
+	}
+	tbl := uitable.New()
+	tbl.AddRow("KIND", "NAME", "NAMESPACE")
+	for _, r := range resources {
+		tbl.AddRow(r.Kind, r.Name, r.Namespace)
+	}
+	return output.EncodeTable(out, tbl)
+}
+
+func writePlannedHooks(out io.Writer, hooks []release.PlannedHook) error {
+	if len(hooks) == 0 {
+		return nil
+	}
+	fmt.Fprintln(out, "\nHOOKS (in order):")
+	tbl := uitable.New()
+	tbl.AddRow("PHASE", "KIND", "NAME", "NAMESPACE", "WEIGHT")
+	for _, h := range hooks {
+		tbl.AddRow(h.Event, h.Kind, h.Name, h.Namespace, h.Weight)
+	}
+	return output.EncodeTable(out, tbl)
+}
+
 func validateCascadeFlag(client *action.Uninstall) error {
 	if client.DeletionPropagation != "background" && client.DeletionPropagation != "foreground" && client.DeletionPropagation != "orphan" {
 		return fmt.Errorf("invalid cascade value (%s). Must be \"background\", \"foreground\", or \"orphan\"", client.DeletionPropagation)
@@ -63,6 +63,18 @@ func TestUninstall(t *testing.T) {
 			golden: "output/uninstall-wait.txt",
 			rels:   []*release.Release{release.Mock(&release.MockReleaseOptions{Name: "aeneas"})},
 		},
+		{
+			name:   "dry run",
+			cmd:    "uninstall aeneas --dry-run",
+			golden: "output/uninstall-dry-run.txt",
+			rels:   []*release.Release{plannedRelease()},
+		},
+		{
+			name:   "dry run with JSON output",
+			cmd:    "uninstall aeneas --dry-run -o json",
+			golden: "output/uninstall-dry-run.json",
+			rels:   []*release.Release{plannedRelease()},
+		},
 		{
 			name:      "uninstall without release",
 			cmd:       "uninstall",
@@ -73,6 +85,40 @@ func TestUninstall(t *testing.T) {
 	runTestCmd(t, tests)
 }
 
+// plannedRelease is a release with resources kept by their resource policy
+// and delete hooks.
+func plannedRelease() *release.Release {
+	rel := release.Mock(&release.MockReleaseOptions{Name: "aeneas"})
+	rel.Manifest = `---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: fixture
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: data
+  annotations:
+    helm.sh/resource-policy: keep
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: frontend
+`
+	rel.Hooks = append(rel.Hooks, &release.Hook{
+		Name:     "backup",
+		Kind:     "Job",
+		Path:     "backup.yaml",
+		Manifest: "apiVersion: batch/v1\nkind: Job\nmetadata:\n  name: backup\n",
+		Events:   []release.HookEvent{release.HookPreDelete},
+		Weight:   -1,
+	})
+	return rel
+}
+
 func TestUninstallCompletion(t *testing.T) {
 	checkReleaseCompletion(t, "uninstall", true)
 }
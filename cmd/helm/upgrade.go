@@ -74,6 +74,34 @@ or '--set' flags. Priority is given to new values.
 
     $ helm upgrade --reuse-values --set foo=bar --set foo=newbar redis ./redis
 
+The '--plan' flag shows what the upgrade would do without doing it: whether
+each resource would be created, patched, replaced (with '--force'), adopted or
+deleted, the resources that would be orphaned because of their
+'helm.sh/resource-policy: keep' annotation, and the hooks that would run in
+each phase, in order. A plan saved with '--output json' can be given back to
+'--from-plan' once it is approved: the upgrade is then refused if it would do
+anything else, for example because the release was upgraded or a resource was
+modified in the cluster in the meantime. Charts that render different
+manifests each time, for example with random values, cannot be upgraded from a
+plan.
+
+    $ helm upgrade redis ./redis --plan -o json > plan.json
+    $ helm upgrade redis ./redis --from-plan plan.json
+
+An upgrade can be verified once it succeeds, and rolled back to the last
+successful revision if the verification fails, as with '--atomic'.
+'--verify-tests' runs the test hooks of the chart, as 'helm test' does.
+'--verify-condition' gives a condition a resource of the release must meet, as
+KIND/NAME:condition=TYPE for a status condition that must be True, or
+KIND/NAME:jsonpath=EXPR for a JSONPath expression optionally followed by '=' and
+the expected value. The conditions must be met within '--timeout', then keep
+being met for the '--soak-period'. The rollback waits for the resources only if
+'--wait' is set. The failed revision records why its verification failed in its
+description.
+
+    $ helm upgrade web ./web --wait --verify-tests \
+        --verify-condition deployment/web:condition=Available --soak-period 5m
+
 The --dry-run flag will output all generated chart manifests, including Secrets
 which can contain sensitive values. To hide Kubernetes Secrets use the
 --hide-secret flag. Please carefully consider how and when these flags are used.
@@ -84,6 +112,8 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	valueOpts := &values.Options{}
 	var outfmt output.Format
 	var createNamespace bool
+	var plan bool
+	var fromPlan string
 
 	cmd := &cobra.Command{
 		Use:   "upgrade [RELEASE] [CHART]",
@@ -115,6 +145,14 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 			if client.DryRunOption == "" {
 				client.DryRunOption = "none"
 			}
+			if plan && fromPlan != "" {
+				return errors.New("--plan and --from-plan cannot be used together")
+			}
+			if fromPlan != "" {
+				if client.FromPlan, err = loadPlan(fromPlan); err != nil {
+					return err
+				}
+			}
 			// Fixes #7002 - Support reading values from STDIN for `upgrade` command
 			// Must load values AFTER determining if we have to call install so that values loaded from stdin are not read twice
 			if client.Install {
@@ -123,8 +161,11 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 				histClient.Max = 1
 				versions, err := histClient.Run(args[0])
 				if err == driver.ErrReleaseNotFound || isReleaseUninstalled(versions) {
+					if client.FromPlan != nil {
+						return errors.Errorf("release %q does not exist, --from-plan can only be used to upgrade a release", args[0])
+					}
 					// Only print this to stdout for table output
-					if outfmt == output.Table {
+					if outfmt == output.Table && !plan {
 						fmt.Fprintf(out, "Release %q does not exist. Installing it now.\n", args[0])
 					}
 					instClient := action.NewInstall(cfg)
@@ -149,6 +190,7 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 					instClient.Description = client.Description
 					instClient.DependencyUpdate = client.DependencyUpdate
 					instClient.Labels = client.Labels
+					instClient.ChangeCause = client.ChangeCause
 					instClient.EnableDNS = client.EnableDNS
 					instClient.HideSecret = client.HideSecret
 
@@ -156,11 +198,21 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 						instClient.Replace = true
 					}
 
+					if plan {
+						p, err := runInstallPlan(args, instClient, valueOpts, out)
+						if err != nil {
+							return err
+						}
+						return outfmt.Write(out, &planWriter{p})
+					}
+
+					done := reportWaitProgress(cfg, instClient.Wait || instClient.Atomic)
 					rel, err := runInstall(args, instClient, valueOpts, out)
+					done(err)
 					if err != nil {
 						return err
 					}
-					return outfmt.Write(out, &statusPrinter{rel, settings.Debug, false, false, false, instClient.HideNotes})
+					return outfmt.Write(out, &statusPrinter{rel, settings.Debug, false, false, false, instClient.HideNotes, false})
 				} else if err != nil {
 					return err
 				}
@@ -196,14 +248,18 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 					err = errors.Wrap(err, "An error occurred while checking for chart dependencies. You may need to run `helm dependency build` to fetch missing dependencies")
 					if client.DependencyUpdate {
 						man := &downloader.Manager{
-							Out:              out,
-							ChartPath:        chartPath,
-							Keyring:          client.ChartPathOptions.Keyring,
-							SkipUpdate:       false,
-							Getters:          p,
-							RepositoryConfig: settings.RepositoryConfig,
-							RepositoryCache:  settings.RepositoryCache,
-							Debug:            settings.Debug,
+							Out:               out,
+							ChartPath:         chartPath,
+							Keyring:           client.ChartPathOptions.Keyring,
+							SkipUpdate:        false,
+							Getters:           p,
+							RepositoryConfig:  settings.RepositoryConfig,
+							RepositoryCache:   settings.RepositoryCache,
+							TrustPolicyConfig: settings.TrustPolicyConfig,
+							RegistryConfig:    settings.RegistryConfig,
+							ChartCache:        settings.ChartCache,
+							Offline:           client.Offline,
+							Debug:             settings.Debug,
 						}
 						if err := man.Update(); err != nil {
 							return err
@@ -222,6 +278,14 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 				warning("This chart is deprecated")
 			}
 
+			if plan {
+				p, err := client.Plan(args[0], ch, vals)
+				if err != nil {
+					return errors.Wrap(err, "UPGRADE PLAN FAILED")
+				}
+				return outfmt.Write(out, &planWriter{p})
+			}
+
 			// Create context and prepare the handle of SIGTERM
 			ctx := context.Background()
 			ctx, cancel := context.WithCancel(ctx)
@@ -237,7 +301,9 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 				cancel()
 			}()
 
+			done := reportWaitProgress(cfg, client.Wait || client.Atomic)
 			rel, err := client.RunWithContext(ctx, args[0], ch, vals)
+			done(err)
 
 			if err != nil {
 				return errors.Wrap(err, "UPGRADE FAILED")
@@ -247,7 +313,7 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 				fmt.Fprintf(out, "Release %q has been upgraded. Happy Helming!\n", args[0])
 			}
 
-			return outfmt.Write(out, &statusPrinter{rel, settings.Debug, false, false, false, client.HideNotes})
+			return outfmt.Write(out, &statusPrinter{rel, settings.Debug, false, false, false, client.HideNotes, false})
 		},
 	}
 
@@ -268,7 +334,7 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	f.BoolVar(&client.ResetValues, "reset-values", false, "when upgrading, reset the values to the ones built into the chart")
 	f.BoolVar(&client.ReuseValues, "reuse-values", false, "when upgrading, reuse the last release's values and merge in any overrides from the command line via --set and -f. If '--reset-values' is specified, this is ignored")
 	f.BoolVar(&client.ResetThenReuseValues, "reset-then-reuse-values", false, "when upgrading, reset the values to the ones built into the chart, apply the last release's values and merge in any overrides from the command line via --set and -f. If '--reset-values' or '--reuse-values' is specified, this is ignored")
-	f.BoolVar(&client.Wait, "wait", false, "if set, will wait until all Pods, PVCs, Services, and minimum number of Pods of a Deployment, StatefulSet, or ReplicaSet are in a ready state before marking the release as successful. It will wait for as long as --timeout")
+	f.BoolVar(&client.Wait, "wait", false, "if set, will wait until all Pods, PVCs, Services, custom resources, and minimum number of Pods of a Deployment, StatefulSet, or ReplicaSet are in a ready state before marking the release as successful. It will wait for as long as --timeout")
 	f.BoolVar(&client.WaitForJobs, "wait-for-jobs", false, "if set and --wait enabled, will wait until all Jobs have been completed before marking the release as successful. It will wait for as long as --timeout")
 	f.BoolVar(&client.Atomic, "atomic", false, "if set, upgrade process rolls back changes made in case of failed upgrade. The --wait flag will be set automatically if --atomic is used")
 	f.IntVar(&client.MaxHistory, "history-max", settings.MaxHistory, "limit the maximum number of revisions saved per release. Use 0 for no limit")
@@ -278,8 +344,14 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	f.BoolVar(&client.SkipSchemaValidation, "skip-schema-validation", false, "if set, disables JSON schema validation")
 	f.StringToStringVarP(&client.Labels, "labels", "l", nil, "Labels that would be added to release metadata. Should be separated by comma. Original release labels will be merged with upgrade labels. You can unset label using null.")
 	f.StringVar(&client.Description, "description", "", "add a custom description")
+	f.StringVar(&client.ChangeCause, "change-cause", "", "record why the release is upgraded in its revision")
 	f.BoolVar(&client.DependencyUpdate, "dependency-update", false, "update dependencies if they are missing before installing the chart")
 	f.BoolVar(&client.EnableDNS, "enable-dns", false, "enable DNS lookups when rendering templates")
+	f.BoolVar(&plan, "plan", false, "show what the upgrade would do to each resource and which hooks would run, without upgrading")
+	f.StringVar(&fromPlan, "from-plan", "", "upgrade only if the upgrade would do what the plan saved in this file describes")
+	f.BoolVar(&client.VerifyTests, "verify-tests", false, "run the tests of the release once it is upgraded, and roll back to the last successful revision if they fail")
+	f.StringArrayVar(&client.VerifyConditions, "verify-condition", []string{}, "roll back to the last successful revision if this condition is not met once the release is upgraded (can specify multiple). Written as KIND/NAME:condition=TYPE or KIND/NAME:jsonpath=EXPR")
+	f.DurationVar(&client.SoakPeriod, "soak-period", 0, "time the conditions of --verify-condition must keep being met once they are met")
 	addChartPathOptionsFlags(f, &client.ChartPathOptions)
 	addValueOptionsFlags(f, valueOpts)
 	bindOutputFlag(cmd, &outfmt)
@@ -168,6 +168,20 @@ func TestUpgradeCmd(t *testing.T) {
 			golden: "output/upgrade.txt",
 			rels:   []*release.Release{relWithStatusMock("funny-bunny", 2, ch, release.StatusFailed)},
 		},
+		{
+			name:      "upgrade a release with an unmet verification condition",
+			cmd:       fmt.Sprintf("upgrade funny-bunny '%s' --verify-condition deployment/web:condition=Available", chartPath),
+			golden:    "output/upgrade-with-failed-verification.txt",
+			wantError: true,
+			rels:      []*release.Release{relMock("funny-bunny", 2, ch)},
+		},
+		{
+			name:      "upgrade a release with an invalid verification condition",
+			cmd:       fmt.Sprintf("upgrade funny-bunny '%s' --verify-condition deployment/web=Available", chartPath),
+			golden:    "output/upgrade-with-invalid-verification.txt",
+			wantError: true,
+			rels:      []*release.Release{relMock("funny-bunny", 2, ch)},
+		},
 		{
 			name:      "upgrade a pending install release",
 			cmd:       fmt.Sprintf("upgrade funny-bunny '%s'", chartPath),
@@ -465,6 +479,37 @@ func TestUpgradeInstallWithLabels(t *testing.T) {
 	}
 }
 
+func TestUpgradeInstallWithChangeCause(t *testing.T) {
+	releaseName := "funny-bunny-cause"
+	_, _, chartPath := prepareMockRelease(releaseName, t)
+
+	defer resetEnv()()
+
+	store := storageFixture()
+
+	cmd := fmt.Sprintf("upgrade %s --install --change-cause 'first release' '%s'", releaseName, chartPath)
+	if _, _, err := executeActionCommandC(store, cmd); err != nil {
+		t.Errorf("unexpected error, got '%v'", err)
+	}
+	cmd = fmt.Sprintf("upgrade %s --change-cause 'second release' '%s'", releaseName, chartPath)
+	if _, _, err := executeActionCommandC(store, cmd); err != nil {
+		t.Errorf("unexpected error, got '%v'", err)
+	}
+
+	for version, cause := range map[int]string{1: "first release", 2: "second release"} {
+		rel, err := store.Get(releaseName, version)
+		if err != nil {
+			t.Fatalf("unexpected error, got '%v'", err)
+		}
+		if rel.Info.Origin == nil || rel.Info.Origin.ChangeCause != cause {
+			t.Errorf("expected revision %d to have the change cause %q, got %+v", version, cause, rel.Info.Origin)
+		}
+		if rel.Info.Origin != nil && rel.Info.Origin.ChartSource != chartPath {
+			t.Errorf("expected revision %d to have the chart source %q, got %q", version, chartPath, rel.Info.Origin.ChartSource)
+		}
+	}
+}
+
 func prepareMockReleaseWithSecret(releaseName string, t *testing.T) (func(n string, v int, ch *chart.Chart) *release.Release, *chart.Chart, string) {
 	tmpChart := t.TempDir()
 	configmapData, err := os.ReadFile("testdata/testcharts/chart-with-secret/templates/configmap.yaml")
@@ -565,3 +610,103 @@ func TestUpgradeWithDryRun(t *testing.T) {
 		t.Error("expected error when --hide-secret used without --dry-run")
 	}
 }
+
+func TestUpgradePlan(t *testing.T) {
+	tmpChart := t.TempDir()
+	cfile := &chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: chart.APIVersionV1,
+			Name:       "testUpgradeChart",
+			Version:    "0.1.0",
+		},
+		Templates: []*chart.File{
+			{Name: "templates/migrate.yaml", Data: []byte(`apiVersion: batch/v1
+kind: Job
+metadata:
+  name: migrate
+  annotations:
+    helm.sh/hook: pre-install,pre-upgrade
+    helm.sh/hook-weight: "-5"
+`)},
+			{Name: "templates/notify.yaml", Data: []byte(`apiVersion: v1
+kind: Pod
+metadata:
+  name: notify
+  annotations:
+    helm.sh/hook: post-upgrade
+`)},
+		},
+	}
+	chartPath := filepath.Join(tmpChart, cfile.Metadata.Name)
+	if err := chartutil.SaveDir(cfile, tmpChart); err != nil {
+		t.Fatalf("Error creating chart: %v", err)
+	}
+	ch, err := loader.Load(chartPath)
+	if err != nil {
+		t.Fatalf("Error loading chart: %v", err)
+	}
+	relMock := func(n string, v int) *release.Release {
+		return release.Mock(&release.MockReleaseOptions{Name: n, Version: v, Chart: ch})
+	}
+
+	outdatedPlan := filepath.Join(t.TempDir(), "plan.json")
+	plan := `{"name":"funny-bunny","namespace":"default","revision":3,"chart":"testUpgradeChart-0.1.0","manifest_digest":"sha256:0","changes":[]}`
+	if err := os.WriteFile(outdatedPlan, []byte(plan), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []cmdTestCase{
+		{
+			name:   "plan an upgrade",
+			cmd:    fmt.Sprintf("upgrade funny-bunny '%s' --plan", chartPath),
+			golden: "output/upgrade-plan.txt",
+			rels:   []*release.Release{relMock("funny-bunny", 3)},
+		},
+		{
+			name:   "plan an upgrade with JSON output",
+			cmd:    fmt.Sprintf("upgrade funny-bunny '%s' --plan -o json", chartPath),
+			golden: "output/upgrade-plan.json",
+			rels:   []*release.Release{relMock("funny-bunny", 3)},
+		},
+		{
+			name:   "plan an install with 'upgrade --install'",
+			cmd:    fmt.Sprintf("upgrade zany-bunny -i '%s' --plan", chartPath),
+			golden: "output/upgrade-plan-install.txt",
+		},
+		{
+			name:      "upgrade from an outdated plan",
+			cmd:       fmt.Sprintf("upgrade funny-bunny '%s' --from-plan '%s'", chartPath, outdatedPlan),
+			golden:    "output/upgrade-from-outdated-plan.txt",
+			wantError: true,
+			rels:      []*release.Release{relMock("funny-bunny", 3)},
+		},
+		{
+			name:      "plan and upgrade from a plan",
+			cmd:       fmt.Sprintf("upgrade funny-bunny '%s' --plan --from-plan '%s'", chartPath, outdatedPlan),
+			wantError: true,
+			rels:      []*release.Release{relMock("funny-bunny", 3)},
+		},
+	}
+	runTestCmd(t, tests)
+
+	// A plan that is still accurate is followed.
+	defer resetEnv()()
+	store := storageFixture()
+	if err := store.Create(relMock("funny-bunny", 3)); err != nil {
+		t.Fatal(err)
+	}
+	_, out, err := executeActionCommandC(store, fmt.Sprintf("upgrade funny-bunny '%s' --plan -o json", chartPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	planFile := filepath.Join(t.TempDir(), "plan.json")
+	if err := os.WriteFile(planFile, []byte(out), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, out, err = executeActionCommandC(store, fmt.Sprintf("upgrade funny-bunny '%s' --from-plan '%s'", chartPath, planFile)); err != nil {
+		t.Fatalf("expected the upgrade to follow the plan, got %s: %s", err, out)
+	}
+	if rel, err := store.Last("funny-bunny"); err != nil || rel.Version != 4 {
+		t.Errorf("expected the release to be upgraded to revision 4, got %v, %v", rel, err)
+	}
+}
@@ -23,6 +23,7 @@ import (
 
 	"helm.sh/helm/v3/cmd/helm/require"
 	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/registry"
 )
 
 const verifyDesc = `
@@ -33,14 +34,43 @@ tampered with, and was packaged by a trusted provider.
 
 This command can be used to verify a local chart. Several other commands provide
 '--verify' flags that run the same validation. To generate a signed package, use
-the 'helm package --sign' command.
+the 'helm package --sign' command. The keyring given with '--keyring' must match
+the signing scheme of the provenance file: a PGP keyring, a file of trusted
+ed25519 public keys, an SSH allowed_signers file or a bundle of CA certificates.
+
+A chart in an OCI registry is verified through the signatures attached to its
+manifest by 'helm push --sign':
+
+    $ helm verify oci://example.com/charts/mychart:1.2.3
+
+The keyring defines which signers are trusted. Use '--require-attestation' to
+also require artifacts of the given types, such as an SBOM, to be attached to
+the chart. An attestation only counts when it is signed by a trusted signer,
+as 'helm push --sign' does for the attestations it attaches.
+
+With '--policy', the chart is verified according to the rule of the trust
+policy file ($HELM_TRUST_POLICY_CONFIG) that matches its location. Local charts
+match 'file://' sources. The same policy is applied automatically to the charts
+downloaded by 'helm install', 'helm upgrade', 'helm pull' and 'helm dependency
+build', and to the local charts given to 'helm install' and 'helm upgrade':
+
+    policies:
+    - source: https://charts.example.com
+      mode: require          # require, warn or skip
+      keyring: keys/example.gpg
+      signers:
+      - Example Charts <charts@example.com>
 `
 
 func newVerifyCmd(out io.Writer) *cobra.Command {
 	client := action.NewVerify()
+	var (
+		certFile, keyFile, caFile                string
+		insecureSkipTLSverify, plainHTTP, policy bool
+	)
 
 	cmd := &cobra.Command{
-		Use:   "verify PATH",
+		Use:   "verify [PATH|oci://REF]",
 		Short: "verify that a chart at the given path has been signed and is valid",
 		Long:  verifyDesc,
 		Args:  require.ExactArgs(1),
@@ -53,6 +83,16 @@ func newVerifyCmd(out io.Writer) *cobra.Command {
 			return noMoreArgsComp()
 		},
 		RunE: func(_ *cobra.Command, args []string) error {
+			if policy {
+				client.TrustPolicyConfig = settings.TrustPolicyConfig
+			}
+			if registry.IsOCI(args[0]) {
+				registryClient, err := newRegistryClient(certFile, keyFile, caFile, insecureSkipTLSverify, plainHTTP)
+				if err != nil {
+					return fmt.Errorf("missing registry client: %w", err)
+				}
+				client.SetRegistryClient(registryClient)
+			}
 			err := client.Run(args[0])
 			if err != nil {
 				return err
@@ -64,7 +104,15 @@ func newVerifyCmd(out io.Writer) *cobra.Command {
 		},
 	}
 
-	cmd.Flags().StringVar(&client.Keyring, "keyring", defaultKeyring(), "keyring containing public keys")
+	f := cmd.Flags()
+	f.StringVar(&client.Keyring, "keyring", defaultKeyring(), "keyring containing public keys")
+	f.BoolVar(&policy, "policy", false, "verify the chart according to the rule of the trust policy that matches it")
+	f.StringArrayVar(&client.RequiredAttestations, "require-attestation", []string{}, "artifact type of an attestation that must be attached to an OCI chart (can specify multiple)")
+	f.StringVar(&certFile, "cert-file", "", "identify registry client using this SSL certificate file")
+	f.StringVar(&keyFile, "key-file", "", "identify registry client using this SSL key file")
+	f.StringVar(&caFile, "ca-file", "", "verify certificates of HTTPS-enabled servers using this CA bundle")
+	f.BoolVar(&insecureSkipTLSverify, "insecure-skip-tls-verify", false, "skip tls certificate checks for the chart download")
+	f.BoolVar(&plainHTTP, "plain-http", false, "use insecure HTTP connections for the chart download")
 
 	return cmd
 }
@@ -17,8 +17,15 @@ package main
 
 import (
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
+
+	"helm.sh/helm/v3/pkg/registry"
+	"helm.sh/helm/v3/pkg/repo/repotest"
 )
 
 func TestVerifyCmd(t *testing.T) {
@@ -41,7 +48,7 @@ func TestVerifyCmd(t *testing.T) {
 		{
 			name:      "verify requires a chart",
 			cmd:       "verify",
-			expect:    "\"helm verify\" requires 1 argument\n\nUsage:  helm verify PATH [flags]",
+			expect:    "\"helm verify\" requires 1 argument\n\nUsage:  helm verify [PATH|oci://REF] [flags]",
 			wantError: true,
 		},
 		{
@@ -91,6 +98,197 @@ func TestVerifyCmd(t *testing.T) {
 	}
 }
 
+func TestVerifyOCICmd(t *testing.T) {
+	srv, err := repotest.NewTempServerWithCleanup(t, "testdata/testcharts/*.tgz*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Stop()
+
+	ociSrv, err := repotest.NewOCIServer(t, srv.Root())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ociSrv.Run(t)
+
+	sbom := filepath.Join(srv.Root(), "sbom.json")
+	if err := os.WriteFile(sbom, []byte(`{"spdxVersion":"SPDX-2.3"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	registryFlags := fmt.Sprintf("--plain-http --registry-config %s", filepath.Join(srv.Root(), "config.json"))
+	remote := fmt.Sprintf("oci://%s/u/ocitestuser", ociSrv.RegistryURL)
+	signed := remote + "/signtest:0.1.0"
+
+	push := fmt.Sprintf("push testdata/testcharts/signtest-0.1.0.tgz %s --sign --key helm-test --keyring testdata/helm-test-key.secret --attestation application/spdx+json=%s %s",
+		remote, sbom, registryFlags)
+	if _, _, err := executeActionCommand(push); err != nil {
+		t.Fatalf("failed to push signed chart: %s", err)
+	}
+
+	digest, err := ociSrv.Client.Resolve(strings.TrimPrefix(signed, "oci://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Anyone with push access can attach an attestation without signing it.
+	client, err := registry.NewClient(
+		registry.ClientOptPlainHTTP(),
+		registry.ClientOptWriter(io.Discard),
+		registry.ClientOptCredentialsFile(filepath.Join(srv.Root(), "config.json")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	unsigned := []registry.Blob{{MediaType: "application/vnd.cyclonedx+json", Data: []byte(`{"bomFormat":"CycloneDX"}`)}}
+	if _, err := client.PushReferrer(strings.TrimPrefix(signed, "oci://"), "application/vnd.cyclonedx+json", unsigned, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		cmd       string
+		expect    string
+		wantError bool
+	}{
+		{
+			name: "verify validates a signed OCI chart",
+			cmd:  fmt.Sprintf("verify %s --keyring testdata/helm-test-key.pub %s", signed, registryFlags),
+			expect: "Signed by: Helm Testing (This key should only be used for testing. DO NOT TRUST.) <helm-testing@helm.sh>\n" +
+				"Using Key With Fingerprint: 5E615389B53CA37F0EE60BD3843BBF981FC18762\n" +
+				"Manifest Digest Verified: " + digest + "\n",
+		},
+		{
+			name:   "verify validates a signed OCI chart by digest",
+			cmd:    fmt.Sprintf("verify %s/signtest@%s --keyring testdata/helm-test-key.pub %s", remote, digest, registryFlags),
+			expect: "Manifest Digest Verified: " + digest + "\n",
+		},
+		{
+			name:   "verify checks required attestations",
+			cmd:    fmt.Sprintf("verify %s --keyring testdata/helm-test-key.pub --require-attestation application/spdx+json %s", signed, registryFlags),
+			expect: "Attestation Verified: application/spdx+json sha256:",
+		},
+		{
+			name:      "verify fails when a required attestation is not signed",
+			cmd:       fmt.Sprintf("verify %s --keyring testdata/helm-test-key.pub --require-attestation application/vnd.cyclonedx+json %s", signed, registryFlags),
+			expect:    "no signed attestation application/vnd.cyclonedx+json of " + strings.TrimPrefix(signed, "oci://") + " could be verified",
+			wantError: true,
+		},
+		{
+			name:      "verify fails when a required attestation is missing",
+			cmd:       fmt.Sprintf("verify %s --keyring testdata/helm-test-key.pub --require-attestation application/vnd.in-toto+json %s", signed, registryFlags),
+			expect:    "required attestation application/vnd.in-toto+json is not attached to",
+			wantError: true,
+		},
+		{
+			name:      "verify fails for an OCI chart without signatures",
+			cmd:       fmt.Sprintf("verify %s/oci-dependent-chart:0.1.0 --keyring testdata/helm-test-key.pub %s", remote, registryFlags),
+			expect:    "no signatures found for",
+			wantError: true,
+		},
+		{
+			name:      "verify fails for an untrusted signer",
+			cmd:       fmt.Sprintf("verify %s --keyring ../../pkg/provenance/testdata/helm-password-key.secret %s", signed, registryFlags),
+			expect:    "no signature of " + strings.TrimPrefix(signed, "oci://") + " could be verified",
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, out, err := executeActionCommand(tt.cmd)
+			if tt.wantError {
+				if err == nil {
+					t.Fatalf("Expected error, but got none: %q", out)
+				}
+				if !strings.Contains(err.Error(), tt.expect) {
+					t.Errorf("Expected error containing %q, got %q", tt.expect, err)
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if !strings.Contains(out, tt.expect) {
+				t.Errorf("Expected %q in %q", tt.expect, out)
+			}
+		})
+	}
+}
+
+func TestVerifyPolicyCmd(t *testing.T) {
+	chart, err := filepath.Abs("testdata/testcharts/signtest-0.1.0.tgz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyring, err := filepath.Abs("testdata/helm-test-key.pub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	source := "file://" + filepath.ToSlash(filepath.Dir(chart))
+
+	defer func(path string) { settings.TrustPolicyConfig = path }(settings.TrustPolicyConfig)
+
+	tests := []struct {
+		name      string
+		policy    string
+		expect    string
+		wantError bool
+	}{
+		{
+			name:   "no rule matches",
+			policy: "policies: []\n",
+			expect: "Trust Policy: no rule matches file://",
+		},
+		{
+			name:   "rule keyring and signers are satisfied",
+			policy: "policies:\n- source: " + source + "\n  mode: require\n  keyring: " + keyring + "\n  signers: ['5E615389B53CA37F0EE60BD3843BBF981FC18762']\n",
+			expect: "Trust Policy: " + source + " (require)\n" +
+				"Signed by: Helm Testing (This key should only be used for testing. DO NOT TRUST.) <helm-testing@helm.sh>\n" +
+				"Using Key With Fingerprint: 5E615389B53CA37F0EE60BD3843BBF981FC18762\n" +
+				"Chart Hash Verified: sha256:e5ef611620fb97704d8751c16bab17fedb68883bfb0edc76f78a70e9173f9b55\n" +
+				"Policy Result: satisfied\n",
+		},
+		{
+			name:      "require fails for an untrusted signer",
+			policy:    "policies:\n- source: " + source + "\n  mode: require\n  keyring: " + keyring + "\n  signers: [Someone Else]\n",
+			expect:    "who is not a trusted signer for " + source,
+			wantError: true,
+		},
+		{
+			name:   "warn reports an untrusted signer",
+			policy: "policies:\n- source: " + source + "\n  mode: warn\n  keyring: " + keyring + "\n  signers: [Someone Else]\n",
+			expect: "Policy Result: warning: ",
+		},
+		{
+			name:   "skip does not verify",
+			policy: "policies:\n- source: " + source + "\n  mode: skip\n",
+			expect: "Trust Policy: " + source + " (skip)\nPolicy Result: skipped\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := filepath.Join(t.TempDir(), "trust-policy.yaml")
+			if err := os.WriteFile(policy, []byte(tt.policy), 0644); err != nil {
+				t.Fatal(err)
+			}
+			settings.TrustPolicyConfig = policy
+
+			_, out, err := executeActionCommand(fmt.Sprintf("verify %s --policy --keyring %s", chart, keyring))
+			if tt.wantError {
+				if err == nil || !strings.Contains(err.Error(), tt.expect) {
+					t.Fatalf("Expected error containing %q, got %v", tt.expect, err)
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if !strings.Contains(out, tt.expect) {
+				t.Errorf("Expected %q in %q", tt.expect, out)
+			}
+		})
+	}
+}
+
 func TestVerifyFileCompletion(t *testing.T) {
 	checkFileCompletion(t, "verify", true)
 	checkFileCompletion(t, "verify mypath", false)
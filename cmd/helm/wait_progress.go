@@ -0,0 +1,141 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/gosuri/uitable"
+	"golang.org/x/term"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/kube"
+)
+
+// waitProgress shows the readiness of the resources an action waits for.
+//
+// On a terminal, it is a table redrawn in place each time a resource changes.
+// Otherwise, each change is written on its own line.
+type waitProgress struct {
+	out  io.Writer
+	live bool
+
+	mu     sync.Mutex
+	events []kube.WaitEvent
+	index  map[string]int
+	// lines is the number of lines of the last table drawn.
+	lines int
+}
+
+func newWaitProgress(out io.Writer) *waitProgress {
+	live := false
+	if f, ok := out.(*os.File); ok {
+		live = term.IsTerminal(int(f.Fd()))
+	}
+	return &waitProgress{out: out, live: live, index: map[string]int{}}
+}
+
+// reportWaitProgress shows the progress of the action on stderr when it waits
+// for resources. The returned function must be called with the result of the
+// action: if it failed, the resources that were not ready are listed.
+func reportWaitProgress(cfg *action.Configuration, wait bool) func(error) {
+	if !wait {
+		return func(error) {}
+	}
+	p := newWaitProgress(os.Stderr)
+	cfg.WaitProgress = p.handle
+	return func(err error) {
+		cfg.WaitProgress = nil
+		if err != nil {
+			p.summary()
+		}
+	}
+}
+
+func (p *waitProgress) handle(e kube.WaitEvent) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key := e.Kind + "/" + e.Namespace + "/" + e.Name
+	if i, ok := p.index[key]; ok {
+		p.events[i] = e
+	} else {
+		p.index[key] = len(p.events)
+		p.events = append(p.events, e)
+	}
+
+	if !p.live {
+		fmt.Fprintf(p.out, "%s %s: %s\n", e.Kind, resourceName(e), readiness(e))
+		return
+	}
+	if p.lines > 0 {
+		// Move the cursor back to the start of the table and clear it.
+		fmt.Fprintf(p.out, "\x1b[%dA\x1b[J", p.lines)
+	}
+	table := progressTable(p.events)
+	fmt.Fprintln(p.out, table)
+	p.lines = strings.Count(table.String(), "\n") + 1
+}
+
+// summary lists the resources that were not ready when the wait ended.
+func (p *waitProgress) summary() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var notReady []kube.WaitEvent
+	for _, e := range p.events {
+		if !e.Ready {
+			notReady = append(notReady, e)
+		}
+	}
+	if len(notReady) == 0 {
+		return
+	}
+	fmt.Fprintf(p.out, "%d of %d resources were not ready:\n", len(notReady), len(p.events))
+	fmt.Fprintln(p.out, progressTable(notReady))
+}
+
+func progressTable(events []kube.WaitEvent) *uitable.Table {
+	table := uitable.New()
+	table.AddRow("KIND", "NAME", "STATUS")
+	for _, e := range events {
+		table.AddRow(e.Kind, resourceName(e), readiness(e))
+	}
+	return table
+}
+
+func resourceName(e kube.WaitEvent) string {
+	if e.Namespace == "" {
+		return e.Name
+	}
+	return e.Namespace + "/" + e.Name
+}
+
+func readiness(e kube.WaitEvent) string {
+	switch {
+	case e.Ready:
+		return "ready"
+	case e.Reason != "":
+		return fmt.Sprintf("not ready: %s", e.Reason)
+	default:
+		return "not ready"
+	}
+}
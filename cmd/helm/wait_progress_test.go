@@ -0,0 +1,66 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v3/pkg/kube"
+)
+
+func TestWaitProgress(t *testing.T) {
+	var buf bytes.Buffer
+	p := newWaitProgress(&buf)
+
+	p.handle(kube.WaitEvent{Kind: "Deployment", Namespace: "default", Name: "web", Reason: "2/3 expected pods ready"})
+	p.handle(kube.WaitEvent{Kind: "Service", Namespace: "default", Name: "web", Ready: true})
+	p.handle(kube.WaitEvent{Kind: "Database", Namespace: "default", Name: "db", Reason: "condition Ready is False"})
+	p.handle(kube.WaitEvent{Kind: "Deployment", Namespace: "default", Name: "web", Ready: true})
+	p.summary()
+
+	expect := `Deployment default/web: not ready: 2/3 expected pods ready
+Service default/web: ready
+Database default/db: not ready: condition Ready is False
+Deployment default/web: ready
+1 of 3 resources were not ready:
+KIND    	NAME      	STATUS                             
+Database	default/db	not ready: condition Ready is False
+`
+	if got := buf.String(); got != expect {
+		t.Errorf("expected:\n%s\ngot:\n%s", expect, got)
+	}
+}
+
+func TestWaitProgressLive(t *testing.T) {
+	var buf bytes.Buffer
+	p := newWaitProgress(&buf)
+	p.live = true
+
+	p.handle(kube.WaitEvent{Kind: "Deployment", Namespace: "default", Name: "web", Reason: "2/3 expected pods ready"})
+	p.handle(kube.WaitEvent{Kind: "Deployment", Namespace: "default", Name: "web", Ready: true})
+
+	// The second table replaces the first one.
+	tables := strings.Split(buf.String(), "\x1b[2A\x1b[J")
+	if len(tables) != 2 {
+		t.Fatalf("expected the table to be redrawn once, got %q", buf.String())
+	}
+	if !strings.Contains(tables[1], "ready") || strings.Contains(tables[1], "not ready") {
+		t.Errorf("expected the redrawn table to show the deployment as ready, got %q", tables[1])
+	}
+}
@@ -17,20 +17,29 @@ limitations under the License.
 package monocular
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"path"
+	"strings"
 	"time"
 
 	"helm.sh/helm/v3/internal/version"
 	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
 )
 
 // SearchPath is the url path to the search API in monocular.
 const SearchPath = "api/chartsvc/v1/charts/search"
 
+// maxArchiveSize caps the size of a chart archive downloaded for its metadata.
+const maxArchiveSize = 20 * 1024 * 1024
+
 // The structs below represent the structure of the response from the monocular
 // search API. The structs were not imported from monocular because monocular
 // imports from Helm v2 (avoiding circular version dependency) and the mappings
@@ -97,6 +106,88 @@ type ChartVersion struct {
 	Values     string    `json:"values"`
 }
 
+// Metadata returns the chart metadata of the latest version of a search
+// result, as far as the search API provides it.
+func (r SearchResult) Metadata() *chart.Metadata {
+	return &chart.Metadata{
+		Name:        r.Attributes.Name,
+		Description: r.Attributes.Description,
+		Home:        r.Attributes.Home,
+		Keywords:    r.Attributes.Keywords,
+		Maintainers: maintainers(r.Attributes.Maintainers),
+		Sources:     r.Attributes.Sources,
+		Icon:        r.Attributes.Icon,
+		Version:     r.Relationships.LatestChartVersion.Data.Version,
+		AppVersion:  r.Relationships.LatestChartVersion.Data.AppVersion,
+	}
+}
+
+// ChartMetadata returns the metadata of the latest version of a search result
+// from its chart archive. Unlike Metadata, it is complete: the search API does
+// not return the annotations, deprecation or Kubernetes version constraint of
+// a chart.
+func (c *Client) ChartMetadata(r SearchResult) (*chart.Metadata, error) {
+	data := r.Relationships.LatestChartVersion.Data
+	if len(data.Urls) == 0 {
+		return nil, fmt.Errorf("no archive found for %s %s", r.ID, data.Version)
+	}
+	u, err := url.Parse(data.Urls[0])
+	if err != nil {
+		return nil, err
+	}
+	// Archive URLs may be relative to the repository.
+	if !u.IsAbs() {
+		base, err := url.Parse(strings.TrimSuffix(r.Attributes.Repo.URL, "/") + "/")
+		if err != nil {
+			return nil, err
+		}
+		u = base.ResolveReference(u)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", version.GetUserAgent())
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != 200 {
+		return nil, fmt.Errorf("failed to fetch %s : %s", u.String(), res.Status)
+	}
+
+	archive, err := io.ReadAll(io.LimitReader(res.Body, maxArchiveSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(archive) > maxArchiveSize {
+		return nil, fmt.Errorf("chart archive %s is larger than %d bytes", u.String(), maxArchiveSize)
+	}
+	if data.Digest != "" {
+		if sum := sha256.Sum256(archive); hex.EncodeToString(sum[:]) != data.Digest {
+			return nil, fmt.Errorf("chart archive %s does not match its digest %s", u.String(), data.Digest)
+		}
+	}
+
+	ch, err := loader.LoadArchive(bytes.NewReader(archive))
+	if err != nil {
+		return nil, err
+	}
+	return ch.Metadata, nil
+}
+
+func maintainers(m []chart.Maintainer) []*chart.Maintainer {
+	res := make([]*chart.Maintainer, len(m))
+	for i := range m {
+		res[i] = &m[i]
+	}
+	return res
+}
+
 // Search performs a search against the monocular search API
 func (c *Client) Search(term string) ([]SearchResult, error) {
 
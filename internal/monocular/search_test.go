@@ -20,7 +20,11 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
 	"testing"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
 )
 
 // A search response for phpmyadmin containing 2 results
@@ -47,3 +51,40 @@ func TestSearch(t *testing.T) {
 		t.Error("Did not receive the expected number of results")
 	}
 }
+
+func TestChartMetadata(t *testing.T) {
+	dir := t.TempDir()
+	archive, err := chartutil.Save(&chart.Chart{Metadata: &chart.Metadata{
+		APIVersion:  chart.APIVersionV2,
+		Name:        "mariadb",
+		Version:     "7.0.0",
+		Annotations: map[string]string{"category": "Database"},
+	}}, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(http.FileServer(http.Dir(dir)))
+	defer ts.Close()
+
+	c, err := New(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var r SearchResult
+	r.ID = "stable/mariadb"
+	r.Attributes.Repo.URL = ts.URL
+	r.Relationships.LatestChartVersion.Data.Urls = []string{filepath.Base(archive)}
+
+	md, err := c.ChartMetadata(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if md.Annotations["category"] != "Database" {
+		t.Errorf("Expected the annotations of the chart, got %v", md.Annotations)
+	}
+
+	r.Relationships.LatestChartVersion.Data.Digest = "119c499251bffd4b06ff0cd5ac98c2ce32231f84899fb4825be6c2d90971c742"
+	if _, err := c.ChartMetadata(r); err == nil {
+		t.Error("Expected an error for an archive that does not match its digest")
+	}
+}
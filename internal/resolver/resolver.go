@@ -58,6 +58,20 @@ func (r *Resolver) Resolve(reqs []*chart.Dependency, repoNames map[string]string
 	locked := make([]*chart.Dependency, len(reqs))
 	missing := []string{}
 	for i, d := range reqs {
+		// OCI dependencies pinned to a digest, optionally alongside their
+		// version (e.g. 1.2.3@sha256:...), are locked as they are.
+		if registry.IsOCI(d.Repository) && strings.Contains(d.Version, "@") {
+			if _, _, err := registry.SplitDigest(d.Version); err != nil {
+				return nil, errors.Wrapf(err, "dependency %q has an invalid digest", d.Name)
+			}
+			locked[i] = &chart.Dependency{
+				Name:       d.Name,
+				Repository: d.Repository,
+				Version:    d.Version,
+			}
+			continue
+		}
+
 		constraint, err := semver.NewConstraint(d.Version)
 		if err != nil {
 			return nil, errors.Wrapf(err, "dependency %q has an invalid version/constraint format", d.Name)
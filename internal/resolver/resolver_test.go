@@ -125,6 +125,24 @@ func TestResolve(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "oci repo pinned to a digest",
+			req: []*chart.Dependency{
+				{Name: "oci-chart", Repository: "oci://example.com/charts", Version: "1.2.3@sha256:e5ef611620fb97704d8751c16bab17fedb68883bfb0edc76f78a70e9173f9b55"},
+			},
+			expect: &chart.Lock{
+				Dependencies: []*chart.Dependency{
+					{Name: "oci-chart", Repository: "oci://example.com/charts", Version: "1.2.3@sha256:e5ef611620fb97704d8751c16bab17fedb68883bfb0edc76f78a70e9173f9b55"},
+				},
+			},
+		},
+		{
+			name: "oci repo pinned to an invalid digest",
+			req: []*chart.Dependency{
+				{Name: "oci-chart", Repository: "oci://example.com/charts", Version: "1.2.3@sha256:1234"},
+			},
+			err: true,
+		},
 		{
 			name: "repo from invalid path under charts path",
 			req: []*chart.Dependency{
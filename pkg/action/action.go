@@ -95,6 +95,19 @@ type Configuration struct {
 	Capabilities *chartutil.Capabilities
 
 	Log func(string, ...interface{})
+
+	// WaitProgress, if set, is called while an action waits for resources to
+	// be ready, each time the readiness of one of them changes.
+	WaitProgress func(kube.WaitEvent)
+
+	// LockHolder identifies this client in the locks of the releases it
+	// operates on. It defaults to the user, host and process ID.
+	LockHolder string
+
+	// Origin, if set, returns who makes the revisions of releases and from
+	// where, recorded on them with their chart source and change cause. It
+	// defaults to the Kubernetes user, the hostname and the Helm version.
+	Origin func() release.Origin
 }
 
 // renderResources renders the templates in a chart
@@ -373,6 +386,12 @@ func (cfg *Configuration) recordRelease(r *release.Release) {
 func (cfg *Configuration) Init(getter genericclioptions.RESTClientGetter, namespace, helmDriver string, log DebugLog) error {
 	kc := kube.New(getter)
 	kc.Log = log
+	// WaitProgress may be set after Init, so it is read on each event.
+	kc.WaitProgress = func(e kube.WaitEvent) {
+		if cfg.WaitProgress != nil {
+			cfg.WaitProgress(e)
+		}
+	}
 
 	lazyClient := &lazyClient{
 		namespace: namespace,
@@ -385,10 +404,12 @@ func (cfg *Configuration) Init(getter genericclioptions.RESTClientGetter, namesp
 		d := driver.NewSecrets(newSecretClient(lazyClient))
 		d.Log = log
 		store = storage.Init(d)
+		store.Locker = newLeases(lazyClient, log)
 	case "configmap", "configmaps":
 		d := driver.NewConfigMaps(newConfigMapClient(lazyClient))
 		d.Log = log
 		store = storage.Init(d)
+		store.Locker = newLeases(lazyClient, log)
 	case "memory":
 		var d *driver.Memory
 		if cfg.Releases != nil {
@@ -28,6 +28,7 @@ import (
 
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/registry"
 )
 
 // Dependency is the action for building a given chart's dependency tree.
@@ -37,6 +38,7 @@ type Dependency struct {
 	Verify      bool
 	Keyring     string
 	SkipRefresh bool
+	Offline     bool
 	ColumnWidth uint
 }
 
@@ -128,8 +130,8 @@ func (d *Dependency) dependencyStatus(chartpath string, dep *chart.Dependency, p
 		return "missing"
 	}
 
-	if depChart.Metadata.Version != dep.Version {
-		constraint, err := semver.NewConstraint(dep.Version)
+	if version := dependencyVersion(dep); depChart.Metadata.Version != version {
+		constraint, err := semver.NewConstraint(version)
 		if err != nil {
 			return "invalid version"
 		}
@@ -147,6 +149,23 @@ func (d *Dependency) dependencyStatus(chartpath string, dep *chart.Dependency, p
 	return "unpacked"
 }
 
+// dependencyVersion returns the version constraint of a dependency. The digest
+// an OCI dependency may be pinned to is not part of the constraint, and a
+// dependency pinned only by digest accepts any version.
+func dependencyVersion(dep *chart.Dependency) string {
+	if !registry.IsOCI(dep.Repository) {
+		return dep.Version
+	}
+	version, digest, err := registry.SplitDigest(dep.Version)
+	if err != nil {
+		return dep.Version
+	}
+	if version == "" && digest != "" {
+		return ">=0.0.0-0"
+	}
+	return version
+}
+
 // stat an archive and return a message if the stat is successful
 //
 // This is a refactor of the code originally in dependencyStatus. It is here to
@@ -161,8 +180,8 @@ func statArchiveForStatus(archive string, dep *chart.Dependency) string {
 			return "misnamed"
 		}
 
-		if c.Metadata.Version != dep.Version {
-			constraint, err := semver.NewConstraint(dep.Version)
+		if version := dependencyVersion(dep); c.Metadata.Version != version {
+			constraint, err := semver.NewConstraint(version)
 			if err != nil {
 				return "invalid version"
 			}
@@ -36,6 +36,8 @@ type Metadata struct {
 	Revision   int    `json:"revision" yaml:"revision"`
 	Status     string `json:"status" yaml:"status"`
 	DeployedAt string `json:"deployedAt" yaml:"deployedAt"`
+	// ChartDigest is set when the chart was installed from an OCI registry
+	ChartDigest string `json:"chartDigest,omitempty" yaml:"chartDigest,omitempty"`
 }
 
 // NewGetMetadata creates a new GetMetadata object with the given configuration.
@@ -57,13 +59,14 @@ func (g *GetMetadata) Run(name string) (*Metadata, error) {
 	}
 
 	return &Metadata{
-		Name:       rel.Name,
-		Chart:      rel.Chart.Metadata.Name,
-		Version:    rel.Chart.Metadata.Version,
-		AppVersion: rel.Chart.Metadata.AppVersion,
-		Namespace:  rel.Namespace,
-		Revision:   rel.Version,
-		Status:     rel.Info.Status.String(),
-		DeployedAt: rel.Info.LastDeployed.Format(time.RFC3339),
+		Name:        rel.Name,
+		Chart:       rel.Chart.Metadata.Name,
+		Version:     rel.Chart.Metadata.Version,
+		AppVersion:  rel.Chart.Metadata.AppVersion,
+		Namespace:   rel.Namespace,
+		Revision:    rel.Version,
+		Status:      rel.Info.Status.String(),
+		DeployedAt:  rel.Info.LastDeployed.Format(time.RFC3339),
+		ChartDigest: rel.Info.ChartDigest,
 	}, nil
 }
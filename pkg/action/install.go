@@ -98,6 +98,8 @@ type Install struct {
 	DisableOpenAPIValidation bool
 	IncludeCRDs              bool
 	Labels                   map[string]string
+	// ChangeCause is why the release is installed, recorded on the revision
+	ChangeCause string
 	// KubeVersion allows specifying a custom kubernetes version to use and
 	// APIVersions allows a manual set of supported API Versions to be passed
 	// (for things like templating). These are ignored if ClientOnly is false
@@ -125,6 +127,7 @@ type ChartPathOptions struct {
 	InsecureSkipTLSverify bool   // --insecure-skip-verify
 	PlainHTTP             bool   // --plain-http
 	Keyring               string // --keyring
+	Offline               bool   // --offline
 	Password              string // --password
 	PassCredentialsAll    bool   // --pass-credentials
 	RepoURL               string // --repo
@@ -132,9 +135,20 @@ type ChartPathOptions struct {
 	Verify                bool   // --verify
 	Version               string // --version
 
+	// Out receives the messages written while locating a chart, such as
+	// download progress and trust policy warnings. It defaults to os.Stdout.
+	Out io.Writer
+
 	// registryClient provides a registry client but is not added with
 	// options from a flag
 	registryClient *registry.Client
+
+	// chartDigest is the OCI manifest digest of the chart found by
+	// LocateChart, recorded on the release
+	chartDigest string
+	// chartSource is where LocateChart found the chart, recorded on the
+	// release
+	chartSource string
 }
 
 // NewInstall creates a new Install object with the given configuration.
@@ -229,7 +243,7 @@ func (i *Install) Run(chrt *chart.Chart, vals map[string]interface{}) (*release.
 //
 // When the task is cancelled through ctx, the function returns and the install
 // proceeds in the background.
-func (i *Install) RunWithContext(ctx context.Context, chrt *chart.Chart, vals map[string]interface{}) (*release.Release, error) {
+func (i *Install) RunWithContext(ctx context.Context, chrt *chart.Chart, vals map[string]interface{}) (_ *release.Release, err error) {
 	// Check reachability of cluster unless in client-only mode (e.g. `helm template` without `--validate`)
 	if !i.ClientOnly {
 		if err := i.cfg.KubeClient.IsReachable(); err != nil {
@@ -242,6 +256,16 @@ func (i *Install) RunWithContext(ctx context.Context, chrt *chart.Chart, vals ma
 		return nil, errors.New("Hiding Kubernetes secrets requires a dry-run mode")
 	}
 
+	// Lock the release so that concurrent operations on it wait for this
+	// one, unless the name is invalid, which availableName reports.
+	if !i.ClientOnly && !i.isDryRun() && chartutil.ValidateReleaseName(i.ReleaseName) == nil {
+		unlock, lockErr := i.cfg.lockRelease(i.ReleaseName)
+		if lockErr != nil {
+			return nil, lockErr
+		}
+		defer unlock(&err)
+	}
+
 	if err := i.availableName(); err != nil {
 		return nil, err
 	}
@@ -339,6 +363,9 @@ func (i *Install) RunWithContext(ctx context.Context, chrt *chart.Chart, vals ma
 	if err != nil {
 		return nil, err
 	}
+	if rel.Waves, err = releaseWaves(resources); err != nil {
+		return nil, err
+	}
 
 	// Install requires an extra validation step of checking that resources
 	// don't already exist before we actually create resources. If we continue
@@ -412,6 +439,51 @@ func (i *Install) RunWithContext(ctx context.Context, chrt *chart.Chart, vals ma
 	return rel, err
 }
 
+// Plan returns what installing the chart would do, without changing the
+// cluster or storing the release.
+func (i *Install) Plan(chrt *chart.Chart, vals map[string]interface{}) (*release.Plan, error) {
+	if i.ClientOnly {
+		return nil, errors.New("planning an install requires a connection to the cluster")
+	}
+
+	// The install is run as a server-side dry run to render the release, after
+	// checking that its name is available, which dry runs do not check.
+	dryRun, dryRunOption := i.DryRun, i.DryRunOption
+	defer func() { i.DryRun, i.DryRunOption = dryRun, dryRunOption }()
+	i.DryRun, i.DryRunOption = false, "none"
+	if err := i.availableName(); err != nil {
+		return nil, err
+	}
+	i.DryRun, i.DryRunOption = true, "server"
+	rel, err := i.Run(chrt, vals)
+	if err != nil {
+		return nil, err
+	}
+
+	resources, err := i.cfg.KubeClient.Build(bytes.NewBufferString(rel.Manifest), !i.DisableOpenAPIValidation)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to build kubernetes objects from release manifest")
+	}
+	if err := resources.Visit(setMetadataVisitor(rel.Name, rel.Namespace, true)); err != nil {
+		return nil, err
+	}
+	var toBeAdopted kube.ResourceList
+	if i.TakeOwnership {
+		toBeAdopted, err = requireAdoption(resources)
+	} else {
+		toBeAdopted, err = existingResourceConflict(resources, rel.Name, rel.Namespace)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to continue with install")
+	}
+
+	var events []release.HookEvent
+	if !i.DisableHooks {
+		events = []release.HookEvent{release.HookPreInstall, release.HookPostInstall}
+	}
+	return i.cfg.planRelease(rel, toBeAdopted, resources, toBeAdopted, i.Force, events...)
+}
+
 func (i *Install) performInstallCtx(ctx context.Context, rel *release.Release, toBeAdopted kube.ResourceList, resources kube.ResourceList) (*release.Release, error) {
 	type Msg struct {
 		r *release.Release
@@ -452,10 +524,8 @@ func (i *Install) performInstall(rel *release.Release, toBeAdopted kube.Resource
 	// At this point, we can do the install. Note that before we were detecting whether to
 	// do an update, but it's not clear whether we WANT to do an update if the re-use is set
 	// to true, since that is basically an upgrade operation.
-	if len(toBeAdopted) == 0 && len(resources) > 0 {
-		_, err = i.cfg.KubeClient.Create(resources)
-	} else if len(resources) > 0 {
-		_, err = i.cfg.KubeClient.Update(toBeAdopted, resources, i.Force)
+	if len(resources) > 0 {
+		_, err = i.cfg.applyInWaves(toBeAdopted, resources, i.Force, len(toBeAdopted) == 0, i.cfg.waitForWave(i.Wait, i.WaitForJobs, i.Timeout))
 	}
 	if err != nil {
 		return rel, err
@@ -506,6 +576,7 @@ func (i *Install) failRelease(rel *release.Release, err error) (*release.Release
 		uninstall.DisableHooks = i.DisableHooks
 		uninstall.KeepHistory = false
 		uninstall.Timeout = i.Timeout
+		uninstall.locked = true
 		if _, uninstallErr := uninstall.Run(i.ReleaseName); uninstallErr != nil {
 			return rel, errors.Wrapf(uninstallErr, "an error occurred while uninstalling the release. original install error: %s", err)
 		}
@@ -559,6 +630,8 @@ func (i *Install) createRelease(chrt *chart.Chart, rawVals map[string]interface{
 			FirstDeployed: ts,
 			LastDeployed:  ts,
 			Status:        release.StatusUnknown,
+			ChartDigest:   i.chartDigest,
+			Origin:        i.cfg.origin(i.chartSource, i.ChangeCause, i.isDryRun()),
 		},
 		Version: 1,
 		Labels:  labels,
@@ -727,6 +800,46 @@ OUTER:
 	return nil
 }
 
+// out returns the writer for the messages written while locating a chart.
+func (c *ChartPathOptions) out() io.Writer {
+	if c.Out == nil {
+		return os.Stdout
+	}
+	return c.Out
+}
+
+// verifyLocalChart verifies a local chart if Verify is set, or if a rule of
+// the trust policy matches its file:// URL. A rule in warn mode only prints a
+// warning when the chart does not satisfy it.
+func (c *ChartPathOptions) verifyLocalChart(abs string, settings *cli.EnvSettings) error {
+	policy, err := downloader.LoadTrustPolicy(settings.TrustPolicyConfig)
+	if err != nil {
+		return err
+	}
+	rule := policy.Match((&url.URL{Scheme: "file", Path: filepath.ToSlash(abs)}).String())
+	if rule == nil || rule.Mode == downloader.TrustSkip {
+		if c.Verify {
+			_, err := downloader.VerifyChart(abs, c.Keyring)
+			return err
+		}
+		return nil
+	}
+
+	keyring := c.Keyring
+	if rule.Keyring != "" {
+		keyring = rule.Keyring
+	}
+	ver, err := downloader.VerifyChart(abs, keyring)
+	if err == nil {
+		err = rule.CheckSigner(abs, ver)
+	}
+	if err != nil && rule.Mode == downloader.TrustWarn && !c.Verify {
+		fmt.Fprintf(c.out(), "WARNING: %s does not satisfy the trust policy for %s: %s\n", abs, rule.Source, err)
+		return nil
+	}
+	return err
+}
+
 // LocateChart looks for a chart directory in known places, and returns either the full path or an error.
 //
 // This does not ensure that the chart is well-formed; only that the requested filename exists.
@@ -750,11 +863,10 @@ func (c *ChartPathOptions) LocateChart(name string, settings *cli.EnvSettings) (
 		if err != nil {
 			return abs, err
 		}
-		if c.Verify {
-			if _, err := downloader.VerifyChart(abs, c.Keyring); err != nil {
-				return "", err
-			}
+		if err := c.verifyLocalChart(abs, settings); err != nil {
+			return "", err
 		}
+		c.chartSource = abs
 		return abs, nil
 	}
 	if filepath.IsAbs(name) || strings.HasPrefix(name, ".") {
@@ -762,7 +874,7 @@ func (c *ChartPathOptions) LocateChart(name string, settings *cli.EnvSettings) (
 	}
 
 	dl := downloader.ChartDownloader{
-		Out:     os.Stdout,
+		Out:     c.out(),
 		Keyring: c.Keyring,
 		Getters: getter.All(settings),
 		Options: []getter.Option{
@@ -771,9 +883,13 @@ func (c *ChartPathOptions) LocateChart(name string, settings *cli.EnvSettings) (
 			getter.WithInsecureSkipVerifyTLS(c.InsecureSkipTLSverify),
 			getter.WithPlainHTTP(c.PlainHTTP),
 		},
-		RepositoryConfig: settings.RepositoryConfig,
-		RepositoryCache:  settings.RepositoryCache,
-		RegistryClient:   c.registryClient,
+		RepositoryConfig:  settings.RepositoryConfig,
+		RepositoryCache:   settings.RepositoryCache,
+		TrustPolicyConfig: settings.TrustPolicyConfig,
+		RegistryConfig:    settings.RegistryConfig,
+		ChartCache:        settings.ChartCache,
+		Offline:           c.Offline,
+		RegistryClient:    c.registryClient,
 	}
 
 	if registry.IsOCI(name) {
@@ -818,10 +934,33 @@ func (c *ChartPathOptions) LocateChart(name string, settings *cli.EnvSettings) (
 		return "", err
 	}
 
+	if registry.IsOCI(name) {
+		// Pin the reference to the manifest digest before pulling, so the
+		// digest recorded on the release is exactly the artifact installed.
+		u, err := dl.ResolveChartVersion(name, version)
+		if err != nil {
+			return "", err
+		}
+		ref, digest, err := registry.SplitDigest(u.String())
+		if err != nil {
+			return "", err
+		}
+		if digest == "" {
+			digest, err = c.registryClient.Resolve(strings.TrimPrefix(ref, fmt.Sprintf("%s://", registry.OCIScheme)))
+			if err != nil {
+				return "", err
+			}
+		}
+		name = fmt.Sprintf("%s@%s", ref, digest)
+		version = ""
+		c.chartDigest = digest
+	}
+
 	filename, _, err := dl.DownloadTo(name, version, settings.RepositoryCache)
 	if err != nil {
 		return "", err
 	}
+	c.chartSource = dl.ResolvedURL
 
 	lname, err := filepath.Abs(filename)
 	if err != nil {
@@ -17,6 +17,7 @@ limitations under the License.
 package action
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
@@ -34,7 +35,9 @@ import (
 	"helm.sh/helm/v3/internal/test"
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/cli"
 	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+	"helm.sh/helm/v3/pkg/registry"
 	"helm.sh/helm/v3/pkg/release"
 	"helm.sh/helm/v3/pkg/storage/driver"
 	helmtime "helm.sh/helm/v3/pkg/time"
@@ -798,3 +801,84 @@ func TestInstallWithSystemLabels(t *testing.T) {
 
 	is.Equal(fmt.Errorf("user supplied labels contains system reserved label name. System labels: %+v", driver.GetSystemLabels()), err)
 }
+
+func TestInstallRelease_Plan(t *testing.T) {
+	is := assert.New(t)
+	req := require.New(t)
+
+	instAction := installAction(t)
+	plan, err := instAction.Plan(buildChart(), map[string]interface{}{})
+	req.NoError(err)
+	is.Equal("test-install-release", plan.Name)
+	is.Equal("spaced", plan.Namespace)
+	is.Equal(1, plan.Revision)
+	req.Len(plan.Hooks, 1)
+	is.Equal(release.HookPostInstall, plan.Hooks[0].Event)
+
+	// Planning does not install the release.
+	is.False(instAction.DryRun)
+	_, err = instAction.cfg.Releases.Last(plan.Name)
+	is.Error(err)
+
+	// Planning fails when the install would.
+	req.NoError(instAction.cfg.Releases.Create(namedReleaseStub(plan.Name, release.StatusDeployed)))
+	_, err = instAction.Plan(buildChart(), map[string]interface{}{})
+	is.EqualError(err, "cannot re-use a name that is still in use")
+}
+
+func TestLocateChartTrustPolicy(t *testing.T) {
+	signed, err := filepath.Abs("../downloader/testdata/signtest-0.1.0.tgz")
+	require.NoError(t, err)
+	unsigned, err := filepath.Abs("testdata/charts/compressedchart-0.1.0.tgz")
+	require.NoError(t, err)
+	keyring, err := filepath.Abs("../downloader/testdata/helm-test-key.pub")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		mode    string
+		chart   string
+		wantErr bool
+		wantOut string
+	}{
+		{name: "require accepts a signed chart", mode: "require", chart: signed},
+		{name: "require refuses an unsigned chart", mode: "require", chart: unsigned, wantErr: true},
+		{name: "warn accepts an unsigned chart", mode: "warn", chart: unsigned, wantOut: "WARNING: " + unsigned + " does not satisfy the trust policy"},
+		{name: "skip accepts an unsigned chart", mode: "skip", chart: unsigned},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := filepath.Join(t.TempDir(), "trust-policy.yaml")
+			source := "file://" + filepath.ToSlash(filepath.Dir(tt.chart))
+			data := fmt.Sprintf("policies:\n- source: %s\n  mode: %s\n  keyring: %s\n", source, tt.mode, keyring)
+			require.NoError(t, os.WriteFile(policy, []byte(data), 0644))
+
+			var out bytes.Buffer
+			c := ChartPathOptions{Out: &out}
+			_, err := c.LocateChart(tt.chart, &cli.EnvSettings{TrustPolicyConfig: policy})
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			if tt.wantOut == "" {
+				assert.Empty(t, out.String())
+			} else {
+				assert.Contains(t, out.String(), tt.wantOut)
+			}
+		})
+	}
+}
+
+func TestLocateChartOfflineOCI(t *testing.T) {
+	client, err := registry.NewClient()
+	require.NoError(t, err)
+
+	// The registry is not reachable, and must not be queried.
+	for _, version := range []string{"1.2.3", "^1.0.0"} {
+		c := ChartPathOptions{Offline: true, Version: version, registryClient: client}
+		_, err = c.LocateChart("oci://127.0.0.1:1/charts/mychart", &cli.EnvSettings{RepositoryCache: t.TempDir(), ChartCache: t.TempDir()})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "is not pinned to a digest, it cannot be installed offline")
+	}
+}
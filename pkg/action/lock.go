@@ -0,0 +1,72 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/pkg/storage/driver"
+)
+
+func newLeases(lc *lazyClient, log DebugLog) *driver.Leases {
+	l := driver.NewLeases(newLeaseClient(lc))
+	l.Log = log
+	return l
+}
+
+// lockRelease locks the named release for the duration of an operation, and
+// returns the function that unlocks it. If the lock was lost during the
+// operation, the function sets the error of the operation, unless it already
+// failed.
+func (cfg *Configuration) lockRelease(name string) (func(*error), error) {
+	unlock, err := cfg.Releases.Lock(name, cfg.lockHolder())
+	if errors.Is(err, driver.ErrReleaseLocked) {
+		return nil, errors.Wrap(err, errPending.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return func(err *error) {
+		if uerr := unlock(); uerr != nil && *err == nil {
+			*err = uerr
+		}
+	}, nil
+}
+
+func (cfg *Configuration) lockHolder() string {
+	if cfg.LockHolder == "" {
+		cfg.LockHolder = defaultLockHolder()
+	}
+	return cfg.LockHolder
+}
+
+// defaultLockHolder identifies this process as user@host/pid.
+func defaultLockHolder() string {
+	name := "unknown"
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s@%s/%d", name, host, os.Getpid())
+}
@@ -0,0 +1,58 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"os"
+
+	"helm.sh/helm/v3/internal/version"
+	"helm.sh/helm/v3/pkg/kube"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// origin returns the origin to record on a revision made from a chart with
+// the given source, for the given cause. The Kubernetes user is not looked up
+// for dry runs, whose revisions are not stored.
+func (cfg *Configuration) origin(chartSource, changeCause string, dryRun bool) *release.Origin {
+	var origin release.Origin
+	if cfg.Origin != nil {
+		origin = cfg.Origin()
+	} else {
+		origin = cfg.defaultOrigin(!dryRun)
+	}
+	origin.ChartSource = chartSource
+	origin.ChangeCause = changeCause
+	return &origin
+}
+
+// defaultOrigin returns the Kubernetes user of the client if lookupUser is
+// set, the hostname and the version of Helm. The user is left out if the
+// Kubernetes client cannot find it.
+func (cfg *Configuration) defaultOrigin(lookupUser bool) release.Origin {
+	origin := release.Origin{HelmVersion: version.GetVersion()}
+	if kubeClient, ok := cfg.KubeClient.(kube.InterfaceWhoAmI); ok && lookupUser {
+		user, err := kubeClient.WhoAmI()
+		if err != nil {
+			cfg.Log("unable to find the Kubernetes user: %s", err)
+		}
+		origin.User = user
+	}
+	if hostname, err := os.Hostname(); err == nil {
+		origin.Hostname = hostname
+	}
+	return origin
+}
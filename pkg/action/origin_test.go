@@ -0,0 +1,95 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v3/internal/version"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+type whoAmIKubeClient struct {
+	kubefake.FailingKubeClient
+}
+
+func (c *whoAmIKubeClient) WhoAmI() (string, error) {
+	return "jane", nil
+}
+
+func TestDefaultOrigin(t *testing.T) {
+	cfg := actionConfigFixture(t)
+	cfg.KubeClient = &whoAmIKubeClient{}
+
+	hostname, err := os.Hostname()
+	require.NoError(t, err)
+	assert.Equal(t, &release.Origin{
+		User:        "jane",
+		Hostname:    hostname,
+		HelmVersion: version.GetVersion(),
+		ChartSource: "oci://example.com/charts/web@sha256:abc",
+		ChangeCause: "scale up",
+	}, cfg.origin("oci://example.com/charts/web@sha256:abc", "scale up", false))
+
+	// Dry runs are not stored, the user is not looked up.
+	assert.Equal(t, "", cfg.origin("", "", true).User)
+}
+
+func TestRevisionOrigin(t *testing.T) {
+	is := assert.New(t)
+	req := require.New(t)
+
+	instAction := installAction(t)
+	instAction.cfg.Origin = func() release.Origin {
+		return release.Origin{User: "jane", Hostname: "ci-runner", HelmVersion: "v3.99.0"}
+	}
+	instAction.chartSource = "https://charts.example.com/hello-0.1.0.tgz"
+	instAction.ChangeCause = "first release"
+	rel, err := instAction.Run(buildChart(), map[string]interface{}{})
+	req.NoError(err)
+	is.Equal(&release.Origin{
+		User:        "jane",
+		Hostname:    "ci-runner",
+		HelmVersion: "v3.99.0",
+		ChartSource: "https://charts.example.com/hello-0.1.0.tgz",
+		ChangeCause: "first release",
+	}, rel.Info.Origin)
+
+	upAction := NewUpgrade(instAction.cfg)
+	upAction.Namespace = instAction.Namespace
+	upAction.ChangeCause = "new settings"
+	rel, err = upAction.RunWithContext(context.Background(), rel.Name, buildChart(), map[string]interface{}{"name": "value"})
+	req.NoError(err)
+	is.Equal("new settings", rel.Info.Origin.ChangeCause)
+	is.Empty(rel.Info.Origin.ChartSource, "the chart was not located by the upgrade")
+
+	rollAction := NewRollback(instAction.cfg)
+	rollAction.ChangeCause = "bad settings"
+	req.NoError(rollAction.Run(rel.Name))
+	rel, err = instAction.cfg.Releases.Last(rel.Name)
+	req.NoError(err)
+	is.Equal(3, rel.Version)
+	is.Equal("bad settings", rel.Info.Origin.ChangeCause)
+	is.Equal("https://charts.example.com/hello-0.1.0.tgz", rel.Info.Origin.ChartSource, "the chart source of the revision rolled back to")
+	is.Equal("jane", rel.Info.Origin.User)
+}
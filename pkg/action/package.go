@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"os"
 	"syscall"
+	"time"
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/pkg/errors"
@@ -35,7 +36,10 @@ import (
 //
 // It provides the implementation of 'helm package'.
 type Package struct {
-	Sign             bool
+	Sign bool
+	// SignScheme is the signing scheme, PGP when empty. For schemes other
+	// than PGP, Key is the path of the private key and Keyring is unused.
+	SignScheme       string
 	Key              string
 	Keyring          string
 	PassphraseFile   string
@@ -43,6 +47,10 @@ type Package struct {
 	AppVersion       string
 	Destination      string
 	DependencyUpdate bool
+	// Timestamp, when set, makes the archive reproducible: every file of the
+	// archive is stamped with it instead of the current time, and packaging
+	// the same chart always gives the same archive.
+	Timestamp time.Time
 
 	RepositoryConfig string
 	RepositoryCache  string
@@ -91,7 +99,12 @@ func (p *Package) Run(path string, _ map[string]interface{}) (string, error) {
 		dest = p.Destination
 	}
 
-	name, err := chartutil.Save(ch, dest)
+	var name string
+	if p.Timestamp.IsZero() {
+		name, err = chartutil.Save(ch, dest)
+	} else {
+		name, err = chartutil.SaveReproducible(ch, dest, p.Timestamp)
+	}
 	if err != nil {
 		return "", errors.Wrap(err, "failed to save")
 	}
@@ -113,12 +126,7 @@ func validateVersion(ver string) error {
 
 // Clearsign signs a chart
 func (p *Package) Clearsign(filename string) error {
-	// Load keyring
-	signer, err := provenance.NewFromKeyring(p.Keyring, p.Key)
-	if err != nil {
-		return err
-	}
-
+	var err error
 	passphraseFetcher := promptUser
 	if p.PassphraseFile != "" {
 		passphraseFetcher, err = passphraseFileFetcher(p.PassphraseFile, os.Stdin)
@@ -127,8 +135,22 @@ func (p *Package) Clearsign(filename string) error {
 		}
 	}
 
-	if err := signer.DecryptKey(passphraseFetcher); err != nil {
-		return err
+	var signer *provenance.Signatory
+	if p.SignScheme == "" || p.SignScheme == provenance.SchemePGP {
+		// Load keyring
+		signer, err = provenance.NewFromKeyring(p.Keyring, p.Key)
+		if err != nil {
+			return err
+		}
+		if err := signer.DecryptKey(passphraseFetcher); err != nil {
+			return err
+		}
+	} else {
+		s, err := provenance.LoadSigner(p.SignScheme, p.Key, passphraseFetcher)
+		if err != nil {
+			return err
+		}
+		signer = &provenance.Signatory{Signer: s}
 	}
 
 	sig, err := signer.ClearSign(filename)
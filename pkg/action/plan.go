@@ -0,0 +1,309 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/cli-runtime/pkg/resource"
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v3/pkg/kube"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// plannedResource identifies the resource of a manifest. A resource without
+// namespace in its manifest is in the namespace the Kubernetes client
+// resolves for it, or in the given namespace if the client cannot resolve it.
+func (cfg *Configuration) plannedResource(manifest, namespace string) release.PlannedResource {
+	var head struct {
+		APIVersion string `json:"apiVersion"`
+		Kind       string `json:"kind"`
+		Metadata   struct {
+			Name      string `json:"name"`
+			Namespace string `json:"namespace"`
+		} `json:"metadata"`
+	}
+	// Manifests of releases were validated when they were rendered, so a
+	// manifest that cannot be read is shown without its identity.
+	_ = yaml.Unmarshal([]byte(manifest), &head)
+
+	res := release.PlannedResource{
+		APIVersion: head.APIVersion,
+		Kind:       head.Kind,
+		Name:       head.Metadata.Name,
+		Namespace:  head.Metadata.Namespace,
+	}
+	if res.Namespace != "" {
+		return res
+	}
+	// The client knows which kinds are cluster-scoped.
+	if infos, err := cfg.KubeClient.Build(strings.NewReader(manifest), false); err == nil && len(infos) == 1 {
+		res.Namespace = infos[0].Namespace
+		return res
+	}
+	res.Namespace = namespace
+	return res
+}
+
+// plannedHooks returns the hooks of a release that run on the given events,
+// in the order they would run.
+func (cfg *Configuration) plannedHooks(rel *release.Release, events ...release.HookEvent) []release.PlannedHook {
+	var planned []release.PlannedHook
+	for _, event := range events {
+		var hooks []*release.Hook
+		for _, h := range rel.Hooks {
+			for _, e := range h.Events {
+				if e == event {
+					hooks = append(hooks, h)
+				}
+			}
+		}
+		// Same order as execHook.
+		sort.Stable(hookByWeight(hooks))
+		for _, h := range hooks {
+			planned = append(planned, release.PlannedHook{
+				PlannedResource: cfg.plannedResource(h.Manifest, rel.Namespace),
+				Event:           event,
+				Weight:          h.Weight,
+			})
+		}
+	}
+	return planned
+}
+
+// plannedNamespaces returns the sorted namespaces of the given resources.
+func plannedNamespaces(resources []release.PlannedResource, hooks []release.PlannedHook) []string {
+	seen := map[string]bool{}
+	namespaces := []string{}
+	add := func(ns string) {
+		if ns != "" && !seen[ns] {
+			seen[ns] = true
+			namespaces = append(namespaces, ns)
+		}
+	}
+	for _, r := range resources {
+		add(r.Namespace)
+	}
+	for _, h := range hooks {
+		add(h.Namespace)
+	}
+	sort.Strings(namespaces)
+	return namespaces
+}
+
+// planRelease plans the install or upgrade of a release from the current
+// resources to the target resources, as Update would do it. The resources in
+// adopted are existing resources brought under the release, and the hooks of
+// the given events are the hooks that would run.
+func (cfg *Configuration) planRelease(rel *release.Release, current, target, adopted kube.ResourceList, force bool, events ...release.HookEvent) (*release.Plan, error) {
+	planner, ok := cfg.KubeClient.(kube.InterfacePlan)
+	if !ok {
+		return nil, errors.New("unable to get kubeClient with interface InterfacePlan")
+	}
+	changes, err := planner.Plan(current, target, force)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to plan the changes to the resources")
+	}
+
+	adoptedKeys := map[string]bool{}
+	for _, r := range adopted {
+		adoptedKeys[objectKey(r)] = true
+	}
+
+	plan := &release.Plan{
+		Name:           rel.Name,
+		Namespace:      rel.Namespace,
+		Revision:       rel.Version,
+		Chart:          fmt.Sprintf("%s-%s", rel.Chart.Name(), rel.Chart.Metadata.Version),
+		ManifestDigest: manifestDigest(rel),
+		Changes:        []release.PlannedChange{},
+		Orphaned:       []release.PlannedResource{},
+		Hooks:          cfg.plannedHooks(rel, events...),
+	}
+	var resources []release.PlannedResource
+	for _, c := range changes {
+		res := infoResource(c.Info)
+		if c.Operation == kube.OperationKeep {
+			plan.Orphaned = append(plan.Orphaned, res)
+			continue
+		}
+		op := planOperations[c.Operation]
+		if c.Operation != kube.OperationCreate && c.Operation != kube.OperationDelete && adoptedKeys[objectKey(c.Info)] {
+			op = release.PlanAdopt
+		}
+		plan.Changes = append(plan.Changes, release.PlannedChange{
+			PlannedResource: res,
+			Operation:       op,
+			ResourceVersion: c.ResourceVersion,
+		})
+		resources = append(resources, res)
+	}
+	plan.Namespaces = plannedNamespaces(resources, plan.Hooks)
+	return plan, nil
+}
+
+var planOperations = map[kube.Operation]release.PlanOperation{
+	kube.OperationCreate:  release.PlanCreate,
+	kube.OperationPatch:   release.PlanPatch,
+	kube.OperationReplace: release.PlanReplace,
+	kube.OperationNone:    release.PlanUnchanged,
+	kube.OperationDelete:  release.PlanDelete,
+}
+
+func infoResource(info *resource.Info) release.PlannedResource {
+	res := release.PlannedResource{Name: info.Name, Namespace: info.Namespace}
+	if info.Object != nil {
+		gvk := info.Object.GetObjectKind().GroupVersionKind()
+		res.APIVersion, res.Kind = gvk.GroupVersion().String(), gvk.Kind
+	}
+	if info.Mapping != nil {
+		gvk := info.Mapping.GroupVersionKind
+		res.APIVersion, res.Kind = gvk.GroupVersion().String(), gvk.Kind
+	}
+	return res
+}
+
+// manifestDigest returns the digest of the manifest and hooks of a release.
+func manifestDigest(rel *release.Release) string {
+	h := sha256.New()
+	h.Write([]byte(rel.Manifest))
+	for _, hook := range rel.Hooks {
+		fmt.Fprintf(h, "\n---\n# Source: %s\n%s", hook.Path, hook.Manifest)
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil))
+}
+
+// checkPlan returns an error describing how the actual plan of an operation
+// differs from the expected plan, or nil if they are the same.
+func checkPlan(expected, actual *release.Plan) error {
+	if expected.Name != actual.Name || expected.Namespace != actual.Namespace {
+		return errors.Errorf("the plan is for release %s in namespace %s, not %s in namespace %s", expected.Name, expected.Namespace, actual.Name, actual.Namespace)
+	}
+
+	var diffs []string
+	if expected.Revision != actual.Revision {
+		diffs = append(diffs, fmt.Sprintf("the release would be upgraded to revision %d instead of %d", actual.Revision, expected.Revision))
+	}
+	if expected.Chart != actual.Chart {
+		diffs = append(diffs, fmt.Sprintf("the chart is %s instead of %s", actual.Chart, expected.Chart))
+	}
+	if expected.ManifestDigest != actual.ManifestDigest {
+		diffs = append(diffs, "the rendered manifests differ")
+	}
+
+	key := func(r release.PlannedResource) string {
+		return fmt.Sprintf("%s %s", r.Kind, resourcePath(r.Namespace, r.Name))
+	}
+	planned := map[string]release.PlannedChange{}
+	for _, c := range expected.Changes {
+		planned[key(c.PlannedResource)] = c
+	}
+	for _, c := range actual.Changes {
+		k := key(c.PlannedResource)
+		e, ok := planned[k]
+		delete(planned, k)
+		switch {
+		case !ok:
+			diffs = append(diffs, fmt.Sprintf("%s would be %s but was not planned", k, pastTense(c.Operation)))
+		case e.Operation != c.Operation:
+			diffs = append(diffs, fmt.Sprintf("%s would be %s instead of %s", k, pastTense(c.Operation), pastTense(e.Operation)))
+		case e.ResourceVersion != c.ResourceVersion:
+			diffs = append(diffs, fmt.Sprintf("%s was modified in the cluster", k))
+		}
+	}
+	for _, c := range expected.Changes {
+		k := key(c.PlannedResource)
+		if _, ok := planned[k]; ok {
+			diffs = append(diffs, fmt.Sprintf("%s was planned to be %s but would be left untouched", k, pastTense(c.Operation)))
+		}
+	}
+	diffs = append(diffs, checkPlannedHooks(expected.Hooks, actual.Hooks)...)
+
+	if len(diffs) == 0 {
+		return nil
+	}
+	return errors.Errorf("release %s changed since the plan was made:\n\t%s", actual.Name, strings.Join(diffs, "\n\t"))
+}
+
+// checkPlannedHooks describes how the hooks an operation would run differ
+// from the planned hooks.
+func checkPlannedHooks(expected, actual []release.PlannedHook) []string {
+	key := func(h release.PlannedHook) string {
+		return fmt.Sprintf("hook %s %s on %s", h.Kind, resourcePath(h.Namespace, h.Name), h.Event)
+	}
+	planned := map[string]bool{}
+	for _, h := range expected {
+		planned[key(h)] = true
+	}
+	run := map[string]bool{}
+	for _, h := range actual {
+		run[key(h)] = true
+	}
+
+	var diffs []string
+	for _, h := range actual {
+		if !planned[key(h)] {
+			diffs = append(diffs, fmt.Sprintf("%s would run but was not planned", key(h)))
+		}
+	}
+	for _, h := range expected {
+		if !run[key(h)] {
+			diffs = append(diffs, fmt.Sprintf("%s was planned to run but would not", key(h)))
+		}
+	}
+	if len(diffs) > 0 {
+		return diffs
+	}
+	reordered := []string{"the hooks would run in another order or with other weights"}
+	if len(expected) != len(actual) {
+		return reordered
+	}
+	for i := range expected {
+		if expected[i] != actual[i] {
+			return reordered
+		}
+	}
+	return nil
+}
+
+func resourcePath(namespace, name string) string {
+	if namespace == "" {
+		return name
+	}
+	return namespace + "/" + name
+}
+
+var pastTenses = map[release.PlanOperation]string{
+	release.PlanCreate:  "created",
+	release.PlanPatch:   "patched",
+	release.PlanReplace: "replaced",
+	release.PlanAdopt:   "adopted",
+	release.PlanDelete:  "deleted",
+}
+
+func pastTense(op release.PlanOperation) string {
+	if s, ok := pastTenses[op]; ok {
+		return s
+	}
+	return string(op)
+}
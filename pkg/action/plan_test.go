@@ -0,0 +1,140 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v3/pkg/release"
+)
+
+func TestCheckPlan(t *testing.T) {
+	web := release.PlannedResource{APIVersion: "apps/v1", Kind: "Deployment", Name: "web", Namespace: "default"}
+	cfg := release.PlannedResource{APIVersion: "v1", Kind: "ConfigMap", Name: "config", Namespace: "default"}
+	migrate := release.PlannedHook{
+		PlannedResource: release.PlannedResource{APIVersion: "batch/v1", Kind: "Job", Name: "migrate", Namespace: "default"},
+		Event:           release.HookPreUpgrade,
+	}
+	smoke := release.PlannedHook{
+		PlannedResource: release.PlannedResource{APIVersion: "v1", Kind: "Pod", Name: "smoke", Namespace: "default"},
+		Event:           release.HookPostUpgrade,
+	}
+	newPlan := func(changes ...release.PlannedChange) *release.Plan {
+		return &release.Plan{
+			Name:           "aeneas",
+			Namespace:      "default",
+			Revision:       2,
+			Chart:          "hello-0.1.0",
+			ManifestDigest: "sha256:abc",
+			Changes:        changes,
+			Hooks:          []release.PlannedHook{migrate, smoke},
+		}
+	}
+	expected := newPlan(
+		release.PlannedChange{PlannedResource: web, Operation: release.PlanPatch, ResourceVersion: "10"},
+		release.PlannedChange{PlannedResource: cfg, Operation: release.PlanCreate},
+	)
+
+	tests := []struct {
+		name   string
+		actual func(p *release.Plan)
+		errors []string
+	}{
+		{
+			name:   "same plan",
+			actual: func(_ *release.Plan) {},
+		},
+		{
+			name:   "other release",
+			actual: func(p *release.Plan) { p.Name = "dido" },
+			errors: []string{"the plan is for release aeneas in namespace default, not dido in namespace default"},
+		},
+		{
+			name: "new revision and manifests",
+			actual: func(p *release.Plan) {
+				p.Revision = 3
+				p.ManifestDigest = "sha256:def"
+			},
+			errors: []string{"revision 3 instead of 2", "the rendered manifests differ"},
+		},
+		{
+			name:   "modified resource",
+			actual: func(p *release.Plan) { p.Changes[0].ResourceVersion = "11" },
+			errors: []string{"Deployment default/web was modified in the cluster"},
+		},
+		{
+			name:   "other operation",
+			actual: func(p *release.Plan) { p.Changes[1].Operation = release.PlanAdopt },
+			errors: []string{"ConfigMap default/config would be adopted instead of created"},
+		},
+		{
+			name: "unplanned and missing resources",
+			actual: func(p *release.Plan) {
+				p.Changes[1].Name = "settings"
+			},
+			errors: []string{
+				"ConfigMap default/settings would be created but was not planned",
+				"ConfigMap default/config was planned to be created but would be left untouched",
+			},
+		},
+		{
+			name:   "hooks disabled",
+			actual: func(p *release.Plan) { p.Hooks = nil },
+			errors: []string{
+				"hook Job default/migrate on pre-upgrade was planned to run but would not",
+				"hook Pod default/smoke on post-upgrade was planned to run but would not",
+			},
+		},
+		{
+			name: "unplanned hook",
+			actual: func(p *release.Plan) {
+				p.Hooks = append(p.Hooks, release.PlannedHook{PlannedResource: web, Event: release.HookPostUpgrade})
+			},
+			errors: []string{"hook Deployment default/web on post-upgrade would run but was not planned"},
+		},
+		{
+			name: "reweighted hook",
+			actual: func(p *release.Plan) {
+				p.Hooks = []release.PlannedHook{migrate, smoke}
+				p.Hooks[0].Weight = 5
+			},
+			errors: []string{"the hooks would run in another order or with other weights"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := newPlan(append([]release.PlannedChange{}, expected.Changes...)...)
+			tt.actual(actual)
+			err := checkPlan(expected, actual)
+			if len(tt.errors) == 0 {
+				if err != nil {
+					t.Fatalf("expected the plans to match, got %s", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected the plans to differ")
+			}
+			for _, e := range tt.errors {
+				if !strings.Contains(err.Error(), e) {
+					t.Errorf("expected error to contain %q, got %q", e, err)
+				}
+			}
+		})
+	}
+}
@@ -92,9 +92,13 @@ func (p *Pull) Run(chartRef string) (string, error) {
 			getter.WithInsecureSkipVerifyTLS(p.InsecureSkipTLSverify),
 			getter.WithPlainHTTP(p.PlainHTTP),
 		},
-		RegistryClient:   p.cfg.RegistryClient,
-		RepositoryConfig: p.Settings.RepositoryConfig,
-		RepositoryCache:  p.Settings.RepositoryCache,
+		RegistryClient:    p.cfg.RegistryClient,
+		RepositoryConfig:  p.Settings.RepositoryConfig,
+		RepositoryCache:   p.Settings.RepositoryCache,
+		TrustPolicyConfig: p.Settings.TrustPolicyConfig,
+		RegistryConfig:    p.Settings.RegistryConfig,
+		ChartCache:        p.Settings.ChartCache,
+		Offline:           p.Offline,
 	}
 
 	if registry.IsOCI(chartRef) {
@@ -135,10 +139,10 @@ func (p *Pull) Run(chartRef string) (string, error) {
 	}
 
 	if p.Verify {
-		for name := range v.SignedBy.Identities {
+		for _, name := range v.Identity.Names {
 			fmt.Fprintf(&out, "Signed by: %v\n", name)
 		}
-		fmt.Fprintf(&out, "Using Key With Fingerprint: %X\n", v.SignedBy.PrimaryKey.Fingerprint)
+		fmt.Fprintf(&out, "Using Key With Fingerprint: %s\n", v.Identity.Fingerprint)
 		fmt.Fprintf(&out, "Chart Hash Verified: %s\n", v.FileHash)
 	}
 
@@ -149,11 +153,16 @@ func (p *Pull) Run(chartRef string) (string, error) {
 			ud = filepath.Join(p.DestDir, ud)
 		}
 		// Let udCheck to check conflict file/dir without replacing ud when untarDir is the current directory(.).
+		_, chartName := filepath.Split(chartRef)
+		if registry.IsOCI(chartRef) {
+			// Drop any digest and tag the OCI reference is pinned to
+			chartName, _, _ = strings.Cut(chartName, "@")
+			chartName, _, _ = strings.Cut(chartName, ":")
+		}
 		udCheck := ud
 		if udCheck == "." {
-			_, udCheck = filepath.Split(chartRef)
+			udCheck = chartName
 		} else {
-			_, chartName := filepath.Split(chartRef)
 			udCheck = filepath.Join(udCheck, chartName)
 		}
 
@@ -17,12 +17,22 @@ limitations under the License.
 package action
 
 import (
+	"fmt"
 	"io"
+	"os"
+	"path"
 	"strings"
+	"time"
 
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/internal/urlutil"
+	"helm.sh/helm/v3/pkg/chart/loader"
 	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/provenance"
 	"helm.sh/helm/v3/pkg/pusher"
 	"helm.sh/helm/v3/pkg/registry"
+	"helm.sh/helm/v3/pkg/repo"
 	"helm.sh/helm/v3/pkg/uploader"
 )
 
@@ -38,6 +48,20 @@ type Push struct {
 	insecureSkipTLSverify bool
 	plainHTTP             bool
 	out                   io.Writer
+	sign                  bool
+	key                   string
+	keyring               string
+	passphraseFile        string
+	attestations          []attestation
+	force                 bool
+	uploadAPI             string
+	creationTime          time.Time
+}
+
+// attestation is a file attached to a pushed chart as an OCI referrer.
+type attestation struct {
+	artifactType string
+	path         string
 }
 
 // PushOpt is a type of function that sets options for a push action.
@@ -80,6 +104,51 @@ func WithPushOptWriter(out io.Writer) PushOpt {
 	}
 }
 
+// WithPushSign signs the pushed chart with the named key from the keyring. The
+// signature is attached to the chart manifest as an OCI referrer.
+func WithPushSign(key, keyring, passphraseFile string) PushOpt {
+	return func(p *Push) {
+		p.sign = true
+		p.key = key
+		p.keyring = keyring
+		p.passphraseFile = passphraseFile
+	}
+}
+
+// WithPushAttestation attaches the file at path, such as an SBOM or a
+// provenance statement, to the pushed chart manifest as an OCI referrer of the
+// given artifact type.
+func WithPushAttestation(artifactType, path string) PushOpt {
+	return func(p *Push) {
+		p.attestations = append(p.attestations, attestation{artifactType: artifactType, path: path})
+	}
+}
+
+// WithPushForce overwrites a chart version that already exists in an HTTP
+// chart repository.
+func WithPushForce(force bool) PushOpt {
+	return func(p *Push) {
+		p.force = force
+	}
+}
+
+// WithPushUploadAPI sets the API used to upload charts to HTTP chart
+// repositories, pusher.UploadChartMuseum or pusher.UploadPut.
+func WithPushUploadAPI(api string) PushOpt {
+	return func(p *Push) {
+		p.uploadAPI = api
+	}
+}
+
+// WithPushCreationTime sets the creation time recorded in the manifest of a
+// chart pushed to an OCI registry, so that pushing the same chart archive
+// always gives the same manifest digest.
+func WithPushCreationTime(t time.Time) PushOpt {
+	return func(p *Push) {
+		p.creationTime = t
+	}
+}
+
 // NewPushWithOpts creates a new push, with configuration options.
 func NewPushWithOpts(opts ...PushOpt) *Push {
 	p := &Push{}
@@ -100,6 +169,7 @@ func (p *Push) Run(chartRef string, remote string) (string, error) {
 			pusher.WithTLSClientConfig(p.certFile, p.keyFile, p.caFile),
 			pusher.WithInsecureSkipTLSVerify(p.insecureSkipTLSverify),
 			pusher.WithPlainHTTP(p.plainHTTP),
+			pusher.WithCreationTime(p.creationTime),
 		},
 	}
 
@@ -108,5 +178,117 @@ func (p *Push) Run(chartRef string, remote string) (string, error) {
 		c.Options = append(c.Options, pusher.WithRegistryClient(p.cfg.RegistryClient))
 	}
 
-	return out.String(), c.UploadTo(chartRef, remote)
+	if !registry.IsOCI(remote) {
+		if p.sign || len(p.attestations) > 0 {
+			return "", errors.New("signatures and attestations can only be attached to charts pushed to an OCI registry")
+		}
+		entry, err := p.repoEntry(remote)
+		if err != nil {
+			return "", err
+		}
+		c.Options = append(c.Options,
+			pusher.WithForce(p.force),
+			pusher.WithUploadAPI(p.uploadAPI))
+		if entry != nil {
+			c.Options = append(c.Options, pusher.WithBasicAuth(entry.Username, entry.Password))
+			if p.certFile == "" && p.keyFile == "" && p.caFile == "" {
+				c.Options = append(c.Options, pusher.WithTLSClientConfig(entry.CertFile, entry.KeyFile, entry.CAFile))
+			}
+			if entry.InsecureSkipTLSverify {
+				c.Options = append(c.Options, pusher.WithInsecureSkipTLSVerify(true))
+			}
+		}
+		return out.String(), c.UploadTo(chartRef, remote)
+	}
+
+	// Load the key before uploading so that a wrong passphrase does not leave
+	// an unsigned chart behind.
+	var signer *provenance.Signatory
+	if p.sign {
+		var err error
+		if signer, err = p.signatory(); err != nil {
+			return out.String(), err
+		}
+	}
+
+	if err := c.UploadTo(chartRef, remote); err != nil {
+		return out.String(), err
+	}
+	if signer == nil && len(p.attestations) == 0 {
+		return out.String(), nil
+	}
+
+	meta, err := loader.Load(chartRef)
+	if err != nil {
+		return out.String(), err
+	}
+	repository := path.Join(strings.TrimPrefix(remote, fmt.Sprintf("%s://", registry.OCIScheme)), meta.Metadata.Name)
+	ref := fmt.Sprintf("%s:%s", repository, meta.Metadata.Version)
+
+	client := p.cfg.RegistryClient
+	if signer != nil {
+		if _, err := client.PushSignature(ref, registry.SignatureLayerMediaType, signer.DetachSign); err != nil {
+			return out.String(), err
+		}
+	}
+	for _, a := range p.attestations {
+		data, err := os.ReadFile(a.path)
+		if err != nil {
+			return out.String(), err
+		}
+		layers := []registry.Blob{{MediaType: a.artifactType, Data: data}}
+		dgst, err := client.PushReferrer(ref, a.artifactType, layers, nil)
+		if err != nil {
+			return out.String(), err
+		}
+		// The attestation is signed like the chart, otherwise anyone who can
+		// push to the repository could vouch for it.
+		if signer != nil {
+			if _, err := client.PushSignature(repository+"@"+dgst, registry.SignatureLayerMediaType, signer.DetachSign); err != nil {
+				return out.String(), err
+			}
+		}
+	}
+	return out.String(), nil
+}
+
+// repoEntry returns the entry of the repositories file for the repository at
+// the given URL, or nil if the repository has not been added.
+func (p *Push) repoEntry(remote string) (*repo.Entry, error) {
+	if p.Settings == nil {
+		return nil, nil
+	}
+	rf, err := repo.LoadFile(p.Settings.RepositoryConfig)
+	if err != nil {
+		if os.IsNotExist(errors.Cause(err)) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	for _, e := range rf.Repositories {
+		if urlutil.Equal(e.URL, remote) {
+			return e, nil
+		}
+	}
+	return nil, nil
+}
+
+// signatory loads and unlocks the signing key.
+func (p *Push) signatory() (*provenance.Signatory, error) {
+	signer, err := provenance.NewFromKeyring(p.keyring, p.key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load signing key")
+	}
+
+	passphraseFetcher := promptUser
+	if p.passphraseFile != "" {
+		passphraseFetcher, err = passphraseFileFetcher(p.passphraseFile, os.Stdin)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := signer.DecryptKey(passphraseFetcher); err != nil {
+		return nil, err
+	}
+	return signer, nil
 }
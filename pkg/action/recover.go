@@ -0,0 +1,146 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/release"
+	helmtime "helm.sh/helm/v3/pkg/time"
+)
+
+// Recover is the action for recovering a release whose last operation was
+// interrupted, leaving its last revision in a pending state.
+//
+// It provides the implementation of 'helm release recover'.
+type Recover struct {
+	cfg *Configuration
+
+	// MinAge is how long the last revision must have been pending before it
+	// is considered stuck. Helm does not update a pending revision until its
+	// operation ends, so a younger revision may belong to an operation that
+	// is still running but does not lock the release, such as one run by an
+	// older version of Helm.
+	MinAge time.Duration
+	// Force recovers the release even if its last revision has been pending
+	// for less than MinAge.
+	Force bool
+	// Rollback rolls the release back to its last deployed revision once the
+	// pending revision is marked as failed.
+	Rollback bool
+	DryRun   bool
+
+	// Timeout, Wait, WaitForJobs and DisableHooks apply to the rollback.
+	Timeout      time.Duration
+	Wait         bool
+	WaitForJobs  bool
+	DisableHooks bool
+}
+
+// RecoverResult describes the recovery of a release.
+type RecoverResult struct {
+	// Release is the revision that was pending, marked as failed.
+	Release *release.Release
+	// PendingStatus is the status the revision was stuck in.
+	PendingStatus release.Status
+	// RolledBackTo is the revision the release was rolled back to, or 0.
+	RolledBackTo int
+}
+
+// NewRecover creates a new Recover object with the given configuration.
+func NewRecover(cfg *Configuration) *Recover {
+	return &Recover{
+		cfg: cfg,
+	}
+}
+
+// Run executes 'helm release recover' against the given release.
+func (r *Recover) Run(name string) (_ *RecoverResult, err error) {
+	if err := chartutil.ValidateReleaseName(name); err != nil {
+		return nil, errors.Errorf("recover: Release name is invalid: %s", name)
+	}
+
+	// An operation that is still running holds the lock of the release.
+	unlock, err := r.cfg.lockRelease(name)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock(&err)
+
+	last, err := r.cfg.Releases.Last(name)
+	if err != nil {
+		return nil, err
+	}
+	status := last.Info.Status
+	if !status.IsPending() {
+		return nil, errors.Errorf("release %s is not stuck: its last revision %d is %s", name, last.Version, status)
+	}
+
+	pendingFor := helmtime.Now().Sub(last.Info.LastDeployed).Truncate(time.Second)
+	if !r.Force && pendingFor < r.MinAge {
+		return nil, errors.Errorf("revision %d of release %s has been %s for less than %s, its operation may still be running (use --force to recover it anyway)", last.Version, name, status, r.MinAge)
+	}
+
+	// Some storage drivers return the stored release itself, so a copy is
+	// updated to leave it untouched in a dry run.
+	failed := *last
+	info := *last.Info
+	failed.Info = &info
+	result := &RecoverResult{Release: &failed, PendingStatus: status}
+
+	var deployed *release.Release
+	if r.Rollback {
+		deployed, err = r.cfg.Releases.Deployed(name)
+		if err != nil && !strings.Contains(err.Error(), "has no deployed releases") {
+			return nil, err
+		}
+		if deployed == nil {
+			return nil, errors.Errorf("release %s has no deployed revision to roll back to", name)
+		}
+	}
+
+	r.cfg.Log("marking revision %d of %s as failed (was %s for %s)", last.Version, name, status, pendingFor)
+	failed.SetStatus(release.StatusFailed, fmt.Sprintf("Recovered after %s was interrupted: %s", status, last.Info.Description))
+	if !r.DryRun {
+		if err := r.cfg.Releases.Update(&failed); err != nil {
+			return nil, errors.Wrapf(err, "failed to mark revision %d of release %s as failed", last.Version, name)
+		}
+	}
+
+	if deployed == nil {
+		return result, nil
+	}
+
+	rollback := NewRollback(r.cfg)
+	rollback.Version = deployed.Version
+	rollback.DryRun = r.DryRun
+	rollback.Timeout = r.Timeout
+	rollback.Wait = r.Wait
+	rollback.WaitForJobs = r.WaitForJobs
+	rollback.DisableHooks = r.DisableHooks
+	rollback.locked = true
+	if err := rollback.Run(name); err != nil {
+		return result, errors.Wrapf(err, "failed to roll back release %s to revision %d", name, deployed.Version)
+	}
+	result.RolledBackTo = deployed.Version
+	return result, nil
+}
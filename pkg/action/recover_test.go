@@ -0,0 +1,156 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"helm.sh/helm/v3/pkg/release"
+	helmtime "helm.sh/helm/v3/pkg/time"
+)
+
+func TestRecover(t *testing.T) {
+	stuck := func(version int, status release.Status, age time.Duration) *release.Release {
+		rel := namedReleaseStub("stuck", status)
+		rel.Version = version
+		rel.Info.LastDeployed = helmtime.Now().Add(-age)
+		return rel
+	}
+
+	tests := []struct {
+		name     string
+		rels     []*release.Release
+		force    bool
+		rollback bool
+		dryRun   bool
+		// wantStatus is the status of each stored revision after the recovery.
+		wantStatus   []release.Status
+		wantRollback int
+		wantErr      string
+	}{
+		{
+			name:       "not pending",
+			rels:       []*release.Release{stuck(1, release.StatusDeployed, time.Hour)},
+			wantStatus: []release.Status{release.StatusDeployed},
+			wantErr:    "release stuck is not stuck: its last revision 1 is deployed",
+		},
+		{
+			name:       "pending for less than the minimum age",
+			rels:       []*release.Release{stuck(1, release.StatusPendingInstall, time.Minute)},
+			wantStatus: []release.Status{release.StatusPendingInstall},
+			wantErr:    "its operation may still be running",
+		},
+		{
+			name:       "forced",
+			rels:       []*release.Release{stuck(1, release.StatusPendingInstall, time.Minute)},
+			force:      true,
+			wantStatus: []release.Status{release.StatusFailed},
+		},
+		{
+			name:       "stuck install",
+			rels:       []*release.Release{stuck(1, release.StatusPendingInstall, time.Hour)},
+			wantStatus: []release.Status{release.StatusFailed},
+		},
+		{
+			name:       "dry run",
+			rels:       []*release.Release{stuck(1, release.StatusPendingInstall, time.Hour)},
+			dryRun:     true,
+			wantStatus: []release.Status{release.StatusPendingInstall},
+		},
+		{
+			name: "stuck upgrade rolled back",
+			rels: []*release.Release{
+				stuck(1, release.StatusDeployed, 2*time.Hour),
+				stuck(2, release.StatusPendingUpgrade, time.Hour),
+			},
+			rollback:     true,
+			wantStatus:   []release.Status{release.StatusSuperseded, release.StatusFailed, release.StatusDeployed},
+			wantRollback: 1,
+		},
+		{
+			name: "rollback in a dry run",
+			rels: []*release.Release{
+				stuck(1, release.StatusDeployed, 2*time.Hour),
+				stuck(2, release.StatusPendingUpgrade, time.Hour),
+			},
+			rollback:     true,
+			dryRun:       true,
+			wantStatus:   []release.Status{release.StatusDeployed, release.StatusPendingUpgrade},
+			wantRollback: 1,
+		},
+		{
+			name:       "no deployed revision to roll back to",
+			rels:       []*release.Release{stuck(1, release.StatusPendingInstall, time.Hour)},
+			rollback:   true,
+			wantStatus: []release.Status{release.StatusPendingInstall},
+			wantErr:    "release stuck has no deployed revision to roll back to",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := assert.New(t)
+			cfg := actionConfigFixture(t)
+			for _, rel := range tt.rels {
+				is.NoError(cfg.Releases.Create(rel))
+			}
+
+			client := NewRecover(cfg)
+			client.MinAge = 10 * time.Minute
+			client.Force = tt.force
+			client.Rollback = tt.rollback
+			client.DryRun = tt.dryRun
+			res, err := client.Run("stuck")
+			if tt.wantErr != "" {
+				is.ErrorContains(err, tt.wantErr)
+			} else {
+				is.NoError(err)
+				is.Equal(release.StatusFailed, res.Release.Info.Status)
+				is.Contains(res.Release.Info.Description, "was interrupted")
+				is.Equal(tt.wantRollback, res.RolledBackTo)
+			}
+
+			history, err := cfg.Releases.History("stuck")
+			is.NoError(err)
+			var statuses []release.Status
+			for _, rel := range history {
+				statuses = append(statuses, rel.Info.Status)
+			}
+			is.ElementsMatch(tt.wantStatus, statuses)
+		})
+	}
+}
+
+func TestRecoverLocked(t *testing.T) {
+	is := assert.New(t)
+	cfg := actionConfigFixture(t)
+	rel := namedReleaseStub("stuck", release.StatusPendingUpgrade)
+	rel.Info.LastDeployed = helmtime.Now().Add(-time.Hour)
+	is.NoError(cfg.Releases.Create(rel))
+
+	// The upgrade is still running, and holds the lock of the release.
+	is.NoError(cfg.Releases.Locker.AcquireLock("stuck", "someone@elsewhere/1", time.Minute))
+
+	_, err := NewRecover(cfg).Run("stuck")
+	is.ErrorContains(err, "is locked by someone@elsewhere/1")
+	last, err := cfg.Releases.Last("stuck")
+	is.NoError(err)
+	is.Equal(release.StatusPendingUpgrade, last.Info.Status)
+}
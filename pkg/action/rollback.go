@@ -19,13 +19,16 @@ package action
 import (
 	"bytes"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/pkg/errors"
 
+	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/releaseutil"
 	helmtime "helm.sh/helm/v3/pkg/time"
 )
 
@@ -45,6 +48,26 @@ type Rollback struct {
 	Force         bool // will (if true) force resource upgrade through uninstall/recreate if needed
 	CleanupOnFail bool
 	MaxHistory    int // MaxHistory limits the maximum number of revisions saved per release
+	// ChangeCause is why the release is rolled back, recorded on the revision
+	ChangeCause string
+
+	// ToStatus, if set, rolls back to the last revision before the current
+	// one with this status. As a deployed revision is superseded once it is
+	// replaced, StatusDeployed also matches superseded revisions.
+	ToStatus release.Status
+	// ToLabels, if set, rolls back to the last revision before the current
+	// one carrying all these labels.
+	ToLabels map[string]string
+	// ToChartVersion, if set, rolls back to the last revision before the
+	// current one made with this version of the chart.
+	ToChartVersion string
+	// ToAppVersion, if set, rolls back to the last revision before the
+	// current one made with a chart of this app version.
+	ToAppVersion string
+
+	// locked is set when the rollback is run by an operation that already
+	// holds the lock of the release.
+	locked bool
 }
 
 // NewRollback creates a new Rollback object with the given configuration.
@@ -55,13 +78,21 @@ func NewRollback(cfg *Configuration) *Rollback {
 }
 
 // Run executes 'helm rollback' against the given release.
-func (r *Rollback) Run(name string) error {
+func (r *Rollback) Run(name string) (err error) {
 	if err := r.cfg.KubeClient.IsReachable(); err != nil {
 		return err
 	}
 
 	r.cfg.Releases.MaxHistory = r.MaxHistory
 
+	if !r.DryRun && !r.locked && chartutil.ValidateReleaseName(name) == nil {
+		unlock, lockErr := r.cfg.lockRelease(name)
+		if lockErr != nil {
+			return lockErr
+		}
+		defer unlock(&err)
+	}
+
 	r.cfg.Log("preparing rollback of %s", name)
 	currentRelease, targetRelease, err := r.prepareRollback(name)
 	if err != nil {
@@ -89,9 +120,55 @@ func (r *Rollback) Run(name string) error {
 	return nil
 }
 
+// Preview returns the current release and the revision a rollback would roll
+// back to, without changing the release or the cluster.
+func (r *Rollback) Preview(name string) (*release.Release, *release.Release, error) {
+	if err := r.cfg.KubeClient.IsReachable(); err != nil {
+		return nil, nil, err
+	}
+	return r.findPreviousRelease(name)
+}
+
 // prepareRollback finds the previous release and prepares a new release object with
 // the previous release's configuration
 func (r *Rollback) prepareRollback(name string) (*release.Release, *release.Release, error) {
+	currentRelease, previousRelease, err := r.findPreviousRelease(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	previousVersion := previousRelease.Version
+
+	// Store a new release object with previous release's configuration
+	targetRelease := &release.Release{
+		Name:      name,
+		Namespace: currentRelease.Namespace,
+		Chart:     previousRelease.Chart,
+		Config:    previousRelease.Config,
+		Info: &release.Info{
+			FirstDeployed: currentRelease.Info.FirstDeployed,
+			LastDeployed:  helmtime.Now(),
+			Status:        release.StatusPendingRollback,
+			Notes:         previousRelease.Info.Notes,
+			ChartDigest:   previousRelease.Info.ChartDigest,
+			Origin:        r.cfg.origin(chartSource(previousRelease), r.ChangeCause, r.DryRun),
+			// Because we lose the reference to previous version elsewhere, we set the
+			// message here, and only override it later if we experience failure.
+			Description: fmt.Sprintf("Rollback to %d", previousVersion),
+		},
+		Version:  currentRelease.Version + 1,
+		Labels:   previousRelease.Labels,
+		Manifest: previousRelease.Manifest,
+		Hooks:    previousRelease.Hooks,
+	}
+
+	return currentRelease, targetRelease, nil
+}
+
+// findPreviousRelease returns the current release and the revision to roll
+// back to: Version, the last revision before the current one matching
+// ToStatus, ToLabels, ToChartVersion and ToAppVersion if any is set, or else
+// the revision before the current one.
+func (r *Rollback) findPreviousRelease(name string) (*release.Release, *release.Release, error) {
 	if err := chartutil.ValidateReleaseName(name); err != nil {
 		return nil, nil, errors.Errorf("prepareRollback: Release name is invalid: %s", name)
 	}
@@ -99,22 +176,35 @@ func (r *Rollback) prepareRollback(name string) (*release.Release, *release.Rele
 	if r.Version < 0 {
 		return nil, nil, errInvalidRevision
 	}
+	if err := checkRollbackStatus(r.ToStatus); err != nil {
+		return nil, nil, err
+	}
+	criteria := r.targetCriteria()
+	if r.Version > 0 && len(criteria) > 0 {
+		return nil, nil, errors.Errorf("a revision to roll back to cannot be given together with %s", strings.Join(criteria, ", "))
+	}
 
 	currentRelease, err := r.cfg.Releases.Last(name)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	previousVersion := r.Version
-	if r.Version == 0 {
-		previousVersion = currentRelease.Version - 1
-	}
-
 	historyReleases, err := r.cfg.Releases.History(name)
 	if err != nil {
 		return nil, nil, err
 	}
 
+	previousVersion := r.Version
+	if len(criteria) > 0 {
+		previousRelease := r.matchingRelease(currentRelease, historyReleases)
+		if previousRelease == nil {
+			return nil, nil, errors.Errorf("release %s has no revision before revision %d with %s", name, currentRelease.Version, strings.Join(criteria, ", "))
+		}
+		previousVersion = previousRelease.Version
+	} else if r.Version == 0 {
+		previousVersion = currentRelease.Version - 1
+	}
+
 	// Check if the history version to be rolled back exists
 	previousVersionExist := false
 	for _, historyRelease := range historyReleases {
@@ -134,29 +224,107 @@ func (r *Rollback) prepareRollback(name string) (*release.Release, *release.Rele
 	if err != nil {
 		return nil, nil, err
 	}
+	return currentRelease, previousRelease, nil
+}
+
+// targetCriteria describes what the revision to roll back to must match,
+// other than its number.
+func (r *Rollback) targetCriteria() []string {
+	var criteria []string
+	if r.ToStatus != "" {
+		criteria = append(criteria, fmt.Sprintf("status %s", r.ToStatus))
+	}
+	if len(r.ToLabels) > 0 {
+		keys := make([]string, 0, len(r.ToLabels))
+		for k := range r.ToLabels {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		labels := make([]string, 0, len(keys))
+		for _, k := range keys {
+			labels = append(labels, k+"="+r.ToLabels[k])
+		}
+		criteria = append(criteria, fmt.Sprintf("labels %s", strings.Join(labels, ",")))
+	}
+	if r.ToChartVersion != "" {
+		criteria = append(criteria, fmt.Sprintf("chart version %s", r.ToChartVersion))
+	}
+	if r.ToAppVersion != "" {
+		criteria = append(criteria, fmt.Sprintf("app version %s", r.ToAppVersion))
+	}
+	return criteria
+}
 
-	// Store a new release object with previous release's configuration
-	targetRelease := &release.Release{
-		Name:      name,
-		Namespace: currentRelease.Namespace,
-		Chart:     previousRelease.Chart,
-		Config:    previousRelease.Config,
-		Info: &release.Info{
-			FirstDeployed: currentRelease.Info.FirstDeployed,
-			LastDeployed:  helmtime.Now(),
-			Status:        release.StatusPendingRollback,
-			Notes:         previousRelease.Info.Notes,
-			// Because we lose the reference to previous version elsewhere, we set the
-			// message here, and only override it later if we experience failure.
-			Description: fmt.Sprintf("Rollback to %d", previousVersion),
-		},
-		Version:  currentRelease.Version + 1,
-		Labels:   previousRelease.Labels,
-		Manifest: previousRelease.Manifest,
-		Hooks:    previousRelease.Hooks,
+// matchingRelease returns the last release of the history before the current
+// one that matches the target criteria, or nil if there is none.
+func (r *Rollback) matchingRelease(currentRelease *release.Release, history []*release.Release) *release.Release {
+	history = append([]*release.Release(nil), history...)
+	releaseutil.Reverse(history, releaseutil.SortByRevision)
+	for _, rel := range history {
+		if rel.Version < currentRelease.Version && r.matches(rel) {
+			return rel
+		}
 	}
+	return nil
+}
 
-	return currentRelease, targetRelease, nil
+func (r *Rollback) matches(rel *release.Release) bool {
+	if r.ToStatus != "" {
+		status := rel.Info.Status
+		if status != r.ToStatus && (r.ToStatus != release.StatusDeployed || status != release.StatusSuperseded) {
+			return false
+		}
+	}
+	for k, v := range r.ToLabels {
+		if value, ok := rel.Labels[k]; !ok || value != v {
+			return false
+		}
+	}
+	var metadata chart.Metadata
+	if rel.Chart != nil && rel.Chart.Metadata != nil {
+		metadata = *rel.Chart.Metadata
+	}
+	if r.ToChartVersion != "" && metadata.Version != r.ToChartVersion {
+		return false
+	}
+	return r.ToAppVersion == "" || metadata.AppVersion == r.ToAppVersion
+}
+
+// releaseStatuses are the statuses a revision can have.
+var releaseStatuses = []release.Status{
+	release.StatusUnknown,
+	release.StatusDeployed,
+	release.StatusUninstalled,
+	release.StatusSuperseded,
+	release.StatusFailed,
+	release.StatusUninstalling,
+	release.StatusPendingInstall,
+	release.StatusPendingUpgrade,
+	release.StatusPendingRollback,
+}
+
+// checkRollbackStatus returns an error if a status to roll back to is set
+// but is not the status of any revision.
+func checkRollbackStatus(status release.Status) error {
+	if status == "" {
+		return nil
+	}
+	names := make([]string, 0, len(releaseStatuses))
+	for _, s := range releaseStatuses {
+		if s == status {
+			return nil
+		}
+		names = append(names, s.String())
+	}
+	return errors.Errorf("invalid status %q to roll back to, expected one of %s", status, strings.Join(names, ", "))
+}
+
+// chartSource returns where the chart of a release came from, if recorded.
+func chartSource(rel *release.Release) string {
+	if rel.Info.Origin == nil {
+		return ""
+	}
+	return rel.Info.Origin.ChartSource
 }
 
 func (r *Rollback) performRollback(currentRelease, targetRelease *release.Release) (*release.Release, error) {
@@ -173,6 +341,9 @@ func (r *Rollback) performRollback(currentRelease, targetRelease *release.Releas
 	if err != nil {
 		return targetRelease, errors.Wrap(err, "unable to build kubernetes objects from new release manifest")
 	}
+	if targetRelease.Waves, err = releaseWaves(target); err != nil {
+		return targetRelease, err
+	}
 
 	// pre-rollback hooks
 	if !r.DisableHooks {
@@ -188,7 +359,7 @@ func (r *Rollback) performRollback(currentRelease, targetRelease *release.Releas
 	if err != nil {
 		return targetRelease, errors.Wrap(err, "unable to set metadata visitor from target release")
 	}
-	results, err := r.cfg.KubeClient.Update(current, target, r.Force)
+	results, err := r.cfg.applyInWaves(current, target, r.Force, false, r.cfg.waitForWave(r.Wait, r.WaitForJobs, r.Timeout))
 
 	if err != nil {
 		msg := fmt.Sprintf("Rollback %q failed: %s", targetRelease.Name, err)
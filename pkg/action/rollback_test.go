@@ -0,0 +1,145 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v3/pkg/release"
+)
+
+func rollbackHistoryFixture(t *testing.T) *Configuration {
+	t.Helper()
+	cfg := actionConfigFixture(t)
+	revisions := []struct {
+		status       release.Status
+		chartVersion string
+		appVersion   string
+		labels       map[string]string
+	}{
+		{release.StatusSuperseded, "0.1.0", "1.0", map[string]string{"approved": "true"}},
+		{release.StatusSuperseded, "0.2.0", "1.0", nil},
+		{release.StatusFailed, "0.3.0", "1.1", nil},
+		{release.StatusDeployed, "0.3.0", "1.1", nil},
+	}
+	for i, r := range revisions {
+		rel := namedReleaseStub("rollme", r.status)
+		rel.Version = i + 1
+		rel.Chart.Metadata.Version = r.chartVersion
+		rel.Chart.Metadata.AppVersion = r.appVersion
+		rel.Labels = r.labels
+		require.NoError(t, cfg.Releases.Create(rel))
+	}
+	return cfg
+}
+
+func TestRollbackTarget(t *testing.T) {
+	tests := []struct {
+		name    string
+		options func(r *Rollback)
+		want    int
+		err     string
+	}{
+		{
+			name:    "previous revision",
+			options: func(_ *Rollback) {},
+			want:    3,
+		},
+		{
+			name:    "revision number",
+			options: func(r *Rollback) { r.Version = 1 },
+			want:    1,
+		},
+		{
+			name:    "last deployed revision",
+			options: func(r *Rollback) { r.ToStatus = release.StatusDeployed },
+			want:    2,
+		},
+		{
+			name:    "last failed revision",
+			options: func(r *Rollback) { r.ToStatus = release.StatusFailed },
+			want:    3,
+		},
+		{
+			name:    "labelled revision",
+			options: func(r *Rollback) { r.ToLabels = map[string]string{"approved": "true"} },
+			want:    1,
+		},
+		{
+			name:    "chart version",
+			options: func(r *Rollback) { r.ToChartVersion = "0.2.0" },
+			want:    2,
+		},
+		{
+			name:    "app version",
+			options: func(r *Rollback) { r.ToAppVersion = "1.0" },
+			want:    2,
+		},
+		{
+			name: "several criteria",
+			options: func(r *Rollback) {
+				r.ToStatus = release.StatusDeployed
+				r.ToAppVersion = "1.1"
+			},
+			err: "release rollme has no revision before revision 4 with status deployed, app version 1.1",
+		},
+		{
+			name: "revision and criteria",
+			options: func(r *Rollback) {
+				r.Version = 1
+				r.ToLabels = map[string]string{"approved": "true", "team": "web"}
+			},
+			err: "a revision to roll back to cannot be given together with labels approved=true,team=web",
+		},
+		{
+			name:    "unknown status",
+			options: func(r *Rollback) { r.ToStatus = "deploy" },
+			err:     `invalid status "deploy" to roll back to, expected one of unknown, deployed, uninstalled, superseded, failed, uninstalling, pending-install, pending-upgrade, pending-rollback`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rollback := NewRollback(rollbackHistoryFixture(t))
+			tt.options(rollback)
+			current, previous, err := rollback.Preview("rollme")
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, 4, current.Version)
+			assert.Equal(t, tt.want, previous.Version)
+		})
+	}
+}
+
+func TestRollbackToStatus(t *testing.T) {
+	cfg := rollbackHistoryFixture(t)
+	rollback := NewRollback(cfg)
+	rollback.ToStatus = release.StatusDeployed
+	require.NoError(t, rollback.Run("rollme"))
+
+	rel, err := cfg.Releases.Last("rollme")
+	require.NoError(t, err)
+	assert.Equal(t, 5, rel.Version)
+	assert.Equal(t, release.StatusDeployed, rel.Info.Status)
+	assert.Equal(t, "Rollback to 2", rel.Info.Description)
+	assert.Equal(t, "0.2.0", rel.Chart.Metadata.Version)
+}
@@ -45,6 +45,10 @@ type Uninstall struct {
 	DeletionPropagation string
 	Timeout             time.Duration
 	Description         string
+
+	// locked is set when the uninstall is run by an operation that already
+	// holds the lock of the release.
+	locked bool
 }
 
 // NewUninstall creates a new Uninstall object with the given configuration.
@@ -55,24 +59,35 @@ func NewUninstall(cfg *Configuration) *Uninstall {
 }
 
 // Run uninstalls the given release.
-func (u *Uninstall) Run(name string) (*release.UninstallReleaseResponse, error) {
+func (u *Uninstall) Run(name string) (_ *release.UninstallReleaseResponse, err error) {
 	if err := u.cfg.KubeClient.IsReachable(); err != nil {
 		return nil, err
 	}
 
 	if u.DryRun {
-		// In the dry run case, just see if the release exists
 		r, err := u.cfg.releaseContent(name, 0)
 		if err != nil {
 			return &release.UninstallReleaseResponse{}, err
 		}
-		return &release.UninstallReleaseResponse{Release: r}, nil
+		plan, err := u.plan(r)
+		if err != nil {
+			return &release.UninstallReleaseResponse{Release: r}, err
+		}
+		return &release.UninstallReleaseResponse{Release: r, Plan: plan}, nil
 	}
 
 	if err := chartutil.ValidateReleaseName(name); err != nil {
 		return nil, errors.Errorf("uninstall: Release name is invalid: %s", name)
 	}
 
+	if !u.locked {
+		unlock, lockErr := u.cfg.lockRelease(name)
+		if lockErr != nil {
+			return nil, lockErr
+		}
+		defer unlock(&err)
+	}
+
 	rels, err := u.cfg.Releases.History(name)
 	if err != nil {
 		if u.IgnoreNotFound {
@@ -193,10 +208,43 @@ func joinErrors(errs []error) string {
 	return strings.Join(es, "; ")
 }
 
-// deleteRelease deletes the release and returns list of delete resources and manifests that were kept in the deletion process
-func (u *Uninstall) deleteRelease(rel *release.Release) (kube.ResourceList, string, []error) {
-	var errs []error
+// plan returns what uninstalling the release would do.
+func (u *Uninstall) plan(rel *release.Release) (*release.UninstallPlan, error) {
+	plan := &release.UninstallPlan{
+		Name:       rel.Name,
+		Namespace:  rel.Namespace,
+		Delete:     []release.PlannedResource{},
+		Keep:       []release.PlannedResource{},
+		Hooks:      []release.PlannedHook{},
+		Namespaces: []string{},
+	}
+	// The resources of an uninstalled release were already deleted, only its
+	// history would be.
+	if rel.Info.Status == release.StatusUninstalled {
+		return plan, nil
+	}
+
+	filesToKeep, filesToDelete, err := splitManifestsToKeep(rel)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range filesToDelete {
+		plan.Delete = append(plan.Delete, u.cfg.plannedResource(f.Content, rel.Namespace))
+	}
+	for _, f := range filesToKeep {
+		plan.Keep = append(plan.Keep, u.cfg.plannedResource(f.Content, rel.Namespace))
+	}
+	if !u.DisableHooks {
+		plan.Hooks = append(plan.Hooks, u.cfg.plannedHooks(rel, release.HookPreDelete, release.HookPostDelete)...)
+	}
+	plan.Namespaces = plannedNamespaces(plan.Delete, plan.Hooks)
+	return plan, nil
+}
 
+// splitManifestsToKeep sorts the manifests of a release in uninstall order,
+// and splits the ones kept because of their resource policy from the ones to
+// delete.
+func splitManifestsToKeep(rel *release.Release) (keep, remaining []releaseutil.Manifest, err error) {
 	manifests := releaseutil.SplitManifests(rel.Manifest)
 	_, files, err := releaseutil.SortManifests(manifests, nil, releaseutil.UninstallOrder)
 	if err != nil {
@@ -204,10 +252,21 @@ func (u *Uninstall) deleteRelease(rel *release.Release) (kube.ResourceList, stri
 		// FIXME: One way to delete at this point would be to try a label-based
 		// deletion. The problem with this is that we could get a false positive
 		// and delete something that was not legitimately part of this release.
-		return nil, rel.Manifest, []error{errors.Wrap(err, "corrupted release record. You must manually delete the resources")}
+		return nil, nil, errors.Wrap(err, "corrupted release record. You must manually delete the resources")
+	}
+	keep, remaining = filterManifestsToKeep(files)
+	return keep, remaining, nil
+}
+
+// deleteRelease deletes the release and returns list of delete resources and manifests that were kept in the deletion process
+func (u *Uninstall) deleteRelease(rel *release.Release) (kube.ResourceList, string, []error) {
+	var errs []error
+
+	filesToKeep, filesToDelete, err := splitManifestsToKeep(rel)
+	if err != nil {
+		return nil, rel.Manifest, []error{err}
 	}
 
-	filesToKeep, filesToDelete := filterManifestsToKeep(files)
 	var kept string
 	for _, f := range filesToKeep {
 		kept += "[" + f.Head.Kind + "] " + f.Head.Metadata.Name + "\n"
@@ -138,3 +138,85 @@ func TestUninstallRelease_Cascade(t *testing.T) {
 	is.Error(err)
 	is.Contains(err.Error(), "failed to delete release: come-fail-away")
 }
+
+func TestUninstallRelease_DryRunPlan(t *testing.T) {
+	is := assert.New(t)
+
+	unAction := uninstallAction(t)
+	unAction.DryRun = true
+
+	rel := releaseStub()
+	rel.Name = "planned"
+	rel.Namespace = "spaced"
+	rel.Manifest = `---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: data
+  namespace: shared
+  annotations:
+    helm.sh/resource-policy: keep
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: credentials
+  namespace: other
+`
+	rel.Hooks = []*release.Hook{
+		{Name: "cleanup", Kind: "Job", Weight: 5, Events: []release.HookEvent{release.HookPreDelete},
+			Manifest: "apiVersion: batch/v1\nkind: Job\nmetadata:\n  name: cleanup\n"},
+		{Name: "backup", Kind: "Job", Weight: -5, Events: []release.HookEvent{release.HookPreDelete},
+			Manifest: "apiVersion: batch/v1\nkind: Job\nmetadata:\n  name: backup\n"},
+		{Name: "notify", Kind: "Pod", Events: []release.HookEvent{release.HookPostDelete},
+			Manifest: "apiVersion: v1\nkind: Pod\nmetadata:\n  name: notify\n  namespace: ops\n"},
+		{Name: "migrate", Kind: "Job", Events: []release.HookEvent{release.HookPreInstall},
+			Manifest: "apiVersion: batch/v1\nkind: Job\nmetadata:\n  name: migrate\n"},
+	}
+	is.NoError(unAction.cfg.Releases.Create(rel))
+
+	res, err := unAction.Run(rel.Name)
+	is.NoError(err)
+	is.Equal(rel.Name, res.Release.Name)
+
+	plan := res.Plan
+	is.Equal(&release.UninstallPlan{
+		Name:      "planned",
+		Namespace: "spaced",
+		Delete: []release.PlannedResource{
+			{APIVersion: "v1", Kind: "Service", Name: "web", Namespace: "spaced"},
+			{APIVersion: "apps/v1", Kind: "Deployment", Name: "web", Namespace: "spaced"},
+			{APIVersion: "v1", Kind: "Secret", Name: "credentials", Namespace: "other"},
+		},
+		Keep: []release.PlannedResource{
+			{APIVersion: "v1", Kind: "ConfigMap", Name: "data", Namespace: "shared"},
+		},
+		Hooks: []release.PlannedHook{
+			{PlannedResource: release.PlannedResource{APIVersion: "batch/v1", Kind: "Job", Name: "backup", Namespace: "spaced"}, Event: release.HookPreDelete, Weight: -5},
+			{PlannedResource: release.PlannedResource{APIVersion: "batch/v1", Kind: "Job", Name: "cleanup", Namespace: "spaced"}, Event: release.HookPreDelete, Weight: 5},
+			{PlannedResource: release.PlannedResource{APIVersion: "v1", Kind: "Pod", Name: "notify", Namespace: "ops"}, Event: release.HookPostDelete},
+		},
+		Namespaces: []string{"ops", "other", "spaced"},
+	}, plan)
+
+	// Nothing was uninstalled.
+	last, err := unAction.cfg.Releases.Last(rel.Name)
+	is.NoError(err)
+	is.Equal(release.StatusDeployed, last.Info.Status)
+
+	unAction.DisableHooks = true
+	res, err = unAction.Run(rel.Name)
+	is.NoError(err)
+	is.Empty(res.Plan.Hooks)
+	is.Equal([]string{"other", "spaced"}, res.Plan.Namespaces)
+}
@@ -103,6 +103,8 @@ type Upgrade struct {
 	SkipSchemaValidation bool
 	// Description is the description of this operation
 	Description string
+	// ChangeCause is why the release is upgraded, recorded on the revision
+	ChangeCause string
 	Labels      map[string]string
 	// PostRender is an optional post-renderer
 	//
@@ -119,6 +121,23 @@ type Upgrade struct {
 	EnableDNS bool
 	// TakeOwnership will skip the check for helm annotations and adopt all existing resources.
 	TakeOwnership bool
+	// FromPlan, if set, is the plan the upgrade must follow. The upgrade is
+	// refused if it would do anything else, for example because a resource
+	// was modified in the cluster since the plan was made.
+	FromPlan *release.Plan
+	// VerifyTests runs the test hooks of the release once it is upgraded.
+	// If they fail, the release is rolled back to the last successful
+	// revision, as with Atomic.
+	VerifyTests bool
+	// VerifyConditions are conditions the resources of the release must meet
+	// once it is upgraded, written as KIND/NAME:condition=TYPE or
+	// KIND/NAME:jsonpath=EXPR. If they are not met within Timeout, or stop
+	// being met during SoakPeriod, the release is rolled back to the last
+	// successful revision.
+	VerifyConditions []string
+	// SoakPeriod is how long the resources of the release must keep meeting
+	// VerifyConditions once they meet them.
+	SoakPeriod time.Duration
 }
 
 type resultMessage struct {
@@ -148,7 +167,7 @@ func (u *Upgrade) Run(name string, chart *chart.Chart, vals map[string]interface
 }
 
 // RunWithContext executes the upgrade on the given release with context.
-func (u *Upgrade) RunWithContext(ctx context.Context, name string, chart *chart.Chart, vals map[string]interface{}) (*release.Release, error) {
+func (u *Upgrade) RunWithContext(ctx context.Context, name string, chart *chart.Chart, vals map[string]interface{}) (_ *release.Release, err error) {
 	if err := u.cfg.KubeClient.IsReachable(); err != nil {
 		return nil, err
 	}
@@ -161,12 +180,35 @@ func (u *Upgrade) RunWithContext(ctx context.Context, name string, chart *chart.
 		return nil, errors.Errorf("release name is invalid: %s", name)
 	}
 
+	conditions, err := parseVerifyConditions(u.VerifyConditions)
+	if err != nil {
+		return nil, err
+	}
+
+	if !u.isDryRun() {
+		unlock, lockErr := u.cfg.lockRelease(name)
+		if lockErr != nil {
+			return nil, lockErr
+		}
+		defer unlock(&err)
+	}
+
 	u.cfg.Log("preparing upgrade for %s", name)
 	currentRelease, upgradedRelease, err := u.prepareUpgrade(name, chart, vals)
 	if err != nil {
 		return nil, err
 	}
 
+	if u.FromPlan != nil {
+		plan, err := u.plan(currentRelease, upgradedRelease)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkPlan(u.FromPlan, plan); err != nil {
+			return nil, err
+		}
+	}
+
 	u.cfg.Releases.MaxHistory = u.MaxHistory
 
 	u.cfg.Log("performing update for %s", name)
@@ -181,11 +223,46 @@ func (u *Upgrade) RunWithContext(ctx context.Context, name string, chart *chart.
 		if err := u.cfg.Releases.Update(upgradedRelease); err != nil {
 			return res, err
 		}
+
+		if u.verifies() {
+			if res, err = u.verify(ctx, res, conditions); err != nil {
+				return u.failVerification(res, err)
+			}
+		}
 	}
 
 	return res, nil
 }
 
+// Plan returns what upgrading the given release would do, without changing
+// the release or the cluster.
+func (u *Upgrade) Plan(name string, chart *chart.Chart, vals map[string]interface{}) (*release.Plan, error) {
+	if err := u.cfg.KubeClient.IsReachable(); err != nil {
+		return nil, err
+	}
+	if err := chartutil.ValidateReleaseName(name); err != nil {
+		return nil, errors.Errorf("release name is invalid: %s", name)
+	}
+
+	currentRelease, upgradedRelease, err := u.prepareUpgrade(name, chart, vals)
+	if err != nil {
+		return nil, err
+	}
+	return u.plan(currentRelease, upgradedRelease)
+}
+
+func (u *Upgrade) plan(originalRelease, upgradedRelease *release.Release) (*release.Plan, error) {
+	current, target, toBeAdopted, err := u.upgradeResources(originalRelease, upgradedRelease)
+	if err != nil {
+		return nil, err
+	}
+	var events []release.HookEvent
+	if !u.DisableHooks {
+		events = []release.HookEvent{release.HookPreUpgrade, release.HookPostUpgrade}
+	}
+	return u.cfg.planRelease(upgradedRelease, current, target, toBeAdopted, u.Force, events...)
+}
+
 // isDryRun returns true if Upgrade is set to run as a DryRun
 func (u *Upgrade) isDryRun() bool {
 	if u.DryRun || u.DryRunOption == "client" || u.DryRunOption == "server" || u.DryRunOption == "true" {
@@ -293,6 +370,8 @@ func (u *Upgrade) prepareUpgrade(name string, chart *chart.Chart, vals map[strin
 			LastDeployed:  Timestamper(),
 			Status:        release.StatusPendingUpgrade,
 			Description:   "Preparing upgrade", // This should be overwritten later.
+			ChartDigest:   u.chartDigest,
+			Origin:        u.cfg.origin(u.chartSource, u.ChangeCause, u.isDryRun()),
 		},
 		Version:  revision,
 		Manifest: manifestDoc.String(),
@@ -308,26 +387,68 @@ func (u *Upgrade) prepareUpgrade(name string, chart *chart.Chart, vals map[strin
 }
 
 func (u *Upgrade) performUpgrade(ctx context.Context, originalRelease, upgradedRelease *release.Release) (*release.Release, error) {
-	current, err := u.cfg.KubeClient.Build(bytes.NewBufferString(originalRelease.Manifest), false)
+	current, target, _, err := u.upgradeResources(originalRelease, upgradedRelease)
+	if err != nil {
+		return upgradedRelease, err
+	}
+	if upgradedRelease.Waves, err = releaseWaves(target); err != nil {
+		return upgradedRelease, err
+	}
+
+	// Run if it is a dry run
+	if u.isDryRun() {
+		u.cfg.Log("dry run for %s", upgradedRelease.Name)
+		if len(u.Description) > 0 {
+			upgradedRelease.Info.Description = u.Description
+		} else {
+			upgradedRelease.Info.Description = "Dry run complete"
+		}
+		return upgradedRelease, nil
+	}
+
+	u.cfg.Log("creating upgraded release for %s", upgradedRelease.Name)
+	if err := u.cfg.Releases.Create(upgradedRelease); err != nil {
+		return nil, err
+	}
+	rChan := make(chan resultMessage)
+	ctxChan := make(chan resultMessage)
+	doneChan := make(chan interface{})
+	defer close(doneChan)
+	go u.releasingUpgrade(rChan, upgradedRelease, current, target, originalRelease)
+	go u.handleContext(ctx, doneChan, ctxChan, upgradedRelease)
+	select {
+	case result := <-rChan:
+		return result.r, result.e
+	case result := <-ctxChan:
+		return result.r, result.e
+	}
+}
+
+// upgradeResources builds the resources of the original and upgraded
+// releases. The resources of the upgraded release that already exist in the
+// cluster but not in the original release are adopted: they are returned as
+// toBeAdopted and added to current.
+func (u *Upgrade) upgradeResources(originalRelease, upgradedRelease *release.Release) (current, target, toBeAdopted kube.ResourceList, err error) {
+	current, err = u.cfg.KubeClient.Build(bytes.NewBufferString(originalRelease.Manifest), false)
 	if err != nil {
 		// Checking for removed Kubernetes API error so can provide a more informative error message to the user
 		// Ref: https://github.com/helm/helm/issues/7219
 		if strings.Contains(err.Error(), "unable to recognize \"\": no matches for kind") {
-			return upgradedRelease, errors.Wrap(err, "current release manifest contains removed kubernetes api(s) for this "+
+			return nil, nil, nil, errors.Wrap(err, "current release manifest contains removed kubernetes api(s) for this "+
 				"kubernetes version and it is therefore unable to build the kubernetes "+
 				"objects for performing the diff. error from kubernetes")
 		}
-		return upgradedRelease, errors.Wrap(err, "unable to build kubernetes objects from current release manifest")
+		return nil, nil, nil, errors.Wrap(err, "unable to build kubernetes objects from current release manifest")
 	}
-	target, err := u.cfg.KubeClient.Build(bytes.NewBufferString(upgradedRelease.Manifest), !u.DisableOpenAPIValidation)
+	target, err = u.cfg.KubeClient.Build(bytes.NewBufferString(upgradedRelease.Manifest), !u.DisableOpenAPIValidation)
 	if err != nil {
-		return upgradedRelease, errors.Wrap(err, "unable to build kubernetes objects from new release manifest")
+		return nil, nil, nil, errors.Wrap(err, "unable to build kubernetes objects from new release manifest")
 	}
 
 	// It is safe to use force only on target because these are resources currently rendered by the chart.
 	err = target.Visit(setMetadataVisitor(upgradedRelease.Name, upgradedRelease.Namespace, true))
 	if err != nil {
-		return upgradedRelease, err
+		return nil, nil, nil, err
 	}
 
 	// Do a basic diff using gvk + name to figure out what new resources are being created so we can validate they don't already exist
@@ -343,51 +464,23 @@ func (u *Upgrade) performUpgrade(ctx context.Context, originalRelease, upgradedR
 		}
 	}
 
-	var toBeUpdated kube.ResourceList
 	if u.TakeOwnership {
-		toBeUpdated, err = requireAdoption(toBeCreated)
+		toBeAdopted, err = requireAdoption(toBeCreated)
 	} else {
-		toBeUpdated, err = existingResourceConflict(toBeCreated, upgradedRelease.Name, upgradedRelease.Namespace)
+		toBeAdopted, err = existingResourceConflict(toBeCreated, upgradedRelease.Name, upgradedRelease.Namespace)
 	}
 	if err != nil {
-		return nil, errors.Wrap(err, "Unable to continue with update")
+		return nil, nil, nil, errors.Wrap(err, "Unable to continue with update")
 	}
 
-	toBeUpdated.Visit(func(r *resource.Info, err error) error {
+	toBeAdopted.Visit(func(r *resource.Info, err error) error {
 		if err != nil {
 			return err
 		}
 		current.Append(r)
 		return nil
 	})
-
-	// Run if it is a dry run
-	if u.isDryRun() {
-		u.cfg.Log("dry run for %s", upgradedRelease.Name)
-		if len(u.Description) > 0 {
-			upgradedRelease.Info.Description = u.Description
-		} else {
-			upgradedRelease.Info.Description = "Dry run complete"
-		}
-		return upgradedRelease, nil
-	}
-
-	u.cfg.Log("creating upgraded release for %s", upgradedRelease.Name)
-	if err := u.cfg.Releases.Create(upgradedRelease); err != nil {
-		return nil, err
-	}
-	rChan := make(chan resultMessage)
-	ctxChan := make(chan resultMessage)
-	doneChan := make(chan interface{})
-	defer close(doneChan)
-	go u.releasingUpgrade(rChan, upgradedRelease, current, target, originalRelease)
-	go u.handleContext(ctx, doneChan, ctxChan, upgradedRelease)
-	select {
-	case result := <-rChan:
-		return result.r, result.e
-	case result := <-ctxChan:
-		return result.r, result.e
-	}
+	return current, target, toBeAdopted, nil
 }
 
 // Function used to lock the Mutex, this is important for the case when the atomic flag is set.
@@ -426,7 +519,7 @@ func (u *Upgrade) releasingUpgrade(c chan<- resultMessage, upgradedRelease *rele
 		u.cfg.Log("upgrade hooks disabled for %s", upgradedRelease.Name)
 	}
 
-	results, err := u.cfg.KubeClient.Update(current, target, u.Force)
+	results, err := u.cfg.applyInWaves(current, target, u.Force, false, u.cfg.waitForWave(u.Wait, u.WaitForJobs, u.Timeout))
 	if err != nil {
 		u.cfg.recordRelease(originalRelease)
 		u.reportToPerformUpgrade(c, upgradedRelease, results.Created, err)
@@ -506,32 +599,23 @@ func (u *Upgrade) failRelease(rel *release.Release, created kube.ResourceList, e
 
 		// As a protection, get the last successful release before rollback.
 		// If there are no successful releases, bail out
-		hist := NewHistory(u.cfg)
-		fullHistory, herr := hist.Run(rel.Name)
+		last, herr := u.lastSuccessfulRelease(rel.Name)
 		if herr != nil {
 			return rel, errors.Wrapf(herr, "an error occurred while finding last successful release. original upgrade error: %s", err)
 		}
-
-		// There isn't a way to tell if a previous release was successful, but
-		// generally failed releases do not get superseded unless the next
-		// release is successful, so this should be relatively safe
-		filteredHistory := releaseutil.FilterFunc(func(r *release.Release) bool {
-			return r.Info.Status == release.StatusSuperseded || r.Info.Status == release.StatusDeployed
-		}).Filter(fullHistory)
-		if len(filteredHistory) == 0 {
+		if last == nil {
 			return rel, errors.Wrap(err, "unable to find a previously successful release when attempting to rollback. original upgrade error")
 		}
 
-		releaseutil.Reverse(filteredHistory, releaseutil.SortByRevision)
-
 		rollin := NewRollback(u.cfg)
-		rollin.Version = filteredHistory[0].Version
+		rollin.Version = last.Version
 		rollin.Wait = true
 		rollin.WaitForJobs = u.WaitForJobs
 		rollin.DisableHooks = u.DisableHooks
 		rollin.Recreate = u.Recreate
 		rollin.Force = u.Force
 		rollin.Timeout = u.Timeout
+		rollin.locked = true
 		if rollErr := rollin.Run(rel.Name); rollErr != nil {
 			return rel, errors.Wrapf(rollErr, "an error occurred while rolling back the release. original upgrade error: %s", err)
 		}
@@ -541,6 +625,28 @@ func (u *Upgrade) failRelease(rel *release.Release, created kube.ResourceList, e
 	return rel, err
 }
 
+// lastSuccessfulRelease returns the last deployed or superseded revision of a
+// release, or nil if there is none.
+func (u *Upgrade) lastSuccessfulRelease(name string) (*release.Release, error) {
+	fullHistory, err := NewHistory(u.cfg).Run(name)
+	if err != nil {
+		return nil, err
+	}
+
+	// There isn't a way to tell if a previous release was successful, but
+	// generally failed releases do not get superseded unless the next
+	// release is successful, so this should be relatively safe
+	filteredHistory := releaseutil.FilterFunc(func(r *release.Release) bool {
+		return r.Info.Status == release.StatusSuperseded || r.Info.Status == release.StatusDeployed
+	}).Filter(fullHistory)
+	if len(filteredHistory) == 0 {
+		return nil, nil
+	}
+
+	releaseutil.Reverse(filteredHistory, releaseutil.SortByRevision)
+	return filteredHistory[0], nil
+}
+
 // reuseValues copies values from the current release to a new release if the
 // new release does not have any values.
 //
@@ -91,6 +91,59 @@ func TestUpgradeRelease_Wait(t *testing.T) {
 	is.Equal(res.Info.Status, release.StatusFailed)
 }
 
+func TestUpgradeRelease_Locked(t *testing.T) {
+	is := assert.New(t)
+	req := require.New(t)
+
+	upAction := upgradeAction(t)
+	rel := releaseStub()
+	rel.Name = "locked"
+	rel.Info.Status = release.StatusDeployed
+	upAction.cfg.Releases.Create(rel)
+
+	locker := upAction.cfg.Releases.Locker
+	req.NoError(locker.AcquireLock(rel.Name, "someone@elsewhere/1", time.Minute))
+
+	_, err := upAction.Run(rel.Name, buildChart(), map[string]interface{}{})
+	req.Error(err)
+	is.ErrorIs(err, driver.ErrReleaseLocked)
+	is.Contains(err.Error(), "another operation (install/upgrade/rollback) is in progress")
+	is.Contains(err.Error(), "someone@elsewhere/1")
+
+	req.NoError(locker.ReleaseLock(rel.Name, "someone@elsewhere/1"))
+	res, err := upAction.Run(rel.Name, buildChart(), map[string]interface{}{})
+	req.NoError(err)
+	is.Equal(release.StatusDeployed, res.Info.Status)
+
+	// The lock is released once the upgrade is done.
+	is.NoError(locker.AcquireLock(rel.Name, "someone@elsewhere/1", time.Minute))
+}
+
+func TestUpgradeRelease_LockedInProcess(t *testing.T) {
+	is := assert.New(t)
+	req := require.New(t)
+
+	upAction := upgradeAction(t)
+	rel := releaseStub()
+	rel.Name = "locked"
+	rel.Info.Status = release.StatusDeployed
+	upAction.cfg.Releases.Create(rel)
+
+	// Another operation sharing the configuration holds the lock, with the
+	// same holder.
+	unlock, err := upAction.cfg.Releases.Lock(rel.Name, upAction.cfg.lockHolder())
+	req.NoError(err)
+
+	_, err = upAction.Run(rel.Name, buildChart(), map[string]interface{}{})
+	req.Error(err)
+	is.ErrorIs(err, driver.ErrReleaseLocked)
+
+	req.NoError(unlock())
+	res, err := upAction.Run(rel.Name, buildChart(), map[string]interface{}{})
+	req.NoError(err)
+	is.Equal(release.StatusDeployed, res.Info.Status)
+}
+
 func TestUpgradeRelease_WaitForJobs(t *testing.T) {
 	is := assert.New(t)
 	req := require.New(t)
@@ -190,6 +243,104 @@ func TestUpgradeRelease_Atomic(t *testing.T) {
 	})
 }
 
+func TestUpgradeRelease_Verify(t *testing.T) {
+	is := assert.New(t)
+	req := require.New(t)
+
+	testHook := `kind: Pod
+apiVersion: v1
+metadata:
+  name: smoke-test
+  annotations:
+    "helm.sh/hook": test
+`
+	chartWithTests := func() *chart.Chart {
+		ch := buildChart()
+		ch.Templates = []*chart.File{
+			{Name: "templates/hello", Data: []byte("hello: world")},
+			{Name: "templates/smoke-test", Data: []byte(testHook)},
+		}
+		return ch
+	}
+	deployedRelease := func(upAction *Upgrade, name string) *release.Release {
+		rel := releaseStub()
+		rel.Name = name
+		rel.Info.Status = release.StatusDeployed
+		req.NoError(upAction.cfg.Releases.Create(rel))
+		return rel
+	}
+
+	t.Run("passing tests keep the upgrade", func(t *testing.T) {
+		upAction := upgradeAction(t)
+		rel := deployedRelease(upAction, "tested")
+		upAction.VerifyTests = true
+
+		res, err := upAction.Run(rel.Name, chartWithTests(), map[string]interface{}{})
+		req.NoError(err)
+		is.Equal(2, res.Version)
+
+		last, err := upAction.cfg.Releases.Last(rel.Name)
+		req.NoError(err)
+		is.Equal(2, last.Version)
+		is.Equal(release.StatusDeployed, last.Info.Status)
+		is.Equal(release.HookPhaseSucceeded, last.Hooks[0].LastRun.Phase)
+	})
+
+	t.Run("failing tests roll back", func(t *testing.T) {
+		upAction := upgradeAction(t)
+		rel := deployedRelease(upAction, "untested")
+		failer := upAction.cfg.KubeClient.(*kubefake.FailingKubeClient)
+		failer.WatchUntilReadyError = fmt.Errorf("smoke test failed")
+		upAction.VerifyTests = true
+
+		_, err := upAction.Run(rel.Name, chartWithTests(), map[string]interface{}{})
+		req.Error(err)
+		is.Contains(err.Error(), "smoke test failed")
+		is.Contains(err.Error(), "rolled back to revision 1")
+
+		upgraded, err := upAction.cfg.Releases.Get(rel.Name, 2)
+		req.NoError(err)
+		is.Equal(release.StatusFailed, upgraded.Info.Status)
+		is.Contains(upgraded.Info.Description, "failed verification: tests failed")
+
+		rolledBack, err := upAction.cfg.Releases.Get(rel.Name, 3)
+		req.NoError(err)
+		is.Equal(release.StatusDeployed, rolledBack.Info.Status)
+		is.Equal("Rollback to 1", rolledBack.Info.Description)
+	})
+
+	t.Run("unmet conditions roll back", func(t *testing.T) {
+		upAction := upgradeAction(t)
+		rel := deployedRelease(upAction, "unmet")
+		upAction.VerifyConditions = []string{"deployment/web:condition=Available"}
+
+		_, err := upAction.Run(rel.Name, buildChart(), map[string]interface{}{})
+		req.Error(err)
+		is.Contains(err.Error(), "deployment/web is not a resource of the release")
+
+		upgraded, err := upAction.cfg.Releases.Get(rel.Name, 2)
+		req.NoError(err)
+		is.Equal(release.StatusFailed, upgraded.Info.Status)
+
+		last, err := upAction.cfg.Releases.Last(rel.Name)
+		req.NoError(err)
+		is.Equal(3, last.Version)
+		is.Equal(release.StatusDeployed, last.Info.Status)
+	})
+
+	t.Run("invalid conditions are refused", func(t *testing.T) {
+		upAction := upgradeAction(t)
+		rel := deployedRelease(upAction, "invalid")
+		upAction.VerifyConditions = []string{"deployment/web=Available"}
+
+		_, err := upAction.Run(rel.Name, buildChart(), map[string]interface{}{})
+		is.EqualError(err, `invalid verification condition "deployment/web=Available": must be KIND/NAME:CONDITION`)
+
+		_, err = upAction.cfg.Releases.Get(rel.Name, 2)
+		is.Error(err, "the release should not be upgraded")
+	})
+}
+
 func TestUpgradeRelease_ReuseValues(t *testing.T) {
 	is := assert.New(t)
 
@@ -586,3 +737,45 @@ func TestUpgradeRelease_DryRun(t *testing.T) {
 	done()
 	req.Error(err)
 }
+
+func TestUpgradeRelease_Plan(t *testing.T) {
+	is := assert.New(t)
+	req := require.New(t)
+
+	upAction := upgradeAction(t)
+	rel := releaseStub()
+	rel.Name = "previous-release"
+	rel.Info.Status = release.StatusDeployed
+	req.NoError(upAction.cfg.Releases.Create(rel))
+
+	vals := map[string]interface{}{}
+	plan, err := upAction.Plan(rel.Name, buildChart(), vals)
+	req.NoError(err)
+	is.Equal(rel.Name, plan.Name)
+	is.Equal(2, plan.Revision)
+	is.Equal("hello-0.1.0", plan.Chart)
+	is.Contains(plan.ManifestDigest, "sha256:")
+	req.Len(plan.Hooks, 1)
+	is.Equal(release.HookPostUpgrade, plan.Hooks[0].Event)
+	is.Equal("test-cm", plan.Hooks[0].Name)
+
+	// Planning does not upgrade the release.
+	lastRelease, err := upAction.cfg.Releases.Last(rel.Name)
+	req.NoError(err)
+	is.Equal(1, lastRelease.Version)
+
+	upAction.FromPlan = plan
+	res, err := upAction.Run(rel.Name, buildChart(), vals)
+	req.NoError(err)
+	is.Equal(2, res.Version)
+
+	// The plan is outdated once the release is upgraded.
+	_, err = upAction.Run(rel.Name, buildChart(), vals)
+	req.Error(err)
+	is.Contains(err.Error(), "changed since the plan was made")
+	is.Contains(err.Error(), "revision 3 instead of 2")
+
+	lastRelease, err = upAction.cfg.Releases.Last(rel.Name)
+	req.NoError(err)
+	is.Equal(2, lastRelease.Version)
+}
@@ -0,0 +1,213 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/cli-runtime/pkg/resource"
+
+	"helm.sh/helm/v3/pkg/kube"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// verifyInterval is how often the verification conditions of an upgrade are
+// checked.
+var verifyInterval = 5 * time.Second
+
+// verifyCondition is a condition a resource of a release must meet once it
+// is upgraded, written as KIND/NAME:CONDITION.
+type verifyCondition struct {
+	kind      string
+	name      string
+	condition string
+}
+
+func (c verifyCondition) String() string {
+	return fmt.Sprintf("%s/%s:%s", c.kind, c.name, c.condition)
+}
+
+// parseVerifyConditions parses conditions written as KIND/NAME:CONDITION,
+// where CONDITION is "condition=TYPE" or "jsonpath=EXPR".
+func parseVerifyConditions(conditions []string) ([]verifyCondition, error) {
+	parsed := make([]verifyCondition, 0, len(conditions))
+	for _, s := range conditions {
+		ref, condition, ok := strings.Cut(s, ":")
+		kind, name, hasName := strings.Cut(ref, "/")
+		if !ok || !hasName || kind == "" || name == "" {
+			return nil, errors.Errorf("invalid verification condition %q: must be KIND/NAME:CONDITION", s)
+		}
+		if !strings.HasPrefix(condition, "condition=") && !strings.HasPrefix(condition, "jsonpath=") {
+			return nil, errors.Errorf("invalid verification condition %q: the condition must be condition=TYPE or jsonpath=EXPR", s)
+		}
+		parsed = append(parsed, verifyCondition{kind: kind, name: name, condition: condition})
+	}
+	return parsed, nil
+}
+
+// verifies reports whether the upgrade verifies the upgraded release.
+func (u *Upgrade) verifies() bool {
+	return u.VerifyTests || len(u.VerifyConditions) > 0
+}
+
+// verify runs the test hooks of an upgraded release if VerifyTests is set,
+// then waits for its resources to meet the verification conditions and
+// checks they keep meeting them for the soak period. It returns the release
+// as updated by the tests.
+func (u *Upgrade) verify(ctx context.Context, rel *release.Release, conditions []verifyCondition) (*release.Release, error) {
+	if u.VerifyTests {
+		u.cfg.Log("running tests to verify release %s", rel.Name)
+		test := NewReleaseTesting(u.cfg)
+		test.Namespace = rel.Namespace
+		test.Timeout = u.Timeout
+		tested, err := test.Run(rel.Name)
+		if tested != nil {
+			rel = tested
+		}
+		if err != nil {
+			return rel, errors.Wrap(err, "tests failed")
+		}
+	}
+	if len(conditions) == 0 {
+		return rel, nil
+	}
+
+	resources, err := u.cfg.KubeClient.Build(bytes.NewBufferString(rel.Manifest), false)
+	if err != nil {
+		return rel, errors.Wrap(err, "unable to build kubernetes objects from the release manifest")
+	}
+	if err := checkConditionResources(resources, conditions); err != nil {
+		return rel, err
+	}
+
+	u.cfg.Log("waiting for release %s to meet its verification conditions", rel.Name)
+	deadline := time.Now().Add(u.Timeout)
+	for {
+		err = conditionsMet(resources, conditions)
+		if err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			return rel, err
+		}
+		if err := sleep(ctx, verifyInterval); err != nil {
+			return rel, err
+		}
+	}
+
+	u.cfg.Log("soaking release %s for %s", rel.Name, u.SoakPeriod)
+	end := time.Now().Add(u.SoakPeriod)
+	for remaining := time.Until(end); remaining > 0; remaining = time.Until(end) {
+		if err := sleep(ctx, min(verifyInterval, remaining)); err != nil {
+			return rel, err
+		}
+		if err := conditionsMet(resources, conditions); err != nil {
+			return rel, errors.Wrap(err, "during the soak period")
+		}
+	}
+	return rel, nil
+}
+
+// conditionsMet returns an error for the first condition the resources of a
+// release do not meet in the cluster.
+func conditionsMet(resources kube.ResourceList, conditions []verifyCondition) error {
+	for _, c := range conditions {
+		info := findResource(resources, c.kind, c.name)
+		live, err := resource.NewHelper(info.Client, info.Mapping).Get(info.Namespace, info.Name)
+		if err != nil {
+			return errors.Wrapf(err, "could not get %s", resourceString(info))
+		}
+		met, err := kube.MeetsCondition(live, c.condition)
+		if err != nil {
+			return errors.Wrapf(err, "could not check %s", c)
+		}
+		if !met {
+			return errors.Errorf("%s does not meet %s", resourceString(info), c.condition)
+		}
+	}
+	return nil
+}
+
+// checkConditionResources returns an error if a condition is for a resource
+// that is not in the release.
+func checkConditionResources(resources kube.ResourceList, conditions []verifyCondition) error {
+	for _, c := range conditions {
+		if findResource(resources, c.kind, c.name) == nil {
+			return errors.Errorf("%s/%s is not a resource of the release", c.kind, c.name)
+		}
+	}
+	return nil
+}
+
+// findResource returns the resource with the given kind, matched regardless
+// of case, and name.
+func findResource(resources kube.ResourceList, kind, name string) *resource.Info {
+	for _, info := range resources {
+		if info.Name == name && info.Mapping != nil && strings.EqualFold(info.Mapping.GroupVersionKind.Kind, kind) {
+			return info
+		}
+	}
+	return nil
+}
+
+// failVerification marks an upgraded release that failed its verification
+// as failed, and rolls back to the last successful release.
+func (u *Upgrade) failVerification(rel *release.Release, err error) (*release.Release, error) {
+	msg := fmt.Sprintf("Upgrade %q failed verification: %s", rel.Name, err)
+	u.cfg.Log("warning: %s", msg)
+
+	rel.SetStatus(release.StatusFailed, msg)
+	u.cfg.recordRelease(rel)
+
+	last, herr := u.lastSuccessfulRelease(rel.Name)
+	if herr != nil {
+		return rel, errors.Wrapf(herr, "an error occurred while finding last successful release. original verification error: %s", err)
+	}
+	if last == nil {
+		return rel, errors.Wrap(err, "unable to find a previously successful release when attempting to rollback. original verification error")
+	}
+
+	rollin := NewRollback(u.cfg)
+	rollin.Version = last.Version
+	rollin.Wait = u.Wait
+	rollin.WaitForJobs = u.WaitForJobs
+	rollin.DisableHooks = u.DisableHooks
+	rollin.Recreate = u.Recreate
+	rollin.Force = u.Force
+	rollin.Timeout = u.Timeout
+	rollin.MaxHistory = u.MaxHistory
+	rollin.locked = true
+	if rollErr := rollin.Run(rel.Name); rollErr != nil {
+		return rel, errors.Wrapf(rollErr, "an error occurred while rolling back the release. original verification error: %s", err)
+	}
+	return rel, errors.Wrapf(err, "release %s failed verification, and has been rolled back to revision %d", rel.Name, last.Version)
+}
+
+// sleep waits for the given duration, or until the context is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}
@@ -0,0 +1,88 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"helm.sh/helm/v3/pkg/kube"
+)
+
+func TestParseVerifyConditions(t *testing.T) {
+	conditions, err := parseVerifyConditions([]string{
+		"deployment/web:condition=Available",
+		"Database/main:jsonpath={.status.phase}=Running",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []verifyCondition{
+		{kind: "deployment", name: "web", condition: "condition=Available"},
+		{kind: "Database", name: "main", condition: "jsonpath={.status.phase}=Running"},
+	}, conditions)
+
+	for _, invalid := range []string{"web:condition=Available", "deployment/web", "deployment/:condition=Available", "deployment/web:Available"} {
+		_, err := parseVerifyConditions([]string{invalid})
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestConditionsMet(t *testing.T) {
+	web := newDeploymentWithOwner("web", "default", nil, nil)
+	obj := web.Object.(*appsv1.Deployment)
+	obj.Status.Conditions = []appsv1.DeploymentCondition{{Type: appsv1.DeploymentAvailable, Status: v1.ConditionTrue}}
+	web.Client = fakeClientWith(http.StatusOK, appsV1GV, runtime.EncodeOrDie(appsv1Codec, obj))
+	resources := kube.ResourceList{web}
+
+	tests := []struct {
+		condition string
+		err       string
+	}{
+		{condition: "deployment/web:condition=Available"},
+		{condition: "Deployment/web:jsonpath={.status.conditions[0].status}=True"},
+		{
+			condition: "deployment/web:condition=Progressing",
+			err:       `Deployment "web" in namespace "default" does not meet condition=Progressing`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.condition, func(t *testing.T) {
+			conditions, err := parseVerifyConditions([]string{tt.condition})
+			require.NoError(t, err)
+			require.NoError(t, checkConditionResources(resources, conditions))
+			err = conditionsMet(resources, conditions)
+			if tt.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestCheckConditionResources(t *testing.T) {
+	resources := kube.ResourceList{newDeploymentResource("web", "default")}
+	conditions, err := parseVerifyConditions([]string{"deployment/web:condition=Available", "deployment/api:condition=Available"})
+	require.NoError(t, err)
+	assert.NoError(t, checkConditionResources(resources, conditions[:1]))
+	assert.EqualError(t, checkConditionResources(resources, conditions), "deployment/api is not a resource of the release")
+}
@@ -18,9 +18,16 @@ package action
 
 import (
 	"fmt"
+	"net/url"
+	"path/filepath"
 	"strings"
 
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+
 	"helm.sh/helm/v3/pkg/downloader"
+	"helm.sh/helm/v3/pkg/provenance"
+	"helm.sh/helm/v3/pkg/registry"
 )
 
 // Verify is the action for building a given chart's Verify tree.
@@ -29,6 +36,16 @@ import (
 type Verify struct {
 	Keyring string
 	Out     string
+
+	// RequiredAttestations lists the artifact types that must be attached to
+	// a chart in an OCI registry for it to be trusted.
+	RequiredAttestations []string
+	// TrustPolicyConfig is the path of a trust policy file. When set, the
+	// rule matching the chart decides the keyring, the trusted signers and
+	// whether a failed verification is an error.
+	TrustPolicyConfig string
+
+	registryClient *registry.Client
 }
 
 // NewVerify creates a new Verify object with the given configuration.
@@ -36,24 +53,161 @@ func NewVerify() *Verify {
 	return &Verify{}
 }
 
+// SetRegistryClient sets the registry client used to verify charts in OCI registries.
+func (v *Verify) SetRegistryClient(client *registry.Client) {
+	v.registryClient = client
+}
+
 // Run executes 'helm verify'.
+//
+// A chart in an OCI registry is verified through the signatures attached to
+// its manifest, and must carry an attestation of every required type.
 func (v *Verify) Run(chartfile string) error {
 	var out strings.Builder
-	p, err := downloader.VerifyChart(chartfile, v.Keyring)
+	// TODO(mattfarina): The output is set as a property rather than returned
+	// to maintain the Go API. In Helm v4 this function should return the out
+	// and the property on the struct can be removed.
+	defer func() { v.Out = out.String() }()
+
+	if v.TrustPolicyConfig == "" {
+		return v.verify(&out, chartfile, v.Keyring, nil)
+	}
+
+	policy, err := downloader.LoadTrustPolicy(v.TrustPolicyConfig)
 	if err != nil {
 		return err
 	}
+	source := chartfile
+	if !registry.IsOCI(chartfile) {
+		abs, err := filepath.Abs(chartfile)
+		if err != nil {
+			return err
+		}
+		source = (&url.URL{Scheme: "file", Path: filepath.ToSlash(abs)}).String()
+	}
 
-	for name := range p.SignedBy.Identities {
-		fmt.Fprintf(&out, "Signed by: %v\n", name)
+	rule := policy.Match(source)
+	if rule == nil {
+		fmt.Fprintf(&out, "Trust Policy: no rule matches %s\n", source)
+		return v.verify(&out, chartfile, v.Keyring, nil)
+	}
+	fmt.Fprintf(&out, "Trust Policy: %s (%s)\n", rule.Source, rule.Mode)
+	if rule.Mode == downloader.TrustSkip {
+		fmt.Fprintln(&out, "Policy Result: skipped")
+		return nil
 	}
-	fmt.Fprintf(&out, "Using Key With Fingerprint: %X\n", p.SignedBy.PrimaryKey.Fingerprint)
-	fmt.Fprintf(&out, "Chart Hash Verified: %s\n", p.FileHash)
 
-	// TODO(mattfarina): The output is set as a property rather than returned
-	// to maintain the Go API. In Helm v4 this function should return the out
-	// and the property on the struct can be removed.
-	v.Out = out.String()
+	keyring := v.Keyring
+	if rule.Keyring != "" {
+		keyring = rule.Keyring
+	}
+	if err := v.verify(&out, chartfile, keyring, rule); err != nil {
+		if rule.Mode == downloader.TrustWarn {
+			fmt.Fprintf(&out, "Policy Result: warning: %s\n", err)
+			return nil
+		}
+		return err
+	}
+	fmt.Fprintln(&out, "Policy Result: satisfied")
+	return nil
+}
 
+// verify verifies a chart with the given keyring and writes the result.
+func (v *Verify) verify(out *strings.Builder, chartfile, keyring string, rule *downloader.TrustRule) error {
+	var p *provenance.Verification
+	var err error
+	if registry.IsOCI(chartfile) {
+		if v.registryClient == nil {
+			return errors.New("a registry client is required to verify OCI charts")
+		}
+		p, err = downloader.VerifyOCIChart(v.registryClient, chartfile, keyring)
+	} else {
+		if len(v.RequiredAttestations) > 0 {
+			return errors.New("attestations can only be verified for charts in OCI registries")
+		}
+		p, err = downloader.VerifyChart(chartfile, keyring)
+	}
+	if err != nil {
+		return err
+	}
+	if err := rule.CheckSigner(chartfile, p); err != nil {
+		return err
+	}
+
+	for _, name := range p.Identity.Names {
+		fmt.Fprintf(out, "Signed by: %v\n", name)
+	}
+	fmt.Fprintf(out, "Using Key With Fingerprint: %s\n", p.Identity.Fingerprint)
+	if registry.IsOCI(chartfile) {
+		fmt.Fprintf(out, "Manifest Digest Verified: %s\n", p.FileHash)
+		return v.verifyAttestations(out, p, keyring, rule)
+	}
+	fmt.Fprintf(out, "Chart Hash Verified: %s\n", p.FileHash)
+	return nil
+}
+
+// verifyAttestations checks that an attestation of every required type is
+// attached to the verified chart manifest. An attestation is only trusted when
+// it is signed by a key of the keyring, and by a signer the rule trusts.
+func (v *Verify) verifyAttestations(out *strings.Builder, p *provenance.Verification, keyring string, rule *downloader.TrustRule) error {
+	if len(v.RequiredAttestations) == 0 {
+		return nil
+	}
+	name, _, err := registry.SplitDigest(p.FileName)
+	if err != nil {
+		return err
+	}
+	pinned := name + "@" + p.FileHash
+
+	for _, artifactType := range v.RequiredAttestations {
+		referrers, err := v.registryClient.Referrers(pinned, artifactType)
+		if err != nil {
+			return err
+		}
+		if len(referrers) == 0 {
+			return errors.Errorf("required attestation %s is not attached to %s", artifactType, p.FileName)
+		}
+
+		var lastErr error
+		verified := false
+		for _, desc := range referrers {
+			if err := v.verifyAttestation(out, pinned, name, p.FileHash, desc, keyring, rule); err != nil {
+				lastErr = err
+				continue
+			}
+			verified = true
+		}
+		if !verified {
+			return errors.Wrapf(lastErr, "no signed attestation %s of %s could be verified", artifactType, p.FileName)
+		}
+	}
+	return nil
+}
+
+// verifyAttestation checks the content of an attestation attached to the chart
+// manifest with the given digest, and the signatures attached to it in turn.
+func (v *Verify) verifyAttestation(out *strings.Builder, pinned, name, subject string, desc ocispec.Descriptor, keyring string, rule *downloader.TrustRule) error {
+	// PullReferrer checks the digest of the attestation and its content.
+	referrer, err := v.registryClient.PullReferrer(pinned, desc)
+	if err != nil {
+		return err
+	}
+	if referrer.Subject.Digest.String() != subject {
+		return errors.Errorf("attestation %s is not attached to %s", desc.Digest, subject)
+	}
+
+	attestation := name + "@" + desc.Digest.String()
+	p, err := downloader.VerifyOCIChart(v.registryClient, attestation, keyring)
+	if err != nil {
+		return err
+	}
+	if err := rule.CheckSigner(attestation, p); err != nil {
+		return err
+	}
+	signer := p.Identity.Fingerprint
+	if len(p.Identity.Names) > 0 {
+		signer = p.Identity.Names[0]
+	}
+	fmt.Fprintf(out, "Attestation Verified: %s %s (signed by %s)\n", desc.ArtifactType, desc.Digest, signer)
 	return nil
 }
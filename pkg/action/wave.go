@@ -0,0 +1,147 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/pkg/kube"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// resourceWaves splits resources into waves by their helm.sh/wave annotation.
+// It returns the numbers of the waves in the order they are applied, and the
+// resources of each wave.
+func resourceWaves(resources kube.ResourceList) ([]int, map[int]kube.ResourceList, error) {
+	waves := map[int]kube.ResourceList{}
+	for _, info := range resources {
+		wave := 0
+		if info.Object != nil {
+			annotations, err := accessor.Annotations(info.Object)
+			if err != nil {
+				return nil, nil, err
+			}
+			if v, ok := annotations[release.WaveAnnotation]; ok {
+				if wave, err = strconv.Atoi(v); err != nil {
+					return nil, nil, errors.Errorf("%s has an invalid %s annotation %q: must be an integer", resourceString(info), release.WaveAnnotation, v)
+				}
+			}
+		}
+		waves[wave] = append(waves[wave], info)
+	}
+
+	numbers := make([]int, 0, len(waves))
+	for n := range waves {
+		numbers = append(numbers, n)
+	}
+	sort.Ints(numbers)
+	return numbers, waves, nil
+}
+
+// releaseWaves returns the waves of the resources of a release to record in
+// it, or nil if they are all in the same wave.
+func releaseWaves(resources kube.ResourceList) ([]*release.Wave, error) {
+	numbers, waves, err := resourceWaves(resources)
+	if err != nil || len(numbers) < 2 {
+		return nil, err
+	}
+	recorded := make([]*release.Wave, 0, len(numbers))
+	for _, n := range numbers {
+		wave := &release.Wave{Wave: n}
+		for _, info := range waves[n] {
+			wave.Resources = append(wave.Resources, infoResource(info))
+		}
+		recorded = append(recorded, wave)
+	}
+	return recorded, nil
+}
+
+// applyInWaves creates or updates the target resources of a release from the
+// current ones, one wave at a time. Each wave is applied once the previous
+// one is applied and, if wait is set, ready. A wave that fails stops the
+// later waves. The current resources that are not in the target are deleted
+// after the last wave.
+//
+// If create is set, the resources are created instead of updated. When all
+// the resources are in the same wave, they are applied in a single call to
+// the Kubernetes client and wait is not called.
+func (cfg *Configuration) applyInWaves(current, target kube.ResourceList, force, create bool, wait func(kube.ResourceList) error) (*kube.Result, error) {
+	apply := func(current, target kube.ResourceList) (*kube.Result, error) {
+		if create {
+			return cfg.KubeClient.Create(target)
+		}
+		return cfg.KubeClient.Update(current, target, force)
+	}
+
+	numbers, waves, err := resourceWaves(target)
+	if err != nil {
+		return &kube.Result{}, err
+	}
+	if len(numbers) < 2 {
+		return apply(current, target)
+	}
+
+	result := &kube.Result{}
+	merge := func(res *kube.Result) {
+		if res != nil {
+			result.Created = append(result.Created, res.Created...)
+			result.Updated = append(result.Updated, res.Updated...)
+			result.Deleted = append(result.Deleted, res.Deleted...)
+		}
+	}
+	for _, n := range numbers {
+		wave := waves[n]
+		cfg.Log("applying wave %d (%d resources)", n, len(wave))
+		res, err := apply(current.Intersect(wave), wave)
+		merge(res)
+		if err != nil {
+			return result, errors.Wrapf(err, "wave %d", n)
+		}
+		if wait != nil {
+			if err := wait(wave); err != nil {
+				return result, errors.Wrapf(err, "wave %d did not become ready", n)
+			}
+		}
+	}
+
+	if removed := current.Difference(target); !create && len(removed) > 0 {
+		res, err := cfg.KubeClient.Update(removed, kube.ResourceList{}, force)
+		merge(res)
+		if err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// waitForWave returns the function that waits for the resources of a wave, or
+// nil if the action does not wait.
+func (cfg *Configuration) waitForWave(wait, waitForJobs bool, timeout time.Duration) func(kube.ResourceList) error {
+	if !wait {
+		return nil
+	}
+	return func(resources kube.ResourceList) error {
+		if waitForJobs {
+			return cfg.KubeClient.WaitWithJobs(resources, timeout)
+		}
+		return cfg.KubeClient.Wait(resources, timeout)
+	}
+}
@@ -0,0 +1,167 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/cli-runtime/pkg/resource"
+
+	"helm.sh/helm/v3/pkg/kube"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// waveKubeClient records the calls made to apply waves.
+type waveKubeClient struct {
+	kubefake.PrintingKubeClient
+	calls []string
+	// failOn is the name of a resource that cannot be updated.
+	failOn string
+}
+
+func resourceNames(resources kube.ResourceList) string {
+	var names []string
+	for _, r := range resources {
+		names = append(names, r.Name)
+	}
+	return "[" + strings.Join(names, " ") + "]"
+}
+
+func (c *waveKubeClient) Create(resources kube.ResourceList) (*kube.Result, error) {
+	c.calls = append(c.calls, "create "+resourceNames(resources))
+	return &kube.Result{Created: resources}, nil
+}
+
+func (c *waveKubeClient) Update(original, target kube.ResourceList, _ bool) (*kube.Result, error) {
+	c.calls = append(c.calls, fmt.Sprintf("update %s %s", resourceNames(original), resourceNames(target)))
+	for _, r := range target {
+		if r.Name == c.failOn {
+			return &kube.Result{}, errors.Errorf("cannot update %s", r.Name)
+		}
+	}
+	return &kube.Result{Updated: target, Deleted: original.Difference(target)}, nil
+}
+
+func (c *waveKubeClient) Wait(resources kube.ResourceList, _ time.Duration) error {
+	c.calls = append(c.calls, "wait "+resourceNames(resources))
+	return nil
+}
+
+func newWaveResource(name, wave string) *resource.Info {
+	info := newDeploymentResource(name, "default")
+	info.Namespace = "default"
+	if wave != "" {
+		info.Object.(interface{ SetAnnotations(map[string]string) }).SetAnnotations(map[string]string{release.WaveAnnotation: wave})
+	}
+	return info
+}
+
+func TestApplyInWaves(t *testing.T) {
+	db, web, api := newWaveResource("db", "-1"), newWaveResource("web", ""), newWaveResource("api", "1")
+	old := newWaveResource("old", "")
+
+	tests := []struct {
+		name    string
+		current kube.ResourceList
+		target  kube.ResourceList
+		create  bool
+		wait    bool
+		failOn  string
+		calls   []string
+		err     string
+	}{
+		{
+			name:    "update in waves",
+			current: kube.ResourceList{web, db, old},
+			target:  kube.ResourceList{web, db, api},
+			wait:    true,
+			calls: []string{
+				"update [db] [db]",
+				"wait [db]",
+				"update [web] [web]",
+				"wait [web]",
+				"update [] [api]",
+				"wait [api]",
+				"update [old] []",
+			},
+		},
+		{
+			name:   "create in waves without waiting",
+			target: kube.ResourceList{api, web},
+			create: true,
+			calls:  []string{"create [web]", "create [api]"},
+		},
+		{
+			name:    "single wave",
+			current: kube.ResourceList{web, old},
+			target:  kube.ResourceList{web},
+			wait:    true,
+			calls:   []string{"update [web old] [web]"},
+		},
+		{
+			name:    "failed wave stops later waves",
+			current: kube.ResourceList{web, db},
+			target:  kube.ResourceList{web, db, api},
+			wait:    true,
+			failOn:  "web",
+			calls:   []string{"update [db] [db]", "wait [db]", "update [web] [web]"},
+			err:     "wave 0: cannot update web",
+		},
+		{
+			name:   "invalid wave",
+			target: kube.ResourceList{web, newWaveResource("bad", "first")},
+			err:    `Deployment "bad" in namespace "default" has an invalid helm.sh/wave annotation "first": must be an integer`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := actionConfigFixture(t)
+			client := &waveKubeClient{PrintingKubeClient: kubefake.PrintingKubeClient{Out: io.Discard}, failOn: tt.failOn}
+			cfg.KubeClient = client
+
+			_, err := cfg.applyInWaves(tt.current, tt.target, false, tt.create, cfg.waitForWave(tt.wait, false, time.Minute))
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.calls, client.calls)
+		})
+	}
+}
+
+func TestReleaseWaves(t *testing.T) {
+	waves, err := releaseWaves(kube.ResourceList{newWaveResource("web", ""), newWaveResource("worker", "0")})
+	require.NoError(t, err)
+	assert.Nil(t, waves, "resources in a single wave are not recorded")
+
+	waves, err = releaseWaves(kube.ResourceList{newWaveResource("web", ""), newWaveResource("db", "-1")})
+	require.NoError(t, err)
+	require.Len(t, waves, 2)
+	assert.Equal(t, -1, waves[0].Wave)
+	assert.Equal(t, []release.PlannedResource{{APIVersion: "apps/v1", Kind: "Deployment", Name: "db", Namespace: "default"}}, waves[0].Resources)
+	assert.Equal(t, 0, waves[1].Wave)
+	assert.Equal(t, "web", waves[1].Resources[0].Name)
+}
@@ -110,15 +110,30 @@ func isGZipApplication(data []byte) bool {
 // LoadArchiveFiles reads in files out of an archive into memory. This function
 // performs important path security checks and should always be used before
 // expanding a tarball
+//
+// The archive is read within DefaultArchiveLimits.
 func LoadArchiveFiles(in io.Reader) ([]*BufferedFile, error) {
+	return LoadArchiveFilesWithLimits(in, DefaultArchiveLimits)
+}
+
+// LoadArchiveFilesWithLimits is like LoadArchiveFiles, but reads the archive
+// within the given limits. An archive exceeding them returns an
+// *ArchiveLimitError.
+func LoadArchiveFilesWithLimits(in io.Reader, limits ArchiveLimits) ([]*BufferedFile, error) {
 	unzipped, err := gzip.NewReader(in)
 	if err != nil {
 		return nil, err
 	}
 	defer unzipped.Close()
 
+	var r io.Reader = unzipped
+	if limits.MaxDecompressedSize > 0 {
+		r = newLimitedReader(unzipped, limits.MaxDecompressedSize)
+	}
+
 	files := []*BufferedFile{}
-	tr := tar.NewReader(unzipped)
+	entries := 0
+	tr := tar.NewReader(r)
 	for {
 		b := bytes.NewBuffer(nil)
 		hd, err := tr.Next()
@@ -129,6 +144,11 @@ func LoadArchiveFiles(in io.Reader) ([]*BufferedFile, error) {
 			return nil, err
 		}
 
+		entries++
+		if limits.MaxFiles > 0 && entries > limits.MaxFiles {
+			return nil, &ArchiveLimitError{Limit: LimitFiles, Max: int64(limits.MaxFiles)}
+		}
+
 		if hd.FileInfo().IsDir() {
 			// Use this instead of hd.Typeflag because we don't have to do any
 			// inference chasing.
@@ -178,6 +198,10 @@ func LoadArchiveFiles(in io.Reader) ([]*BufferedFile, error) {
 			return nil, errors.New("chart yaml not in base directory")
 		}
 
+		if limits.MaxFileSize > 0 && hd.Size > limits.MaxFileSize {
+			return nil, &ArchiveLimitError{Limit: LimitFileSize, Max: limits.MaxFileSize, Name: n}
+		}
+
 		if _, err := io.Copy(b, tr); err != nil {
 			return nil, err
 		}
@@ -196,7 +220,13 @@ func LoadArchiveFiles(in io.Reader) ([]*BufferedFile, error) {
 
 // LoadArchive loads from a reader containing a compressed tar archive.
 func LoadArchive(in io.Reader) (*chart.Chart, error) {
-	files, err := LoadArchiveFiles(in)
+	return LoadArchiveWithLimits(in, DefaultArchiveLimits)
+}
+
+// LoadArchiveWithLimits is like LoadArchive, but reads the archive within the
+// given limits.
+func LoadArchiveWithLimits(in io.Reader, limits ArchiveLimits) (*chart.Chart, error) {
+	files, err := LoadArchiveFilesWithLimits(in, limits)
 	if err != nil {
 		return nil, err
 	}
@@ -20,6 +20,7 @@ import (
 	"archive/tar"
 	"bytes"
 	"compress/gzip"
+	"errors"
 	"testing"
 )
 
@@ -88,3 +89,81 @@ func TestLoadArchiveFiles(t *testing.T) {
 		})
 	}
 }
+
+func TestLoadArchiveFilesWithLimits(t *testing.T) {
+	archive := func(t *testing.T, files map[string]int) *bytes.Buffer {
+		t.Helper()
+		buf := &bytes.Buffer{}
+		gzw := gzip.NewWriter(buf)
+		tw := tar.NewWriter(gzw)
+		for name, size := range files {
+			if err := tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: name, Size: int64(size), Mode: 0644}); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := tw.Write(bytes.Repeat([]byte("a"), size)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		_ = tw.Close()
+		_ = gzw.Close()
+		return buf
+	}
+
+	tcs := []struct {
+		name   string
+		files  map[string]int
+		limits ArchiveLimits
+		limit  ArchiveLimit
+	}{
+		{
+			name:   "within the limits",
+			files:  map[string]int{"chart/Chart.yaml": 10, "chart/values.yaml": 10},
+			limits: ArchiveLimits{MaxDecompressedSize: 1 << 20, MaxFileSize: 10, MaxFiles: 2},
+		},
+		{
+			name:   "no limits",
+			files:  map[string]int{"chart/big": 1 << 20},
+			limits: ArchiveLimits{},
+		},
+		{
+			name:   "too many files",
+			files:  map[string]int{"chart/a": 1, "chart/b": 1, "chart/c": 1},
+			limits: ArchiveLimits{MaxFiles: 2},
+			limit:  LimitFiles,
+		},
+		{
+			name:   "file too large",
+			files:  map[string]int{"chart/big": 1025},
+			limits: ArchiveLimits{MaxFileSize: 1024},
+			limit:  LimitFileSize,
+		},
+		{
+			name:   "decompressed archive too large",
+			files:  map[string]int{"chart/a": 1 << 20, "chart/b": 1 << 20},
+			limits: ArchiveLimits{MaxDecompressedSize: 1 << 20},
+			limit:  LimitDecompressedSize,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			files, err := LoadArchiveFilesWithLimits(archive(t, tc.files), tc.limits)
+			if tc.limit == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				if len(files) != len(tc.files) {
+					t.Fatalf("expected %d files, got %d", len(tc.files), len(files))
+				}
+				return
+			}
+			var limitErr *ArchiveLimitError
+			if !errors.As(err, &limitErr) {
+				t.Fatalf("expected an ArchiveLimitError, got %v", err)
+			}
+			if limitErr.Limit != tc.limit {
+				t.Errorf("expected the %s limit to be exceeded, got %s", tc.limit, limitErr.Limit)
+			}
+		})
+	}
+}
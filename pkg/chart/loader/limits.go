@@ -0,0 +1,104 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loader
+
+import (
+	"fmt"
+	"io"
+)
+
+// ArchiveLimits bounds the resources used to read a chart archive, so that a
+// malicious archive cannot exhaust the memory of the process reading it. A
+// zero value means no limit.
+type ArchiveLimits struct {
+	// MaxDecompressedSize is the maximum number of bytes of the decompressed
+	// archive.
+	MaxDecompressedSize int64
+	// MaxFileSize is the maximum size of a file in the archive.
+	MaxFileSize int64
+	// MaxFiles is the maximum number of entries in the archive, directories
+	// included.
+	MaxFiles int
+}
+
+// DefaultArchiveLimits are the limits applied by LoadArchive and
+// LoadArchiveFiles. They are far above the size of real charts, and can be
+// changed by programs that embed Helm.
+var DefaultArchiveLimits = ArchiveLimits{
+	MaxDecompressedSize: 100 << 20,
+	MaxFileSize:         20 << 20,
+	MaxFiles:            10000,
+}
+
+// ArchiveLimit names a limit of ArchiveLimits.
+type ArchiveLimit string
+
+const (
+	// LimitDecompressedSize is the limit on the size of the decompressed archive.
+	LimitDecompressedSize ArchiveLimit = "decompressed size"
+	// LimitFileSize is the limit on the size of a file in the archive.
+	LimitFileSize ArchiveLimit = "file size"
+	// LimitFiles is the limit on the number of entries in the archive.
+	LimitFiles ArchiveLimit = "number of files"
+)
+
+// ArchiveLimitError is returned when a chart archive exceeds one of its
+// limits.
+type ArchiveLimitError struct {
+	// Limit is the limit that was exceeded.
+	Limit ArchiveLimit
+	// Max is the value of the limit.
+	Max int64
+	// Name is the file that exceeded the limit, for LimitFileSize.
+	Name string
+}
+
+func (e *ArchiveLimitError) Error() string {
+	if e.Name != "" {
+		return fmt.Sprintf("chart archive exceeds the maximum %s of %d: %s", e.Limit, e.Max, e.Name)
+	}
+	return fmt.Sprintf("chart archive exceeds the maximum %s of %d", e.Limit, e.Max)
+}
+
+// limitedReader returns an ArchiveLimitError once more than n bytes are read
+// from r.
+type limitedReader struct {
+	r   io.Reader
+	n   int64
+	max int64
+}
+
+func newLimitedReader(r io.Reader, max int64) *limitedReader {
+	return &limitedReader{r: r, n: max, max: max}
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.n < 0 {
+		return 0, &ArchiveLimitError{Limit: LimitDecompressedSize, Max: l.max}
+	}
+	// Read one byte past the limit to tell an archive of exactly the
+	// maximum size from a larger one.
+	if int64(len(p)) > l.n+1 {
+		p = p[:l.n+1]
+	}
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+	if l.n < 0 {
+		return n, &ArchiveLimitError{Limit: LimitDecompressedSize, Max: l.max}
+	}
+	return n, err
+}
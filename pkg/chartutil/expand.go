@@ -30,8 +30,17 @@ import (
 )
 
 // Expand uncompresses and extracts a chart into the specified directory.
+//
+// The archive is read within loader.DefaultArchiveLimits.
 func Expand(dir string, r io.Reader) error {
-	files, err := loader.LoadArchiveFiles(r)
+	return ExpandWithLimits(dir, r, loader.DefaultArchiveLimits)
+}
+
+// ExpandWithLimits is like Expand, but reads the archive within the given
+// limits. An archive exceeding them returns a *loader.ArchiveLimitError and
+// nothing is written to dir.
+func ExpandWithLimits(dir string, r io.Reader, limits loader.ArchiveLimits) error {
+	files, err := loader.LoadArchiveFilesWithLimits(r, limits)
 	if err != nil {
 		return err
 	}
@@ -17,9 +17,12 @@ limitations under the License.
 package chartutil
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
+
+	"helm.sh/helm/v3/pkg/chart/loader"
 )
 
 func TestExpand(t *testing.T) {
@@ -122,3 +125,23 @@ func TestExpandFile(t *testing.T) {
 		}
 	}
 }
+
+func TestExpandWithLimits(t *testing.T) {
+	dest := t.TempDir()
+
+	reader, err := os.Open("testdata/frobnitz-1.2.3.tgz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+
+	err = ExpandWithLimits(dest, reader, loader.ArchiveLimits{MaxFiles: 5})
+	var limitErr *loader.ArchiveLimitError
+	if !errors.As(err, &limitErr) || limitErr.Limit != loader.LimitFiles {
+		t.Fatalf("expected the number of files limit to be exceeded, got %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dest, "frobnitz")); !os.IsNotExist(err) {
+		t.Errorf("expected nothing to be expanded, got %v", err)
+	}
+}
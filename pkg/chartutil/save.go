@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"time"
 
 	"github.com/pkg/errors"
@@ -104,6 +105,27 @@ func SaveDir(c *chart.Chart, dest string) error {
 //
 // This returns the absolute path to the chart archive file.
 func Save(c *chart.Chart, outDir string) (string, error) {
+	return save(c, outDir, &archiveWriter{})
+}
+
+// SaveReproducible creates an archived chart like Save, but the archive only
+// depends on the contents of the chart, so that saving the same chart on any
+// machine gives the same bytes.
+//
+// Every entry of the archive, as well as the archive file itself, is stamped
+// with modTime instead of the current time. Entries are written in a sorted
+// order, with normalized modes and ownership, and the gzip header holds no
+// timestamp or file name.
+func SaveReproducible(c *chart.Chart, outDir string, modTime time.Time) (string, error) {
+	modTime = modTime.UTC().Truncate(time.Second)
+	filename, err := save(c, outDir, &archiveWriter{reproducible: true, modTime: modTime})
+	if err != nil {
+		return filename, err
+	}
+	return filename, os.Chtimes(filename, modTime, modTime)
+}
+
+func save(c *chart.Chart, outDir string, out *archiveWriter) (string, error) {
 	if err := c.Validate(); err != nil {
 		return "", errors.Wrap(err, "chart validation")
 	}
@@ -134,10 +156,10 @@ func Save(c *chart.Chart, outDir string) (string, error) {
 	zipper.Header.Comment = "Helm"
 
 	// Wrap in tar writer
-	twriter := tar.NewWriter(zipper)
+	out.Writer = tar.NewWriter(zipper)
 	rollback := false
 	defer func() {
-		twriter.Close()
+		out.Close()
 		zipper.Close()
 		f.Close()
 		if rollback {
@@ -145,14 +167,43 @@ func Save(c *chart.Chart, outDir string) (string, error) {
 		}
 	}()
 
-	if err := writeTarContents(twriter, c, ""); err != nil {
+	if err := writeTarContents(out, c, ""); err != nil {
 		rollback = true
 		return filename, err
 	}
 	return filename, nil
 }
 
-func writeTarContents(out *tar.Writer, c *chart.Chart, prefix string) error {
+// archiveWriter writes the entries of a chart archive.
+type archiveWriter struct {
+	*tar.Writer
+	// reproducible sorts the entries and stamps them with modTime.
+	reproducible bool
+	modTime      time.Time
+}
+
+// sortedFiles returns the files in the order they are written to the archive.
+func (out *archiveWriter) sortedFiles(files []*chart.File) []*chart.File {
+	if !out.reproducible {
+		return files
+	}
+	sorted := append([]*chart.File(nil), files...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	return sorted
+}
+
+// sortedCharts returns the dependencies in the order they are written to the
+// archive.
+func (out *archiveWriter) sortedCharts(charts []*chart.Chart) []*chart.Chart {
+	if !out.reproducible {
+		return charts
+	}
+	sorted := append([]*chart.Chart(nil), charts...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Name() < sorted[j].Name() })
+	return sorted
+}
+
+func writeTarContents(out *archiveWriter, c *chart.Chart, prefix string) error {
 	err := validateName(c.Name())
 	if err != nil {
 		return err
@@ -211,7 +262,7 @@ func writeTarContents(out *tar.Writer, c *chart.Chart, prefix string) error {
 	}
 
 	// Save templates
-	for _, f := range c.Templates {
+	for _, f := range out.sortedFiles(c.Templates) {
 		n := filepath.Join(base, f.Name)
 		if err := writeToTar(out, n, f.Data); err != nil {
 			return err
@@ -219,7 +270,7 @@ func writeTarContents(out *tar.Writer, c *chart.Chart, prefix string) error {
 	}
 
 	// Save files
-	for _, f := range c.Files {
+	for _, f := range out.sortedFiles(c.Files) {
 		n := filepath.Join(base, f.Name)
 		if err := writeToTar(out, n, f.Data); err != nil {
 			return err
@@ -227,7 +278,7 @@ func writeTarContents(out *tar.Writer, c *chart.Chart, prefix string) error {
 	}
 
 	// Save dependencies
-	for _, dep := range c.Dependencies() {
+	for _, dep := range out.sortedCharts(c.Dependencies()) {
 		if err := writeTarContents(out, dep, filepath.Join(base, ChartsDir)); err != nil {
 			return err
 		}
@@ -236,7 +287,7 @@ func writeTarContents(out *tar.Writer, c *chart.Chart, prefix string) error {
 }
 
 // writeToTar writes a single file to a tar archive.
-func writeToTar(out *tar.Writer, name string, body []byte) error {
+func writeToTar(out *archiveWriter, name string, body []byte) error {
 	// TODO: Do we need to create dummy parent directory names if none exist?
 	h := &tar.Header{
 		Name:    filepath.ToSlash(name),
@@ -244,6 +295,9 @@ func writeToTar(out *tar.Writer, name string, body []byte) error {
 		Size:    int64(len(body)),
 		ModTime: time.Now(),
 	}
+	if out.reproducible {
+		h.ModTime = out.modTime
+	}
 	if err := out.WriteHeader(h); err != nil {
 		return err
 	}
@@ -179,6 +179,93 @@ func TestSavePreservesTimestamps(t *testing.T) {
 	}
 }
 
+func TestSaveReproducible(t *testing.T) {
+	modTime := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	newChart := func(files []*chart.File) *chart.Chart {
+		c := &chart.Chart{
+			Metadata: &chart.Metadata{
+				APIVersion: chart.APIVersionV2,
+				Name:       "ahab",
+				Version:    "1.2.3",
+			},
+			Templates: []*chart.File{
+				{Name: "templates/b.yaml", Data: []byte("b")},
+				{Name: "templates/a.yaml", Data: []byte("a")},
+			},
+			Files: files,
+		}
+		c.AddDependency(&chart.Chart{Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "moby", Version: "0.1.0"}})
+		c.AddDependency(&chart.Chart{Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "dick", Version: "0.1.0"}})
+		return c
+	}
+	one := newChart([]*chart.File{
+		{Name: "scheherazade/shahryar.txt", Data: []byte("1,001 Nights")},
+		{Name: "README.md", Data: []byte("readme")},
+	})
+	two := newChart([]*chart.File{
+		{Name: "README.md", Data: []byte("readme")},
+		{Name: "scheherazade/shahryar.txt", Data: []byte("1,001 Nights")},
+	})
+
+	first, err := SaveReproducible(one, t.TempDir(), modTime)
+	if err != nil {
+		t.Fatalf("Failed to save: %s", err)
+	}
+	time.Sleep(time.Second)
+	second, err := SaveReproducible(two, t.TempDir(), modTime)
+	if err != nil {
+		t.Fatalf("Failed to save: %s", err)
+	}
+
+	firstData, err := os.ReadFile(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secondData, err := os.ReadFile(second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(firstData, secondData) {
+		t.Fatal("expected the archives of the same chart to be identical")
+	}
+
+	fi, err := os.Stat(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fi.ModTime().Equal(modTime) {
+		t.Errorf("expected the archive to be stamped with %s, got %s", modTime, fi.ModTime())
+	}
+
+	headers, err := retrieveAllHeadersFromTar(first)
+	if err != nil {
+		t.Fatalf("Failed to parse tar: %v", err)
+	}
+	var names []string
+	for _, header := range headers {
+		names = append(names, header.Name)
+		if !header.ModTime.Equal(modTime) {
+			t.Errorf("expected %s to be stamped with %s, got %s", header.Name, modTime, header.ModTime)
+		}
+		if header.Mode != 0644 || header.Uid != 0 || header.Gid != 0 || header.Uname != "" || header.Gname != "" {
+			t.Errorf("expected %s to have normalized mode and ownership, got %o %d:%d", header.Name, header.Mode, header.Uid, header.Gid)
+		}
+	}
+	expect := []string{
+		"ahab/Chart.yaml",
+		"ahab/templates/a.yaml",
+		"ahab/templates/b.yaml",
+		"ahab/README.md",
+		"ahab/scheherazade/shahryar.txt",
+		"ahab/charts/dick/Chart.yaml",
+		"ahab/charts/moby/Chart.yaml",
+	}
+	if strings.Join(names, ",") != strings.Join(expect, ",") {
+		t.Errorf("expected entries %v, got %v", expect, names)
+	}
+}
+
 // We could refactor `load.go` to use this `retrieveAllHeadersFromTar` function
 // as well, so we are not duplicating components of the code which iterate
 // through the tar.
@@ -29,6 +29,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/pflag"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
@@ -81,6 +82,10 @@ type EnvSettings struct {
 	RepositoryConfig string
 	// RepositoryCache is the path to the repository cache directory.
 	RepositoryCache string
+	// TrustPolicyConfig is the path to the trust policy file.
+	TrustPolicyConfig string
+	// ChartCache is the path to the content-addressable chart cache directory.
+	ChartCache string
 	// PluginsDirectory is the path to the plugins directory.
 	PluginsDirectory string
 	// MaxHistory is the max release history maintained.
@@ -89,6 +94,9 @@ type EnvSettings struct {
 	BurstLimit int
 	// QPS is queries per second which may be used to avoid throttling.
 	QPS float32
+	// LockTimeout is how long an operation waits for the lock of a release
+	// held by another operation.
+	LockTimeout time.Duration
 }
 
 func New() *EnvSettings {
@@ -107,8 +115,11 @@ func New() *EnvSettings {
 		RegistryConfig:            envOr("HELM_REGISTRY_CONFIG", helmpath.ConfigPath("registry/config.json")),
 		RepositoryConfig:          envOr("HELM_REPOSITORY_CONFIG", helmpath.ConfigPath("repositories.yaml")),
 		RepositoryCache:           envOr("HELM_REPOSITORY_CACHE", helmpath.CachePath("repository")),
+		TrustPolicyConfig:         envOr("HELM_TRUST_POLICY_CONFIG", helmpath.ConfigPath("trust-policy.yaml")),
+		ChartCache:                envOr("HELM_CHART_CACHE", helmpath.CachePath("charts")),
 		BurstLimit:                envIntOr("HELM_BURST_LIMIT", defaultBurstLimit),
 		QPS:                       envFloat32Or("HELM_QPS", defaultQPS),
+		LockTimeout:               envDurationOr("HELM_LOCK_TIMEOUT", 0),
 	}
 	env.Debug, _ = strconv.ParseBool(os.Getenv("HELM_DEBUG"))
 
@@ -160,6 +171,7 @@ func (s *EnvSettings) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&s.RepositoryCache, "repository-cache", s.RepositoryCache, "path to the directory containing cached repository indexes")
 	fs.IntVar(&s.BurstLimit, "burst-limit", s.BurstLimit, "client-side default throttling limit")
 	fs.Float32Var(&s.QPS, "qps", s.QPS, "queries per second used when communicating with the Kubernetes API, not including bursting")
+	fs.DurationVar(&s.LockTimeout, "lock-timeout", s.LockTimeout, "time to wait for the lock of a release held by another operation")
 }
 
 func envOr(name, def string) string {
@@ -205,6 +217,18 @@ func envFloat32Or(name string, def float32) float32 {
 	return float32(ret)
 }
 
+func envDurationOr(name string, def time.Duration) time.Duration {
+	if name == "" {
+		return def
+	}
+	envVal := envOr(name, def.String())
+	ret, err := time.ParseDuration(envVal)
+	if err != nil {
+		return def
+	}
+	return ret
+}
+
 func envCSV(name string) (ls []string) {
 	trimmed := strings.Trim(os.Getenv(name), ", ")
 	if trimmed != "" {
@@ -215,19 +239,22 @@ func envCSV(name string) (ls []string) {
 
 func (s *EnvSettings) EnvVars() map[string]string {
 	envvars := map[string]string{
-		"HELM_BIN":               os.Args[0],
-		"HELM_CACHE_HOME":        helmpath.CachePath(""),
-		"HELM_CONFIG_HOME":       helmpath.ConfigPath(""),
-		"HELM_DATA_HOME":         helmpath.DataPath(""),
-		"HELM_DEBUG":             fmt.Sprint(s.Debug),
-		"HELM_PLUGINS":           s.PluginsDirectory,
-		"HELM_REGISTRY_CONFIG":   s.RegistryConfig,
-		"HELM_REPOSITORY_CACHE":  s.RepositoryCache,
-		"HELM_REPOSITORY_CONFIG": s.RepositoryConfig,
-		"HELM_NAMESPACE":         s.Namespace(),
-		"HELM_MAX_HISTORY":       strconv.Itoa(s.MaxHistory),
-		"HELM_BURST_LIMIT":       strconv.Itoa(s.BurstLimit),
-		"HELM_QPS":               strconv.FormatFloat(float64(s.QPS), 'f', 2, 32),
+		"HELM_BIN":                 os.Args[0],
+		"HELM_CACHE_HOME":          helmpath.CachePath(""),
+		"HELM_CHART_CACHE":         s.ChartCache,
+		"HELM_CONFIG_HOME":         helmpath.ConfigPath(""),
+		"HELM_DATA_HOME":           helmpath.DataPath(""),
+		"HELM_DEBUG":               fmt.Sprint(s.Debug),
+		"HELM_PLUGINS":             s.PluginsDirectory,
+		"HELM_REGISTRY_CONFIG":     s.RegistryConfig,
+		"HELM_REPOSITORY_CACHE":    s.RepositoryCache,
+		"HELM_REPOSITORY_CONFIG":   s.RepositoryConfig,
+		"HELM_TRUST_POLICY_CONFIG": s.TrustPolicyConfig,
+		"HELM_NAMESPACE":           s.Namespace(),
+		"HELM_MAX_HISTORY":         strconv.Itoa(s.MaxHistory),
+		"HELM_BURST_LIMIT":         strconv.Itoa(s.BurstLimit),
+		"HELM_QPS":                 strconv.FormatFloat(float64(s.QPS), 'f', 2, 32),
+		"HELM_LOCK_TIMEOUT":        s.LockTimeout.String(),
 
 		// broken, these are populated from helm flags and not kubeconfig.
 		"HELM_KUBECONTEXT":                  s.KubeContext,
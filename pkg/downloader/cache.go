@@ -0,0 +1,212 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package downloader
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/internal/fileutil"
+)
+
+// ChartCache is a content-addressable cache of chart archives, shared by the
+// commands that download charts.
+//
+// Archives are keyed by the digest that identifies them before they are
+// downloaded: the digest of the archive listed in a repository index, or the
+// digest of the manifest of a chart in an OCI registry. Each archive is stored
+// in a directory named after its digest, next to its provenance file if it
+// has one.
+//
+// The cache does not check the content it stores: archives listed in an index
+// are checked against their digest when they are added and read, and charts in
+// OCI registries are pulled by the digest of their manifest.
+type ChartCache struct {
+	// Root is the directory of the cache.
+	Root string
+}
+
+// CacheEntry is a chart archive stored in the cache.
+type CacheEntry struct {
+	// Digest is the key of the archive.
+	Digest string `json:"digest"`
+	// Name is the file name of the archive.
+	Name string `json:"name"`
+	// Path is the location of the archive in the cache.
+	Path string `json:"path"`
+	// Size is the size of the archive and of its provenance file.
+	Size int64 `json:"size"`
+	// LastUsed is the last time the archive was added or read.
+	LastUsed time.Time `json:"lastUsed"`
+}
+
+// NewChartCache returns the chart cache stored in a directory.
+func NewChartCache(root string) *ChartCache {
+	return &ChartCache{Root: root}
+}
+
+// normalizeDigest returns a digest in the algorithm:hex form. Repository
+// indexes list the hex of the SHA-256 digest of archives without algorithm.
+func normalizeDigest(d string) (digest.Digest, error) {
+	if !strings.Contains(d, ":") {
+		d = string(digest.SHA256) + ":" + d
+	}
+	dgst, err := digest.Parse(d)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid chart digest %q", d)
+	}
+	return dgst, nil
+}
+
+// checkArchiveDigest checks that an archive has the digest listed for it in a
+// repository index.
+func checkArchiveDigest(d string, data []byte) error {
+	dgst, err := normalizeDigest(d)
+	if err != nil {
+		return err
+	}
+	if actual := dgst.Algorithm().FromBytes(data); actual != dgst {
+		return errors.Errorf("chart archive has digest %s, the repository index lists %s", actual, dgst)
+	}
+	return nil
+}
+
+func (c *ChartCache) dir(dgst digest.Digest) string {
+	return filepath.Join(c.Root, dgst.Algorithm().String(), dgst.Encoded())
+}
+
+// Get returns the path of the archive cached with the given digest, and marks
+// it as used. The second result is false if the archive is not cached.
+func (c *ChartCache) Get(d string) (string, bool) {
+	dgst, err := normalizeDigest(d)
+	if err != nil {
+		return "", false
+	}
+	dir := c.dir(dgst)
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return "", false
+	}
+	for _, f := range files {
+		if f.IsDir() || !isTar(f.Name()) {
+			continue
+		}
+		path := filepath.Join(dir, f.Name())
+		now := time.Now()
+		os.Chtimes(path, now, now)
+		return path, true
+	}
+	return "", false
+}
+
+// Put stores an archive with the given digest and file name, and returns its
+// path in the cache.
+func (c *ChartCache) Put(d, name string, data []byte) (string, error) {
+	dgst, err := normalizeDigest(d)
+	if err != nil {
+		return "", err
+	}
+	dir := c.dir(dgst)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, filepath.Base(name))
+	if err := fileutil.AtomicWriteFile(path, bytes.NewReader(data), 0644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// List returns the archives in the cache, the least recently used first.
+func (c *ChartCache) List() ([]CacheEntry, error) {
+	var entries []CacheEntry
+	algorithms, err := os.ReadDir(c.Root)
+	if os.IsNotExist(err) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, algorithm := range algorithms {
+		if !algorithm.IsDir() {
+			continue
+		}
+		dirs, err := os.ReadDir(filepath.Join(c.Root, algorithm.Name()))
+		if err != nil {
+			return nil, err
+		}
+		for _, dir := range dirs {
+			entry, ok := c.entry(algorithm.Name(), dir.Name())
+			if ok {
+				entries = append(entries, entry)
+			}
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].LastUsed.Before(entries[j].LastUsed)
+	})
+	return entries, nil
+}
+
+func (c *ChartCache) entry(algorithm, encoded string) (CacheEntry, bool) {
+	dir := filepath.Join(c.Root, algorithm, encoded)
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return CacheEntry{}, false
+	}
+	entry := CacheEntry{Digest: algorithm + ":" + encoded}
+	for _, f := range files {
+		info, err := f.Info()
+		if err != nil || info.IsDir() {
+			continue
+		}
+		entry.Size += info.Size()
+		if isTar(f.Name()) {
+			entry.Name = f.Name()
+			entry.Path = filepath.Join(dir, f.Name())
+			entry.LastUsed = info.ModTime()
+		}
+	}
+	return entry, entry.Name != ""
+}
+
+// Prune removes the archives that were not used since the given time, and
+// returns them.
+func (c *ChartCache) Prune(before time.Time) ([]CacheEntry, error) {
+	entries, err := c.List()
+	if err != nil {
+		return nil, err
+	}
+	var pruned []CacheEntry
+	for _, entry := range entries {
+		if !entry.LastUsed.Before(before) {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Dir(entry.Path)); err != nil {
+			return pruned, err
+		}
+		pruned = append(pruned, entry)
+	}
+	return pruned, nil
+}
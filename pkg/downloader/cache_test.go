@@ -0,0 +1,125 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package downloader
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestChartCache(t *testing.T) {
+	cache := NewChartCache(t.TempDir())
+	const (
+		hex    = "515c58e5f79d8b2913a10cb400ebb6fa9c77fe813287afbacf1a0b897cd78727"
+		digest = "sha256:" + hex
+	)
+
+	if _, ok := cache.Get(digest); ok {
+		t.Fatal("expected an empty cache")
+	}
+
+	path, err := cache.Put(digest, "alpine-0.2.0.tgz", []byte("chart"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := filepath.Join(cache.Root, "sha256", hex, "alpine-0.2.0.tgz"); path != expect {
+		t.Errorf("expected %s, got %s", expect, path)
+	}
+	if _, err := cache.Put(digest, "alpine-0.2.0.tgz.prov", []byte("prov")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Repository indexes list the digest without its algorithm.
+	for _, d := range []string{digest, hex} {
+		if got, ok := cache.Get(d); !ok || got != path {
+			t.Errorf("Get(%q) = %q, %t, expected %q", d, got, ok, path)
+		}
+	}
+	if _, ok := cache.Get("not a digest"); ok {
+		t.Error("expected an invalid digest to miss")
+	}
+	if _, err := cache.Put("sha256:short", "foo-0.1.0.tgz", []byte("chart")); err == nil {
+		t.Error("expected an error for an invalid digest")
+	}
+
+	entries, err := cache.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(entries))
+	}
+	if e := entries[0]; e.Digest != digest || e.Name != "alpine-0.2.0.tgz" || e.Size != int64(len("chart")+len("prov")) {
+		t.Errorf("unexpected entry %+v", e)
+	}
+
+	pruned, err := cache.Prune(time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pruned) != 0 {
+		t.Errorf("expected a recently used chart to be kept, pruned %v", pruned)
+	}
+
+	old := time.Now().Add(-48 * time.Hour)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatal(err)
+	}
+	pruned, err = cache.Prune(time.Now().Add(-24 * time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pruned) != 1 {
+		t.Errorf("expected 1 pruned chart, got %d", len(pruned))
+	}
+	if _, ok := cache.Get(digest); ok {
+		t.Error("expected the pruned chart to be removed")
+	}
+}
+
+func TestChartCacheListMissing(t *testing.T) {
+	entries, err := NewChartCache(filepath.Join(t.TempDir(), "missing")).List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("expected no entries, got %v", entries)
+	}
+}
+
+func TestCheckArchiveDigest(t *testing.T) {
+	const other = "3b2e7e8e5c2cd3e2ed0f1a0bd8a7d4c1e7f0c6ce8d2b5a4d0b7ac0f27e3b1a5c"
+	data := []byte("chart")
+	actual := fmt.Sprintf("%x", sha256.Sum256(data))
+
+	if err := checkArchiveDigest(actual, data); err != nil {
+		t.Errorf("expected the digest of an index to match, got %s", err)
+	}
+	if err := checkArchiveDigest("sha256:"+actual, data); err != nil {
+		t.Errorf("expected a prefixed digest to match, got %s", err)
+	}
+	if err := checkArchiveDigest(other, data); err == nil {
+		t.Error("expected an error for a digest that does not match")
+	}
+	if err := checkArchiveDigest("nope", data); err == nil {
+		t.Error("expected an error for an invalid digest")
+	}
+}
@@ -16,6 +16,7 @@ limitations under the License.
 package downloader
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"net/url"
@@ -28,6 +29,7 @@ import (
 
 	"helm.sh/helm/v3/internal/fileutil"
 	"helm.sh/helm/v3/internal/urlutil"
+	"helm.sh/helm/v3/pkg/chart/loader"
 	"helm.sh/helm/v3/pkg/getter"
 	"helm.sh/helm/v3/pkg/helmpath"
 	"helm.sh/helm/v3/pkg/provenance"
@@ -73,6 +75,25 @@ type ChartDownloader struct {
 	RegistryClient   *registry.Client
 	RepositoryConfig string
 	RepositoryCache  string
+	// TrustPolicyConfig is the path of the trust policy file. Its rules
+	// strengthen the verification strategy of the charts they match.
+	TrustPolicyConfig string
+	// RegistryConfig is the registry credentials file, used by repositories
+	// that reference registry credentials.
+	RegistryConfig string
+	// ChartCache is the directory of the content-addressable chart cache.
+	// Charts are not cached when it is empty.
+	ChartCache string
+	// Offline only takes charts from the chart cache, and fails for charts
+	// that are not cached instead of downloading them.
+	Offline bool
+
+	// ResolvedURL is set by DownloadTo to the URL the chart reference was
+	// resolved to, without credentials.
+	//
+	// TODO: The URL is set as a property rather than returned to maintain the
+	// Go API. In Helm v4 DownloadTo should return it.
+	ResolvedURL string
 }
 
 // DownloadTo retrieves a chart. Depending on the settings, it may also download a provenance file.
@@ -84,28 +105,79 @@ type ChartDownloader struct {
 //
 // For VerifyNever and VerifyIfPossible, the Verification may be empty.
 //
+// A rule of the trust policy that matches the chart source may require a
+// verification, or turn verification failures into warnings, see TrustPolicy.
+//
+// When ChartCache is set, charts are looked up in the chart cache by the digest
+// listed in the repository index or by the digest of their OCI manifest, and
+// downloaded charts are added to it.
+//
 // Returns a string path to the location where the file was downloaded and a verification
 // (if provenance was verified), or an error if something bad happened.
 func (c *ChartDownloader) DownloadTo(ref, version, dest string) (string, *provenance.Verification, error) {
-	u, err := c.ResolveChartVersion(ref, version)
+	resolved, rc, dgst, err := c.resolveChartVersion(ref, version)
 	if err != nil {
 		return "", nil, err
 	}
+	source := *resolved
+	source.User = nil
+	c.ResolvedURL = source.String()
 
-	g, err := c.Getters.ByScheme(u.Scheme)
+	g, err := c.Getters.ByScheme(resolved.Scheme)
 	if err != nil {
 		return "", nil, err
 	}
 
-	data, err := g.Get(u.String(), c.Options...)
-	if err != nil {
-		return "", nil, err
+	// An archive listed in a repository index is only cached, and taken from
+	// the cache, if it has the digest listed in the index. A chart in an OCI
+	// registry is pulled by the digest of its manifest, which the registry
+	// client checks.
+	archiveDigest := dgst != "" && resolved.Scheme != registry.OCIScheme
+	fetch := resolved
+	if dgst != "" && resolved.Scheme == registry.OCIScheme {
+		fetch = pinOCIDigest(resolved, dgst)
+	}
+
+	u := resolved
+	cache := c.cache()
+	data, name, ok := fromCache(cache, dgst, archiveDigest)
+	if !ok && c.Offline {
+		return "", nil, errors.Errorf("chart %s is not in the chart cache, it cannot be installed offline", ref)
 	}
 
-	name := filepath.Base(u.Path)
-	if u.Scheme == registry.OCIScheme {
-		idx := strings.LastIndexByte(name, ':')
-		name = fmt.Sprintf("%s-%s.tgz", name[:idx], name[idx+1:])
+	if !ok {
+		// The getter only sends the credentials of the repository to the
+		// host of its URL, and to mirrors if PassCredentialsAll is set.
+		chosen, err := repo.TryMirrors(c.chartURLs(fetch, rc), func(u string) error {
+			data, err = g.Get(u, c.Options...)
+			return err
+		})
+		if err != nil {
+			return "", nil, err
+		}
+		if u, err = url.Parse(chosen); err != nil {
+			return "", nil, err
+		}
+
+		name = filepath.Base(u.Path)
+		if u.Scheme == registry.OCIScheme {
+			name, err = ociArchiveName(name, data)
+			if err != nil {
+				return "", nil, err
+			}
+		}
+
+		if cache != nil && dgst != "" {
+			if archiveDigest {
+				err = checkArchiveDigest(dgst, data.Bytes())
+			}
+			if err == nil {
+				_, err = cache.Put(dgst, name, data.Bytes())
+			}
+			if err != nil {
+				fmt.Fprintf(c.Out, "WARNING: failed to add %s to the chart cache: %s\n", ref, err)
+			}
+		}
 	}
 
 	destfile := filepath.Join(dest, name)
@@ -113,65 +185,311 @@ func (c *ChartDownloader) DownloadTo(ref, version, dest string) (string, *proven
 		return destfile, nil, err
 	}
 
+	policy, err := LoadTrustPolicy(c.TrustPolicyConfig)
+	if err != nil {
+		return destfile, nil, err
+	}
+	rule := policy.Match(c.chartSources(ref, resolved, u)...)
+
+	strategy := c.Verify
+	if rule != nil {
+		switch {
+		case rule.Mode == TrustRequire:
+			strategy = VerifyAlways
+		case rule.Mode == TrustWarn && strategy != VerifyAlways:
+			strategy = VerifyIfPossible
+		}
+	}
+
+	ver, err := c.verify(ref, u, g, destfile, dgst, strategy, rule)
+	if err != nil && rule != nil && rule.Mode == TrustWarn && c.Verify != VerifyAlways {
+		fmt.Fprintf(c.Out, "WARNING: %s does not satisfy the trust policy for %s: %s\n", ref, rule.Source, err)
+		return destfile, &provenance.Verification{}, nil
+	}
+	return destfile, ver, err
+}
+
+// verify checks the provenance of a downloaded chart with the given strategy.
+// When a trust policy rule applies, its keyring and signers are used.
+func (c *ChartDownloader) verify(ref string, u *url.URL, g getter.Getter, destfile, dgst string, strategy VerificationStrategy, rule *TrustRule) (*provenance.Verification, error) {
 	// If provenance is requested, verify it.
 	ver := &provenance.Verification{}
-	if c.Verify > VerifyNever {
-		body, err := g.Get(u.String() + ".prov")
+	if strategy == VerifyNever {
+		return ver, nil
+	}
+	keyring := rule.keyring(c.Keyring)
+
+	// Charts in OCI registries matched by a rule are verified through the
+	// signatures attached to their manifest.
+	if rule != nil && u.Scheme == registry.OCIScheme && c.RegistryClient != nil && strategy != VerifyLater {
+		ver, err := VerifyOCIChart(c.RegistryClient, u.String(), keyring)
 		if err != nil {
-			if c.Verify == VerifyAlways {
-				return destfile, ver, errors.Errorf("failed to fetch provenance %q", u.String()+".prov")
-			}
-			fmt.Fprintf(c.Out, "WARNING: Verification not found for %s: %s\n", ref, err)
-			return destfile, ver, nil
+			return ver, err
 		}
-		provfile := destfile + ".prov"
-		if err := fileutil.AtomicWriteFile(provfile, body, 0644); err != nil {
-			return destfile, nil, err
+		return ver, rule.CheckSigner(ref, ver)
+	}
+
+	body, err := c.provenance(u, g, destfile, dgst)
+	if err != nil {
+		if strategy == VerifyAlways {
+			return ver, errors.Errorf("failed to fetch provenance %q", u.String()+".prov")
 		}
+		fmt.Fprintf(c.Out, "WARNING: Verification not found for %s: %s\n", ref, err)
+		return ver, nil
+	}
+	provfile := destfile + ".prov"
+	if err := fileutil.AtomicWriteFile(provfile, body, 0644); err != nil {
+		return nil, err
+	}
 
-		if c.Verify != VerifyLater {
-			ver, err = VerifyChart(destfile, c.Keyring)
-			if err != nil {
-				// Fail always in this case, since it means the verification step
-				// failed.
-				return destfile, ver, err
-			}
+	if strategy != VerifyLater {
+		ver, err = VerifyChart(destfile, keyring)
+		if err != nil {
+			// Fail always in this case, since it means the verification step
+			// failed.
+			return ver, err
+		}
+		return ver, rule.CheckSigner(ref, ver)
+	}
+	return ver, nil
+}
+
+// provenance returns the provenance file of a chart, from the chart cache if
+// the chart is cached with it. Downloaded provenance files are cached next to
+// their chart.
+func (c *ChartDownloader) provenance(u *url.URL, g getter.Getter, destfile, dgst string) (*bytes.Buffer, error) {
+	cache := c.cache()
+	if cache == nil || dgst == "" {
+		return g.Get(u.String() + ".prov")
+	}
+	name := filepath.Base(destfile) + ".prov"
+	cached, ok := cache.Get(dgst)
+	if ok {
+		if b, err := os.ReadFile(filepath.Join(filepath.Dir(cached), name)); err == nil {
+			return bytes.NewBuffer(b), nil
 		}
 	}
-	return destfile, ver, nil
+	if c.Offline {
+		return nil, errors.Errorf("provenance of %s is not in the chart cache", u)
+	}
+	body, err := g.Get(u.String() + ".prov")
+	if err != nil {
+		return nil, err
+	}
+	// The provenance file is only cached next to its chart.
+	if ok {
+		if _, err := cache.Put(dgst, name, body.Bytes()); err != nil {
+			fmt.Fprintf(c.Out, "WARNING: failed to add %s to the chart cache: %s\n", name, err)
+		}
+	}
+	return body, nil
+}
+
+// fromCache returns a chart archive and its file name from the chart cache.
+// When archiveDigest is set, the archive is only returned if its content has
+// the digest it is cached by.
+func fromCache(cache *ChartCache, dgst string, archiveDigest bool) (*bytes.Buffer, string, bool) {
+	if cache == nil || dgst == "" {
+		return nil, "", false
+	}
+	cached, ok := cache.Get(dgst)
+	if !ok {
+		return nil, "", false
+	}
+	b, err := os.ReadFile(cached)
+	if err != nil {
+		return nil, "", false
+	}
+	if archiveDigest && checkArchiveDigest(dgst, b) != nil {
+		return nil, "", false
+	}
+	return bytes.NewBuffer(b), filepath.Base(cached), true
+}
+
+// pinOCIDigest returns an OCI chart URL pinned to the digest of its manifest.
+func pinOCIDigest(u *url.URL, dgst string) *url.URL {
+	if _, d, err := registry.SplitDigest(u.String()); err == nil && d != "" {
+		return u
+	}
+	pinned := *u
+	pinned.Path = fmt.Sprintf("%s@%s", u.Path, dgst)
+	return &pinned
+}
+
+// cache returns the chart cache, or nil if charts are not cached.
+func (c *ChartDownloader) cache() *ChartCache {
+	if c.ChartCache == "" {
+		return nil
+	}
+	return NewChartCache(c.ChartCache)
+}
+
+// chartURLs returns the URLs a resolved chart can be downloaded from: its URL
+// and its location on each mirror of its repository, after the rewrite rules
+// of the repositories file are applied.
+func (c *ChartDownloader) chartURLs(u *url.URL, rc *repo.Entry) []string {
+	urls := []string{u.String()}
+	if rc != nil {
+		urls = rc.MirrorURLs(u.String())
+	}
+	rf, err := loadRepoConfig(c.RepositoryConfig)
+	if err != nil || rf == nil {
+		return urls
+	}
+	for i, u := range urls {
+		urls[i] = rf.Rewrites.Apply(u)
+	}
+	return urls
+}
+
+// chartSources returns the locations of a chart matched against the trust
+// policy: its URL, the URL it was downloaded from if it is a mirror, and the
+// URL of the repository it was named from.
+func (c *ChartDownloader) chartSources(ref string, resolved, u *url.URL) []string {
+	sources := []string{resolved.String()}
+	if u.String() != resolved.String() {
+		sources = append(sources, u.String())
+	}
+	if strings.Contains(ref, "://") {
+		return sources
+	}
+	name, _, ok := strings.Cut(ref, "/")
+	if !ok {
+		return sources
+	}
+	rf, err := repo.LoadFile(c.RepositoryConfig)
+	if err != nil {
+		return sources
+	}
+	if r := rf.Get(name); r != nil {
+		sources = append(sources, r.URL)
+	}
+	return sources
 }
 
 func (c *ChartDownloader) getOciURI(ref, version string, u *url.URL) (*url.URL, error) {
 	var tag string
-	var err error
 
-	// Evaluate whether an explicit version has been provided. Otherwise, determine version to use
-	_, errSemVer := semver.NewVersion(version)
-	if errSemVer == nil {
-		tag = version
-	} else {
-		// Retrieve list of repository tags
-		tags, err := c.RegistryClient.Tags(strings.TrimPrefix(ref, fmt.Sprintf("%s://", registry.OCIScheme)))
+	// A digest may be pinned on the reference (chart@sha256:...) or on the
+	// version (1.2.3@sha256:...). A tag given on the reference (chart:1.2.3,
+	// or chart:1.2.3@sha256:...) is used as is, and must match the version if
+	// both are given.
+	ref, digest, err := registry.SplitDigest(ref)
+	if err != nil {
+		return nil, err
+	}
+	if digest == "" {
+		version, digest, err = registry.SplitDigest(version)
 		if err != nil {
 			return nil, err
 		}
-		if len(tags) == 0 {
-			return nil, errors.Errorf("Unable to locate any tags in provided repository: %s", ref)
-		}
+	}
+	ref, tag = splitOCITag(ref)
+
+	switch {
+	case tag != "" && version != "" && version != tag:
+		return nil, errors.Errorf("chart reference %s:%s does not match version %s", ref, tag, version)
+	case tag != "":
+		// The reference pins the tag, and the digest if any
+	case digest != "":
+		// The digest identifies the manifest on its own, so the version is
+		// only carried along as the tag and is not resolved
+		tag = version
+	default:
+		// Evaluate whether an explicit version has been provided. Otherwise, determine version to use
+		_, errSemVer := semver.NewVersion(version)
+		if errSemVer == nil {
+			tag = version
+		} else {
+			// Retrieve list of repository tags
+			tags, err := c.RegistryClient.Tags(strings.TrimPrefix(ref, fmt.Sprintf("%s://", registry.OCIScheme)))
+			if err != nil {
+				return nil, err
+			}
+			if len(tags) == 0 {
+				return nil, errors.Errorf("Unable to locate any tags in provided repository: %s", ref)
+			}
 
-		// Determine if version provided
-		// If empty, try to get the highest available tag
-		// If exact version, try to find it
-		// If semver constraint string, try to find a match
-		tag, err = registry.GetTagMatchingVersionOrConstraint(tags, version)
-		if err != nil {
-			return nil, err
+			// Determine if version provided
+			// If empty, try to get the highest available tag
+			// If exact version, try to find it
+			// If semver constraint string, try to find a match
+			tag, err = registry.GetTagMatchingVersionOrConstraint(tags, version)
+			if err != nil {
+				return nil, err
+			}
 		}
 	}
 
-	u.Path = fmt.Sprintf("%s:%s", u.Path, tag)
+	u, err = url.Parse(ref)
+	if err != nil {
+		return nil, errors.Errorf("invalid chart URL format: %s", ref)
+	}
+	if tag != "" {
+		u.Path = fmt.Sprintf("%s:%s", u.Path, tag)
+	}
+	if digest != "" {
+		u.Path = fmt.Sprintf("%s@%s", u.Path, digest)
+	}
+
+	return u, nil
+}
 
-	return u, err
+// ociDigest returns the digest of the manifest an OCI chart URL points to, to
+// look the chart up in the chart cache. The registry is only queried when the
+// URL is not pinned to a digest and charts are cached.
+func (c *ChartDownloader) ociDigest(u *url.URL) (string, error) {
+	if _, dgst, err := registry.SplitDigest(u.String()); err != nil || dgst != "" {
+		return dgst, err
+	}
+	if c.ChartCache == "" {
+		return "", nil
+	}
+	if c.Offline {
+		return "", errors.Errorf("chart %s is not pinned to a digest, it cannot be installed offline", u)
+	}
+	if c.RegistryClient == nil {
+		return "", nil
+	}
+	return c.RegistryClient.Resolve(strings.TrimPrefix(u.String(), fmt.Sprintf("%s://", registry.OCIScheme)))
+}
+
+// ociPinned reports whether an OCI chart reference or its version is pinned to
+// a manifest digest.
+func ociPinned(ref, version string) bool {
+	if _, dgst, err := registry.SplitDigest(ref); err == nil && dgst != "" {
+		return true
+	}
+	_, dgst, err := registry.SplitDigest(version)
+	return err == nil && dgst != ""
+}
+
+// splitOCITag separates the tag, if any, from the last path element of an OCI
+// reference. A colon before the last slash belongs to the registry port.
+func splitOCITag(ref string) (string, string) {
+	idx := strings.LastIndexByte(ref, ':')
+	if idx < 0 || strings.Contains(ref[idx:], "/") {
+		return ref, ""
+	}
+	return ref[:idx], ref[idx+1:]
+}
+
+// ociArchiveName builds the archive file name for a chart pulled from an OCI
+// reference. When the reference is pinned only by digest, the version is read
+// from the chart itself.
+func ociArchiveName(name string, data *bytes.Buffer) (string, error) {
+	name, _, err := registry.SplitDigest(name)
+	if err != nil {
+		return "", err
+	}
+	if name, tag := splitOCITag(name); tag != "" {
+		return fmt.Sprintf("%s-%s.tgz", name, tag), nil
+	}
+	ch, err := loader.LoadArchive(bytes.NewReader(data.Bytes()))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s-%s.tgz", name, ch.Metadata.Version), nil
 }
 
 // ResolveChartVersion resolves a chart reference to a URL.
@@ -190,18 +508,35 @@ func (c *ChartDownloader) getOciURI(ref, version string, u *url.URL) (*url.URL,
 //   - If version is empty, this will return the URL for the latest version
 //   - If no version can be found, an error is returned
 func (c *ChartDownloader) ResolveChartVersion(ref, version string) (*url.URL, error) {
+	u, _, _, err := c.resolveChartVersion(ref, version)
+	return u, err
+}
+
+// resolveChartVersion resolves a chart reference like ResolveChartVersion,
+// and also returns the entry of the repository the chart is in, if any, and
+// the digest the chart is cached by, if known.
+func (c *ChartDownloader) resolveChartVersion(ref, version string) (*url.URL, *repo.Entry, string, error) {
 	u, err := url.Parse(ref)
 	if err != nil {
-		return nil, errors.Errorf("invalid chart URL format: %s", ref)
+		return nil, nil, "", errors.Errorf("invalid chart URL format: %s", ref)
 	}
 
 	if registry.IsOCI(u.String()) {
-		return c.getOciURI(ref, version, u)
+		// Resolving a tag or a version constraint needs the registry.
+		if c.Offline && !ociPinned(ref, version) {
+			return u, nil, "", errors.Errorf("chart %s is not pinned to a digest, it cannot be installed offline", ref)
+		}
+		u, err := c.getOciURI(ref, version, u)
+		if err != nil {
+			return u, nil, "", err
+		}
+		dgst, err := c.ociDigest(u)
+		return u, nil, dgst, err
 	}
 
 	rf, err := loadRepoConfig(c.RepositoryConfig)
 	if err != nil {
-		return u, err
+		return u, nil, "", err
 	}
 
 	if u.IsAbs() && len(u.Host) > 0 && len(u.Path) > 0 {
@@ -211,16 +546,16 @@ func (c *ChartDownloader) ResolveChartVersion(ref, version string) (*url.URL, er
 		// we want to find the repo in case we have special SSL cert config
 		// for that repo.
 
-		rc, err := c.scanReposForURL(ref, rf)
+		rc, dgst, err := c.scanReposForURL(ref, rf)
 		if err != nil {
 			// If there is no special config, return the default HTTP client and
 			// swallow the error.
 			if err == ErrNoOwnerRepo {
 				// Make sure to add the ref URL as the URL for the getter
 				c.Options = append(c.Options, getter.WithURL(ref))
-				return u, nil
+				return u, nil, "", nil
 			}
-			return u, err
+			return u, nil, "", err
 		}
 
 		// If we get here, we don't need to go through the next phase of looking
@@ -239,13 +574,17 @@ func (c *ChartDownloader) ResolveChartVersion(ref, version string) (*url.URL, er
 				getter.WithPassCredentialsAll(rc.PassCredentialsAll),
 			)
 		}
-		return u, nil
+		if f := rc.Credentials(c.RegistryConfig); f != nil {
+			c.Options = append(c.Options, getter.WithCredentials(f), getter.WithPassCredentialsAll(rc.PassCredentialsAll))
+		}
+		c.Options = append(c.Options, rc.AuthOptions()...)
+		return u, rc, dgst, nil
 	}
 
 	// See if it's of the form: repo/path_to_chart
 	p := strings.SplitN(u.Path, "/", 2)
 	if len(p) < 2 {
-		return u, errors.Errorf("non-absolute URLs should be in form of repo_name/path_to_chart, got: %s", u)
+		return u, nil, "", errors.Errorf("non-absolute URLs should be in form of repo_name/path_to_chart, got: %s", u)
 	}
 
 	repoName := p[0]
@@ -253,7 +592,7 @@ func (c *ChartDownloader) ResolveChartVersion(ref, version string) (*url.URL, er
 	rc, err := pickChartRepositoryConfigByName(repoName, rf.Repositories)
 
 	if err != nil {
-		return u, err
+		return u, nil, "", err
 	}
 
 	// Now that we have the chart repository information we can use that URL
@@ -262,7 +601,7 @@ func (c *ChartDownloader) ResolveChartVersion(ref, version string) (*url.URL, er
 
 	r, err := repo.NewChartRepository(rc, c.Getters)
 	if err != nil {
-		return u, err
+		return u, nil, "", err
 	}
 
 	if r != nil && r.Config != nil {
@@ -275,32 +614,37 @@ func (c *ChartDownloader) ResolveChartVersion(ref, version string) (*url.URL, er
 				getter.WithPassCredentialsAll(r.Config.PassCredentialsAll),
 			)
 		}
+		if f := r.Config.Credentials(c.RegistryConfig); f != nil {
+			c.Options = append(c.Options, getter.WithCredentials(f), getter.WithPassCredentialsAll(r.Config.PassCredentialsAll))
+		}
+		c.Options = append(c.Options, r.Config.AuthOptions()...)
 	}
 
 	// Next, we need to load the index, and actually look up the chart.
 	idxFile := filepath.Join(c.RepositoryCache, helmpath.CacheIndexFile(r.Config.Name))
 	i, err := repo.LoadIndexFile(idxFile)
 	if err != nil {
-		return u, errors.Wrap(err, "no cached repo found. (try 'helm repo update')")
+		return u, nil, "", errors.Wrap(err, "no cached repo found. (try 'helm repo update')")
 	}
 
 	cv, err := i.Get(chartName, version)
 	if err != nil {
-		return u, errors.Wrapf(err, "chart %q matching %s not found in %s index. (try 'helm repo update')", chartName, version, r.Config.Name)
+		return u, nil, "", errors.Wrapf(err, "chart %q matching %s not found in %s index. (try 'helm repo update')", chartName, version, r.Config.Name)
 	}
 
 	if len(cv.URLs) == 0 {
-		return u, errors.Errorf("chart %q has no downloadable URLs", ref)
+		return u, nil, "", errors.Errorf("chart %q has no downloadable URLs", ref)
 	}
 
 	// TODO: Seems that picking first URL is not fully correct
 	resolvedURL, err := repo.ResolveReferenceURL(rc.URL, cv.URLs[0])
 
 	if err != nil {
-		return u, errors.Errorf("invalid chart URL format: %s", ref)
+		return u, nil, "", errors.Errorf("invalid chart URL format: %s", ref)
 	}
 
-	return url.Parse(resolvedURL)
+	u, err = url.Parse(resolvedURL)
+	return u, rc, cv.Digest, err
 }
 
 // VerifyChart takes a path to a chart archive and a keyring, and verifies the chart.
@@ -323,13 +667,64 @@ func VerifyChart(path, keyring string) (*provenance.Verification, error) {
 		return nil, errors.Wrapf(err, "could not load provenance file %s", provfile)
 	}
 
-	sig, err := provenance.NewFromKeyring(keyring, "")
+	sig, err := provenance.NewFromProvenance(keyring, provfile)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to load keyring")
 	}
 	return sig.Verify(path, provfile)
 }
 
+// VerifyOCIChart verifies the signatures attached to a chart in an OCI registry.
+//
+// The chart is verified when at least one of the signatures attached to its
+// manifest was made by a key in the keyring. The returned verification holds
+// the digest of the chart manifest as its hash.
+func VerifyOCIChart(client *registry.Client, ref, keyring string) (*provenance.Verification, error) {
+	ref = strings.TrimPrefix(ref, fmt.Sprintf("%s://", registry.OCIScheme))
+	sig, err := provenance.NewFromKeyring(keyring, "")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load keyring")
+	}
+
+	dgst, err := client.Resolve(ref)
+	if err != nil {
+		return nil, err
+	}
+	pinned := ref
+	if name, _, err := registry.SplitDigest(ref); err == nil {
+		pinned = name + "@" + dgst
+	}
+	signatures, err := client.Signatures(pinned)
+	if err != nil {
+		return nil, err
+	}
+	if len(signatures) == 0 {
+		return nil, errors.Errorf("no signatures found for %s", ref)
+	}
+
+	var lastErr error
+	for _, s := range signatures {
+		if s.MediaType != registry.SignatureLayerMediaType {
+			continue
+		}
+		by, err := sig.VerifyDetached(s.Payload, s.Data)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return &provenance.Verification{
+			SignedBy: by,
+			Identity: provenance.PGPIdentity(by),
+			FileHash: dgst,
+			FileName: ref,
+		}, nil
+	}
+	if lastErr != nil {
+		return nil, errors.Wrapf(lastErr, "no signature of %s could be verified", ref)
+	}
+	return nil, errors.Errorf("no supported signatures found for %s", ref)
+}
+
 // isTar tests whether the given file is a tar file.
 //
 // Currently, this simply checks extension, since a subsequent function will
@@ -354,7 +749,8 @@ func pickChartRepositoryConfigByName(name string, cfgs []*repo.Entry) (*repo.Ent
 //
 // This will attempt to find the given URL in all of the known repositories files.
 //
-// If the URL is found, this will return the repo entry that contained that URL.
+// If the URL is found, this will return the repo entry that contained that URL,
+// and the digest of the chart listed in the index.
 //
 // If all of the repos are checked, but the URL is not found, an ErrNoOwnerRepo
 // error is returned.
@@ -368,33 +764,33 @@ func pickChartRepositoryConfigByName(name string, cfgs []*repo.Entry) (*repo.Ent
 // The same URL can technically exist in two or more repositories. This algorithm
 // will return the first one it finds. Order is determined by the order of repositories
 // in the repositories.yaml file.
-func (c *ChartDownloader) scanReposForURL(u string, rf *repo.File) (*repo.Entry, error) {
+func (c *ChartDownloader) scanReposForURL(u string, rf *repo.File) (*repo.Entry, string, error) {
 	// FIXME: This is far from optimal. Larger installations and index files will
 	// incur a performance hit for this type of scanning.
 	for _, rc := range rf.Repositories {
 		r, err := repo.NewChartRepository(rc, c.Getters)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 
 		idxFile := filepath.Join(c.RepositoryCache, helmpath.CacheIndexFile(r.Config.Name))
 		i, err := repo.LoadIndexFile(idxFile)
 		if err != nil {
-			return nil, errors.Wrap(err, "no cached repo found. (try 'helm repo update')")
+			return nil, "", errors.Wrap(err, "no cached repo found. (try 'helm repo update')")
 		}
 
 		for _, entry := range i.Entries {
 			for _, ver := range entry {
 				for _, dl := range ver.URLs {
 					if urlutil.Equal(u, dl) {
-						return rc, nil
+						return rc, ver.Digest, nil
 					}
 				}
 			}
 		}
 	}
 	// This means that there is no repo file for the given URL.
-	return nil, ErrNoOwnerRepo
+	return nil, "", ErrNoOwnerRepo
 }
 
 func loadRepoConfig(file string) (*repo.File, error) {
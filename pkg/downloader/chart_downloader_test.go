@@ -18,11 +18,14 @@ package downloader
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
+	"time"
 
 	"helm.sh/helm/v3/internal/test/ensure"
 	"helm.sh/helm/v3/pkg/cli"
 	"helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/helmpath"
 	"helm.sh/helm/v3/pkg/repo"
 	"helm.sh/helm/v3/pkg/repo/repotest"
 )
@@ -32,6 +35,8 @@ const (
 	repoCache  = "testdata/repository"
 )
 
+const testDigest = "sha256:e5ef611620fb97704d8751c16bab17fedb68883bfb0edc76f78a70e9173f9b55"
+
 func TestResolveChartRef(t *testing.T) {
 	tests := []struct {
 		name, ref, expect, version string
@@ -53,6 +58,16 @@ func TestResolveChartRef(t *testing.T) {
 		{name: "full URL, file", ref: "file:///foo-1.2.3.tgz", fail: true},
 		{name: "invalid", ref: "invalid-1.2.3", fail: true},
 		{name: "not found", ref: "nosuchthing/invalid-1.2.3", fail: true},
+		{name: "oci, version", ref: "oci://example.com:5000/charts/foo", version: "1.2.3", expect: "oci://example.com:5000/charts/foo:1.2.3"},
+		{name: "oci, tag", ref: "oci://example.com:5000/charts/foo:1.2.3", expect: "oci://example.com:5000/charts/foo:1.2.3"},
+		{name: "oci, tag and same version", ref: "oci://example.com/charts/foo:1.2.3", version: "1.2.3", expect: "oci://example.com/charts/foo:1.2.3"},
+		{name: "oci, tag and other version", ref: "oci://example.com/charts/foo:1.2.3", version: "1.2.4", fail: true},
+		{name: "oci, tag, digest and other version", ref: "oci://example.com/charts/foo:1.2.3@" + testDigest, version: "1.2.4", fail: true},
+		{name: "oci, digest", ref: "oci://example.com/charts/foo@" + testDigest, expect: "oci://example.com/charts/foo@" + testDigest},
+		{name: "oci, digest and version", ref: "oci://example.com/charts/foo@" + testDigest, version: "1.2.3", expect: "oci://example.com/charts/foo:1.2.3@" + testDigest},
+		{name: "oci, tag and digest", ref: "oci://example.com/charts/foo:1.2.3@" + testDigest, expect: "oci://example.com/charts/foo:1.2.3@" + testDigest},
+		{name: "oci, digest on version", ref: "oci://example.com/charts/foo", version: "1.2.3@" + testDigest, expect: "oci://example.com/charts/foo:1.2.3@" + testDigest},
+		{name: "oci, malformed digest", ref: "oci://example.com/charts/foo@sha256:1234", fail: true},
 	}
 
 	c := ChartDownloader{
@@ -135,7 +150,7 @@ func TestResolveChartOpts(t *testing.T) {
 			continue
 		}
 
-		if *(got.(*getter.HTTPGetter)) != *(expect.(*getter.HTTPGetter)) {
+		if !reflect.DeepEqual(got, expect) {
 			t.Errorf("%s: expected %s, got %s", tt.name, expect, got)
 		}
 	}
@@ -311,6 +326,78 @@ func TestDownloadTo_VerifyLater(t *testing.T) {
 	}
 }
 
+func TestDownloadTo_Mirrors(t *testing.T) {
+	srv, err := repotest.NewTempServerWithCleanup(t, "testdata/*.tgz*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Stop()
+
+	tests := []struct {
+		name  string
+		entry *repo.Entry
+		rules repo.Rewrites
+	}{
+		{
+			name:  "falls back to a mirror when the repository is down",
+			entry: &repo.Entry{Name: "mirrored", URL: "http://127.0.0.1:1/charts", Mirrors: []string{srv.URL()}},
+		},
+		{
+			name:  "rewrites the repository URL",
+			entry: &repo.Entry{Name: "rewritten", URL: "https://charts.example.invalid/charts"},
+			rules: repo.Rewrites{{From: "https://charts.example.invalid/charts/*", To: srv.URL() + "/*"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			cache := filepath.Join(dir, "repository")
+			if err := os.MkdirAll(cache, 0755); err != nil {
+				t.Fatal(err)
+			}
+
+			// The cached index points at the repository URL.
+			index, err := repo.IndexDirectory(srv.Root(), tt.entry.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := index.WriteFile(filepath.Join(cache, helmpath.CacheIndexFile(tt.entry.Name)), 0644); err != nil {
+				t.Fatal(err)
+			}
+			rf := repo.NewFile()
+			rf.Add(tt.entry)
+			rf.Rewrites = tt.rules
+			config := filepath.Join(dir, "repositories.yaml")
+			if err := rf.WriteFile(config, 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			c := ChartDownloader{
+				Out:              os.Stderr,
+				Verify:           VerifyAlways,
+				Keyring:          "testdata/helm-test-key.pub",
+				RepositoryConfig: config,
+				RepositoryCache:  cache,
+				Getters: getter.All(&cli.EnvSettings{
+					RepositoryConfig: config,
+					RepositoryCache:  cache,
+				}),
+			}
+			where, v, err := c.DownloadTo(tt.entry.Name+"/signtest", "0.1.0", dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if expect := filepath.Join(dir, "signtest-0.1.0.tgz"); where != expect {
+				t.Errorf("Expected download to %s, got %s", expect, where)
+			}
+			if v.FileHash == "" {
+				t.Error("File hash was empty, but verification is required.")
+			}
+		})
+	}
+}
+
 func TestScanReposForURL(t *testing.T) {
 	c := ChartDownloader{
 		Out:              os.Stderr,
@@ -329,7 +416,7 @@ func TestScanReposForURL(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	entry, err := c.scanReposForURL(u, rf)
+	entry, dgst, err := c.scanReposForURL(u, rf)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -337,10 +424,147 @@ func TestScanReposForURL(t *testing.T) {
 	if entry.Name != "testing" {
 		t.Errorf("Unexpected repo %q for URL %q", entry.Name, u)
 	}
+	if dgst != "515c58e5f79d8b2913a10cb400ebb6fa9c77fe813287afbacf1a0b897cd78727" {
+		t.Errorf("Unexpected digest %q for URL %q", dgst, u)
+	}
 
 	// A lookup failure should produce an ErrNoOwnerRepo
 	u = "https://no.such.repo/foo/bar-1.23.4.tgz"
-	if _, err = c.scanReposForURL(u, rf); err != ErrNoOwnerRepo {
+	if _, _, err = c.scanReposForURL(u, rf); err != ErrNoOwnerRepo {
 		t.Fatalf("expected ErrNoOwnerRepo, got %v", err)
 	}
 }
+
+func TestDownloadTo_ChartCache(t *testing.T) {
+	srv, err := repotest.NewTempServerWithCleanup(t, "testdata/*.tgz*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Stop()
+	if err := srv.CreateIndex(); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.LinkIndices(); err != nil {
+		t.Fatal(err)
+	}
+
+	repoConfig := filepath.Join(srv.Root(), "repositories.yaml")
+	repoCache := srv.Root()
+
+	c := ChartDownloader{
+		Out:              os.Stderr,
+		Verify:           VerifyAlways,
+		Keyring:          "testdata/helm-test-key.pub",
+		RepositoryConfig: repoConfig,
+		RepositoryCache:  repoCache,
+		ChartCache:       t.TempDir(),
+		Getters: getter.All(&cli.EnvSettings{
+			RepositoryConfig: repoConfig,
+			RepositoryCache:  repoCache,
+		}),
+	}
+	if _, _, err := c.DownloadTo("test/signtest", "", t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	if expect := srv.URL() + "/signtest-0.1.0.tgz"; c.ResolvedURL != expect {
+		t.Errorf("Expected the chart to be resolved to %s, got %s", expect, c.ResolvedURL)
+	}
+
+	entries, err := NewChartCache(c.ChartCache).List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name != "signtest-0.1.0.tgz" {
+		t.Fatalf("expected signtest-0.1.0.tgz to be cached, got %v", entries)
+	}
+
+	// The chart and its provenance file are taken from the cache once the
+	// repository is gone.
+	srv.Stop()
+	c.Offline = true
+	dest := t.TempDir()
+	where, v, err := c.DownloadTo("test/signtest", "", dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := filepath.Join(dest, "signtest-0.1.0.tgz"); where != expect {
+		t.Errorf("Expected download to %s, got %s", expect, where)
+	}
+	if v.FileHash == "" {
+		t.Error("File hash was empty, but verification is required.")
+	}
+
+	if _, _, err := c.DownloadTo("test/foo", "", t.TempDir()); err == nil {
+		t.Error("expected an error for a chart that is not cached")
+	}
+
+	// An archive that no longer has the digest listed in the index is not
+	// taken from the cache.
+	if err := os.WriteFile(entries[0].Path, []byte("tampered"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := c.DownloadTo("test/signtest", "", t.TempDir()); err == nil {
+		t.Error("expected an error for a cached chart that does not match its digest")
+	}
+}
+
+func TestDownloadTo_OAuth2(t *testing.T) {
+	srv, err := repotest.NewTempServerWithCleanup(t, "testdata/*.tgz*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Stop()
+	if err := srv.CreateIndex(); err != nil {
+		t.Fatal(err)
+	}
+	tokenURL := srv.WithOAuth2TokenEndpoint("helm", "secret", "token", time.Hour)
+	srv.RequireHeader("Authorization", "Bearer token")
+	srv.RequireHeader("X-Api-Key", "key")
+
+	repoConfig := filepath.Join(srv.Root(), "repositories.yaml")
+	repoCache := t.TempDir()
+	entry := &repo.Entry{
+		Name:    "test",
+		URL:     srv.URL(),
+		Headers: map[string]string{"X-Api-Key": "key"},
+		OAuth2: &repo.OAuth2Config{
+			TokenURL:     tokenURL,
+			ClientID:     "helm",
+			ClientSecret: "secret",
+		},
+	}
+	rf := repo.NewFile()
+	rf.Add(entry)
+	if err := rf.WriteFile(repoConfig, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	getters := getter.All(&cli.EnvSettings{
+		RepositoryConfig: repoConfig,
+		RepositoryCache:  repoCache,
+	})
+	r, err := repo.NewChartRepository(entry, getters)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.CachePath = repoCache
+	if _, err := r.DownloadIndexFile(); err != nil {
+		t.Fatal(err)
+	}
+
+	c := ChartDownloader{
+		Out:              os.Stderr,
+		Verify:           VerifyAlways,
+		Keyring:          "testdata/helm-test-key.pub",
+		RepositoryConfig: repoConfig,
+		RepositoryCache:  repoCache,
+		Getters:          getters,
+	}
+	if _, _, err := c.DownloadTo("test/signtest", "", t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+
+	if issued := srv.OAuth2TokensIssued(); issued != 1 {
+		t.Errorf("expected the token to be issued once and reused, got %d tokens", issued)
+	}
+}
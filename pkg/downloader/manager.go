@@ -75,6 +75,17 @@ type Manager struct {
 	RegistryClient   *registry.Client
 	RepositoryConfig string
 	RepositoryCache  string
+	// TrustPolicyConfig is the path of the trust policy applied to downloaded dependencies.
+	TrustPolicyConfig string
+	// RegistryConfig is the registry credentials file, used by repositories
+	// that reference registry credentials.
+	RegistryConfig string
+	// ChartCache is the directory of the content-addressable chart cache
+	// dependencies are taken from and added to.
+	ChartCache string
+	// Offline only takes dependencies from the chart cache. It implies
+	// SkipUpdate.
+	Offline bool
 }
 
 // Build rebuilds a local charts directory from a lockfile.
@@ -133,7 +144,7 @@ func (m *Manager) Build() error {
 		return err
 	}
 
-	if !m.SkipUpdate {
+	if !m.SkipUpdate && !m.Offline {
 		// For each repo in the file, update the cached copy of that repo
 		if err := m.UpdateRepositories(); err != nil {
 			return err
@@ -183,7 +194,7 @@ func (m *Manager) Update() error {
 
 	// For each of the repositories Helm is configured to know about, update
 	// the index information locally.
-	if !m.SkipUpdate {
+	if !m.SkipUpdate && !m.Offline {
 		if err := m.UpdateRepositories(); err != nil {
 			return err
 		}
@@ -326,13 +337,17 @@ func (m *Manager) downloadAll(deps []*chart.Dependency) error {
 		fmt.Fprintf(m.Out, "Downloading %s from repo %s\n", dep.Name, dep.Repository)
 
 		dl := ChartDownloader{
-			Out:              m.Out,
-			Verify:           m.Verify,
-			Keyring:          m.Keyring,
-			RepositoryConfig: m.RepositoryConfig,
-			RepositoryCache:  m.RepositoryCache,
-			RegistryClient:   m.RegistryClient,
-			Getters:          m.Getters,
+			Out:               m.Out,
+			Verify:            m.Verify,
+			Keyring:           m.Keyring,
+			RepositoryConfig:  m.RepositoryConfig,
+			RepositoryCache:   m.RepositoryCache,
+			TrustPolicyConfig: m.TrustPolicyConfig,
+			RegistryConfig:    m.RegistryConfig,
+			ChartCache:        m.ChartCache,
+			Offline:           m.Offline,
+			RegistryClient:    m.RegistryClient,
+			Getters:           m.Getters,
 			Options: []getter.Option{
 				getter.WithBasicAuth(username, password),
 				getter.WithPassCredentialsAll(passcredentialsall),
@@ -374,6 +389,11 @@ func (m *Manager) downloadAll(deps []*chart.Dependency) error {
 }
 
 func parseOCIRef(chartRef string) (string, string, error) {
+	// A pinned digest is set aside so its colon is not mistaken for the tag
+	chartRef, digest, err := registry.SplitDigest(chartRef)
+	if err != nil {
+		return "", "", err
+	}
 	refTagRegexp := regexp.MustCompile(`^(oci://[^:]+(:[0-9]{1,5})?[^:]+):(.*)$`)
 	caps := refTagRegexp.FindStringSubmatch(chartRef)
 	if len(caps) != 4 {
@@ -381,6 +401,9 @@ func parseOCIRef(chartRef string) (string, string, error) {
 	}
 	chartRef = caps[1]
 	tag := caps[3]
+	if digest != "" {
+		tag = fmt.Sprintf("%s@%s", tag, digest)
+	}
 
 	return chartRef, tag, nil
 }
@@ -479,8 +502,9 @@ Loop:
 		if dd.Repository == "" {
 			continue
 		}
+		rewritten := rf.Rewrites.Apply(dd.Repository)
 		for _, repo := range repos {
-			if urlutil.Equal(repo.URL, strings.TrimSuffix(dd.Repository, "/")) {
+			if urlutil.Equal(repo.URL, strings.TrimSuffix(dd.Repository, "/")) || urlutil.Equal(repo.URL, strings.TrimSuffix(rewritten, "/")) {
 				continue Loop
 			}
 		}
@@ -544,7 +568,7 @@ func (m *Manager) ensureMissingRepos(repoNames map[string]string, deps []*chart.
 	// repositories configured by the user. Here we update repos found in
 	// the dependencies that are not known to the user if update skipping
 	// is not configured.
-	if !m.SkipUpdate && len(ru) > 0 {
+	if !m.SkipUpdate && !m.Offline && len(ru) > 0 {
 		fmt.Fprintln(m.Out, "Getting updates for unmanaged Helm repositories...")
 		if err := m.parallelRepoUpdate(ru); err != nil {
 			return repoNames, err
@@ -603,7 +627,7 @@ func (m *Manager) resolveRepoNames(deps []*chart.Dependency) (map[string]string,
 				dd.Repository = repo.URL
 				reposMap[dd.Name] = repo.Name
 				break
-			} else if urlutil.Equal(repo.URL, dd.Repository) {
+			} else if urlutil.Equal(repo.URL, dd.Repository) || urlutil.Equal(repo.URL, rf.Rewrites.Apply(dd.Repository)) {
 				found = true
 				reposMap[dd.Name] = repo.Name
 				break
@@ -661,6 +685,7 @@ func (m *Manager) UpdateRepositories() error {
 
 func (m *Manager) parallelRepoUpdate(repos []*repo.Entry) error {
 
+	rewrites := m.rewrites()
 	var wg sync.WaitGroup
 	for _, c := range repos {
 		r, err := repo.NewChartRepository(c, m.Getters)
@@ -668,6 +693,8 @@ func (m *Manager) parallelRepoUpdate(repos []*repo.Entry) error {
 			return err
 		}
 		r.CachePath = m.RepositoryCache
+		r.Rewrites = rewrites
+		r.RegistryConfig = m.RegistryConfig
 		wg.Add(1)
 		go func(r *repo.ChartRepository) {
 			if _, err := r.DownloadIndexFile(); err != nil {
@@ -695,6 +722,15 @@ func (m *Manager) parallelRepoUpdate(repos []*repo.Entry) error {
 	return nil
 }
 
+// rewrites returns the rules rewriting the URLs of chart repositories.
+func (m *Manager) rewrites() repo.Rewrites {
+	rf, err := loadRepoConfig(m.RepositoryConfig)
+	if err != nil || rf == nil {
+		return nil
+	}
+	return rf.Rewrites
+}
+
 // findChartURL searches the cache of repo data for a chart that has the name and the repoURL specified.
 //
 // 'name' is the name of the chart. Version is an exact semver, or an empty string. If empty, the
@@ -708,9 +744,10 @@ func (m *Manager) findChartURL(name, version, repoURL string, repos map[string]*
 		return fmt.Sprintf("%s/%s:%s", repoURL, name, version), "", "", false, false, "", "", "", nil
 	}
 
+	rewritten := m.rewrites().Apply(repoURL)
 	for _, cr := range repos {
 
-		if urlutil.Equal(repoURL, cr.Config.URL) {
+		if urlutil.Equal(repoURL, cr.Config.URL) || urlutil.Equal(rewritten, cr.Config.URL) {
 			var entry repo.ChartVersions
 			entry, err = findEntryByName(name, cr)
 			if err != nil {
@@ -726,7 +763,7 @@ func (m *Manager) findChartURL(name, version, repoURL string, repos map[string]*
 				//nolint:nakedret
 				return
 			}
-			url, err = normalizeURL(repoURL, ve.URLs[0])
+			url, err = normalizeURL(cr.Config.URL, ve.URLs[0])
 			if err != nil {
 				//nolint:nakedret
 				return
@@ -742,7 +779,7 @@ func (m *Manager) findChartURL(name, version, repoURL string, repos map[string]*
 			return
 		}
 	}
-	url, err = repo.FindChartInRepoURL(repoURL, name, version, certFile, keyFile, caFile, m.Getters)
+	url, err = repo.FindChartInRepoURL(rewritten, name, version, certFile, keyFile, caFile, m.Getters)
 	if err == nil {
 		return url, username, password, false, false, "", "", "", err
 	}
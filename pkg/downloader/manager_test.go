@@ -131,6 +131,76 @@ func TestFindChartURL(t *testing.T) {
 	}
 }
 
+func TestFindChartURLWithRewrites(t *testing.T) {
+	data, err := os.ReadFile(repoConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data = append(data, []byte(`
+rewrites:
+  - from: https://charts.example.org/stable
+    to: http://example.com/charts
+`)...)
+	config := filepath.Join(t.TempDir(), "repositories.yaml")
+	if err := os.WriteFile(config, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var b bytes.Buffer
+	m := &Manager{
+		Out:              &b,
+		RepositoryConfig: config,
+		RepositoryCache:  repoCache,
+	}
+	repos, err := m.loadChartRepositories()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The repository of the dependency is rewritten to a configured repository.
+	deps := []*chart.Dependency{{Name: "alpine", Version: "0.1.0", Repository: "https://charts.example.org/stable"}}
+	if err := m.hasAllRepos(deps); err != nil {
+		t.Fatal(err)
+	}
+	names, err := m.resolveRepoNames(deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names["alpine"] != "kubernetes-charts" {
+		t.Errorf("Unexpected repository name %q", names["alpine"])
+	}
+
+	churl, _, _, _, _, _, _, _, err := m.findChartURL("alpine", "0.1.0", "https://charts.example.org/stable", repos)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if churl != "https://charts.helm.sh/stable/alpine-0.1.0.tgz" {
+		t.Errorf("Unexpected URL %q", churl)
+	}
+}
+
+func TestParseOCIRef(t *testing.T) {
+	tests := []struct {
+		ref, wantRef, wantVersion string
+	}{
+		{ref: "oci://example.com/charts/foo:1.2.3", wantRef: "oci://example.com/charts/foo", wantVersion: "1.2.3"},
+		{ref: "oci://example.com:5000/charts/foo:1.2.3", wantRef: "oci://example.com:5000/charts/foo", wantVersion: "1.2.3"},
+		{ref: "oci://example.com/charts/foo:1.2.3@" + testDigest, wantRef: "oci://example.com/charts/foo", wantVersion: "1.2.3@" + testDigest},
+		{ref: "oci://example.com/charts/foo:@" + testDigest, wantRef: "oci://example.com/charts/foo", wantVersion: "@" + testDigest},
+	}
+
+	for _, tt := range tests {
+		ref, version, err := parseOCIRef(tt.ref)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", tt.ref, err)
+			continue
+		}
+		if ref != tt.wantRef || version != tt.wantVersion {
+			t.Errorf("%s: expected (%q, %q), got (%q, %q)", tt.ref, tt.wantRef, tt.wantVersion, ref, version)
+		}
+	}
+}
+
 func TestGetRepoNames(t *testing.T) {
 	b := bytes.NewBuffer(nil)
 	m := &Manager{
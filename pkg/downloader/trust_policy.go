@@ -0,0 +1,187 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package downloader
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v3/pkg/provenance"
+)
+
+// TrustMode is the verification mode of a trust policy rule.
+type TrustMode string
+
+const (
+	// TrustRequire fails when a chart does not carry a valid signature from
+	// a trusted signer.
+	TrustRequire TrustMode = "require"
+	// TrustWarn verifies charts, but only prints a warning when they do not
+	// carry a valid signature from a trusted signer.
+	TrustWarn TrustMode = "warn"
+	// TrustSkip adds no requirement beyond the verification requested for
+	// the operation. It is used to exempt sources from a broader rule.
+	TrustSkip TrustMode = "skip"
+)
+
+// TrustPolicy maps chart sources to their signature requirements.
+//
+// A trust policy file looks like this:
+//
+//	policies:
+//	- source: https://charts.example.com
+//	  mode: require
+//	  keyring: keys/example.gpg
+//	  signers:
+//	  - Example Charts <charts@example.com>
+//	- source: oci://registry.example.com/team
+//	  mode: warn
+//
+// The rule with the longest source matching the location of a chart applies.
+type TrustPolicy struct {
+	Policies []*TrustRule `json:"policies"`
+}
+
+// TrustRule is the signature requirement of a chart source.
+type TrustRule struct {
+	// Source is a repository URL or an OCI registry prefix, such as
+	// oci://registry.example.com/team. Local charts match the file:// URL
+	// of their absolute path.
+	Source string `json:"source"`
+	// Mode is the verification mode.
+	Mode TrustMode `json:"mode"`
+	// Keyring is the keyring used to verify charts from the source, instead
+	// of the keyring of the operation. Relative paths are relative to the
+	// policy file.
+	Keyring string `json:"keyring,omitempty"`
+	// Signers restricts the trusted signers to the given names or key
+	// fingerprints. Any signer of the keyring is trusted when it is empty.
+	Signers []string `json:"signers,omitempty"`
+}
+
+// LoadTrustPolicy loads a trust policy file. A missing file is an empty policy.
+func LoadTrustPolicy(path string) (*TrustPolicy, error) {
+	p := &TrustPolicy{}
+	if path == "" {
+		return p, nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return p, nil
+		}
+		return nil, errors.Wrapf(err, "couldn't load trust policy file (%s)", path)
+	}
+	if err := yaml.UnmarshalStrict(b, p); err != nil {
+		return nil, errors.Wrapf(err, "couldn't parse trust policy file (%s)", path)
+	}
+
+	for _, r := range p.Policies {
+		if r.Source == "" {
+			return nil, errors.Errorf("trust policy file (%s) has a rule without a source", path)
+		}
+		switch r.Mode {
+		case TrustRequire, TrustWarn, TrustSkip:
+		default:
+			return nil, errors.Errorf("trust policy rule for %s has an invalid mode %q, expected require, warn or skip", r.Source, r.Mode)
+		}
+		if r.Keyring != "" && !filepath.IsAbs(r.Keyring) {
+			r.Keyring = filepath.Join(filepath.Dir(path), r.Keyring)
+		}
+	}
+	return p, nil
+}
+
+// Match returns the rule that applies to a chart located at any of the given
+// sources, or nil if none applies. The rule with the longest source wins.
+func (p *TrustPolicy) Match(sources ...string) *TrustRule {
+	if p == nil {
+		return nil
+	}
+	var match *TrustRule
+	for _, r := range p.Policies {
+		prefix := strings.TrimSuffix(r.Source, "/")
+		for _, s := range sources {
+			if !hasSourcePrefix(s, prefix) {
+				continue
+			}
+			if match == nil || len(prefix) > len(strings.TrimSuffix(match.Source, "/")) {
+				match = r
+			}
+		}
+	}
+	return match
+}
+
+// hasSourcePrefix checks that a source starts with the prefix at a path,
+// tag or digest boundary, so that oci://example.com/team does not match
+// oci://example.com/teamwork.
+func hasSourcePrefix(source, prefix string) bool {
+	if !strings.HasPrefix(source, prefix) {
+		return false
+	}
+	rest := source[len(prefix):]
+	return rest == "" || strings.ContainsAny(rest[:1], "/:@?")
+}
+
+// Trusts checks that a chart signed by the given identity satisfies the rule.
+func (r *TrustRule) Trusts(id *provenance.Identity) bool {
+	if len(r.Signers) == 0 {
+		return true
+	}
+	if id == nil {
+		return false
+	}
+	for _, s := range r.Signers {
+		if strings.EqualFold(s, id.Fingerprint) {
+			return true
+		}
+		for _, name := range id.Names {
+			if s == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// CheckSigner checks that the signer of a verified chart is trusted by the
+// rule. A nil rule trusts any signer.
+func (r *TrustRule) CheckSigner(ref string, ver *provenance.Verification) error {
+	if r == nil || r.Trusts(ver.Identity) {
+		return nil
+	}
+	signer := "an unknown signer"
+	if id := ver.Identity; id != nil && len(id.Names) > 0 {
+		signer = id.Names[0]
+	} else if id != nil {
+		signer = id.Fingerprint
+	}
+	return errors.Errorf("%s is signed by %s, who is not a trusted signer for %s", ref, signer, r.Source)
+}
+
+// keyring returns the keyring of the rule, or the given default.
+func (r *TrustRule) keyring(def string) string {
+	if r != nil && r.Keyring != "" {
+		return r.Keyring
+	}
+	return def
+}
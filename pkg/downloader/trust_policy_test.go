@@ -0,0 +1,226 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package downloader
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/provenance"
+	"helm.sh/helm/v3/pkg/repo/repotest"
+)
+
+const testSigner = "Helm Testing (This key should only be used for testing. DO NOT TRUST.) <helm-testing@helm.sh>"
+
+func writeTrustPolicy(t *testing.T, policy string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "trust-policy.yaml")
+	if err := os.WriteFile(path, []byte(policy), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadTrustPolicy(t *testing.T) {
+	p, err := LoadTrustPolicy(filepath.Join(t.TempDir(), "missing.yaml"))
+	if err != nil {
+		t.Fatalf("expected a missing policy file to be empty, got %s", err)
+	}
+	if len(p.Policies) != 0 {
+		t.Errorf("expected no rules, got %d", len(p.Policies))
+	}
+
+	path := writeTrustPolicy(t, `policies:
+- source: https://charts.example.com
+  mode: require
+  keyring: keys/example.gpg
+- source: oci://registry.example.com/team
+  mode: warn
+  keyring: /etc/helm/team.gpg
+`)
+	p, err = LoadTrustPolicy(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Policies) != 2 {
+		t.Fatalf("expected 2 rules, got %d", len(p.Policies))
+	}
+	if expect := filepath.Join(filepath.Dir(path), "keys/example.gpg"); p.Policies[0].Keyring != expect {
+		t.Errorf("expected keyring %q, got %q", expect, p.Policies[0].Keyring)
+	}
+	if p.Policies[1].Keyring != "/etc/helm/team.gpg" {
+		t.Errorf("expected absolute keyring to be kept, got %q", p.Policies[1].Keyring)
+	}
+
+	for name, policy := range map[string]string{
+		"invalid mode":   "policies:\n- source: https://example.com\n  mode: maybe\n",
+		"missing source": "policies:\n- mode: require\n",
+		"unknown field":  "policies:\n- source: https://example.com\n  mode: require\n  keyrings: []\n",
+	} {
+		if _, err := LoadTrustPolicy(writeTrustPolicy(t, policy)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestTrustPolicyMatch(t *testing.T) {
+	p := &TrustPolicy{Policies: []*TrustRule{
+		{Source: "https://charts.example.com/", Mode: TrustWarn},
+		{Source: "https://charts.example.com/stable", Mode: TrustRequire},
+		{Source: "oci://registry.example.com/team", Mode: TrustRequire},
+		{Source: "oci://registry.example.com/team/sandbox", Mode: TrustSkip},
+	}}
+
+	tests := []struct {
+		sources []string
+		expect  string
+	}{
+		{[]string{"https://charts.example.com/alpine-0.1.0.tgz"}, "https://charts.example.com/"},
+		{[]string{"https://charts.example.com/stable/alpine-0.1.0.tgz"}, "https://charts.example.com/stable"},
+		{[]string{"https://cdn.example.com/alpine-0.1.0.tgz", "https://charts.example.com/stable"}, "https://charts.example.com/stable"},
+		{[]string{"https://charts.example.com/stableish/alpine-0.1.0.tgz"}, "https://charts.example.com/"},
+		{[]string{"oci://registry.example.com/team/app:1.0.0"}, "oci://registry.example.com/team"},
+		{[]string{"oci://registry.example.com/team:1.0.0@sha256:abc"}, "oci://registry.example.com/team"},
+		{[]string{"oci://registry.example.com/team/sandbox/app:1.0.0"}, "oci://registry.example.com/team/sandbox"},
+		{[]string{"oci://registry.example.com/teamwork/app:1.0.0"}, ""},
+		{[]string{"https://other.example.com/alpine-0.1.0.tgz"}, ""},
+	}
+	for _, tt := range tests {
+		rule := p.Match(tt.sources...)
+		switch {
+		case rule == nil && tt.expect != "":
+			t.Errorf("%v: expected rule %q, got none", tt.sources, tt.expect)
+		case rule != nil && rule.Source != tt.expect:
+			t.Errorf("%v: expected rule %q, got %q", tt.sources, tt.expect, rule.Source)
+		}
+	}
+
+	var empty *TrustPolicy
+	if empty.Match("https://charts.example.com") != nil {
+		t.Error("expected a nil policy to match nothing")
+	}
+}
+
+func TestTrustRuleTrusts(t *testing.T) {
+	id := &provenance.Identity{Names: []string{"Example <charts@example.com>"}, Fingerprint: "5E615389B53CA37F"}
+
+	if !(&TrustRule{}).Trusts(id) {
+		t.Error("expected a rule without signers to trust any signer")
+	}
+	if !(&TrustRule{Signers: []string{"Example <charts@example.com>"}}).Trusts(id) {
+		t.Error("expected the signer to be trusted by name")
+	}
+	if !(&TrustRule{Signers: []string{"5e615389b53ca37f"}}).Trusts(id) {
+		t.Error("expected the signer to be trusted by fingerprint")
+	}
+	if (&TrustRule{Signers: []string{"Someone Else"}}).Trusts(id) {
+		t.Error("expected the signer not to be trusted")
+	}
+	if (&TrustRule{Signers: []string{"Someone Else"}}).Trusts(nil) {
+		t.Error("expected an unknown signer not to be trusted")
+	}
+}
+
+func TestDownloadTo_TrustPolicy(t *testing.T) {
+	srv, err := repotest.NewTempServerWithCleanup(t, "testdata/*.tgz*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Stop()
+	if err := srv.CreateIndex(); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.LinkIndices(); err != nil {
+		t.Fatal(err)
+	}
+	keyring, err := filepath.Abs("testdata/helm-test-key.pub")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		chart   string
+		policy  string
+		expect  string
+		warning string
+	}{
+		{
+			name:   "require verifies signed charts",
+			chart:  "/signtest-0.1.0.tgz",
+			policy: "policies:\n- source: " + srv.URL() + "\n  mode: require\n  keyring: " + keyring + "\n",
+		},
+		{
+			name:   "require fails for unsigned charts",
+			chart:  "/local-subchart-0.1.0.tgz",
+			policy: "policies:\n- source: " + srv.URL() + "\n  mode: require\n  keyring: " + keyring + "\n",
+			expect: "failed to fetch provenance",
+		},
+		{
+			name:   "require fails for untrusted signers",
+			chart:  "/signtest-0.1.0.tgz",
+			policy: "policies:\n- source: " + srv.URL() + "\n  mode: require\n  keyring: " + keyring + "\n  signers: [Someone Else]\n",
+			expect: "is signed by " + testSigner + ", who is not a trusted signer for " + srv.URL(),
+		},
+		{
+			name:    "warn reports failures",
+			chart:   "/signtest-0.1.0.tgz",
+			policy:  "policies:\n- source: " + srv.URL() + "\n  mode: warn\n  keyring: " + keyring + "\n  signers: [Someone Else]\n",
+			warning: "WARNING: " + srv.URL() + "/signtest-0.1.0.tgz does not satisfy the trust policy for " + srv.URL(),
+		},
+		{
+			name:   "skip exempts a source from a broader rule",
+			chart:  "/local-subchart-0.1.0.tgz",
+			policy: "policies:\n- source: " + srv.URL() + "\n  mode: require\n- source: " + srv.URL() + "/local-subchart-0.1.0.tgz\n  mode: skip\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			c := ChartDownloader{
+				Out:               &out,
+				Keyring:           "testdata/nosuchkeyring",
+				RepositoryConfig:  repoConfig,
+				RepositoryCache:   repoCache,
+				TrustPolicyConfig: writeTrustPolicy(t, tt.policy),
+				Getters: getter.All(&cli.EnvSettings{
+					RepositoryConfig: repoConfig,
+					RepositoryCache:  repoCache,
+				}),
+			}
+			_, _, err := c.DownloadTo(srv.URL()+tt.chart, "", t.TempDir())
+			if tt.expect != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expect) {
+					t.Fatalf("expected error containing %q, got %v", tt.expect, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(out.String(), tt.warning) {
+				t.Errorf("expected output to contain %q, got %q", tt.warning, out.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package getter
+
+import (
+	"bytes"
+	"encoding/json"
+	"os/exec"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/pkg/registry"
+)
+
+// Credentials authenticate a request, either with a username and a password
+// or with a bearer token.
+type Credentials struct {
+	Username string
+	Password string
+	Token    string
+}
+
+// CredentialsFunc returns the credentials of a request to a URL, or nil if
+// the request is not authenticated. It is called before each request, so
+// short-lived credentials are refreshed when they expire.
+type CredentialsFunc func(u string) (*Credentials, error)
+
+// WithCredentials sets the function providing the credentials of requests.
+// Like the credentials set with WithBasicAuth, they are only sent to the URL
+// set with WithURL unless WithPassCredentialsAll is set, and they take
+// precedence over them.
+func WithCredentials(f CredentialsFunc) Option {
+	return func(opts *options) {
+		opts.credentials = f
+	}
+}
+
+// credentialsNotFound is the message of Docker credential helpers that do not
+// have credentials for a server.
+const credentialsNotFound = "credentials not found"
+
+// CredentialHelper returns the credentials provided by a credential helper
+// command for a server URL.
+//
+// The command follows the protocol of Docker credential helpers: it is run
+// with the "get" argument and the server URL on its standard input, and prints
+// a JSON object with the Username and Secret fields. An empty username, or the
+// "<token>" username, makes the secret a bearer token.
+func CredentialHelper(command, serverURL string) CredentialsFunc {
+	return func(_ string) (*Credentials, error) {
+		var stdout, stderr bytes.Buffer
+		cmd := exec.Command(command, "get")
+		cmd.Stdin = strings.NewReader(serverURL)
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			msg := strings.TrimSpace(stdout.String() + stderr.String())
+			if strings.Contains(msg, credentialsNotFound) {
+				return nil, nil
+			}
+			return nil, errors.Wrapf(err, "credential helper %s failed: %s", command, msg)
+		}
+
+		var resp struct {
+			Username string
+			Secret   string
+		}
+		if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+			return nil, errors.Wrapf(err, "invalid output of credential helper %s", command)
+		}
+		if resp.Username == "" || resp.Username == "<token>" {
+			return &Credentials{Token: resp.Secret}, nil
+		}
+		return &Credentials{Username: resp.Username, Password: resp.Secret}, nil
+	}
+}
+
+// RegistryCredentials returns the credentials stored for a server in the
+// registry credentials file, as written by 'helm registry login'. Credential
+// stores and helpers configured in the file are used as well.
+func RegistryCredentials(credentialsFile, server string) CredentialsFunc {
+	return func(_ string) (*Credentials, error) {
+		username, password, err := registry.LookupCredential(credentialsFile, server)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case username == "" && password == "":
+			return nil, nil
+		case username == "":
+			// A blank username is a bearer token
+			return &Credentials{Token: password}, nil
+		}
+		return &Credentials{Username: username, Password: password}, nil
+	}
+}
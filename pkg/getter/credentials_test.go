@@ -0,0 +1,164 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package getter
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// writeCredentialHelper writes a credential helper script printing output for
+// the server URL it is given, and failing with a not found error otherwise.
+func writeCredentialHelper(t *testing.T, server, output string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("credential helper scripts are shell scripts")
+	}
+	script := fmt.Sprintf(`#!/bin/sh
+[ "$1" = "get" ] || exit 1
+read server
+if [ "$server" = %q ]; then
+  echo '%s'
+else
+  echo "credentials not found in native keychain"
+  exit 1
+fi
+`, server, output)
+	path := filepath.Join(t.TempDir(), "helm-credential-test")
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestCredentialHelper(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		expect Credentials
+	}{
+		{"basic", `{"ServerURL":"https://charts.example.com","Username":"user","Secret":"pass"}`, Credentials{Username: "user", Password: "pass"}},
+		{"token", `{"ServerURL":"https://charts.example.com","Username":"<token>","Secret":"token"}`, Credentials{Token: "token"}},
+		{"blank username", `{"ServerURL":"https://charts.example.com","Secret":"token"}`, Credentials{Token: "token"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			helper := writeCredentialHelper(t, "https://charts.example.com", tt.output)
+			creds, err := CredentialHelper(helper, "https://charts.example.com")("https://charts.example.com/index.yaml")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if creds == nil || *creds != tt.expect {
+				t.Errorf("expected %+v, got %+v", tt.expect, creds)
+			}
+		})
+	}
+
+	helper := writeCredentialHelper(t, "https://charts.example.com", "{}")
+	creds, err := CredentialHelper(helper, "https://other.example.com")("https://other.example.com/index.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if creds != nil {
+		t.Errorf("expected no credentials for an unknown server, got %+v", creds)
+	}
+
+	if _, err := CredentialHelper(filepath.Join(t.TempDir(), "missing"), "https://charts.example.com")(""); err == nil {
+		t.Error("expected an error for a missing credential helper")
+	}
+}
+
+func TestRegistryCredentials(t *testing.T) {
+	auth := base64.StdEncoding.EncodeToString([]byte("user:pass"))
+	config := fmt.Sprintf(`{"auths":{"charts.example.com":{"auth":%q},"token.example.com":{"identitytoken":"token"}}}`, auth)
+	credentialsFile := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(credentialsFile, []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	creds, err := RegistryCredentials(credentialsFile, "charts.example.com")("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := (Credentials{Username: "user", Password: "pass"}); creds == nil || *creds != expect {
+		t.Errorf("expected %+v, got %+v", expect, creds)
+	}
+
+	creds, err = RegistryCredentials(credentialsFile, "token.example.com")("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := (Credentials{Token: "token"}); creds == nil || *creds != expect {
+		t.Errorf("expected %+v, got %+v", expect, creds)
+	}
+}
+
+func TestHTTPGetterCredentials(t *testing.T) {
+	var authorization string
+	srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+	}))
+	defer srv.Close()
+
+	calls := 0
+	token := func(_ string) (*Credentials, error) {
+		calls++
+		return &Credentials{Token: fmt.Sprintf("token-%d", calls)}, nil
+	}
+
+	g, err := NewHTTPGetter(WithURL(srv.URL), WithBasicAuth("user", "pass"), WithCredentials(token))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= 2; i++ {
+		if _, err := g.Get(srv.URL + "/index.yaml"); err != nil {
+			t.Fatal(err)
+		}
+		// The credentials are requested again for each request.
+		if expect := fmt.Sprintf("Bearer token-%d", i); authorization != expect {
+			t.Errorf("expected %q, got %q", expect, authorization)
+		}
+	}
+
+	basic := func(_ string) (*Credentials, error) {
+		return &Credentials{Username: "helper", Password: "secret"}, nil
+	}
+	if _, err := g.Get(srv.URL+"/index.yaml", WithCredentials(basic)); err != nil {
+		t.Fatal(err)
+	}
+	if expect := "Basic " + base64.StdEncoding.EncodeToString([]byte("helper:secret")); authorization != expect {
+		t.Errorf("expected %q, got %q", expect, authorization)
+	}
+
+	// Credentials are not sent to another host.
+	g, err = NewHTTPGetter(WithURL("https://charts.example.com"), WithCredentials(basic))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.Get(srv.URL + "/index.yaml"); err != nil {
+		t.Fatal(err)
+	}
+	if authorization != "" {
+		t.Errorf("expected no credentials, got %q", authorization)
+	}
+}
@@ -46,6 +46,12 @@ type options struct {
 	registryClient        *registry.Client
 	timeout               time.Duration
 	transport             *http.Transport
+	validators            *CacheValidators
+	acceptGzip            bool
+	credentials           CredentialsFunc
+	bearerToken           string
+	headers               map[string]string
+	oauth2                *oauth2ClientCredentials
 }
 
 // Option allows specifying various settings configurable by the user for overriding the defaults
@@ -68,6 +74,37 @@ func WithBasicAuth(username, password string) Option {
 	}
 }
 
+// WithBearerToken sets the request's Authorization header to use the provided
+// bearer token.
+func WithBearerToken(token string) Option {
+	return func(opts *options) {
+		opts.bearerToken = token
+	}
+}
+
+// WithHeaders sets static headers on the request, for example to send an API
+// key. Like credentials, they are only sent to the URL set with WithURL unless
+// WithPassCredentialsAll is set.
+func WithHeaders(headers map[string]string) Option {
+	return func(opts *options) {
+		opts.headers = headers
+	}
+}
+
+// WithOAuth2ClientCredentials authenticates the request with an access token
+// obtained with the OAuth2 client credentials flow from the token endpoint.
+// Tokens are cached until they expire.
+func WithOAuth2ClientCredentials(tokenURL, clientID, clientSecret string, scopes []string) Option {
+	return func(opts *options) {
+		opts.oauth2 = &oauth2ClientCredentials{
+			tokenURL:     tokenURL,
+			clientID:     clientID,
+			clientSecret: clientSecret,
+			scopes:       scopes,
+		}
+	}
+}
+
 func WithPassCredentialsAll(pass bool) Option {
 	return func(opts *options) {
 		opts.passCredentialsAll = pass
@@ -135,6 +172,40 @@ func WithTransport(transport *http.Transport) Option {
 	}
 }
 
+// CacheValidators are the validators of a cached copy of a document, which
+// make a request conditional on the document having changed.
+type CacheValidators struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+}
+
+// ErrNotModified is returned by a conditional request when the document has
+// not changed since it was cached.
+var ErrNotModified = errors.New("not modified")
+
+// ErrNotFound is matched by the error of a getter when the document does not
+// exist, so that callers can tell it from a failure to reach the server.
+var ErrNotFound = errors.New("not found")
+
+// WithCacheValidators makes the request conditional on the document having
+// changed since it was cached with the given validators. Getters that support
+// conditional requests return ErrNotModified if it has not, and otherwise
+// update the validators with those of the fetched document. Other getters
+// always fetch the document.
+func WithCacheValidators(validators *CacheValidators) Option {
+	return func(opts *options) {
+		opts.validators = validators
+	}
+}
+
+// WithAcceptGzip allows the server to compress the response with gzip. It is
+// decompressed by the getter.
+func WithAcceptGzip() Option {
+	return func(opts *options) {
+		opts.acceptGzip = true
+	}
+}
+
 // Getter is an interface to support GET to the specified URL.
 type Getter interface {
 	// Get file content by url string
@@ -17,7 +17,9 @@ package getter
 
 import (
 	"bytes"
+	"compress/gzip"
 	"crypto/tls"
+	"fmt"
 	"io"
 	"net/http"
 	"net/url"
@@ -69,18 +71,58 @@ func (g *HTTPGetter) get(href string) (*bytes.Buffer, error) {
 		return nil, errors.Wrap(err, "Unable to parse URL getting from")
 	}
 
+	client, err := g.httpClient()
+	if err != nil {
+		return nil, err
+	}
+
 	// Host on URL (returned from url.Parse) contains the port if present.
 	// This check ensures credentials are not passed between different
 	// services on different ports.
 	if g.opts.passCredentialsAll || (u1.Scheme == u2.Scheme && u1.Host == u2.Host) {
+		for name, value := range g.opts.headers {
+			req.Header.Set(name, value)
+		}
 		if g.opts.username != "" && g.opts.password != "" {
 			req.SetBasicAuth(g.opts.username, g.opts.password)
 		}
+		if g.opts.bearerToken != "" {
+			req.Header.Set("Authorization", "Bearer "+g.opts.bearerToken)
+		}
+		if g.opts.oauth2 != nil {
+			tok, err := g.opts.oauth2.token(client)
+			if err != nil {
+				return nil, err
+			}
+			tok.SetAuthHeader(req)
+		}
+		if g.opts.credentials != nil {
+			creds, err := g.opts.credentials(href)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to get the credentials for %s", href)
+			}
+			switch {
+			case creds == nil:
+			case creds.Token != "":
+				req.Header.Set("Authorization", "Bearer "+creds.Token)
+			default:
+				req.SetBasicAuth(creds.Username, creds.Password)
+			}
+		}
 	}
 
-	client, err := g.httpClient()
-	if err != nil {
-		return nil, err
+	if v := g.opts.validators; v != nil {
+		if v.ETag != "" {
+			req.Header.Set("If-None-Match", v.ETag)
+		}
+		if v.LastModified != "" {
+			req.Header.Set("If-Modified-Since", v.LastModified)
+		}
+	}
+	if g.opts.acceptGzip {
+		// The transport does not decompress responses, so that chart
+		// archives served with a gzip encoding are kept intact.
+		req.Header.Set("Accept-Encoding", "gzip")
 	}
 
 	resp, err := client.Do(req)
@@ -88,15 +130,48 @@ func (g *HTTPGetter) get(href string) (*bytes.Buffer, error) {
 		return nil, err
 	}
 	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotModified && g.opts.validators != nil {
+		return nil, ErrNotModified
+	}
 	if resp.StatusCode != http.StatusOK {
-		return nil, errors.Errorf("failed to fetch %s : %s", href, resp.Status)
+		return nil, &fetchError{href: href, status: resp.Status, code: resp.StatusCode}
+	}
+	if v := g.opts.validators; v != nil {
+		v.ETag = resp.Header.Get("ETag")
+		v.LastModified = resp.Header.Get("Last-Modified")
+	}
+
+	body := io.Reader(resp.Body)
+	if g.opts.acceptGzip && resp.Header.Get("Content-Encoding") == "gzip" {
+		zr, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to decompress %s", href)
+		}
+		defer zr.Close()
+		body = zr
 	}
 
 	buf := bytes.NewBuffer(nil)
-	_, err = io.Copy(buf, resp.Body)
+	_, err = io.Copy(buf, body)
 	return buf, err
 }
 
+// fetchError is the error of a request answered with an unexpected status.
+type fetchError struct {
+	href   string
+	status string
+	code   int
+}
+
+func (e *fetchError) Error() string {
+	return fmt.Sprintf("failed to fetch %s : %s", e.href, e.status)
+}
+
+// Is reports whether the document was not found.
+func (e *fetchError) Is(target error) bool {
+	return target == ErrNotFound && e.code == http.StatusNotFound
+}
+
 // NewHTTPGetter constructs a valid http/https client as a Getter
 func NewHTTPGetter(options ...Option) (Getter, error) {
 	var client HTTPGetter
@@ -16,6 +16,7 @@ limitations under the License.
 package getter
 
 import (
+	"compress/gzip"
 	"fmt"
 	"io"
 	"net/http"
@@ -530,3 +531,138 @@ func TestHTTPTransportOption(t *testing.T) {
 		t.Fatal("transport.TLSClientConfig should not be set")
 	}
 }
+
+func TestHTTPGetterConditional(t *testing.T) {
+	const body = "apiVersion: v1\n"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Last-Modified", "Mon, 02 Jan 2006 15:04:05 GMT")
+		if r.Header.Get("Accept-Encoding") == "gzip" {
+			w.Header().Set("Content-Encoding", "gzip")
+			zw := gzip.NewWriter(w)
+			defer zw.Close()
+			zw.Write([]byte(body))
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	g, err := NewHTTPGetter(WithURL(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	validators := &CacheValidators{}
+	got, err := g.Get(srv.URL, WithCacheValidators(validators), WithAcceptGzip())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.String() != body {
+		t.Errorf("Expected the decompressed body %q, got %q", body, got.String())
+	}
+	if validators.ETag != `"v1"` || validators.LastModified != "Mon, 02 Jan 2006 15:04:05 GMT" {
+		t.Errorf("Expected the validators of the response, got %+v", validators)
+	}
+
+	if _, err := g.Get(srv.URL, WithCacheValidators(validators)); !errors.Is(err, ErrNotModified) {
+		t.Errorf("Expected ErrNotModified, got %v", err)
+	}
+
+	// A request without validators always fetches the document.
+	g, err = NewHTTPGetter(WithURL(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.Get(srv.URL); err != nil {
+		t.Errorf("Expected an unconditional request to succeed, got %v", err)
+	}
+}
+
+func TestHTTPGetterNotFound(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	g, err := NewHTTPGetter(WithURL(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = g.Get(srv.URL + "/missing")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+	if want := "failed to fetch " + srv.URL + "/missing : 404 Not Found"; err == nil || err.Error() != want {
+		t.Errorf("Expected %q, got %v", want, err)
+	}
+	if _, err := g.Get(srv.URL + "/forbidden"); err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected an error other than ErrNotFound, got %v", err)
+	}
+}
+
+func TestHTTPGetterAuthOptions(t *testing.T) {
+	var tokensIssued int
+	var header http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			if id, secret, ok := r.BasicAuth(); !ok || id != "client" || secret != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			tokensIssued++
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"access_token":"token-%d","token_type":"Bearer","expires_in":3600}`, tokensIssued)
+			return
+		}
+		header = r.Header
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name   string
+		opts   []Option
+		header string
+		expect string
+	}{
+		{"bearer token", []Option{WithBearerToken("token")}, "Authorization", "Bearer token"},
+		{"headers", []Option{WithHeaders(map[string]string{"X-Api-Key": "key"})}, "X-Api-Key", "key"},
+		{"oauth2", []Option{WithOAuth2ClientCredentials(srv.URL+"/token", "client", "secret", nil)}, "Authorization", "Bearer token-1"},
+		// The token is cached until it expires.
+		{"oauth2 cached token", []Option{WithOAuth2ClientCredentials(srv.URL+"/token", "client", "secret", nil)}, "Authorization", "Bearer token-1"},
+		{"not sent to another host", []Option{WithURL("https://charts.example.com"), WithBearerToken("token"), WithHeaders(map[string]string{"X-Api-Key": "key"})}, "Authorization", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := NewHTTPGetter(append([]Option{WithURL(srv.URL)}, tt.opts...)...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := g.Get(srv.URL + "/index.yaml"); err != nil {
+				t.Fatal(err)
+			}
+			if got := header.Get(tt.header); got != tt.expect {
+				t.Errorf("expected %s header %q, got %q", tt.header, tt.expect, got)
+			}
+		})
+	}
+	if tokensIssued != 1 {
+		t.Errorf("expected 1 token to be issued, got %d", tokensIssued)
+	}
+
+	g, err := NewHTTPGetter(WithURL(srv.URL), WithOAuth2ClientCredentials(srv.URL+"/token", "client", "wrong", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.Get(srv.URL + "/index.yaml"); err == nil || !strings.Contains(err.Error(), "failed to get an OAuth2 token") {
+		t.Errorf("expected a token error, got %v", err)
+	}
+}
@@ -0,0 +1,69 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package getter
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+// oauth2ClientCredentials configures the OAuth2 client credentials flow.
+type oauth2ClientCredentials struct {
+	tokenURL     string
+	clientID     string
+	clientSecret string
+	scopes       []string
+}
+
+// oauth2Tokens caches the token sources of the client credentials flows, so
+// that a token is reused by the requests made until it expires.
+var oauth2Tokens = struct {
+	sync.Mutex
+	sources map[string]oauth2.TokenSource
+}{sources: map[string]oauth2.TokenSource{}}
+
+// token returns a valid access token, requesting a new one from the token
+// endpoint with the given client when the cached one expired.
+func (c *oauth2ClientCredentials) token(client *http.Client) (*oauth2.Token, error) {
+	key := strings.Join(append([]string{c.tokenURL, c.clientID, c.clientSecret}, c.scopes...), "\x00")
+
+	oauth2Tokens.Lock()
+	source, ok := oauth2Tokens.sources[key]
+	if !ok {
+		conf := &clientcredentials.Config{
+			ClientID:     c.clientID,
+			ClientSecret: c.clientSecret,
+			TokenURL:     c.tokenURL,
+			Scopes:       c.scopes,
+		}
+		source = conf.TokenSource(context.WithValue(context.Background(), oauth2.HTTPClient, client))
+		oauth2Tokens.sources[key] = source
+	}
+	oauth2Tokens.Unlock()
+
+	tok, err := source.Token()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get an OAuth2 token from %s", c.tokenURL)
+	}
+	return tok, nil
+}
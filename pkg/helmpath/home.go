@@ -14,6 +14,8 @@
 // Package helmpath calculates filesystem paths to Helm's configuration, cache and data.
 package helmpath
 
+import "strings"
+
 // This helper builds paths to Helm's configuration, cache and data paths.
 const lp = lazypath("helm")
 
@@ -42,3 +44,18 @@ func CacheChartsFile(name string) string {
 	}
 	return name + "charts.txt"
 }
+
+// CacheIndexValidatorsFile returns the path to the HTTP cache validators of
+// the index of the given named repository.
+func CacheIndexValidatorsFile(name string) string {
+	if name != "" {
+		name += "-"
+	}
+	return name + "index-validators.json"
+}
+
+// CacheOCIMetadataFile returns the path to the chart metadata cached while
+// searching the given OCI registry host.
+func CacheOCIMetadataFile(host string) string {
+	return "oci-" + strings.NewReplacer(":", "_", "/", "_").Replace(host) + "-metadata.json"
+}
@@ -80,6 +80,9 @@ type Client struct {
 	// chance of it changing.
 	Factory Factory
 	Log     func(string, ...interface{})
+	// WaitProgress, if set, is called while waiting for resources to be
+	// ready, each time the readiness of one of them changes.
+	WaitProgress func(WaitEvent)
 	// Namespace allows to bypass the kubeconfig file for the choice of the namespace
 	Namespace string
 
@@ -289,9 +292,10 @@ func (c *Client) Wait(resources ResourceList, timeout time.Duration) error {
 	}
 	checker := NewReadyChecker(cs, c.Log, PausedAsReady(true))
 	w := waiter{
-		c:       checker,
-		log:     c.Log,
-		timeout: timeout,
+		c:        checker,
+		log:      c.Log,
+		timeout:  timeout,
+		progress: c.WaitProgress,
 	}
 	return w.waitForResources(resources)
 }
@@ -304,9 +308,10 @@ func (c *Client) WaitWithJobs(resources ResourceList, timeout time.Duration) err
 	}
 	checker := NewReadyChecker(cs, c.Log, PausedAsReady(true), CheckJobs(true))
 	w := waiter{
-		c:       checker,
-		log:     c.Log,
-		timeout: timeout,
+		c:        checker,
+		log:      c.Log,
+		timeout:  timeout,
+		progress: c.WaitProgress,
 	}
 	return w.waitForResources(resources)
 }
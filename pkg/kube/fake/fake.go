@@ -41,6 +41,7 @@ type FailingKubeClient struct {
 	DeleteWithPropagationError       error
 	WatchUntilReadyError             error
 	UpdateError                      error
+	PlanError                        error
 	BuildError                       error
 	BuildTableError                  error
 	BuildDummy                       bool
@@ -114,6 +115,14 @@ func (f *FailingKubeClient) Update(r, modified kube.ResourceList, ignoreMe bool)
 	return f.PrintingKubeClient.Update(r, modified, ignoreMe)
 }
 
+// Plan returns the configured error if set or plans
+func (f *FailingKubeClient) Plan(original, target kube.ResourceList, force bool) ([]kube.PlannedChange, error) {
+	if f.PlanError != nil {
+		return nil, f.PlanError
+	}
+	return f.PrintingKubeClient.Plan(original, target, force)
+}
+
 // Build returns the configured error if set or prints
 func (f *FailingKubeClient) Build(r io.Reader, _ bool) (kube.ResourceList, error) {
 	if f.BuildError != nil {
@@ -101,6 +101,22 @@ func (p *PrintingKubeClient) Update(_, modified kube.ResourceList, _ bool) (*kub
 	return &kube.Result{Updated: modified}, nil
 }
 
+// Plan implements KubeClient Plan.
+//
+// It plans to create every target resource.
+func (p *PrintingKubeClient) Plan(_, target kube.ResourceList, _ bool) ([]kube.PlannedChange, error) {
+	changes := make([]kube.PlannedChange, 0, len(target))
+	for _, info := range target {
+		changes = append(changes, kube.PlannedChange{Info: info, Operation: kube.OperationCreate})
+	}
+	return changes, nil
+}
+
+// WhoAmI implements KubeClient WhoAmI. The user is unknown.
+func (p *PrintingKubeClient) WhoAmI() (string, error) {
+	return "", nil
+}
+
 // Build implements KubeClient Build.
 func (p *PrintingKubeClient) Build(_ io.Reader, _ bool) (kube.ResourceList, error) {
 	return []*resource.Info{}, nil
@@ -110,7 +110,26 @@ type InterfaceResources interface {
 	BuildTable(reader io.Reader, validate bool) (ResourceList, error)
 }
 
+// InterfacePlan is introduced to avoid breaking backwards compatibility for Interface implementers.
+//
+// TODO Helm 4: Remove InterfacePlan and integrate its method(s) into the Interface.
+type InterfacePlan interface {
+	// Plan returns what Update would do with the given resources, without
+	// changing them.
+	Plan(original, target ResourceList, force bool) ([]PlannedChange, error)
+}
+
+// InterfaceWhoAmI is introduced to avoid breaking backwards compatibility for Interface implementers.
+//
+// TODO Helm 4: Remove InterfaceWhoAmI and integrate its method(s) into the Interface.
+type InterfaceWhoAmI interface {
+	// WhoAmI returns the name of the user the client acts as in the cluster.
+	WhoAmI() (string, error)
+}
+
 var _ Interface = (*Client)(nil)
 var _ InterfaceExt = (*Client)(nil)
 var _ InterfaceDeletionPropagation = (*Client)(nil)
 var _ InterfaceResources = (*Client)(nil)
+var _ InterfacePlan = (*Client)(nil)
+var _ InterfaceWhoAmI = (*Client)(nil)
@@ -0,0 +1,126 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube // import "helm.sh/helm/v3/pkg/kube"
+
+import (
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/resource"
+)
+
+// Operation is what Update does with a resource.
+type Operation string
+
+const (
+	// OperationCreate creates a resource that does not exist.
+	OperationCreate Operation = "create"
+	// OperationPatch patches a resource that differs from its target.
+	OperationPatch Operation = "patch"
+	// OperationReplace replaces a resource, when updates are forced.
+	OperationReplace Operation = "replace"
+	// OperationNone leaves a resource that matches its target untouched.
+	OperationNone Operation = "none"
+	// OperationDelete deletes a resource that is no longer in the target.
+	OperationDelete Operation = "delete"
+	// OperationKeep leaves a resource that is no longer in the target
+	// because of its resource policy.
+	OperationKeep Operation = "keep"
+)
+
+// PlannedChange is what Update would do with a resource.
+type PlannedChange struct {
+	Info      *resource.Info
+	Operation Operation
+	// ResourceVersion is the version of the resource in the cluster, or
+	// empty if it does not exist.
+	ResourceVersion string
+}
+
+// Plan returns what Update would do with the given resources, in the order
+// Update would do it, without changing them or the cluster.
+//
+// Resources of the original list that no longer exist in the cluster are left
+// out, as Update skips them.
+func (c *Client) Plan(original, target ResourceList, force bool) ([]PlannedChange, error) {
+	var changes []PlannedChange
+
+	err := target.Visit(func(info *resource.Info, err error) error {
+		if err != nil {
+			return err
+		}
+
+		helper := resource.NewHelper(info.Client, info.Mapping)
+		live, err := helper.Get(info.Namespace, info.Name)
+		if err != nil {
+			if !apierrors.IsNotFound(err) {
+				return errors.Wrap(err, "could not get information about the resource")
+			}
+			changes = append(changes, PlannedChange{Info: info, Operation: OperationCreate})
+			return nil
+		}
+
+		originalInfo := original.Get(info)
+		if originalInfo == nil {
+			kind := info.Mapping.GroupVersionKind.Kind
+			return errors.Errorf("no %s with the name %q found", kind, info.Name)
+		}
+
+		change := PlannedChange{Info: info, Operation: OperationReplace, ResourceVersion: resourceVersion(live)}
+		if !force {
+			patch, _, err := createPatch(info, originalInfo.Object)
+			if err != nil {
+				return errors.Wrap(err, "failed to create patch")
+			}
+			change.Operation = OperationPatch
+			if patch == nil || string(patch) == "{}" {
+				change.Operation = OperationNone
+			}
+		}
+		changes = append(changes, change)
+		return nil
+	})
+	if err != nil {
+		return changes, err
+	}
+
+	for _, info := range original.Difference(target) {
+		live, err := resource.NewHelper(info.Client, info.Mapping).Get(info.Namespace, info.Name)
+		if err != nil {
+			c.Log("Unable to get obj %q, err: %s", info.Name, err)
+			continue
+		}
+		change := PlannedChange{Info: info, Operation: OperationDelete, ResourceVersion: resourceVersion(live)}
+		annotations, err := metadataAccessor.Annotations(live)
+		if err != nil {
+			c.Log("Unable to get annotations on %q, err: %s", info.Name, err)
+		}
+		if annotations != nil && annotations[ResourcePolicyAnno] == KeepPolicy {
+			change.Operation = OperationKeep
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+func resourceVersion(obj runtime.Object) string {
+	v, err := metadataAccessor.ResourceVersion(obj)
+	if err != nil {
+		return ""
+	}
+	return v
+}
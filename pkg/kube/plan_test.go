@@ -0,0 +1,128 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"net/http"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/rest/fake"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+)
+
+func TestPlan(t *testing.T) {
+	listA := newPodList("starfish", "otter", "squid", "whale")
+	listB := newPodList("starfish", "otter", "dolphin")
+	listB.Items[0].Spec.Containers[0].Ports = []v1.ContainerPort{{Name: "https", ContainerPort: 443}}
+
+	live := newPodList("starfish", "otter", "squid", "whale")
+	for i := range live.Items {
+		live.Items[i].ResourceVersion = "1" + live.Items[i].Name
+	}
+	live.Items[3].Annotations = map[string]string{ResourcePolicyAnno: KeepPolicy}
+
+	c := newTestClient(t)
+	c.Factory.(*cmdtesting.TestFactory).UnstructuredClient = &fake.RESTClient{
+		NegotiatedSerializer: unstructuredSerializer,
+		Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			p, m := req.URL.Path, req.Method
+			if m != "GET" {
+				t.Fatalf("unexpected request: %s %s", m, p)
+			}
+			switch p {
+			case "/namespaces/default/pods/starfish":
+				return newResponse(200, &live.Items[0])
+			case "/namespaces/default/pods/otter":
+				return newResponse(200, &live.Items[1])
+			case "/namespaces/default/pods/squid":
+				return newResponse(200, &live.Items[2])
+			case "/namespaces/default/pods/whale":
+				return newResponse(200, &live.Items[3])
+			case "/namespaces/default/pods/dolphin":
+				return newResponse(404, notFoundBody())
+			default:
+				t.Fatalf("unexpected request: %s %s", m, p)
+				return nil, nil
+			}
+		}),
+	}
+	first, err := c.Build(objBody(&listA), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := c.Build(objBody(&listB), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		force bool
+		want  map[string]Operation
+	}{
+		{
+			name: "patch",
+			want: map[string]Operation{
+				"starfish": OperationPatch,
+				"otter":    OperationNone,
+				"dolphin":  OperationCreate,
+				"squid":    OperationDelete,
+				"whale":    OperationKeep,
+			},
+		},
+		{
+			name:  "force",
+			force: true,
+			want: map[string]Operation{
+				"starfish": OperationReplace,
+				"otter":    OperationReplace,
+				"dolphin":  OperationCreate,
+				"squid":    OperationDelete,
+				"whale":    OperationKeep,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes, err := c.Plan(first, second, tt.force)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(changes) != len(tt.want) {
+				t.Fatalf("expected %d changes, got %d", len(tt.want), len(changes))
+			}
+			order := []string{"starfish", "otter", "dolphin", "squid", "whale"}
+			for i, change := range changes {
+				name := change.Info.Name
+				if name != order[i] {
+					t.Errorf("expected change %d to be for %s, got %s", i, order[i], name)
+				}
+				if change.Operation != tt.want[name] {
+					t.Errorf("expected %s to be planned as %s, got %s", name, tt.want[name], change.Operation)
+				}
+				wantVersion := "1" + name
+				if name == "dolphin" {
+					wantVersion = ""
+				}
+				if change.ResourceVersion != wantVersion {
+					t.Errorf("expected resource version %q for %s, got %q", wantVersion, name, change.ResourceVersion)
+				}
+			}
+		})
+	}
+}
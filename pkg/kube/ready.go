@@ -17,8 +17,10 @@ limitations under the License.
 package kube // import "helm.sh/helm/v3/pkg/kube"
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"strings"
 
 	appsv1 "k8s.io/api/apps/v1"
 	appsv1beta1 "k8s.io/api/apps/v1beta1"
@@ -29,15 +31,27 @@ import (
 	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apiextv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/cli-runtime/pkg/resource"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/util/jsonpath"
 
 	deploymentutil "helm.sh/helm/v3/internal/third_party/k8s.io/kubernetes/deployment/util"
 )
 
+const (
+	// ReadyConditionAnnotation names the status condition that must be True
+	// for a custom resource to be ready, instead of Ready or Available.
+	ReadyConditionAnnotation = "helm.sh/ready-condition"
+	// ReadyJSONPathAnnotation is a JSONPath expression that decides when a
+	// custom resource is ready, such as '{.status.phase}=Running'. Without a
+	// value, the resource is ready when the expression has a non-empty result.
+	ReadyJSONPathAnnotation = "helm.sh/ready-jsonpath"
+)
+
 // ReadyCheckerOption is a function that configures a ReadyChecker.
 type ReadyCheckerOption func(*ReadyChecker)
 
@@ -80,16 +94,28 @@ type ReadyChecker struct {
 	log           func(string, ...interface{})
 	checkJobs     bool
 	pausedAsReady bool
+	// reason is why the resource of the last call to IsReady is not ready.
+	reason string
+}
+
+// notReady logs why a resource is not ready, and keeps the reason to report
+// the progress of a wait.
+func (c *ReadyChecker) notReady(kind string, obj metav1.Object, format string, args ...interface{}) {
+	c.reason = fmt.Sprintf(format, args...)
+	c.log("%s is not ready: %s/%s. %s", kind, obj.GetNamespace(), obj.GetName(), c.reason)
 }
 
 // IsReady checks if v is ready. It supports checking readiness for pods,
 // deployments, persistent volume claims, services, daemon sets, custom
 // resource definitions, stateful sets, replication controllers, jobs (optional),
-// and replica sets. All other resource kinds are always considered ready.
+// and replica sets. Custom resources are ready once their status conditions
+// say so, see customResourceReady. All other resource kinds are always
+// considered ready.
 //
 // IsReady will fetch the latest state of the object from the server prior to
 // performing readiness checks, and it will return any error encountered.
 func (c *ReadyChecker) IsReady(ctx context.Context, v *resource.Info) (bool, error) {
+	c.reason = ""
 	switch value := AsVersioned(v).(type) {
 	case *corev1.Pod:
 		pod, err := c.client.CoreV1().Pods(v.Namespace).Get(ctx, v.Name, metav1.GetOptions{})
@@ -155,6 +181,7 @@ func (c *ReadyChecker) IsReady(ctx context.Context, v *resource.Info) (bool, err
 			return false, err
 		}
 		if !c.crdBetaReady(*crd) {
+			c.notReady("CustomResourceDefinition", crd, "not established")
 			return false, nil
 		}
 	case *apiextv1.CustomResourceDefinition:
@@ -166,6 +193,7 @@ func (c *ReadyChecker) IsReady(ctx context.Context, v *resource.Info) (bool, err
 			return false, err
 		}
 		if !c.crdReady(*crd) {
+			c.notReady("CustomResourceDefinition", crd, "not established")
 			return false, nil
 		}
 	case *appsv1.StatefulSet, *appsv1beta1.StatefulSet, *appsv1beta2.StatefulSet:
@@ -200,6 +228,18 @@ func (c *ReadyChecker) IsReady(ctx context.Context, v *resource.Info) (bool, err
 		if !ready || err != nil {
 			return false, err
 		}
+	default:
+		if kubernetesNativeScheme().Recognizes(value.GetObjectKind().GroupVersionKind()) {
+			break
+		}
+		if err := v.Get(); err != nil {
+			return false, err
+		}
+		obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(v.Object)
+		if err != nil {
+			return false, err
+		}
+		return c.customResourceReady(&unstructured.Unstructured{Object: obj})
 	}
 	return true, nil
 }
@@ -211,6 +251,7 @@ func (c *ReadyChecker) podsReadyForObject(ctx context.Context, namespace string,
 	}
 	for _, pod := range pods {
 		if !c.isPodReady(&pod) {
+			c.reason = fmt.Sprintf("pod %s: %s", pod.Name, c.reason)
 			return false, nil
 		}
 	}
@@ -233,7 +274,13 @@ func (c *ReadyChecker) isPodReady(pod *corev1.Pod) bool {
 			return true
 		}
 	}
-	c.log("Pod is not ready: %s/%s", pod.GetNamespace(), pod.GetName())
+	ready := 0
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Ready {
+			ready++
+		}
+	}
+	c.notReady("Pod", pod, "%d/%d containers ready", ready, len(pod.Spec.Containers))
 	return false
 }
 
@@ -244,7 +291,7 @@ func (c *ReadyChecker) jobReady(job *batchv1.Job) (bool, error) {
 		return false, fmt.Errorf("job is failed: %s/%s", job.GetNamespace(), job.GetName())
 	}
 	if job.Spec.Completions != nil && job.Status.Succeeded < *job.Spec.Completions {
-		c.log("Job is not completed: %s/%s", job.GetNamespace(), job.GetName())
+		c.notReady("Job", job, "%d/%d completions succeeded", job.Status.Succeeded, *job.Spec.Completions)
 		return false, nil
 	}
 	return true, nil
@@ -258,7 +305,7 @@ func (c *ReadyChecker) serviceReady(s *corev1.Service) bool {
 
 	// Ensure that the service cluster IP is not empty
 	if s.Spec.ClusterIP == "" {
-		c.log("Service does not have cluster IP address: %s/%s", s.GetNamespace(), s.GetName())
+		c.notReady("Service", s, "no cluster IP address")
 		return false
 	}
 
@@ -271,7 +318,7 @@ func (c *ReadyChecker) serviceReady(s *corev1.Service) bool {
 		}
 
 		if s.Status.LoadBalancer.Ingress == nil {
-			c.log("Service does not have load balancer ingress IP address: %s/%s", s.GetNamespace(), s.GetName())
+			c.notReady("Service", s, "no load balancer ingress IP address")
 			return false
 		}
 	}
@@ -281,7 +328,7 @@ func (c *ReadyChecker) serviceReady(s *corev1.Service) bool {
 
 func (c *ReadyChecker) volumeReady(v *corev1.PersistentVolumeClaim) bool {
 	if v.Status.Phase != corev1.ClaimBound {
-		c.log("PersistentVolumeClaim is not bound: %s/%s", v.GetNamespace(), v.GetName())
+		c.notReady("PersistentVolumeClaim", v, "not bound")
 		return false
 	}
 	return true
@@ -294,13 +341,13 @@ func (c *ReadyChecker) deploymentReady(rs *appsv1.ReplicaSet, dep *appsv1.Deploy
 	}
 	// Verify the generation observed by the deployment controller matches the spec generation
 	if dep.Status.ObservedGeneration != dep.ObjectMeta.Generation {
-		c.log("Deployment is not ready: %s/%s. observedGeneration (%d) does not match spec generation (%d).", dep.Namespace, dep.Name, dep.Status.ObservedGeneration, dep.ObjectMeta.Generation)
+		c.notReady("Deployment", dep, "observedGeneration (%d) does not match spec generation (%d)", dep.Status.ObservedGeneration, dep.ObjectMeta.Generation)
 		return false
 	}
 
 	expectedReady := *dep.Spec.Replicas - deploymentutil.MaxUnavailable(*dep)
 	if !(rs.Status.ReadyReplicas >= expectedReady) {
-		c.log("Deployment is not ready: %s/%s. %d out of %d expected pods are ready", dep.Namespace, dep.Name, rs.Status.ReadyReplicas, expectedReady)
+		c.notReady("Deployment", dep, "%d/%d expected pods ready", rs.Status.ReadyReplicas, expectedReady)
 		return false
 	}
 	return true
@@ -309,7 +356,7 @@ func (c *ReadyChecker) deploymentReady(rs *appsv1.ReplicaSet, dep *appsv1.Deploy
 func (c *ReadyChecker) daemonSetReady(ds *appsv1.DaemonSet) bool {
 	// Verify the generation observed by the daemonSet controller matches the spec generation
 	if ds.Status.ObservedGeneration != ds.ObjectMeta.Generation {
-		c.log("DaemonSet is not ready: %s/%s. observedGeneration (%d) does not match spec generation (%d).", ds.Namespace, ds.Name, ds.Status.ObservedGeneration, ds.ObjectMeta.Generation)
+		c.notReady("DaemonSet", ds, "observedGeneration (%d) does not match spec generation (%d)", ds.Status.ObservedGeneration, ds.ObjectMeta.Generation)
 		return false
 	}
 
@@ -320,7 +367,7 @@ func (c *ReadyChecker) daemonSetReady(ds *appsv1.DaemonSet) bool {
 
 	// Make sure all the updated pods have been scheduled
 	if ds.Status.UpdatedNumberScheduled != ds.Status.DesiredNumberScheduled {
-		c.log("DaemonSet is not ready: %s/%s. %d out of %d expected pods have been scheduled", ds.Namespace, ds.Name, ds.Status.UpdatedNumberScheduled, ds.Status.DesiredNumberScheduled)
+		c.notReady("DaemonSet", ds, "%d/%d expected pods scheduled", ds.Status.UpdatedNumberScheduled, ds.Status.DesiredNumberScheduled)
 		return false
 	}
 	maxUnavailable, err := intstr.GetScaledValueFromIntOrPercent(ds.Spec.UpdateStrategy.RollingUpdate.MaxUnavailable, int(ds.Status.DesiredNumberScheduled), true)
@@ -333,7 +380,7 @@ func (c *ReadyChecker) daemonSetReady(ds *appsv1.DaemonSet) bool {
 
 	expectedReady := int(ds.Status.DesiredNumberScheduled) - maxUnavailable
 	if !(int(ds.Status.NumberReady) >= expectedReady) {
-		c.log("DaemonSet is not ready: %s/%s. %d out of %d expected pods are ready", ds.Namespace, ds.Name, ds.Status.NumberReady, expectedReady)
+		c.notReady("DaemonSet", ds, "%d/%d expected pods ready", ds.Status.NumberReady, expectedReady)
 		return false
 	}
 	return true
@@ -385,7 +432,7 @@ func (c *ReadyChecker) crdReady(crd apiextv1.CustomResourceDefinition) bool {
 func (c *ReadyChecker) statefulSetReady(sts *appsv1.StatefulSet) bool {
 	// Verify the generation observed by the statefulSet controller matches the spec generation
 	if sts.Status.ObservedGeneration != sts.ObjectMeta.Generation {
-		c.log("StatefulSet is not ready: %s/%s. observedGeneration (%d) does not match spec generation (%d).", sts.Namespace, sts.Name, sts.Status.ObservedGeneration, sts.ObjectMeta.Generation)
+		c.notReady("StatefulSet", sts, "observedGeneration (%d) does not match spec generation (%d)", sts.Status.ObservedGeneration, sts.ObjectMeta.Generation)
 		return false
 	}
 
@@ -417,19 +464,19 @@ func (c *ReadyChecker) statefulSetReady(sts *appsv1.StatefulSet) bool {
 
 	// Make sure all the updated pods have been scheduled
 	if int(sts.Status.UpdatedReplicas) < expectedReplicas {
-		c.log("StatefulSet is not ready: %s/%s. %d out of %d expected pods have been scheduled", sts.Namespace, sts.Name, sts.Status.UpdatedReplicas, expectedReplicas)
+		c.notReady("StatefulSet", sts, "%d/%d expected pods scheduled", sts.Status.UpdatedReplicas, expectedReplicas)
 		return false
 	}
 
 	if int(sts.Status.ReadyReplicas) != replicas {
-		c.log("StatefulSet is not ready: %s/%s. %d out of %d expected pods are ready", sts.Namespace, sts.Name, sts.Status.ReadyReplicas, replicas)
+		c.notReady("StatefulSet", sts, "%d/%d expected pods ready", sts.Status.ReadyReplicas, replicas)
 		return false
 	}
 	// This check only makes sense when all partitions are being upgraded otherwise during a
 	// partitioned rolling upgrade, this condition will never evaluate to true, leading to
 	// error.
 	if partition == 0 && sts.Status.CurrentRevision != sts.Status.UpdateRevision {
-		c.log("StatefulSet is not ready: %s/%s. currentRevision %s does not yet match updateRevision %s", sts.Namespace, sts.Name, sts.Status.CurrentRevision, sts.Status.UpdateRevision)
+		c.notReady("StatefulSet", sts, "currentRevision %s does not yet match updateRevision %s", sts.Status.CurrentRevision, sts.Status.UpdateRevision)
 		return false
 	}
 
@@ -440,7 +487,7 @@ func (c *ReadyChecker) statefulSetReady(sts *appsv1.StatefulSet) bool {
 func (c *ReadyChecker) replicationControllerReady(rc *corev1.ReplicationController) bool {
 	// Verify the generation observed by the replicationController controller matches the spec generation
 	if rc.Status.ObservedGeneration != rc.ObjectMeta.Generation {
-		c.log("ReplicationController is not ready: %s/%s. observedGeneration (%d) does not match spec generation (%d).", rc.Namespace, rc.Name, rc.Status.ObservedGeneration, rc.ObjectMeta.Generation)
+		c.notReady("ReplicationController", rc, "observedGeneration (%d) does not match spec generation (%d)", rc.Status.ObservedGeneration, rc.ObjectMeta.Generation)
 		return false
 	}
 	return true
@@ -449,7 +496,7 @@ func (c *ReadyChecker) replicationControllerReady(rc *corev1.ReplicationControll
 func (c *ReadyChecker) replicaSetReady(rs *appsv1.ReplicaSet) bool {
 	// Verify the generation observed by the replicaSet controller matches the spec generation
 	if rs.Status.ObservedGeneration != rs.ObjectMeta.Generation {
-		c.log("ReplicaSet is not ready: %s/%s. observedGeneration (%d) does not match spec generation (%d).", rs.Namespace, rs.Name, rs.Status.ObservedGeneration, rs.ObjectMeta.Generation)
+		c.notReady("ReplicaSet", rs, "observedGeneration (%d) does not match spec generation (%d)", rs.Status.ObservedGeneration, rs.ObjectMeta.Generation)
 		return false
 	}
 	return true
@@ -461,3 +508,115 @@ func getPods(ctx context.Context, client kubernetes.Interface, namespace, select
 	})
 	return list.Items, err
 }
+
+// customResourceReady checks the readiness of a custom resource from its
+// status. The resource is not ready while status.observedGeneration is behind
+// metadata.generation. Then, the ReadyJSONPathAnnotation or the
+// ReadyConditionAnnotation of the resource decide, if set. Otherwise, the
+// resource is ready when its Ready condition, or else its Available
+// condition, is True. A resource with neither condition is ready.
+func (c *ReadyChecker) customResourceReady(obj *unstructured.Unstructured) (bool, error) {
+	observed, found, err := unstructured.NestedInt64(obj.Object, "status", "observedGeneration")
+	if err == nil && found && observed < obj.GetGeneration() {
+		c.notReady(obj.GetKind(), obj, "observedGeneration (%d) does not match generation (%d)", observed, obj.GetGeneration())
+		return false, nil
+	}
+
+	annotations := obj.GetAnnotations()
+	if expr, ok := annotations[ReadyJSONPathAnnotation]; ok {
+		ready, err := jsonPathReady(obj, expr)
+		if err != nil {
+			return false, fmt.Errorf("invalid %s annotation on %s/%s: %w", ReadyJSONPathAnnotation, obj.GetNamespace(), obj.GetName(), err)
+		}
+		if !ready {
+			c.notReady(obj.GetKind(), obj, "%s does not match", expr)
+		}
+		return ready, nil
+	}
+
+	conditions := statusConditions(obj)
+	if conditionType, ok := annotations[ReadyConditionAnnotation]; ok {
+		if conditions[conditionType] != string(metav1.ConditionTrue) {
+			c.notReady(obj.GetKind(), obj, "condition %s is not True", conditionType)
+			return false, nil
+		}
+		return true, nil
+	}
+	for _, conditionType := range []string{"Ready", "Available"} {
+		status, ok := conditions[conditionType]
+		if !ok {
+			continue
+		}
+		if status != string(metav1.ConditionTrue) {
+			c.notReady(obj.GetKind(), obj, "condition %s is %s", conditionType, status)
+			return false, nil
+		}
+		return true, nil
+	}
+	return true, nil
+}
+
+// statusConditions returns the status of each condition of status.conditions
+// by type.
+func statusConditions(obj *unstructured.Unstructured) map[string]string {
+	conditions := map[string]string{}
+	list, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, item := range list {
+		condition, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		conditionType, _, _ := unstructured.NestedString(condition, "type")
+		status, _, _ := unstructured.NestedString(condition, "status")
+		if conditionType != "" {
+			conditions[conditionType] = status
+		}
+	}
+	return conditions
+}
+
+// jsonPathReady evaluates a JSONPath expression against an object, optionally
+// followed by '=' and the expected result, as with 'kubectl wait --for'.
+func jsonPathReady(obj *unstructured.Unstructured, expr string) (bool, error) {
+	want, hasWant := "", false
+	if i := strings.LastIndex(expr, "}"); i >= 0 && strings.HasPrefix(expr[i+1:], "=") {
+		expr, want, hasWant = expr[:i+1], expr[i+2:], true
+	}
+	j := jsonpath.New(ReadyJSONPathAnnotation).AllowMissingKeys(true)
+	if err := j.Parse(expr); err != nil {
+		return false, err
+	}
+	var buf bytes.Buffer
+	if err := j.Execute(&buf, obj.Object); err != nil {
+		return false, err
+	}
+	got := strings.TrimSpace(buf.String())
+	if !hasWant {
+		return got != "", nil
+	}
+	return got == want, nil
+}
+
+// MeetsCondition reports whether an object meets a condition written as with
+// 'kubectl wait --for': either "condition=TYPE" for a status condition that
+// must be True, or "jsonpath=EXPR" for a JSONPath expression, optionally
+// followed by '=' and the expected result.
+func MeetsCondition(obj runtime.Object, condition string) (bool, error) {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return false, err
+		}
+		u = &unstructured.Unstructured{Object: content}
+	}
+	switch {
+	case strings.HasPrefix(condition, "condition="):
+		conditionType := strings.TrimPrefix(condition, "condition=")
+		return statusConditions(u)[conditionType] == string(metav1.ConditionTrue), nil
+	case strings.HasPrefix(condition, "jsonpath="):
+		return jsonPathReady(u, strings.TrimPrefix(condition, "jsonpath="))
+	default:
+		return false, fmt.Errorf("invalid condition %q: must be condition=TYPE or jsonpath=EXPR", condition)
+	}
+}
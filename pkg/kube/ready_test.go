@@ -23,8 +23,10 @@ import (
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/cli-runtime/pkg/resource"
 	"k8s.io/client-go/kubernetes/fake"
 )
 
@@ -521,6 +523,189 @@ func Test_ReadyChecker_serviceReady(t *testing.T) {
 	}
 }
 
+func Test_ReadyChecker_customResourceReady(t *testing.T) {
+	tests := []struct {
+		name        string
+		generation  int64
+		status      map[string]interface{}
+		annotations map[string]string
+		want        bool
+		wantErr     bool
+	}{
+		{
+			name: "custom resource without status is ready",
+			want: true,
+		},
+		{
+			name:   "custom resource with a true Ready condition is ready",
+			status: newCustomResourceStatus(0, "Ready", "True"),
+			want:   true,
+		},
+		{
+			name:   "custom resource with a false Ready condition is not ready",
+			status: newCustomResourceStatus(0, "Ready", "False"),
+			want:   false,
+		},
+		{
+			name:   "custom resource with a true Available condition is ready",
+			status: newCustomResourceStatus(0, "Available", "True"),
+			want:   true,
+		},
+		{
+			name:   "custom resource with an unknown Available condition is not ready",
+			status: newCustomResourceStatus(0, "Available", "Unknown"),
+			want:   false,
+		},
+		{
+			name:   "custom resource with other conditions is ready",
+			status: newCustomResourceStatus(0, "Issuing", "False"),
+			want:   true,
+		},
+		{
+			name:       "custom resource with an old observed generation is not ready",
+			generation: 2,
+			status:     newCustomResourceStatus(1, "Ready", "True"),
+			want:       false,
+		},
+		{
+			name:       "custom resource with the current observed generation is ready",
+			generation: 2,
+			status:     newCustomResourceStatus(2, "Ready", "True"),
+			want:       true,
+		},
+		{
+			name:        "custom resource with a true annotated condition is ready",
+			status:      newCustomResourceStatus(0, "Synced", "True"),
+			annotations: map[string]string{ReadyConditionAnnotation: "Synced"},
+			want:        true,
+		},
+		{
+			name:        "custom resource without the annotated condition is not ready",
+			status:      newCustomResourceStatus(0, "Ready", "True"),
+			annotations: map[string]string{ReadyConditionAnnotation: "Synced"},
+			want:        false,
+		},
+		{
+			name:        "custom resource matching the annotated JSONPath is ready",
+			status:      map[string]interface{}{"phase": "Running"},
+			annotations: map[string]string{ReadyJSONPathAnnotation: "{.status.phase}=Running"},
+			want:        true,
+		},
+		{
+			name:        "custom resource not matching the annotated JSONPath is not ready",
+			status:      map[string]interface{}{"phase": "Pending"},
+			annotations: map[string]string{ReadyJSONPathAnnotation: "{.status.phase}=Running"},
+			want:        false,
+		},
+		{
+			name:        "custom resource matching an annotated JSONPath filter is ready",
+			status:      newCustomResourceStatus(0, "Synced", "True"),
+			annotations: map[string]string{ReadyJSONPathAnnotation: `{.status.conditions[?(@.type=="Synced")].status}=True`},
+			want:        true,
+		},
+		{
+			name:        "custom resource with a result for the annotated JSONPath is ready",
+			status:      map[string]interface{}{"endpoint": "db.example.com"},
+			annotations: map[string]string{ReadyJSONPathAnnotation: "{.status.endpoint}"},
+			want:        true,
+		},
+		{
+			name:        "custom resource without a result for the annotated JSONPath is not ready",
+			annotations: map[string]string{ReadyJSONPathAnnotation: "{.status.endpoint}"},
+			want:        false,
+		},
+		{
+			name:        "custom resource with an invalid annotated JSONPath",
+			annotations: map[string]string{ReadyJSONPathAnnotation: "{.status.phase"},
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+			obj.SetAPIVersion("example.com/v1")
+			obj.SetKind("Database")
+			obj.SetNamespace(defaultNamespace)
+			obj.SetName("foo")
+			obj.SetGeneration(tt.generation)
+			obj.SetAnnotations(tt.annotations)
+			if tt.status != nil {
+				obj.Object["status"] = tt.status
+			}
+
+			c := NewReadyChecker(fake.NewSimpleClientset(), nil)
+			got, err := c.customResourceReady(obj)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("customResourceReady() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("customResourceReady() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMeetsCondition(t *testing.T) {
+	deployment := newDeployment("foo", 1, 1, 0, true)
+	deployment.Status.Conditions = []appsv1.DeploymentCondition{{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue}}
+	deployment.Status.ReadyReplicas = 1
+
+	tests := []struct {
+		name      string
+		condition string
+		want      bool
+		wantErr   bool
+	}{
+		{name: "true condition", condition: "condition=Available", want: true},
+		{name: "missing condition", condition: "condition=Progressing", want: false},
+		{name: "matching JSONPath", condition: "jsonpath={.status.readyReplicas}=1", want: true},
+		{name: "other JSONPath result", condition: "jsonpath={.status.readyReplicas}=2", want: false},
+		{name: "invalid JSONPath", condition: "jsonpath={.status", wantErr: true},
+		{name: "unknown condition", condition: "ready", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MeetsCondition(deployment, tt.condition)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("MeetsCondition() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("MeetsCondition() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_ReadyChecker_IsReady_unhandledNativeKind(t *testing.T) {
+	info := &resource.Info{
+		Name:      "foo",
+		Namespace: defaultNamespace,
+		Object:    &corev1.ConfigMap{TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"}},
+	}
+	c := NewReadyChecker(fake.NewSimpleClientset(), nil)
+	ready, err := c.IsReady(context.TODO(), info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ready {
+		t.Error("expected a ConfigMap to always be ready")
+	}
+}
+
+func newCustomResourceStatus(observedGeneration int64, conditionType, status string) map[string]interface{} {
+	s := map[string]interface{}{
+		"conditions": []interface{}{
+			map[string]interface{}{"type": conditionType, "status": status},
+		},
+	}
+	if observedGeneration > 0 {
+		s["observedGeneration"] = observedGeneration
+	}
+	return s
+}
+
 func newStatefulSetWithUpdateRevision(name string, replicas, partition, readyReplicas, updatedReplicas int, updateRevision string, generationInSync bool) *appsv1.StatefulSet {
 	ss := newStatefulSet(name, replicas, partition, readyReplicas, updatedReplicas, generationInSync)
 	ss.Status.UpdateRevision = updateRevision
@@ -38,10 +38,26 @@ import (
 	"k8s.io/apimachinery/pkg/util/wait"
 )
 
+// WaitEvent reports the readiness of a resource while waiting for it.
+type WaitEvent struct {
+	// Kind, Namespace and Name identify the resource.
+	Kind      string
+	Namespace string
+	Name      string
+	// Ready is true once the resource is ready.
+	Ready bool
+	// Reason is why the resource is not ready yet, such as
+	// "2/3 expected pods ready".
+	Reason string
+}
+
 type waiter struct {
 	c       ReadyChecker
 	timeout time.Duration
 	log     func(string, ...interface{})
+	// progress, if set, is called each time the readiness of a resource
+	// changes. All the resources are then checked on each poll.
+	progress func(WaitEvent)
 }
 
 // waitForResources polls to get the current status of all pods, PVCs, Services and
@@ -56,9 +72,11 @@ func (w *waiter) waitForResources(created ResourceList) error {
 	for i := range numberOfErrors {
 		numberOfErrors[i] = 0
 	}
+	reported := make([]*WaitEvent, len(created))
 
 	return wait.PollUntilContextCancel(ctx, 2*time.Second, true, func(ctx context.Context) (bool, error) {
 		waitRetries := 30
+		allReady := true
 		for i, v := range created {
 			ready, err := w.c.IsReady(ctx, v)
 
@@ -69,17 +87,51 @@ func (w *waiter) waitForResources(created ResourceList) error {
 					return false, err
 				}
 				w.log("Retrying as current number of retries %d less than max number of retries %d", numberOfErrors[i]-1, waitRetries)
+				w.report(reported, i, v, false, err.Error())
 				return false, nil
 			}
 			numberOfErrors[i] = 0
 			if !ready {
-				return false, err
+				reason := w.c.reason
+				if err != nil {
+					reason = err.Error()
+				}
+				w.report(reported, i, v, false, reason)
+				if err != nil || w.progress == nil {
+					return false, err
+				}
+				allReady = false
+				continue
 			}
+			w.report(reported, i, v, true, "")
 		}
-		return true, nil
+		return allReady, nil
 	})
 }
 
+// report calls the progress function if the readiness of the i-th resource
+// changed since it was last reported.
+func (w *waiter) report(reported []*WaitEvent, i int, v *resource.Info, ready bool, reason string) {
+	if w.progress == nil {
+		return
+	}
+	e := WaitEvent{
+		Kind:      v.Object.GetObjectKind().GroupVersionKind().Kind,
+		Namespace: v.Namespace,
+		Name:      v.Name,
+		Ready:     ready,
+		Reason:    reason,
+	}
+	if v.Mapping != nil {
+		e.Kind = v.Mapping.GroupVersionKind.Kind
+	}
+	if reported[i] != nil && *reported[i] == e {
+		return
+	}
+	reported[i] = &e
+	w.progress(e)
+}
+
 func (w *waiter) isRetryableError(err error, resource *resource.Info) bool {
 	if err == nil {
 		return false
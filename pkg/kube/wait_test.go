@@ -0,0 +1,64 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestWaitForResourcesProgress(t *testing.T) {
+	ready := newPodWithCondition("ready", corev1.ConditionTrue)
+	pending := newPodWithCondition("pending", corev1.ConditionFalse)
+	client := fake.NewSimpleClientset()
+	var resources ResourceList
+	for _, pod := range []*corev1.Pod{pending, ready} {
+		pod.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"}
+		if _, err := client.CoreV1().Pods(defaultNamespace).Create(context.TODO(), pod, metav1.CreateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+		resources = append(resources, &resource.Info{Name: pod.Name, Namespace: defaultNamespace, Object: pod})
+	}
+
+	var events []WaitEvent
+	w := waiter{
+		c:        NewReadyChecker(client, nil),
+		log:      nopLogger,
+		timeout:  100 * time.Millisecond,
+		progress: func(e WaitEvent) { events = append(events, e) },
+	}
+	if err := w.waitForResources(resources); err == nil {
+		t.Fatal("expected the wait to time out")
+	}
+
+	// Each resource is reported once, since its readiness does not change,
+	// including the resources after the first one that is not ready.
+	expect := []WaitEvent{
+		{Kind: "Pod", Namespace: defaultNamespace, Name: "pending", Reason: "0/1 containers ready"},
+		{Kind: "Pod", Namespace: defaultNamespace, Name: "ready", Ready: true},
+	}
+	if !reflect.DeepEqual(events, expect) {
+		t.Errorf("expected events %+v, got %+v", expect, events)
+	}
+}
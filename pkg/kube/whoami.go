@@ -0,0 +1,58 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube // import "helm.sh/helm/v3/pkg/kube"
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+)
+
+// WhoAmI returns the name of the user the client acts as in the cluster: the
+// user it impersonates or authenticates as with a username, as set in its
+// REST config, or else the user the cluster reports with a SelfSubjectReview.
+func (c *Client) WhoAmI() (string, error) {
+	var config *rest.Config
+	var err error
+	if getter, ok := c.Factory.(interface{ ToRESTConfig() (*rest.Config, error) }); ok {
+		config, err = getter.ToRESTConfig()
+	} else {
+		config, err = c.Factory.ToRawKubeConfigLoader().ClientConfig()
+	}
+	if err != nil {
+		return "", err
+	}
+	if config.Impersonate.UserName != "" {
+		return config.Impersonate.UserName, nil
+	}
+	if config.Username != "" {
+		return config.Username, nil
+	}
+
+	client, err := c.getKubeClient()
+	if err != nil {
+		return "", err
+	}
+	review, err := client.AuthenticationV1().SelfSubjectReviews().Create(context.Background(), &authenticationv1.SelfSubjectReview{}, metav1.CreateOptions{})
+	if err != nil {
+		return "", errors.Wrap(err, "could not review the user of the client")
+	}
+	return review.Status.UserInfo.Username, nil
+}
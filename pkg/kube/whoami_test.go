@@ -0,0 +1,69 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"k8s.io/client-go/rest/fake"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+)
+
+func TestWhoAmI(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/apis/authentication.k8s.io/v1/selfsubjectreviews" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"apiVersion":"authentication.k8s.io/v1","kind":"SelfSubjectReview","status":{"userInfo":{"username":"system:serviceaccount:ci:deployer"}}}`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name        string
+		username    string
+		impersonate string
+		want        string
+	}{
+		{name: "impersonated user", username: "admin", impersonate: "jane", want: "jane"},
+		{name: "basic auth user", username: "admin", want: "admin"},
+		{name: "reviewed user", want: "system:serviceaccount:ci:deployer"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(t)
+			tf := c.Factory.(*cmdtesting.TestFactory)
+			tf.Client = &fake.RESTClient{NegotiatedSerializer: unstructuredSerializer}
+			config := tf.ClientConfigVal
+			config.Host = server.URL
+			config.Username = tt.username
+			config.Impersonate.UserName = tt.impersonate
+
+			got, err := c.WhoAmI()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("expected user %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
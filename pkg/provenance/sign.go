@@ -57,8 +57,11 @@ type SumCollection struct {
 
 // Verification contains information about a verification operation.
 type Verification struct {
-	// SignedBy contains the entity that signed a chart.
+	// SignedBy contains the entity that signed a chart. It is only set for
+	// charts signed with PGP.
 	SignedBy *openpgp.Entity
+	// Identity describes who signed a chart, whatever the signing scheme.
+	Identity *Identity
 	// FileHash is the hash, prepended with the scheme, for the file that was verified.
 	FileHash string
 	// FileName is the name of the file that FileHash verifies.
@@ -69,7 +72,8 @@ type Verification struct {
 //
 // Signatories can be constructed from a PGP private key file using NewFromFiles
 // or they can be constructed manually by setting the Entity to a valid
-// PGP entity.
+// PGP entity. Other signing schemes are used by setting the Signer or the
+// Verifier, see LoadSigner and LoadVerifier.
 //
 // The same Signatory can be used to sign or validate multiple charts.
 type Signatory struct {
@@ -77,6 +81,11 @@ type Signatory struct {
 	Entity *openpgp.Entity
 	// The keyring for this instance of Helm. This is used for verification.
 	KeyRing openpgp.EntityList
+	// Signer signs charts with a scheme other than PGP. It takes precedence
+	// over the Entity.
+	Signer Signer
+	// Verifier verifies charts signed with a scheme other than PGP.
+	Verifier Verifier
 }
 
 // NewFromFiles constructs a new Signatory from the PGP key in the given filename.
@@ -150,6 +159,27 @@ func NewFromKeyring(keyringfile, id string) (*Signatory, error) {
 	return s, nil
 }
 
+// NewFromProvenance creates a Signatory that verifies the given provenance
+// file. The keyring is loaded according to the signing scheme of the file.
+func NewFromProvenance(keyringfile, provfile string) (*Signatory, error) {
+	data, err := os.ReadFile(provfile)
+	if err != nil {
+		return nil, err
+	}
+	scheme, err := SignatureScheme(data)
+	if err != nil {
+		return nil, err
+	}
+	if scheme == SchemePGP {
+		return NewFromKeyring(keyringfile, "")
+	}
+	v, err := LoadVerifier(scheme, keyringfile)
+	if err != nil {
+		return nil, err
+	}
+	return &Signatory{Verifier: v}, nil
+}
+
 // PassphraseFetcher returns a passphrase for decrypting keys.
 //
 // This is used as a callback to read a passphrase from some other location. The
@@ -169,6 +199,10 @@ type PassphraseFetcher func(name string) ([]byte, error)
 //
 // If the key is successfully unlocked, it will return nil.
 func (s *Signatory) DecryptKey(fn PassphraseFetcher) error {
+	// Keys of other signing schemes are decrypted by LoadSigner.
+	if s.Signer != nil {
+		return nil
+	}
 	if s.Entity == nil {
 		return errors.New("private key not found")
 	} else if s.Entity.PrivateKey == nil {
@@ -200,9 +234,12 @@ func (s *Signatory) DecryptKey(fn PassphraseFetcher) error {
 //
 // This takes the path to a chart archive file and a key, and it returns a clear signature.
 //
-// The Signatory must have a valid Entity.PrivateKey for this to work. If it does
-// not, an error will be returned.
+// The Signatory must have a valid Entity.PrivateKey or a Signer for this to
+// work. If it does not, an error will be returned.
 func (s *Signatory) ClearSign(chartpath string) (string, error) {
+	if s.Signer != nil {
+		return s.signMessage(chartpath)
+	}
 	if s.Entity == nil {
 		return "", errors.New("private key not found")
 	} else if s.Entity.PrivateKey == nil {
@@ -247,6 +284,51 @@ func (s *Signatory) ClearSign(chartpath string) (string, error) {
 	return out.String(), nil
 }
 
+// signMessage signs the message block of a chart with the Signer.
+func (s *Signatory) signMessage(chartpath string) (string, error) {
+	if fi, err := os.Stat(chartpath); err != nil {
+		return "", err
+	} else if fi.IsDir() {
+		return "", errors.New("cannot sign a directory")
+	}
+
+	b, err := messageBlock(chartpath)
+	if err != nil {
+		return "", err
+	}
+	sig, err := s.Signer.Sign(b.Bytes())
+	if err != nil {
+		return "", errors.Wrap(err, "failed to sign message block")
+	}
+	return encodeSignedMessage(s.Signer.Scheme(), b.Bytes(), sig), nil
+}
+
+// DetachSign creates an armored detached signature of the given data.
+//
+// It is used to sign content that does not live next to a chart archive, such
+// as the descriptor of a chart manifest in an OCI registry.
+//
+// The Signatory must have a valid Entity.PrivateKey for this to work.
+func (s *Signatory) DetachSign(data []byte) ([]byte, error) {
+	if s.Entity == nil {
+		return nil, errors.New("private key not found")
+	} else if s.Entity.PrivateKey == nil {
+		return nil, errors.New("provided key is not a private key. Try providing a keyring with secret keys")
+	}
+
+	out := bytes.NewBuffer(nil)
+	if err := openpgp.ArmoredDetachSign(out, s.Entity, bytes.NewReader(data), &defaultPGPConfig); err != nil {
+		return nil, errors.Wrap(err, "failed to sign data")
+	}
+	return out.Bytes(), nil
+}
+
+// VerifyDetached checks an armored detached signature of the given data
+// against the keyring, and returns the entity that signed it.
+func (s *Signatory) VerifyDetached(data, sig []byte) (*openpgp.Entity, error) {
+	return openpgp.CheckArmoredDetachedSignature(s.KeyRing, bytes.NewReader(data), bytes.NewReader(sig))
+}
+
 // Verify checks a signature and verifies that it is legit for a chart.
 func (s *Signatory) Verify(chartpath, sigpath string) (*Verification, error) {
 	ver := &Verification{}
@@ -259,23 +341,38 @@ func (s *Signatory) Verify(chartpath, sigpath string) (*Verification, error) {
 	}
 
 	// First verify the signature
-	sig, err := s.decodeSignature(sigpath)
+	data, err := os.ReadFile(sigpath)
 	if err != nil {
-		return ver, errors.Wrap(err, "failed to decode signature")
+		return ver, err
 	}
+	var message []byte
+	if scheme, err := SignatureScheme(data); err != nil {
+		return ver, errors.Wrap(err, "failed to decode signature")
+	} else if scheme != SchemePGP {
+		if message, ver.Identity, err = s.verifyMessage(data); err != nil {
+			return ver, err
+		}
+	} else {
+		sig, err := s.decodeSignature(sigpath)
+		if err != nil {
+			return ver, errors.Wrap(err, "failed to decode signature")
+		}
 
-	by, err := s.verifySignature(sig)
-	if err != nil {
-		return ver, err
+		by, err := s.verifySignature(sig)
+		if err != nil {
+			return ver, err
+		}
+		ver.SignedBy = by
+		ver.Identity = PGPIdentity(by)
+		message = sig.Plaintext
 	}
-	ver.SignedBy = by
 
 	// Second, verify the hash of the tarball.
 	sum, err := DigestFile(chartpath)
 	if err != nil {
 		return ver, err
 	}
-	_, sums, err := parseMessageBlock(sig.Plaintext)
+	_, sums, err := parseMessageBlock(message)
 	if err != nil {
 		return ver, err
 	}
@@ -295,6 +392,23 @@ func (s *Signatory) Verify(chartpath, sigpath string) (*Verification, error) {
 	return ver, nil
 }
 
+// verifyMessage verifies a provenance file signed with a scheme other than
+// PGP, and returns its message block and the identity of the signer.
+func (s *Signatory) verifyMessage(data []byte) ([]byte, *Identity, error) {
+	scheme, message, sig, err := decodeSignedMessage(data)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to decode signature")
+	}
+	if s.Verifier == nil || s.Verifier.Scheme() != scheme {
+		return nil, nil, errors.Errorf("no keyring loaded for signing scheme %q", scheme)
+	}
+	id, err := s.Verifier.Verify(message, sig)
+	if err != nil {
+		return nil, nil, err
+	}
+	return message, id, nil
+}
+
 func (s *Signatory) decodeSignature(filename string) (*clearsign.Block, error) {
 	data, err := os.ReadFile(filename)
 	if err != nil {
@@ -0,0 +1,109 @@
+/*
+Copyright The Helm Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provenance
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+const ed25519SignatureType = "ED25519 SIGNATURE"
+
+type ed25519Signer struct {
+	key ed25519.PrivateKey
+}
+
+type ed25519Verifier struct {
+	keys []ed25519.PublicKey
+}
+
+// loadEd25519Signer loads an ed25519 private key in PKCS #8 PEM form, such as
+// the one created by 'openssl genpkey -algorithm ed25519'.
+func loadEd25519Signer(keyfile string) (*ed25519Signer, error) {
+	data, err := os.ReadFile(keyfile)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "PRIVATE KEY" {
+		return nil, errors.Errorf("%s does not contain a PEM encoded private key", keyfile)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse private key %s", keyfile)
+	}
+	k, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, errors.Errorf("%s is not an ed25519 private key", keyfile)
+	}
+	return &ed25519Signer{key: k}, nil
+}
+
+// loadEd25519Verifier loads the trusted ed25519 public keys in PKIX PEM form,
+// such as the ones created by 'openssl pkey -pubout'.
+func loadEd25519Verifier(keyring string) (*ed25519Verifier, error) {
+	data, err := os.ReadFile(keyring)
+	if err != nil {
+		return nil, err
+	}
+	v := &ed25519Verifier{}
+	for block, rest := pem.Decode(data); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "PUBLIC KEY" {
+			continue
+		}
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse public key in %s", keyring)
+		}
+		if k, ok := key.(ed25519.PublicKey); ok {
+			v.keys = append(v.keys, k)
+		}
+	}
+	if len(v.keys) == 0 {
+		return nil, errors.Errorf("no ed25519 public keys found in %s", keyring)
+	}
+	return v, nil
+}
+
+func (s *ed25519Signer) Scheme() string { return SchemeEd25519 }
+
+func (s *ed25519Signer) Sign(message []byte) ([]byte, error) {
+	return pem.EncodeToMemory(&pem.Block{
+		Type:  ed25519SignatureType,
+		Bytes: ed25519.Sign(s.key, message),
+	}), nil
+}
+
+func (v *ed25519Verifier) Scheme() string { return SchemeEd25519 }
+
+func (v *ed25519Verifier) Verify(message, signature []byte) (*Identity, error) {
+	block, _ := pem.Decode(signature)
+	if block == nil || block.Type != ed25519SignatureType {
+		return nil, errors.New("ed25519 signature not found")
+	}
+	for _, k := range v.keys {
+		if ed25519.Verify(k, message, block.Bytes) {
+			return &Identity{Fingerprint: fmt.Sprintf("%X", sha256.Sum256(k))}, nil
+		}
+	}
+	return nil, errors.New("signature was not made by a trusted ed25519 key")
+}
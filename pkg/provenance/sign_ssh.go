@@ -0,0 +1,250 @@
+/*
+Copyright The Helm Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provenance
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/pem"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+)
+
+// SSH signatures follow the SSHSIG format of OpenSSH, so that provenance
+// files can be checked with 'ssh-keygen -Y verify -n helm'.
+const (
+	sshSignatureType  = "SSH SIGNATURE"
+	sshSigMagic       = "SSHSIG"
+	sshSigVersion     = 1
+	sshSigNamespace   = "helm"
+	sshSigHashSHA512  = "sha512"
+	sshSigHashSHA256  = "sha256"
+	sshSigCertOption  = "cert-authority"
+	sshSigNamespaceOp = "namespaces="
+)
+
+type sshSigner struct {
+	signer ssh.Signer
+}
+
+type sshVerifier struct {
+	signers []allowedSigner
+}
+
+// allowedSigner is an entry of an OpenSSH allowed_signers file.
+type allowedSigner struct {
+	principals []string
+	key        ssh.PublicKey
+}
+
+// sshSignature is the blob of an SSHSIG signature, after the magic preamble.
+type sshSignature struct {
+	Version       uint32
+	PublicKey     []byte
+	Namespace     string
+	Reserved      string
+	HashAlgorithm string
+	Signature     []byte
+}
+
+// sshSignedData is the data signed by an SSHSIG signature, after the magic preamble.
+type sshSignedData struct {
+	Namespace     string
+	Reserved      string
+	HashAlgorithm string
+	Hash          []byte
+}
+
+// loadSSHSigner loads an SSH private key, such as one created by ssh-keygen.
+func loadSSHSigner(keyfile string, fn PassphraseFetcher) (*sshSigner, error) {
+	data, err := os.ReadFile(keyfile)
+	if err != nil {
+		return nil, err
+	}
+	signer, err := ssh.ParsePrivateKey(data)
+	if _, ok := err.(*ssh.PassphraseMissingError); ok {
+		var p []byte
+		if p, err = fn(keyfile); err != nil {
+			return nil, err
+		}
+		signer, err = ssh.ParsePrivateKeyWithPassphrase(data, p)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse SSH private key %s", keyfile)
+	}
+	return &sshSigner{signer: signer}, nil
+}
+
+// loadSSHVerifier loads the trusted keys of an OpenSSH allowed_signers file.
+// Entries restricted to other namespaces and certificate authorities are ignored.
+func loadSSHVerifier(keyring string) (*sshVerifier, error) {
+	f, err := os.Open(keyring)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	v := &sshVerifier{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		principals, rest, _ := strings.Cut(line, " ")
+		key, _, options, _, err := ssh.ParseAuthorizedKey([]byte(rest))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse allowed signer %q in %s", principals, keyring)
+		}
+		if !sshSignerAllowed(options) {
+			continue
+		}
+		v.signers = append(v.signers, allowedSigner{principals: strings.Split(principals, ","), key: key})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(v.signers) == 0 {
+		return nil, errors.Errorf("no allowed signers found in %s", keyring)
+	}
+	return v, nil
+}
+
+// sshSignerAllowed checks the options of an allowed signer for Helm signatures.
+func sshSignerAllowed(options []string) bool {
+	for _, o := range options {
+		if strings.EqualFold(o, sshSigCertOption) {
+			return false
+		}
+		if strings.HasPrefix(strings.ToLower(o), sshSigNamespaceOp) {
+			namespaces := strings.Trim(o[len(sshSigNamespaceOp):], `"`)
+			found := false
+			for _, n := range strings.Split(namespaces, ",") {
+				if n == sshSigNamespace {
+					found = true
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// sshSignedMessage returns the data an SSHSIG signature of the message covers.
+func sshSignedMessage(hashAlgorithm string, message []byte) ([]byte, error) {
+	var hash []byte
+	switch hashAlgorithm {
+	case sshSigHashSHA512:
+		h := sha512.Sum512(message)
+		hash = h[:]
+	case sshSigHashSHA256:
+		h := sha256.Sum256(message)
+		hash = h[:]
+	default:
+		return nil, errors.Errorf("unsupported SSH signature hash algorithm %q", hashAlgorithm)
+	}
+	return append([]byte(sshSigMagic), ssh.Marshal(sshSignedData{
+		Namespace:     sshSigNamespace,
+		HashAlgorithm: hashAlgorithm,
+		Hash:          hash,
+	})...), nil
+}
+
+func (s *sshSigner) Scheme() string { return SchemeSSH }
+
+func (s *sshSigner) Sign(message []byte) ([]byte, error) {
+	signed, err := sshSignedMessage(sshSigHashSHA512, message)
+	if err != nil {
+		return nil, err
+	}
+
+	var sig *ssh.Signature
+	// RSA keys must not sign with SHA-1, which is the default of ssh.Signer.
+	if as, ok := s.signer.(ssh.AlgorithmSigner); ok && s.signer.PublicKey().Type() == ssh.KeyAlgoRSA {
+		sig, err = as.SignWithAlgorithm(rand.Reader, signed, ssh.KeyAlgoRSASHA512)
+	} else {
+		sig, err = s.signer.Sign(rand.Reader, signed)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	blob := append([]byte(sshSigMagic), ssh.Marshal(sshSignature{
+		Version:       sshSigVersion,
+		PublicKey:     s.signer.PublicKey().Marshal(),
+		Namespace:     sshSigNamespace,
+		HashAlgorithm: sshSigHashSHA512,
+		Signature:     ssh.Marshal(sig),
+	})...)
+	return pem.EncodeToMemory(&pem.Block{Type: sshSignatureType, Bytes: blob}), nil
+}
+
+func (v *sshVerifier) Scheme() string { return SchemeSSH }
+
+func (v *sshVerifier) Verify(message, signature []byte) (*Identity, error) {
+	block, _ := pem.Decode(signature)
+	if block == nil || block.Type != sshSignatureType {
+		return nil, errors.New("SSH signature not found")
+	}
+	if !bytes.HasPrefix(block.Bytes, []byte(sshSigMagic)) {
+		return nil, errors.New("invalid SSH signature")
+	}
+	var blob sshSignature
+	if err := ssh.Unmarshal(block.Bytes[len(sshSigMagic):], &blob); err != nil {
+		return nil, errors.Wrap(err, "invalid SSH signature")
+	}
+	if blob.Version != sshSigVersion {
+		return nil, errors.Errorf("unsupported SSH signature version %d", blob.Version)
+	}
+	if blob.Namespace != sshSigNamespace {
+		return nil, errors.Errorf("SSH signature was made for namespace %q, not %q", blob.Namespace, sshSigNamespace)
+	}
+
+	key, err := ssh.ParsePublicKey(blob.PublicKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid SSH signature key")
+	}
+	var sig ssh.Signature
+	if err := ssh.Unmarshal(blob.Signature, &sig); err != nil {
+		return nil, errors.Wrap(err, "invalid SSH signature")
+	}
+	if sig.Format == ssh.KeyAlgoRSA {
+		return nil, errors.New("SSH signatures using SHA-1 are not supported")
+	}
+	signed, err := sshSignedMessage(blob.HashAlgorithm, message)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, s := range v.signers {
+		if !bytes.Equal(s.key.Marshal(), key.Marshal()) {
+			continue
+		}
+		if err := key.Verify(signed, &sig); err != nil {
+			return nil, errors.Wrap(err, "SSH signature verification failed")
+		}
+		return &Identity{Names: s.principals, Fingerprint: ssh.FingerprintSHA256(key)}, nil
+	}
+	return nil, errors.Errorf("signing key %s is not an allowed signer", ssh.FingerprintSHA256(key))
+}
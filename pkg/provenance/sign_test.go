@@ -331,6 +331,40 @@ func TestVerify(t *testing.T) {
 	}
 }
 
+func TestDetachSign(t *testing.T) {
+	signer, err := NewFromFiles(testKeyfile, testPubfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := []byte(`{"digest":"sha256:e5ef611620fb97704d8751c16bab17fedb68883bfb0edc76f78a70e9173f9b55"}`)
+	sig, err := signer.DetachSign(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(sig), "-----BEGIN PGP SIGNATURE-----") {
+		t.Errorf("Expected an armored signature, got %q", sig)
+	}
+
+	if by, err := signer.VerifyDetached(data, sig); err != nil {
+		t.Errorf("Failed to verify detached signature. Err: %s", err)
+	} else if by == nil {
+		t.Error("No signing entity")
+	}
+
+	if _, err := signer.VerifyDetached(append(data, '\n'), sig); err == nil {
+		t.Error("Expected tampered data to fail verification")
+	}
+
+	pub, err := NewFromKeyring(testPubfile, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pub.DetachSign(data); err == nil {
+		t.Error("Expected signing without a private key to fail")
+	}
+}
+
 // readSumFile reads a file containing a sum generated by the UNIX shasum tool.
 func readSumFile(sumfile string) (string, error) {
 	data, err := os.ReadFile(sumfile)
@@ -0,0 +1,201 @@
+/*
+Copyright The Helm Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provenance
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+const x509SignatureType = "X509 SIGNATURE"
+
+type x509Signer struct {
+	key   crypto.Signer
+	chain []*x509.Certificate
+}
+
+type x509Verifier struct {
+	roots *x509.CertPool
+}
+
+// loadX509Signer loads a PEM file holding a private key and the certificate
+// chain of that key, leaf first. The chain is embedded in the signatures so
+// that they can be checked against the CA bundle alone.
+func loadX509Signer(keyfile string) (*x509Signer, error) {
+	data, err := os.ReadFile(keyfile)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &x509Signer{}
+	for block, rest := pem.Decode(data); block != nil; block, rest = pem.Decode(rest) {
+		switch block.Type {
+		case "CERTIFICATE":
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to parse certificate in %s", keyfile)
+			}
+			s.chain = append(s.chain, cert)
+		case "PRIVATE KEY", "RSA PRIVATE KEY", "EC PRIVATE KEY":
+			if s.key, err = parsePrivateKey(block); err != nil {
+				return nil, errors.Wrapf(err, "failed to parse private key in %s", keyfile)
+			}
+		}
+	}
+	if s.key == nil {
+		return nil, errors.Errorf("%s does not contain a private key", keyfile)
+	}
+	if len(s.chain) == 0 {
+		return nil, errors.Errorf("%s does not contain a certificate", keyfile)
+	}
+	if pub, ok := s.key.Public().(interface{ Equal(crypto.PublicKey) bool }); !ok || !pub.Equal(s.chain[0].PublicKey) {
+		return nil, errors.Errorf("the first certificate in %s does not match its private key", keyfile)
+	}
+	return s, nil
+}
+
+func parsePrivateKey(block *pem.Block) (crypto.Signer, error) {
+	var key interface{}
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, errors.Errorf("unsupported private key type %T", key)
+	}
+	return signer, nil
+}
+
+// loadX509Verifier loads a bundle of trusted CA certificates.
+func loadX509Verifier(keyring string) (*x509Verifier, error) {
+	data, err := os.ReadFile(keyring)
+	if err != nil {
+		return nil, err
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(data) {
+		return nil, errors.Errorf("no CA certificates found in %s", keyring)
+	}
+	return &x509Verifier{roots: roots}, nil
+}
+
+func (s *x509Signer) Scheme() string { return SchemeX509 }
+
+func (s *x509Signer) Sign(message []byte) ([]byte, error) {
+	digest, opts := message, crypto.SignerOpts(crypto.Hash(0))
+	if _, ok := s.key.Public().(ed25519.PublicKey); !ok {
+		h := sha256.Sum256(message)
+		digest, opts = h[:], crypto.SHA256
+	}
+	sig, err := s.key.Sign(rand.Reader, digest, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	if err := pem.Encode(&out, &pem.Block{Type: x509SignatureType, Bytes: sig}); err != nil {
+		return nil, err
+	}
+	for _, cert := range s.chain {
+		if err := pem.Encode(&out, &pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}); err != nil {
+			return nil, err
+		}
+	}
+	return out.Bytes(), nil
+}
+
+func (v *x509Verifier) Scheme() string { return SchemeX509 }
+
+func (v *x509Verifier) Verify(message, signature []byte) (*Identity, error) {
+	var sig []byte
+	var chain []*x509.Certificate
+	for block, rest := pem.Decode(signature); block != nil; block, rest = pem.Decode(rest) {
+		switch block.Type {
+		case x509SignatureType:
+			sig = block.Bytes
+		case "CERTIFICATE":
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, errors.Wrap(err, "invalid signing certificate")
+			}
+			chain = append(chain, cert)
+		}
+	}
+	if sig == nil {
+		return nil, errors.New("X.509 signature not found")
+	}
+	if len(chain) == 0 {
+		return nil, errors.New("X.509 signature does not include the signing certificate")
+	}
+
+	leaf := chain[0]
+	intermediates := x509.NewCertPool()
+	for _, cert := range chain[1:] {
+		intermediates.AddCert(cert)
+	}
+	if _, err := leaf.Verify(x509.VerifyOptions{
+		Roots:         v.roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+	}); err != nil {
+		return nil, errors.Wrap(err, "signing certificate is not trusted")
+	}
+
+	var algorithm x509.SignatureAlgorithm
+	switch leaf.PublicKey.(type) {
+	case ed25519.PublicKey:
+		algorithm = x509.PureEd25519
+	case *ecdsa.PublicKey:
+		algorithm = x509.ECDSAWithSHA256
+	case *rsa.PublicKey:
+		algorithm = x509.SHA256WithRSA
+	default:
+		return nil, errors.Errorf("unsupported signing certificate key type %T", leaf.PublicKey)
+	}
+	if err := leaf.CheckSignature(algorithm, message, sig); err != nil {
+		return nil, errors.Wrap(err, "X.509 signature verification failed")
+	}
+
+	id := &Identity{Fingerprint: fmt.Sprintf("%X", sha256.Sum256(leaf.Raw))}
+	if leaf.Subject.CommonName != "" {
+		id.Names = append(id.Names, leaf.Subject.CommonName)
+	}
+	id.Names = append(id.Names, leaf.EmailAddresses...)
+	for _, u := range leaf.URIs {
+		id.Names = append(id.Names, u.String())
+	}
+	return id, nil
+}
@@ -0,0 +1,182 @@
+/*
+Copyright The Helm Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provenance
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/openpgp" //nolint
+)
+
+// Signing schemes supported for provenance files.
+const (
+	// SchemePGP signs with an OpenPGP key from a keyring. It is the default.
+	SchemePGP = "pgp"
+	// SchemeEd25519 signs with a raw ed25519 key in PKCS #8 PEM form. The
+	// keyring for verification holds the trusted public keys in PKIX PEM form.
+	SchemeEd25519 = "ed25519"
+	// SchemeSSH signs with an SSH key, in the format of 'ssh-keygen -Y sign'.
+	// The keyring for verification is an 'allowed_signers' file.
+	SchemeSSH = "ssh"
+	// SchemeX509 signs with the key of an X.509 certificate. The keyring for
+	// verification is a bundle of the trusted CA certificates.
+	SchemeX509 = "x509"
+)
+
+// signedMessageHeader starts provenance files that are not signed with PGP.
+const signedMessageHeader = "-----BEGIN HELM SIGNED MESSAGE-----\n"
+
+// Signer creates detached signatures of provenance message blocks.
+type Signer interface {
+	// Scheme returns the signing scheme of the signer.
+	Scheme() string
+	// Sign returns an armored detached signature of the message.
+	Sign(message []byte) ([]byte, error)
+}
+
+// Verifier checks detached signatures created by a Signer of the same scheme.
+type Verifier interface {
+	// Scheme returns the signing scheme of the verifier.
+	Scheme() string
+	// Verify checks the signature of the message against the trusted keys,
+	// and returns the identity of the signer.
+	Verify(message, signature []byte) (*Identity, error)
+}
+
+// Identity describes who signed a chart, whatever the signing scheme.
+type Identity struct {
+	// Names of the signer, such as PGP user IDs, SSH principals or the
+	// subject of an X.509 certificate.
+	Names []string
+	// Fingerprint of the signing key or certificate.
+	Fingerprint string
+}
+
+// PGPIdentity returns the identity of a PGP entity.
+func PGPIdentity(e *openpgp.Entity) *Identity {
+	id := &Identity{Fingerprint: fmt.Sprintf("%X", e.PrimaryKey.Fingerprint)}
+	for name := range e.Identities {
+		id.Names = append(id.Names, name)
+	}
+	sort.Strings(id.Names)
+	return id
+}
+
+// LoadSigner loads the private key at keyfile for the given signing scheme.
+//
+// The PassphraseFetcher is only called when the key is encrypted. PGP keys are
+// loaded from a keyring with NewFromKeyring instead.
+func LoadSigner(scheme, keyfile string, fn PassphraseFetcher) (Signer, error) {
+	switch scheme {
+	case SchemeEd25519:
+		return loadEd25519Signer(keyfile)
+	case SchemeSSH:
+		return loadSSHSigner(keyfile, fn)
+	case SchemeX509:
+		return loadX509Signer(keyfile)
+	case SchemePGP:
+		return nil, errors.New("PGP keys are loaded from a keyring")
+	}
+	return nil, errors.Errorf("unknown signing scheme %q", scheme)
+}
+
+// LoadVerifier loads the trusted keys at keyring for the given signing scheme.
+func LoadVerifier(scheme, keyring string) (Verifier, error) {
+	switch scheme {
+	case SchemeEd25519:
+		return loadEd25519Verifier(keyring)
+	case SchemeSSH:
+		return loadSSHVerifier(keyring)
+	case SchemeX509:
+		return loadX509Verifier(keyring)
+	case SchemePGP:
+		return nil, errors.New("PGP keys are loaded from a keyring")
+	}
+	return nil, errors.Errorf("unknown signing scheme %q", scheme)
+}
+
+// SignatureScheme returns the signing scheme of the content of a provenance file.
+func SignatureScheme(data []byte) (string, error) {
+	if !bytes.HasPrefix(data, []byte(signedMessageHeader)) {
+		return SchemePGP, nil
+	}
+	scheme, _, _, err := decodeSignedMessage(data)
+	return scheme, err
+}
+
+// encodeSignedMessage wraps a message block and its signature in the format
+// of provenance files signed without PGP:
+//
+//	-----BEGIN HELM SIGNED MESSAGE-----
+//	Scheme: ssh
+//
+//	MESSAGE BLOCK
+//	-----BEGIN SSH SIGNATURE-----
+//	...
+//	-----END SSH SIGNATURE-----
+func encodeSignedMessage(scheme string, message, signature []byte) string {
+	var b strings.Builder
+	b.WriteString(signedMessageHeader)
+	fmt.Fprintf(&b, "Scheme: %s\n\n", scheme)
+	b.Write(message)
+	if !bytes.HasSuffix(message, []byte("\n")) {
+		b.WriteString("\n")
+	}
+	b.Write(signature)
+	return b.String()
+}
+
+// decodeSignedMessage splits a provenance file signed without PGP into its
+// signing scheme, message block and signature.
+func decodeSignedMessage(data []byte) (string, []byte, []byte, error) {
+	data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+	if !bytes.HasPrefix(data, []byte(signedMessageHeader)) {
+		return "", nil, nil, errors.New("signed message not found")
+	}
+	data = data[len(signedMessageHeader):]
+
+	end := bytes.Index(data, []byte("\n\n"))
+	if end < 0 {
+		return "", nil, nil, errors.New("signed message has no header")
+	}
+	var scheme string
+	for _, line := range strings.Split(string(data[:end]), "\n") {
+		if k, v, ok := strings.Cut(line, ":"); ok && strings.TrimSpace(k) == "Scheme" {
+			scheme = strings.TrimSpace(v)
+		}
+	}
+	if scheme == "" {
+		return "", nil, nil, errors.New("signed message does not name its signing scheme")
+	}
+	body := data[end+2:]
+
+	// The signature follows the sums, which come after the YAML document end
+	// marker of the chart metadata.
+	sums := bytes.Index(body, []byte("\n...\n"))
+	if sums < 0 {
+		return "", nil, nil, errors.New("message block must have at least two parts")
+	}
+	sig := bytes.Index(body[sums:], []byte("\n-----BEGIN "))
+	if sig < 0 {
+		return "", nil, nil, errors.New("signature block not found")
+	}
+	sig += sums + 1
+	return scheme, body[:sig], body[sig:], nil
+}
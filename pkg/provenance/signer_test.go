@@ -0,0 +1,250 @@
+/*
+Copyright The Helm Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provenance
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// testKeys writes a private key and a keyring trusting it for a signing
+// scheme, and returns their paths along with the name of the signer.
+type testKeys func(t *testing.T, dir string) (keyfile, keyring, name string)
+
+func ed25519TestKeys(t *testing.T, dir string) (string, string, string) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privDER, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyfile := writePEM(t, dir, "key.pem", &pem.Block{Type: "PRIVATE KEY", Bytes: privDER})
+	keyring := writePEM(t, dir, "keyring.pem", &pem.Block{Type: "PUBLIC KEY", Bytes: pubDER})
+	return keyfile, keyring, ""
+}
+
+func sshTestKeys(t *testing.T, dir string) (string, string, string) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := ssh.MarshalPrivateKey(priv, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sshPub, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyfile := writePEM(t, dir, "id_ed25519", block)
+	keyring := filepath.Join(dir, "allowed_signers")
+	signers := "# trusted chart signers\n" +
+		"other@example.com namespaces=\"git\" " + strings.TrimSpace(string(ssh.MarshalAuthorizedKey(sshPub))) + "\n" +
+		"helm@example.com namespaces=\"git,helm\" " + string(ssh.MarshalAuthorizedKey(sshPub))
+	if err := os.WriteFile(keyring, []byte(signers), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return keyfile, keyring, "helm@example.com"
+}
+
+func x509TestKeys(t *testing.T, dir string) (string, string, string) {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Helm Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, ca, ca, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ca, err = x509.ParseCertificate(caDER); err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf := &x509.Certificate{
+		SerialNumber:   big.NewInt(2),
+		Subject:        pkix.Name{CommonName: "Helm Test Signer"},
+		EmailAddresses: []string{"helm@example.com"},
+		NotBefore:      time.Now().Add(-time.Hour),
+		NotAfter:       time.Now().Add(time.Hour),
+		KeyUsage:       x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:    []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+	}
+	leafDER, err := x509.CreateCertificate(rand.Reader, leaf, ca, &key.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyfile := writePEM(t, dir, "signer.pem",
+		&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER},
+		&pem.Block{Type: "CERTIFICATE", Bytes: leafDER})
+	keyring := writePEM(t, dir, "ca.pem", &pem.Block{Type: "CERTIFICATE", Bytes: caDER})
+	return keyfile, keyring, "Helm Test Signer"
+}
+
+func writePEM(t *testing.T, dir, name string, blocks ...*pem.Block) string {
+	t.Helper()
+	var data []byte
+	for _, b := range blocks {
+		data = append(data, pem.EncodeToMemory(b)...)
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestSignSchemes(t *testing.T) {
+	for scheme, keys := range map[string]testKeys{
+		SchemeEd25519: ed25519TestKeys,
+		SchemeSSH:     sshTestKeys,
+		SchemeX509:    x509TestKeys,
+	} {
+		t.Run(scheme, func(t *testing.T) {
+			dir := t.TempDir()
+			keyfile, keyring, name := keys(t, dir)
+
+			s, err := LoadSigner(scheme, keyfile, func(string) ([]byte, error) {
+				t.Fatal("unexpected passphrase prompt")
+				return nil, nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			signer := &Signatory{Signer: s}
+			sig, err := signer.ClearSign(testChartfile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(sig, signedMessageHeader+"Scheme: "+scheme+"\n\n"+testMessageBlock) {
+				t.Errorf("expected message block to be in sig: %s", sig)
+			}
+
+			provfile := filepath.Join(dir, "hashtest-1.2.3.tgz.prov")
+			if err := os.WriteFile(provfile, []byte(sig), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			verifier, err := NewFromProvenance(keyring, provfile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ver, err := verifier.Verify(testChartfile, provfile)
+			if err != nil {
+				t.Fatalf("Failed to pass verify. Err: %s", err)
+			}
+			if ver.Identity == nil || ver.Identity.Fingerprint == "" {
+				t.Fatalf("Verification is missing the signer identity: %+v", ver)
+			}
+			if name != "" && (len(ver.Identity.Names) == 0 || ver.Identity.Names[0] != name) {
+				t.Errorf("Expected signer %q, got %v", name, ver.Identity.Names)
+			}
+			if ver.FileHash == "" || ver.FileName != filepath.Base(testChartfile) {
+				t.Errorf("Unexpected verification %+v", ver)
+			}
+
+			// A tampered message block fails verification.
+			tampered := strings.Replace(sig, "version: 1.2.3", "version: 1.2.4", 1)
+			if err := os.WriteFile(provfile, []byte(tampered), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := verifier.Verify(testChartfile, provfile); err == nil {
+				t.Error("Expected tampered provenance file to fail verification")
+			}
+
+			// A signature from another key fails verification.
+			_, otherKeyring, _ := keys(t, t.TempDir())
+			if err := os.WriteFile(provfile, []byte(sig), 0644); err != nil {
+				t.Fatal(err)
+			}
+			other, err := NewFromProvenance(otherKeyring, provfile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := other.Verify(testChartfile, provfile); err == nil {
+				t.Error("Expected a signature from an untrusted key to fail verification")
+			}
+
+			// A PGP keyring cannot verify the signature.
+			pgp, err := NewFromKeyring(testPubfile, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := pgp.Verify(testChartfile, provfile); err == nil {
+				t.Error("Expected a PGP keyring to fail verification")
+			}
+		})
+	}
+}
+
+func TestSignatureScheme(t *testing.T) {
+	data, err := os.ReadFile(testSigBlock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scheme, err := SignatureScheme(data); err != nil || scheme != SchemePGP {
+		t.Errorf("Expected PGP scheme, got %q (%v)", scheme, err)
+	}
+
+	msg := encodeSignedMessage(SchemeSSH, []byte(testMessageBlock), []byte("-----BEGIN SSH SIGNATURE-----\n"))
+	if scheme, err := SignatureScheme([]byte(msg)); err != nil || scheme != SchemeSSH {
+		t.Errorf("Expected SSH scheme, got %q (%v)", scheme, err)
+	}
+
+	if _, err := SignatureScheme([]byte(signedMessageHeader + "\n\n" + testMessageBlock)); err == nil {
+		t.Error("Expected an error for a signed message without a scheme")
+	}
+
+	if _, err := LoadSigner("rot13", "", nil); err == nil {
+		t.Error("Expected an error for an unknown signing scheme")
+	}
+}
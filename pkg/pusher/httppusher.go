@@ -0,0 +1,285 @@
+/*
+Copyright The Helm Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pusher
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/internal/tlsutil"
+	"helm.sh/helm/v3/internal/urlutil"
+	"helm.sh/helm/v3/internal/version"
+	"helm.sh/helm/v3/pkg/chart/loader"
+)
+
+const (
+	// UploadChartMuseum uploads charts with the ChartMuseum API, which adds
+	// them to the repository index.
+	UploadChartMuseum = "chartmuseum"
+	// UploadPut uploads charts with a PUT request next to the repository
+	// index. Updating the index is left to the server.
+	UploadPut = "put"
+)
+
+// ErrChartExists is returned when the pushed chart version already exists in
+// the repository and the push is not forced.
+var ErrChartExists = errors.New("chart version already exists")
+
+// HTTPPusher is the HTTP(/S) backend handler, which uploads charts to classic
+// chart repositories.
+type HTTPPusher struct {
+	opts options
+}
+
+// Push performs a Push from repo.Pusher.
+func (pusher *HTTPPusher) Push(chartRef, href string, options ...Option) error {
+	for _, opt := range options {
+		opt(&pusher.opts)
+	}
+	return pusher.push(chartRef, href)
+}
+
+// upload is a file uploaded to a repository.
+type upload struct {
+	field string
+	name  string
+	data  []byte
+}
+
+func (pusher *HTTPPusher) push(chartRef, href string) error {
+	stat, err := os.Stat(chartRef)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return errors.Errorf("%s: no such file", chartRef)
+		}
+		return err
+	}
+	if stat.IsDir() {
+		return errors.New("cannot push directory, must provide chart archive (.tgz)")
+	}
+
+	meta, err := loader.Load(chartRef)
+	if err != nil {
+		return err
+	}
+
+	chartBytes, err := os.ReadFile(chartRef)
+	if err != nil {
+		return err
+	}
+	files := []upload{{field: "chart", name: filepath.Base(chartRef), data: chartBytes}}
+
+	provRef := fmt.Sprintf("%s.prov", chartRef)
+	if _, err := os.Stat(provRef); err == nil {
+		provBytes, err := os.ReadFile(provRef)
+		if err != nil {
+			return err
+		}
+		files = append(files, upload{field: "prov", name: filepath.Base(provRef), data: provBytes})
+	}
+
+	client, err := pusher.httpClient(href)
+	if err != nil {
+		return err
+	}
+
+	switch pusher.opts.uploadAPI {
+	case "", UploadChartMuseum:
+		err = pusher.pushChartMuseum(client, href, files)
+	case UploadPut:
+		err = pusher.pushPut(client, href, files)
+	default:
+		return errors.Errorf("unknown upload API %q, expected %s or %s", pusher.opts.uploadAPI, UploadChartMuseum, UploadPut)
+	}
+	if errors.Is(err, ErrChartExists) {
+		return errors.Wrapf(err, "%s-%s in %s", meta.Metadata.Name, meta.Metadata.Version, href)
+	}
+	return err
+}
+
+// pushChartMuseum uploads the files in a single multipart request to the
+// ChartMuseum API. The API of a repository served under a path, such as
+// https://example.com/team, is https://example.com/api/team/charts.
+func (pusher *HTTPPusher) pushChartMuseum(client *http.Client, href string, files []upload) error {
+	u, err := url.Parse(href)
+	if err != nil {
+		return errors.Wrapf(err, "invalid repository URL %s", href)
+	}
+	u.Path = path.Join("/api", u.Path, "charts")
+	u.RawPath = ""
+	if pusher.opts.force {
+		q := u.Query()
+		q.Set("force", "true")
+		u.RawQuery = q.Encode()
+	}
+
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+	for _, f := range files {
+		part, err := w.CreateFormFile(f.field, f.name)
+		if err != nil {
+			return err
+		}
+		if _, err := part.Write(f.data); err != nil {
+			return err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	req, err := pusher.newRequest(http.MethodPost, u.String(), body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return checkResponse(resp)
+}
+
+// pushPut uploads each file with a PUT request next to the repository index.
+// Unless the push is forced, nothing is uploaded when any of the files already
+// exists, so that a version is never left half published.
+func (pusher *HTTPPusher) pushPut(client *http.Client, href string, files []upload) error {
+	targets := make([]string, len(files))
+	for i, f := range files {
+		target, err := urlutil.URLJoin(href, f.name)
+		if err != nil {
+			return err
+		}
+		targets[i] = target
+	}
+
+	if !pusher.opts.force {
+		for _, target := range targets {
+			req, err := pusher.newRequest(http.MethodHead, target, nil)
+			if err != nil {
+				return err
+			}
+			resp, err := client.Do(req)
+			if err != nil {
+				return err
+			}
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return ErrChartExists
+			}
+		}
+	}
+
+	for i, f := range files {
+		req, err := pusher.newRequest(http.MethodPut, targets[i], bytes.NewReader(f.data))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/octet-stream")
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		err = checkResponse(resp)
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (pusher *HTTPPusher) newRequest(method, href string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequest(method, href, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", version.GetUserAgent())
+	if pusher.opts.username != "" && pusher.opts.password != "" {
+		req.SetBasicAuth(pusher.opts.username, pusher.opts.password)
+	}
+	return req, nil
+}
+
+// checkResponse turns an unsuccessful upload response into an error. The
+// ChartMuseum API describes errors in a JSON body.
+func checkResponse(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	if resp.StatusCode == http.StatusConflict {
+		return ErrChartExists
+	}
+
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	var body struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(b, &body) == nil && body.Error != "" {
+		return errors.Errorf("failed to push to %s : %s: %s", resp.Request.URL, resp.Status, body.Error)
+	}
+	if msg := strings.TrimSpace(string(b)); msg != "" {
+		return errors.Errorf("failed to push to %s : %s: %s", resp.Request.URL, resp.Status, msg)
+	}
+	return errors.Errorf("failed to push to %s : %s", resp.Request.URL, resp.Status)
+}
+
+// NewHTTPPusher constructs a valid http/https client as a Pusher
+func NewHTTPPusher(ops ...Option) (Pusher, error) {
+	var client HTTPPusher
+
+	for _, opt := range ops {
+		opt(&client.opts)
+	}
+
+	return &client, nil
+}
+
+func (pusher *HTTPPusher) httpClient(href string) (*http.Client, error) {
+	transport := &http.Transport{
+		DisableCompression: true,
+		Proxy:              http.ProxyFromEnvironment,
+	}
+
+	if (pusher.opts.certFile != "" && pusher.opts.keyFile != "") || pusher.opts.caFile != "" || pusher.opts.insecureSkipTLSverify {
+		tlsConf, err := tlsutil.NewClientTLS(pusher.opts.certFile, pusher.opts.keyFile, pusher.opts.caFile, pusher.opts.insecureSkipTLSverify)
+		if err != nil {
+			return nil, errors.Wrap(err, "can't create TLS config for client")
+		}
+
+		sni, err := urlutil.ExtractHostname(href)
+		if err != nil {
+			return nil, err
+		}
+		tlsConf.ServerName = sni
+
+		transport.TLSClientConfig = tlsConf
+	}
+
+	return &http.Client{Transport: transport}, nil
+}
@@ -0,0 +1,152 @@
+/*
+Copyright The Helm Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pusher
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/pkg/repo"
+	"helm.sh/helm/v3/pkg/repo/repotest"
+)
+
+func TestHTTPPusher(t *testing.T) {
+	// A chart archive with a provenance file next to it.
+	chart := filepath.Join(t.TempDir(), "examplechart-0.1.0.tgz")
+	data, err := os.ReadFile("../repo/repotest/testdata/examplechart-0.1.0.tgz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(chart, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(chart+".prov", []byte("provenance"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, api := range []string{UploadChartMuseum, UploadPut} {
+		t.Run(api, func(t *testing.T) {
+			srv := repotest.NewTempServerWithCleanupAndBasicAuth(t, "")
+			defer srv.Stop()
+
+			p, err := NewHTTPPusher(WithBasicAuth("username", "password"), WithUploadAPI(api))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := p.Push(chart, srv.URL()); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, name := range []string{"examplechart-0.1.0.tgz", "examplechart-0.1.0.tgz.prov"} {
+				if _, err := os.Stat(filepath.Join(srv.Root(), name)); err != nil {
+					t.Errorf("expected %s to be uploaded: %s", name, err)
+				}
+			}
+			index, err := repo.LoadIndexFile(filepath.Join(srv.Root(), "index.yaml"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !index.Has("examplechart", "0.1.0") {
+				t.Error("expected the uploaded chart to be in the repository index")
+			}
+
+			// Pushing the same version again is a conflict.
+			err = p.Push(chart, srv.URL())
+			if !errors.Is(err, ErrChartExists) {
+				t.Fatalf("expected a conflict, got %v", err)
+			}
+			if err := p.Push(chart, srv.URL(), WithForce(true)); err != nil {
+				t.Errorf("expected a forced push to overwrite the chart, got %s", err)
+			}
+		})
+	}
+}
+
+func TestHTTPPusherErrors(t *testing.T) {
+	srv := repotest.NewTempServerWithCleanupAndBasicAuth(t, "")
+	defer srv.Stop()
+	chart := "../repo/repotest/testdata/examplechart-0.1.0.tgz"
+
+	p, err := NewHTTPPusher(WithBasicAuth("username", "password"), WithUploadAPI("webdav"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Push(chart, srv.URL()); err == nil {
+		t.Error("expected an error for an unknown upload API")
+	}
+
+	if err := p.Push("../repo/repotest/testdata/examplechart", srv.URL()); err == nil {
+		t.Error("expected an error when pushing a directory")
+	}
+
+	// Errors of the ChartMuseum API are reported.
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"error":"storage is full"}`))
+	}))
+	defer failing.Close()
+	err = p.Push(chart, failing.URL, WithUploadAPI(UploadChartMuseum))
+	if err == nil || !strings.Contains(err.Error(), "storage is full") {
+		t.Errorf("expected the error of the server, got %v", err)
+	}
+}
+
+func TestHTTPPusherPutChecksAllFiles(t *testing.T) {
+	chart := filepath.Join(t.TempDir(), "examplechart-0.1.0.tgz")
+	data, err := os.ReadFile("../repo/repotest/testdata/examplechart-0.1.0.tgz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(chart, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(chart+".prov", []byte("provenance"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the provenance file of the version exists.
+	var puts []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodHead && strings.HasSuffix(r.URL.Path, ".prov"):
+			w.WriteHeader(http.StatusOK)
+		case r.Method == http.MethodHead:
+			w.WriteHeader(http.StatusNotFound)
+		case r.Method == http.MethodPut:
+			puts = append(puts, r.URL.Path)
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+	defer srv.Close()
+
+	p, err := NewHTTPPusher(WithUploadAPI(UploadPut))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Push(chart, srv.URL); !errors.Is(err, ErrChartExists) {
+		t.Fatalf("expected a conflict, got %v", err)
+	}
+	if len(puts) != 0 {
+		t.Errorf("expected nothing to be uploaded, got %v", puts)
+	}
+}
@@ -91,6 +91,9 @@ func (pusher *OCIPusher) push(chartRef, href string) error {
 		meta.Metadata.Version)
 
 	chartCreationTime := ctime.Created(stat)
+	if !pusher.opts.creationTime.IsZero() {
+		chartCreationTime = pusher.opts.creationTime.UTC()
+	}
 	pushOpts = append(pushOpts, registry.PushOptCreationTime(chartCreationTime.Format(time.RFC3339)))
 
 	_, err = client.Push(chartBytes, ref, pushOpts...)
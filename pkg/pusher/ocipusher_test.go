@@ -18,6 +18,7 @@ package pusher
 import (
 	"path/filepath"
 	"testing"
+	"time"
 
 	"helm.sh/helm/v3/pkg/registry"
 )
@@ -37,12 +38,14 @@ func TestNewOCIPusher(t *testing.T) {
 	ca, pub, priv := join(cd, "rootca.crt"), join(cd, "crt.pem"), join(cd, "key.pem")
 	insecureSkipTLSverify := false
 	plainHTTP := false
+	creationTime := time.Unix(1672628645, 0)
 
 	// Test with options
 	p, err = NewOCIPusher(
 		WithTLSClientConfig(pub, priv, ca),
 		WithInsecureSkipTLSVerify(insecureSkipTLSverify),
 		WithPlainHTTP(plainHTTP),
+		WithCreationTime(creationTime),
 	)
 	if err != nil {
 		t.Fatal(err)
@@ -73,6 +76,10 @@ func TestNewOCIPusher(t *testing.T) {
 		t.Errorf("Expected NewOCIPusher to have insecureSkipVerifyTLS as %t, got %t", insecureSkipTLSverify, op.opts.insecureSkipTLSverify)
 	}
 
+	if !op.opts.creationTime.Equal(creationTime) {
+		t.Errorf("Expected NewOCIPusher to have creationTime as %s, got %s", creationTime, op.opts.creationTime)
+	}
+
 	// Test if setting registryClient is being passed to the ops
 	registryClient, err := registry.NewClient()
 	if err != nil {
@@ -17,6 +17,8 @@ limitations under the License.
 package pusher
 
 import (
+	"time"
+
 	"github.com/pkg/errors"
 
 	"helm.sh/helm/v3/pkg/cli"
@@ -33,6 +35,11 @@ type options struct {
 	caFile                string
 	insecureSkipTLSverify bool
 	plainHTTP             bool
+	username              string
+	password              string
+	force                 bool
+	uploadAPI             string
+	creationTime          time.Time
 }
 
 // Option allows specifying various settings configurable by the user for overriding the defaults
@@ -68,6 +75,38 @@ func WithPlainHTTP(plainHTTP bool) Option {
 	}
 }
 
+// WithBasicAuth sets the credentials used to upload charts to HTTP repositories.
+func WithBasicAuth(username, password string) Option {
+	return func(opts *options) {
+		opts.username = username
+		opts.password = password
+	}
+}
+
+// WithForce overwrites a chart version that already exists in an HTTP repository.
+func WithForce(force bool) Option {
+	return func(opts *options) {
+		opts.force = force
+	}
+}
+
+// WithUploadAPI sets the API used to upload charts to HTTP repositories,
+// UploadChartMuseum or UploadPut.
+func WithUploadAPI(api string) Option {
+	return func(opts *options) {
+		opts.uploadAPI = api
+	}
+}
+
+// WithCreationTime sets the creation time recorded in the manifest of charts
+// pushed to OCI registries. By default, it is the time the chart archive was
+// created.
+func WithCreationTime(t time.Time) Option {
+	return func(opts *options) {
+		opts.creationTime = t
+	}
+}
+
 // Pusher is an interface to support upload to the specified URL.
 type Pusher interface {
 	// Push file content by url string
@@ -114,9 +153,14 @@ var ociProvider = Provider{
 	New:     NewOCIPusher,
 }
 
+var httpProvider = Provider{
+	Schemes: []string{"http", "https"},
+	New:     NewHTTPPusher,
+}
+
 // All finds all of the registered pushers as a list of Provider instances.
 // Currently, just the built-in pushers are collected.
 func All(_ *cli.EnvSettings) Providers {
-	result := Providers{ociProvider}
+	result := Providers{ociProvider, httpProvider}
 	return result
 }
@@ -0,0 +1,138 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry // import "helm.sh/helm/v3/pkg/registry"
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+	registryauth "oras.land/oras-go/pkg/registry/remote/auth"
+
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+// catalogPageSize is the number of repositories requested per catalog page.
+const catalogPageSize = 1000
+
+// ErrCatalogUnsupported is returned when a registry does not allow listing its
+// repositories through the catalog API.
+var ErrCatalogUnsupported = errors.New("registry does not support the catalog API")
+
+// Repositories lists the repositories of a registry with the catalog API.
+//
+// Many hosted registries do not offer the catalog API, or only to
+// administrators. In that case the error is ErrCatalogUnsupported.
+func (c *Client) Repositories(host string) ([]string, error) {
+	scheme := "https"
+	if c.plainHTTP {
+		scheme = "http"
+	}
+	next := fmt.Sprintf("%s://%s/v2/_catalog?n=%d", scheme, host, catalogPageSize)
+	rctx := registryauth.AppendScopes(ctx(c.out, c.debug), registryauth.ScopeRegistryCatalog)
+
+	var repositories []string
+	for next != "" {
+		resp, err := c.do(rctx, http.MethodGet, next, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		switch resp.StatusCode {
+		case http.StatusOK:
+		case http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound:
+			resp.Body.Close()
+			return nil, errors.Wrapf(ErrCatalogUnsupported, "%s: %s", host, resp.Status)
+		default:
+			resp.Body.Close()
+			return nil, errors.Errorf("failed to list the repositories of %s: %s", host, resp.Status)
+		}
+
+		var page struct {
+			Repositories []string `json:"repositories"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid catalog response from %s", host)
+		}
+		repositories = append(repositories, page.Repositories...)
+
+		next, err = nextPage(resp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return repositories, nil
+}
+
+// nextPage returns the URL of the next page of a paginated response, from its
+// Link header, or an empty string on the last page.
+func nextPage(resp *http.Response) (string, error) {
+	link := resp.Header.Get("Link")
+	if link == "" {
+		return "", nil
+	}
+	start, end := strings.Index(link, "<"), strings.Index(link, ">")
+	if start < 0 || end < start || !strings.Contains(link[end:], `rel="next"`) {
+		return "", errors.Errorf("invalid Link header %q", link)
+	}
+	u, err := resp.Request.URL.Parse(link[start+1 : end])
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid Link header %q", link)
+	}
+	return u.String(), nil
+}
+
+// ChartMetadata returns the digest of the manifest ref points to, and the
+// chart metadata stored in its config blob. Unlike Pull, the chart archive
+// is not downloaded.
+func (c *Client) ChartMetadata(ref string) (string, *chart.Metadata, error) {
+	parsedRef, err := parseReference(ref)
+	if err != nil {
+		return "", nil, err
+	}
+	desc, err := c.resolveDescriptor(parsedRef)
+	if err != nil {
+		return "", nil, err
+	}
+	rctx := c.referrersContext(parsedRef, registryauth.ActionPull)
+
+	data, err := c.fetch(rctx, parsedRef, "manifests", desc.Digest, ocispec.MediaTypeImageManifest)
+	if err != nil {
+		return "", nil, err
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return "", nil, errors.Wrapf(err, "invalid manifest for %s", ref)
+	}
+	if manifest.Config.MediaType != ConfigMediaType {
+		return "", nil, errors.Errorf("%s is not a chart: config media type is %q", ref, manifest.Config.MediaType)
+	}
+
+	data, err = c.fetch(rctx, parsedRef, "blobs", manifest.Config.Digest, ConfigMediaType)
+	if err != nil {
+		return "", nil, err
+	}
+	meta := &chart.Metadata{}
+	if err := json.Unmarshal(data, meta); err != nil {
+		return "", nil, errors.Wrapf(err, "invalid chart metadata for %s", ref)
+	}
+	return desc.Digest.String(), meta, nil
+}
@@ -0,0 +1,63 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestNextPage(t *testing.T) {
+	tests := []struct {
+		name    string
+		link    string
+		want    string
+		wantErr bool
+	}{
+		{name: "last page"},
+		{
+			name: "relative link",
+			link: `</v2/_catalog?last=org%2Fchart&n=2>; rel="next"`,
+			want: "https://registry.example.com/v2/_catalog?last=org%2Fchart&n=2",
+		},
+		{
+			name: "absolute link",
+			link: `<https://mirror.example.com/v2/_catalog?last=b>; rel="next"`,
+			want: "https://mirror.example.com/v2/_catalog?last=b",
+		},
+		{name: "not a next link", link: `</v2/_catalog?last=b>; rel="prev"`, wantErr: true},
+		{name: "malformed link", link: `/v2/_catalog?last=b`, wantErr: true},
+	}
+
+	base, _ := url.Parse("https://registry.example.com/v2/_catalog?n=2")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{Header: http.Header{}, Request: &http.Request{URL: base}}
+			if tt.link != "" {
+				resp.Header.Set("Link", tt.link)
+			}
+			got, err := nextPage(resp)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %t, got %v", tt.wantErr, err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
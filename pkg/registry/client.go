@@ -17,6 +17,7 @@ limitations under the License.
 package registry // import "helm.sh/helm/v3/pkg/registry"
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -26,6 +27,7 @@ import (
 	"strings"
 
 	"github.com/Masterminds/semver/v3"
+	"github.com/containerd/containerd/images"
 	"github.com/containerd/containerd/remotes"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/pkg/errors"
@@ -39,6 +41,7 @@ import (
 
 	"helm.sh/helm/v3/internal/version"
 	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
 	"helm.sh/helm/v3/pkg/helmpath"
 )
 
@@ -314,6 +317,7 @@ type (
 		withChart         bool
 		withProv          bool
 		ignoreMissingProv bool
+		archiveLimits     loader.ArchiveLimits
 	}
 )
 
@@ -325,7 +329,8 @@ func (c *Client) Pull(ref string, options ...PullOption) (*PullResult, error) {
 	}
 
 	operation := &pullOperation{
-		withChart: true, // By default, always download the chart layer
+		withChart:     true, // By default, always download the chart layer
+		archiveLimits: loader.DefaultArchiveLimits,
 	}
 	for _, option := range options {
 		option(operation)
@@ -360,6 +365,7 @@ func (c *Client) Pull(ref string, options ...PullOption) (*PullResult, error) {
 	manifest, err := oras.Copy(ctx(c.out, c.debug), registryStore, parsedRef.String(), memoryStore, "",
 		oras.WithPullEmptyNameAllowed(),
 		oras.WithAllowedMediaTypes(allowedMediaTypes),
+		oras.WithPullBaseHandler(limitDescriptorSize(operation.archiveLimits)),
 		oras.WithLayerDescriptors(func(l []ocispec.Descriptor) {
 			layers = l
 		}))
@@ -430,6 +436,12 @@ func (c *Client) Pull(ref string, options ...PullOption) (*PullResult, error) {
 	if getManifestErr != nil {
 		return nil, getManifestErr
 	}
+	// Ensure the manifest is the one the reference is pinned to
+	if want, err := parsedRef.Digest(); err == nil {
+		if got := want.Algorithm().FromBytes(result.Manifest.Data); got != want {
+			return nil, errors.Errorf("manifest digest %s does not match the requested digest %s", got, want)
+		}
+	}
 	var getConfigDescriptorErr error
 	if _, configData, ok := memoryStore.Get(*configDescriptor); !ok {
 		getConfigDescriptorErr = errors.Errorf("Unable to retrieve blob with digest %s", configDescriptor.Digest)
@@ -456,6 +468,12 @@ func (c *Client) Pull(ref string, options ...PullOption) (*PullResult, error) {
 		if getChartDescriptorErr != nil {
 			return nil, getChartDescriptorErr
 		}
+		// Only the limits are checked here, the archive itself is
+		// validated when the chart is loaded.
+		var limitErr *loader.ArchiveLimitError
+		if _, err := loader.LoadArchiveFilesWithLimits(bytes.NewReader(result.Chart.Data), operation.archiveLimits); errors.As(err, &limitErr) {
+			return nil, limitErr
+		}
 	}
 	if operation.withProv && !provMissing {
 		var getProvDescriptorErr error
@@ -503,6 +521,31 @@ func PullOptIgnoreMissingProv(ignoreMissingProv bool) PullOption {
 	}
 }
 
+// PullOptArchiveLimits returns a function that sets the limits the chart
+// archive is checked against on pull. By default, loader.DefaultArchiveLimits
+// apply.
+func PullOptArchiveLimits(limits loader.ArchiveLimits) PullOption {
+	return func(operation *pullOperation) {
+		operation.archiveLimits = limits
+	}
+}
+
+// limitDescriptorSize returns a handler that refuses to download a blob larger
+// than the maximum decompressed size of a chart archive, since neither the
+// compressed chart nor any other blob of a chart is expected to be larger.
+func limitDescriptorSize(limits loader.ArchiveLimits) images.HandlerFunc {
+	return func(_ context.Context, desc ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+		if limits.MaxDecompressedSize > 0 && desc.Size > limits.MaxDecompressedSize {
+			return nil, &loader.ArchiveLimitError{
+				Limit: loader.LimitDecompressedSize,
+				Max:   limits.MaxDecompressedSize,
+				Name:  desc.Digest.String(),
+			}
+		}
+		return nil, nil
+	}
+}
+
 type (
 	// PushOption allows specifying various settings on push
 	PushOption func(*pushOperation)
@@ -540,6 +583,10 @@ func (c *Client) Push(data []byte, ref string, options ...PushOption) (*PushResu
 		return nil, err
 	}
 
+	if _, err := parsedRef.Digest(); err == nil {
+		return nil, errors.New("cannot push to a reference pinned to a digest")
+	}
+
 	operation := &pushOperation{
 		strictMode: true, // By default, enable strict mode
 	}
@@ -659,6 +706,21 @@ func PushOptCreationTime(creationTime string) PushOption {
 	}
 }
 
+// Resolve returns the digest of the manifest a reference points to, without
+// pulling any of its content.
+func (c *Client) Resolve(ref string) (string, error) {
+	parsedRef, err := parseReference(ref)
+	if err != nil {
+		return "", err
+	}
+
+	desc, err := c.resolveDescriptor(parsedRef)
+	if err != nil {
+		return "", err
+	}
+	return desc.Digest.String(), nil
+}
+
 // Tags provides a sorted list all semver compliant tags for a given repository
 func (c *Client) Tags(ref string) ([]string, error) {
 	parsedReference, err := registry.ParseReference(ref)
@@ -54,7 +54,11 @@ func (suite *HTTPRegistryClientTestSuite) Test_3_Tags() {
 	testTags(&suite.TestSuite)
 }
 
-func (suite *HTTPRegistryClientTestSuite) Test_4_ManInTheMiddle() {
+func (suite *HTTPRegistryClientTestSuite) Test_4_Referrers() {
+	testReferrers(&suite.TestSuite)
+}
+
+func (suite *HTTPRegistryClientTestSuite) Test_5_ManInTheMiddle() {
 	ref := fmt.Sprintf("%s/testrepo/supposedlysafechart:9.9.9", suite.CompromisedRegistryHost)
 
 	// returns content that does not match the expected digest
@@ -63,6 +67,10 @@ func (suite *HTTPRegistryClientTestSuite) Test_4_ManInTheMiddle() {
 	suite.True(errdefs.IsFailedPrecondition(err))
 }
 
+func (suite *HTTPRegistryClientTestSuite) Test_6_Catalog() {
+	testCatalog(&suite.TestSuite)
+}
+
 func TestHTTPRegistryClientTestSuite(t *testing.T) {
 	suite.Run(t, new(HTTPRegistryClientTestSuite))
 }
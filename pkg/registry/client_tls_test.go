@@ -64,7 +64,11 @@ func (suite *TLSRegistryClientTestSuite) Test_3_Tags() {
 	testTags(&suite.TestSuite)
 }
 
-func (suite *TLSRegistryClientTestSuite) Test_4_Logout() {
+func (suite *TLSRegistryClientTestSuite) Test_4_Referrers() {
+	testReferrers(&suite.TestSuite)
+}
+
+func (suite *TLSRegistryClientTestSuite) Test_5_Logout() {
 	err := suite.RegistryClient.Logout("this-host-aint-real:5000")
 	suite.NotNil(err, "error logging out of registry that has no entry")
 
@@ -34,4 +34,10 @@ const (
 
 	// LegacyChartLayerMediaType is the legacy reserved media type for Helm chart package content.
 	LegacyChartLayerMediaType = "application/tar+gzip"
+
+	// SignatureArtifactType is the artifact type of chart signatures attached as referrers
+	SignatureArtifactType = "application/vnd.cncf.helm.chart.signature.v1"
+
+	// SignatureLayerMediaType is the media type of a detached PGP signature in a signature referrer
+	SignatureLayerMediaType = "application/pgp-signature"
 )
@@ -0,0 +1,480 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry // import "helm.sh/helm/v3/pkg/registry"
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+	"oras.land/oras-go/pkg/registry"
+	registryauth "oras.land/oras-go/pkg/registry/remote/auth"
+
+	helmtime "helm.sh/helm/v3/pkg/time"
+)
+
+// maxReferrerBytes caps the size of manifests and blobs read while handling referrers.
+const maxReferrerBytes = 4 * 1024 * 1024
+
+type (
+	// Blob is a piece of content stored as a layer of a referrer manifest.
+	Blob struct {
+		MediaType string
+		Data      []byte
+	}
+
+	// Referrer is an artifact, such as a signature or an attestation, that is
+	// attached to a chart manifest through its subject.
+	Referrer struct {
+		Manifest ocispec.Descriptor
+		Subject  ocispec.Descriptor
+		Layers   []Blob
+	}
+
+	// Signature is a detached signature attached to a chart manifest.
+	Signature struct {
+		// Digest of the referrer manifest that holds the signature
+		Digest string
+		// Payload is the signed descriptor of the chart manifest
+		Payload []byte
+		// MediaType of the signature
+		MediaType string
+		// Data is the detached signature of the payload
+		Data []byte
+	}
+)
+
+// PushReferrer attaches an artifact of the given type to the manifest that ref
+// points to, and returns the digest of the referrer manifest.
+//
+// Registries without support for the referrers API are handled with the
+// referrers tag schema of the OCI distribution specification.
+func (c *Client) PushReferrer(ref, artifactType string, layers []Blob, annotations map[string]string) (string, error) {
+	parsedRef, err := parseReference(ref)
+	if err != nil {
+		return "", err
+	}
+	subject, err := c.resolveDescriptor(parsedRef)
+	if err != nil {
+		return "", err
+	}
+
+	rctx := c.referrersContext(parsedRef, registryauth.ActionPull, registryauth.ActionPush)
+
+	if err := c.pushBlob(rctx, parsedRef, ocispec.DescriptorEmptyJSON.Digest, ocispec.DescriptorEmptyJSON.Data); err != nil {
+		return "", err
+	}
+	descriptors := make([]ocispec.Descriptor, 0, len(layers))
+	for _, l := range layers {
+		d := digest.FromBytes(l.Data)
+		if err := c.pushBlob(rctx, parsedRef, d, l.Data); err != nil {
+			return "", err
+		}
+		descriptors = append(descriptors, ocispec.Descriptor{
+			MediaType: l.MediaType,
+			Digest:    d,
+			Size:      int64(len(l.Data)),
+		})
+	}
+
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	if _, ok := annotations[ocispec.AnnotationCreated]; !ok {
+		annotations[ocispec.AnnotationCreated] = helmtime.Now().UTC().Format(time.RFC3339)
+	}
+
+	manifest := ocispec.Manifest{
+		Versioned:    specs.Versioned{SchemaVersion: 2},
+		MediaType:    ocispec.MediaTypeImageManifest,
+		ArtifactType: artifactType,
+		Config:       ocispec.DescriptorEmptyJSON,
+		Layers:       descriptors,
+		Subject:      &subject,
+		Annotations:  annotations,
+	}
+	manifestData, err := json.Marshal(manifest)
+	if err != nil {
+		return "", err
+	}
+	manifestDesc := ocispec.Descriptor{
+		MediaType:    ocispec.MediaTypeImageManifest,
+		ArtifactType: artifactType,
+		Digest:       digest.FromBytes(manifestData),
+		Size:         int64(len(manifestData)),
+		Annotations:  annotations,
+	}
+
+	resp, err := c.putManifest(rctx, parsedRef, manifestDesc.Digest.String(), ocispec.MediaTypeImageManifest, manifestData)
+	if err != nil {
+		return "", err
+	}
+
+	// A registry that processed the subject announces it, otherwise the
+	// referrer is recorded in the index tagged after the subject digest.
+	if resp.Header.Get("OCI-Subject") == "" {
+		if err := c.addToReferrersIndex(rctx, parsedRef, subject.Digest, manifestDesc); err != nil {
+			return "", err
+		}
+	}
+
+	fmt.Fprintf(c.out, "Attached: %s (%s)\n", manifestDesc.Digest, artifactType)
+	return manifestDesc.Digest.String(), nil
+}
+
+// Referrers lists the artifacts attached to the manifest that ref points to.
+// When artifactType is not empty, only referrers of that type are returned.
+func (c *Client) Referrers(ref, artifactType string) ([]ocispec.Descriptor, error) {
+	parsedRef, err := parseReference(ref)
+	if err != nil {
+		return nil, err
+	}
+	subject, err := c.resolveDescriptor(parsedRef)
+	if err != nil {
+		return nil, err
+	}
+	rctx := c.referrersContext(parsedRef, registryauth.ActionPull)
+
+	index, err := c.referrersIndex(rctx, parsedRef, subject.Digest)
+	if err != nil {
+		return nil, err
+	}
+
+	var referrers []ocispec.Descriptor
+	for _, d := range index.Manifests {
+		if artifactType == "" || d.ArtifactType == artifactType {
+			referrers = append(referrers, d)
+		}
+	}
+	return referrers, nil
+}
+
+// PullReferrer retrieves a referrer listed by Referrers, together with the
+// content of its layers. The digest of every piece of content is verified.
+func (c *Client) PullReferrer(ref string, desc ocispec.Descriptor) (*Referrer, error) {
+	parsedRef, err := parseReference(ref)
+	if err != nil {
+		return nil, err
+	}
+	rctx := c.referrersContext(parsedRef, registryauth.ActionPull)
+
+	data, err := c.fetch(rctx, parsedRef, "manifests", desc.Digest, ocispec.MediaTypeImageManifest)
+	if err != nil {
+		return nil, err
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, err
+	}
+	if manifest.Subject == nil {
+		return nil, errors.Errorf("referrer %s does not have a subject", desc.Digest)
+	}
+
+	result := &Referrer{
+		Manifest: desc,
+		Subject:  *manifest.Subject,
+	}
+	result.Manifest.ArtifactType = manifest.ArtifactType
+	result.Manifest.Annotations = manifest.Annotations
+	for _, l := range manifest.Layers {
+		data, err := c.fetch(rctx, parsedRef, "blobs", l.Digest, l.MediaType)
+		if err != nil {
+			return nil, err
+		}
+		result.Layers = append(result.Layers, Blob{MediaType: l.MediaType, Data: data})
+	}
+	return result, nil
+}
+
+// PushSignature signs the descriptor of the manifest that ref points to and
+// attaches the signature to it. The sign function is given the payload to sign
+// and returns a detached signature of the given media type.
+func (c *Client) PushSignature(ref, mediaType string, sign func(payload []byte) ([]byte, error)) (string, error) {
+	parsedRef, err := parseReference(ref)
+	if err != nil {
+		return "", err
+	}
+	subject, err := c.resolveDescriptor(parsedRef)
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(subject)
+	if err != nil {
+		return "", err
+	}
+	sig, err := sign(payload)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to sign chart manifest")
+	}
+
+	// Pin the referrer to the signed manifest in case the tag moved meanwhile.
+	pinned := fmt.Sprintf("%s/%s@%s", parsedRef.Registry, parsedRef.Repository, subject.Digest)
+	return c.PushReferrer(pinned, SignatureArtifactType, []Blob{
+		{MediaType: ocispec.MediaTypeDescriptor, Data: payload},
+		{MediaType: mediaType, Data: sig},
+	}, nil)
+}
+
+// Signatures returns the signatures attached to the manifest that ref points
+// to. Referrers whose payload does not describe that manifest are ignored.
+// The signatures themselves are not verified.
+func (c *Client) Signatures(ref string) ([]*Signature, error) {
+	parsedRef, err := parseReference(ref)
+	if err != nil {
+		return nil, err
+	}
+	subject, err := c.resolveDescriptor(parsedRef)
+	if err != nil {
+		return nil, err
+	}
+	pinned := fmt.Sprintf("%s/%s@%s", parsedRef.Registry, parsedRef.Repository, subject.Digest)
+
+	descs, err := c.Referrers(pinned, SignatureArtifactType)
+	if err != nil {
+		return nil, err
+	}
+
+	var signatures []*Signature
+	for _, desc := range descs {
+		referrer, err := c.PullReferrer(pinned, desc)
+		if err != nil {
+			return nil, err
+		}
+		if referrer.Subject.Digest != subject.Digest || len(referrer.Layers) != 2 {
+			continue
+		}
+		payload, sig := referrer.Layers[0], referrer.Layers[1]
+		var signed ocispec.Descriptor
+		if payload.MediaType != ocispec.MediaTypeDescriptor || json.Unmarshal(payload.Data, &signed) != nil {
+			continue
+		}
+		if signed.Digest != subject.Digest || signed.Size != subject.Size {
+			continue
+		}
+		signatures = append(signatures, &Signature{
+			Digest:    desc.Digest.String(),
+			Payload:   payload.Data,
+			MediaType: sig.MediaType,
+			Data:      sig.Data,
+		})
+	}
+	return signatures, nil
+}
+
+// resolveDescriptor returns the descriptor of the manifest a reference points to.
+func (c *Client) resolveDescriptor(ref registry.Reference) (ocispec.Descriptor, error) {
+	remotesResolver, err := c.resolver(ref)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	_, desc, err := remotesResolver.Resolve(ctx(c.out, c.debug), ref.String())
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	return ocispec.Descriptor{
+		MediaType: desc.MediaType,
+		Digest:    desc.Digest,
+		Size:      desc.Size,
+	}, nil
+}
+
+// referrersContext returns a context hinting the repository scope needed for
+// the given actions, so that tokens are requested with the right scope.
+func (c *Client) referrersContext(ref registry.Reference, actions ...string) context.Context {
+	return registryauth.AppendScopes(ctx(c.out, c.debug), registryauth.ScopeRepository(ref.Repository, actions...))
+}
+
+// repositoryURL returns the base URL of the distribution API for a repository.
+func (c *Client) repositoryURL(ref registry.Reference) string {
+	scheme := "https"
+	if c.plainHTTP {
+		scheme = "http"
+	}
+	return fmt.Sprintf("%s://%s/v2/%s", scheme, ref.Host(), ref.Repository)
+}
+
+func (c *Client) do(rctx context.Context, method, target string, header http.Header, body []byte) (*http.Response, error) {
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(rctx, method, target, r)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	return c.registryAuthorizer.Do(req)
+}
+
+// fetch retrieves a manifest or blob by digest and verifies its content.
+func (c *Client) fetch(rctx context.Context, ref registry.Reference, kind string, dgst digest.Digest, accept string) ([]byte, error) {
+	target := fmt.Sprintf("%s/%s/%s", c.repositoryURL(ref), kind, dgst)
+	resp, err := c.do(rctx, http.MethodGet, target, http.Header{"Accept": {accept}}, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("failed to fetch %s: %s", dgst, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxReferrerBytes))
+	if err != nil {
+		return nil, err
+	}
+	if got := dgst.Algorithm().FromBytes(data); got != dgst {
+		return nil, errors.Errorf("content digest %s does not match the expected digest %s", got, dgst)
+	}
+	return data, nil
+}
+
+// pushBlob uploads a blob in a single request, unless the registry already has it.
+func (c *Client) pushBlob(rctx context.Context, ref registry.Reference, dgst digest.Digest, data []byte) error {
+	base := c.repositoryURL(ref)
+	resp, err := c.do(rctx, http.MethodHead, fmt.Sprintf("%s/blobs/%s", base, dgst), nil, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	resp, err = c.do(rctx, http.MethodPost, base+"/blobs/uploads/", nil, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		return errors.Errorf("failed to start upload of %s: %s", dgst, resp.Status)
+	}
+	location, err := resp.Request.URL.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		return err
+	}
+	q := location.Query()
+	q.Set("digest", dgst.String())
+	location.RawQuery = q.Encode()
+
+	resp, err = c.do(rctx, http.MethodPut, location.String(), http.Header{"Content-Type": {"application/octet-stream"}}, data)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return errors.Errorf("failed to upload %s: %s", dgst, resp.Status)
+	}
+	return nil
+}
+
+func (c *Client) putManifest(rctx context.Context, ref registry.Reference, reference, mediaType string, data []byte) (*http.Response, error) {
+	target := fmt.Sprintf("%s/manifests/%s", c.repositoryURL(ref), url.PathEscape(reference))
+	resp, err := c.do(rctx, http.MethodPut, target, http.Header{"Content-Type": {mediaType}}, data)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return nil, errors.Errorf("failed to push manifest %s: %s", reference, resp.Status)
+	}
+	return resp, nil
+}
+
+// referrersTag returns the tag of the referrers index for a subject digest,
+// as defined by the referrers tag schema.
+func referrersTag(subject digest.Digest) string {
+	tag := fmt.Sprintf("%s-%s", subject.Algorithm(), subject.Encoded())
+	if len(tag) > 128 {
+		tag = tag[:128]
+	}
+	return tag
+}
+
+// referrersIndex returns the referrers of a subject, using the referrers API
+// when the registry supports it and the referrers tag schema otherwise.
+func (c *Client) referrersIndex(rctx context.Context, ref registry.Reference, subject digest.Digest) (*ocispec.Index, error) {
+	target := fmt.Sprintf("%s/referrers/%s", c.repositoryURL(ref), subject)
+	resp, err := c.do(rctx, http.MethodGet, target, http.Header{"Accept": {ocispec.MediaTypeImageIndex}}, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK && strings.HasPrefix(resp.Header.Get("Content-Type"), ocispec.MediaTypeImageIndex) {
+		var index ocispec.Index
+		if err := json.NewDecoder(io.LimitReader(resp.Body, maxReferrerBytes)).Decode(&index); err != nil {
+			return nil, err
+		}
+		return &index, nil
+	}
+	return c.taggedReferrersIndex(rctx, ref, subject)
+}
+
+// taggedReferrersIndex returns the index tagged after the subject digest, or
+// an empty index when there is none.
+func (c *Client) taggedReferrersIndex(rctx context.Context, ref registry.Reference, subject digest.Digest) (*ocispec.Index, error) {
+	index := &ocispec.Index{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispec.MediaTypeImageIndex,
+	}
+	target := fmt.Sprintf("%s/manifests/%s", c.repositoryURL(ref), referrersTag(subject))
+	resp, err := c.do(rctx, http.MethodGet, target, http.Header{"Accept": {ocispec.MediaTypeImageIndex}}, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		if err := json.NewDecoder(io.LimitReader(resp.Body, maxReferrerBytes)).Decode(index); err != nil {
+			return nil, err
+		}
+	case http.StatusNotFound:
+	default:
+		return nil, errors.Errorf("failed to fetch referrers of %s: %s", subject, resp.Status)
+	}
+	return index, nil
+}
+
+// addToReferrersIndex records a referrer in the index tagged after the subject digest.
+func (c *Client) addToReferrersIndex(rctx context.Context, ref registry.Reference, subject digest.Digest, desc ocispec.Descriptor) error {
+	index, err := c.taggedReferrersIndex(rctx, ref, subject)
+	if err != nil {
+		return err
+	}
+	for _, d := range index.Manifests {
+		if d.Digest == desc.Digest {
+			return nil
+		}
+	}
+	index.Manifests = append(index.Manifests, desc)
+	data, err := json.Marshal(index)
+	if err != nil {
+		return err
+	}
+	_, err = c.putManifest(rctx, ref, referrersTag(subject), ocispec.MediaTypeImageIndex, data)
+	return err
+}
@@ -28,15 +28,18 @@ import (
 	helmtime "helm.sh/helm/v3/pkg/time"
 
 	"github.com/Masterminds/semver/v3"
+	"github.com/opencontainers/go-digest"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+	dockerauth "oras.land/oras-go/pkg/auth/docker"
 	orascontext "oras.land/oras-go/pkg/context"
 	"oras.land/oras-go/pkg/registry"
 
 	"helm.sh/helm/v3/internal/tlsutil"
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/helmpath"
 )
 
 var immutableOciAnnotations = []string{
@@ -120,6 +123,14 @@ func ctx(out io.Writer, debug bool) context.Context {
 // converted to underscores (_) before pushing
 // See https://github.com/helm/helm/issues/10166
 func parseReference(raw string) (registry.Reference, error) {
+	// Set any digest aside so that only the tag is considered below. A
+	// reference pinned to a digest (e.g. chart:1.2.3@sha256:...) is resolved
+	// by its digest, the tag is informational.
+	raw, dgst, err := SplitDigest(raw)
+	if err != nil {
+		return registry.Reference{}, err
+	}
+
 	// The sole possible reference modification is replacing plus (+) signs
 	// present in tags with underscores (_). To do this properly, we first
 	// need to identify a tag, and then pass it on to the reference parser
@@ -137,9 +148,48 @@ func parseReference(raw string) (registry.Reference, error) {
 		}
 	}
 
+	if dgst != "" {
+		raw = fmt.Sprintf("%s@%s", raw, dgst)
+	}
+
 	return registry.ParseReference(raw)
 }
 
+// SplitDigest separates a trailing digest (e.g. "@sha256:...") from an OCI
+// reference or a chart version, returning the remainder and the digest. The
+// digest is empty when the input is not pinned to one.
+func SplitDigest(ref string) (string, string, error) {
+	idx := strings.LastIndex(ref, "@")
+	// An '@' followed by a path is user info in a URL, not a digest
+	if idx < 0 || strings.Contains(ref[idx+1:], "/") {
+		return ref, "", nil
+	}
+	dgst, err := digest.Parse(ref[idx+1:])
+	if err != nil {
+		return "", "", errors.Wrapf(err, "invalid digest in reference %q", ref)
+	}
+	return ref[:idx], dgst.String(), nil
+}
+
+// LookupCredential returns the username and password stored for a server in
+// a registry credentials file, or in the Docker configuration as a fallback.
+// A blank username with a password is an identity token. If no credentials
+// file is given, the default one is used.
+func LookupCredential(credentialsFile, server string) (string, string, error) {
+	if credentialsFile == "" {
+		credentialsFile = helmpath.ConfigPath(CredentialsFileBasename)
+	}
+	client, err := dockerauth.NewClientWithDockerFallback(credentialsFile)
+	if err != nil {
+		return "", "", err
+	}
+	dockerClient, ok := client.(*dockerauth.Client)
+	if !ok {
+		return "", "", errors.New("unable to obtain docker client")
+	}
+	return dockerClient.Credential(server)
+}
+
 // NewRegistryClientWithTLS is a helper function to create a new registry client with TLS enabled.
 func NewRegistryClientWithTLS(out io.Writer, certFile, keyFile, caFile string, insecureSkipTLSverify bool, registryConfig string, debug bool) (*Client, error) {
 	tlsConf, err := tlsutil.NewClientTLS(certFile, keyFile, caFile, insecureSkipTLSverify)
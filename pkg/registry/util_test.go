@@ -273,3 +273,38 @@ func TestGenerateOCICreatedAnnotations(t *testing.T) {
 	}
 
 }
+
+func TestSplitDigest(t *testing.T) {
+	dgst := "sha256:e5ef611620fb97704d8751c16bab17fedb68883bfb0edc76f78a70e9173f9b55"
+
+	tests := []struct {
+		ref        string
+		wantRef    string
+		wantDigest string
+		wantErr    bool
+	}{
+		{ref: "oci://registry/repo/chart", wantRef: "oci://registry/repo/chart"},
+		{ref: "registry/repo/chart:1.2.3", wantRef: "registry/repo/chart:1.2.3"},
+		{ref: "registry/repo/chart@" + dgst, wantRef: "registry/repo/chart", wantDigest: dgst},
+		{ref: "oci://registry:5000/repo/chart:1.2.3+build@" + dgst, wantRef: "oci://registry:5000/repo/chart:1.2.3+build", wantDigest: dgst},
+		{ref: "1.2.3@" + dgst, wantRef: "1.2.3", wantDigest: dgst},
+		{ref: "oci://user@registry/repo/chart", wantRef: "oci://user@registry/repo/chart"},
+		{ref: "registry/repo/chart@sha256:abc", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		ref, dgst, err := SplitDigest(tt.ref)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s: expected an error", tt.ref)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", tt.ref, err)
+		}
+		if ref != tt.wantRef || dgst != tt.wantDigest {
+			t.Errorf("%s: expected (%q, %q), got (%q, %q)", tt.ref, tt.wantRef, tt.wantDigest, ref, dgst)
+		}
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -36,11 +37,13 @@ import (
 	_ "github.com/distribution/distribution/v3/registry/auth/htpasswd"
 	_ "github.com/distribution/distribution/v3/registry/storage/driver/inmemory"
 	"github.com/foxcpp/go-mockdns"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/phayes/freeport"
 	"github.com/stretchr/testify/suite"
 	"golang.org/x/crypto/bcrypt"
 
 	"helm.sh/helm/v3/internal/tlsutil"
+	"helm.sh/helm/v3/pkg/chart/loader"
 )
 
 const (
@@ -379,6 +382,37 @@ func testPull(suite *TestSuite) {
 		string(result.Config.Data))
 	suite.Equal(chartData, result.Chart.Data)
 	suite.Equal(provData, result.Prov.Data)
+
+	// resolve the tag to the manifest digest
+	dgst, err := suite.RegistryClient.Resolve(ref)
+	suite.Nil(err, "no error resolving a chart reference")
+	suite.Equal(result.Manifest.Digest, dgst)
+
+	// pull pinned to the digest, with and without the tag
+	for _, pinned := range []string{
+		fmt.Sprintf("%s@%s", ref, dgst),
+		fmt.Sprintf("%s/testrepo/%s@%s", suite.DockerRegistryHost, meta.Name, dgst),
+	} {
+		result, err = suite.RegistryClient.Pull(pinned, PullOptWithProv(true))
+		suite.Nil(err, "no error pulling a chart pinned to a digest")
+		suite.Equal(dgst, result.Manifest.Digest)
+		suite.Equal(chartData, result.Chart.Data)
+	}
+
+	// pull pinned to a digest that does not exist
+	_, err = suite.RegistryClient.Pull(fmt.Sprintf("%s@sha256:%064d", ref, 0))
+	suite.NotNil(err, "error pulling a chart pinned to a missing digest")
+
+	// blobs larger than the archive limits are not downloaded
+	var limitErr *loader.ArchiveLimitError
+	_, err = suite.RegistryClient.Pull(ref, PullOptArchiveLimits(loader.ArchiveLimits{MaxDecompressedSize: 512}))
+	suite.True(errors.As(err, &limitErr), "archive limit error pulling a chart larger than the limits")
+	suite.Equal(loader.LimitDecompressedSize, limitErr.Limit)
+
+	// the chart archive is checked against the limits
+	_, err = suite.RegistryClient.Pull(ref, PullOptArchiveLimits(loader.ArchiveLimits{MaxFiles: 1}))
+	suite.True(errors.As(err, &limitErr), "archive limit error pulling a chart with too many files")
+	suite.Equal(loader.LimitFiles, limitErr.Limit)
 }
 
 func testTags(suite *TestSuite) {
@@ -394,3 +428,118 @@ func testTags(suite *TestSuite) {
 	suite.Nil(err, "no error retrieving tags")
 	suite.Equal(1, len(tags))
 }
+
+func testReferrers(suite *TestSuite) {
+	// Load test chart (to build ref pushed in previous test)
+	chartData, err := os.ReadFile("../downloader/testdata/signtest-0.1.0.tgz")
+	suite.Nil(err, "no error loading test chart")
+	meta, err := extractChartMeta(chartData)
+	suite.Nil(err, "no error extracting chart meta")
+	ref := fmt.Sprintf("%s/testrepo/%s:%s", suite.DockerRegistryHost, meta.Name, meta.Version)
+
+	subject, err := suite.RegistryClient.Resolve(ref)
+	suite.Nil(err, "no error resolving the subject")
+
+	// no referrers yet
+	referrers, err := suite.RegistryClient.Referrers(ref, "")
+	suite.Nil(err, "no error listing referrers")
+	suite.Empty(referrers)
+
+	signature := []Blob{{MediaType: SignatureLayerMediaType, Data: []byte("signature")}}
+	sigDigest, err := suite.RegistryClient.PushReferrer(ref, SignatureArtifactType, signature, nil)
+	suite.Nil(err, "no error attaching a signature")
+
+	sbom := []Blob{{MediaType: "application/spdx+json", Data: []byte(`{"spdxVersion":"SPDX-2.3"}`)}}
+	_, err = suite.RegistryClient.PushReferrer(ref, "application/spdx+json", sbom, map[string]string{"org.example": "sbom"})
+	suite.Nil(err, "no error attaching an attestation")
+
+	// a second signature is attached alongside the first
+	_, err = suite.RegistryClient.PushReferrer(ref, SignatureArtifactType, signature, map[string]string{
+		ocispec.AnnotationCreated: "1977-09-02T22:04:05Z",
+	})
+	suite.Nil(err, "no error attaching a second signature")
+
+	referrers, err = suite.RegistryClient.Referrers(ref, "")
+	suite.Nil(err, "no error listing referrers")
+	suite.Len(referrers, 3)
+
+	referrers, err = suite.RegistryClient.Referrers(ref, SignatureArtifactType)
+	suite.Nil(err, "no error listing signature referrers")
+	suite.Len(referrers, 2)
+	suite.Equal(sigDigest, referrers[0].Digest.String())
+
+	// referrers can also be listed through a digest reference
+	pinned := fmt.Sprintf("%s/testrepo/%s@%s", suite.DockerRegistryHost, meta.Name, subject)
+	referrers, err = suite.RegistryClient.Referrers(pinned, SignatureArtifactType)
+	suite.Nil(err, "no error listing referrers of a pinned reference")
+	suite.Len(referrers, 2)
+
+	referrer, err := suite.RegistryClient.PullReferrer(ref, referrers[0])
+	suite.Nil(err, "no error pulling a referrer")
+	suite.Equal(subject, referrer.Subject.Digest.String())
+	suite.Equal(SignatureArtifactType, referrer.Manifest.ArtifactType)
+	suite.Equal(signature, referrer.Layers)
+
+	// signatures carry the descriptor of the manifest they sign
+	var payload []byte
+	_, err = suite.RegistryClient.PushSignature(ref, SignatureLayerMediaType, func(p []byte) ([]byte, error) {
+		payload = p
+		return []byte("detached"), nil
+	})
+	suite.Nil(err, "no error pushing a signature")
+	suite.Contains(string(payload), subject)
+
+	// malformed signature referrers pushed above are ignored
+	signatures, err := suite.RegistryClient.Signatures(ref)
+	suite.Nil(err, "no error listing signatures")
+	suite.Len(signatures, 1)
+	suite.Equal(payload, signatures[0].Payload)
+	suite.Equal(SignatureLayerMediaType, signatures[0].MediaType)
+	suite.Equal([]byte("detached"), signatures[0].Data)
+
+	_, err = suite.RegistryClient.PushSignature(ref, SignatureLayerMediaType, func([]byte) ([]byte, error) {
+		return nil, fmt.Errorf("no key")
+	})
+	suite.NotNil(err, "error when signing fails")
+
+	// referrers cannot be attached to a missing manifest
+	_, err = suite.RegistryClient.PushReferrer(fmt.Sprintf("%s/testrepo/no-existy:1.2.3", suite.DockerRegistryHost), SignatureArtifactType, signature, nil)
+	suite.NotNil(err, "error attaching a referrer to a missing manifest")
+}
+
+func testCatalog(suite *TestSuite) {
+	// Load test chart (to build ref pushed in previous test)
+	chartData, err := os.ReadFile("../downloader/testdata/local-subchart-0.1.0.tgz")
+	suite.Nil(err, "no error loading test chart")
+	meta, err := extractChartMeta(chartData)
+	suite.Nil(err, "no error extracting chart meta")
+	ref := fmt.Sprintf("%s/testrepo/%s:%s", suite.DockerRegistryHost, meta.Name, meta.Version)
+
+	repositories, err := suite.RegistryClient.Repositories(suite.DockerRegistryHost)
+	suite.Nil(err, "no error listing repositories")
+	suite.Contains(repositories, "testrepo/"+meta.Name)
+	suite.Contains(repositories, "testrepo/signtest")
+
+	digest, err := suite.RegistryClient.Resolve(ref)
+	suite.Nil(err, "no error resolving the chart")
+
+	// metadata is read from the config without pulling the chart
+	manifestDigest, chartMeta, err := suite.RegistryClient.ChartMetadata(ref)
+	suite.Nil(err, "no error reading chart metadata")
+	suite.Equal(digest, manifestDigest)
+	suite.Equal(meta.Name, chartMeta.Name)
+	suite.Equal(meta.Version, chartMeta.Version)
+	suite.Equal(meta.Description, chartMeta.Description)
+
+	// artifacts other than charts are rejected
+	signed := fmt.Sprintf("%s/testrepo/signtest:0.1.0", suite.DockerRegistryHost)
+	referrers, err := suite.RegistryClient.Referrers(signed, SignatureArtifactType)
+	suite.Nil(err, "no error listing referrers")
+	suite.NotEmpty(referrers)
+	_, _, err = suite.RegistryClient.ChartMetadata(fmt.Sprintf("%s/testrepo/signtest@%s", suite.DockerRegistryHost, referrers[0].Digest))
+	suite.NotNil(err, "error reading chart metadata of a signature")
+	suite.Contains(err.Error(), "is not a chart")
+
+	_, _, err = suite.RegistryClient.ChartMetadata(fmt.Sprintf("%s/testrepo/no-existy:1.2.3", suite.DockerRegistryHost))
+	suite.NotNil(err, "error reading chart metadata of a missing chart")
+}
@@ -37,4 +37,23 @@ type Info struct {
 	Notes string `json:"notes,omitempty"`
 	// Contains the deployed resources information
 	Resources map[string][]runtime.Object `json:"resources,omitempty"`
+	// ChartDigest is the digest of the OCI manifest the chart was pulled from
+	ChartDigest string `json:"chart_digest,omitempty"`
+	// Origin records who made this revision, from where and why
+	Origin *Origin `json:"origin,omitempty"`
+}
+
+// Origin describes who made a revision of a release, from where and why.
+type Origin struct {
+	// User is the Kubernetes user that made the revision.
+	User string `json:"user,omitempty"`
+	// Hostname is the name of the host the revision was made from.
+	Hostname string `json:"hostname,omitempty"`
+	// HelmVersion is the version of Helm that made the revision.
+	HelmVersion string `json:"helm_version,omitempty"`
+	// ChartSource is where the chart came from: the URL of the chart in its
+	// repository, its OCI reference pinned to its digest, or its local path.
+	ChartSource string `json:"chart_source,omitempty"`
+	// ChangeCause is why the revision was made, as given by the user.
+	ChangeCause string `json:"change_cause,omitempty"`
 }
@@ -0,0 +1,105 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package release
+
+// PlannedResource identifies a Kubernetes resource an operation would act on.
+type PlannedResource struct {
+	APIVersion string `json:"api_version,omitempty"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	Namespace  string `json:"namespace,omitempty"`
+}
+
+// PlannedHook is a hook an operation would run.
+type PlannedHook struct {
+	PlannedResource
+	// Event is the event the hook would run on.
+	Event HookEvent `json:"event"`
+	// Weight is the weight of the hook among the hooks of the same event.
+	Weight int `json:"weight,omitempty"`
+}
+
+// UninstallPlan describes what uninstalling a release would do.
+type UninstallPlan struct {
+	// Name is the name of the release.
+	Name string `json:"name"`
+	// Namespace is the namespace of the release.
+	Namespace string `json:"namespace"`
+	// Delete lists the resources that would be deleted, in deletion order.
+	Delete []PlannedResource `json:"delete"`
+	// Keep lists the resources that would be kept because of their
+	// helm.sh/resource-policy annotation.
+	Keep []PlannedResource `json:"keep"`
+	// Hooks lists the hooks that would run, in execution order.
+	Hooks []PlannedHook `json:"hooks"`
+	// Namespaces lists the namespaces of the resources and hooks that would
+	// be deleted or run.
+	Namespaces []string `json:"namespaces"`
+}
+
+// PlanOperation is what an install or upgrade would do with a resource.
+type PlanOperation string
+
+const (
+	// PlanCreate creates a resource that does not exist.
+	PlanCreate PlanOperation = "create"
+	// PlanPatch patches a resource that differs from the chart.
+	PlanPatch PlanOperation = "patch"
+	// PlanReplace replaces a resource, when updates are forced.
+	PlanReplace PlanOperation = "replace"
+	// PlanAdopt brings an existing resource under the release.
+	PlanAdopt PlanOperation = "adopt"
+	// PlanUnchanged leaves a resource that matches the chart untouched.
+	PlanUnchanged PlanOperation = "unchanged"
+	// PlanDelete deletes a resource that was removed from the chart.
+	PlanDelete PlanOperation = "delete"
+)
+
+// PlannedChange is a resource an install or upgrade would act on.
+type PlannedChange struct {
+	PlannedResource
+	// Operation is what would be done with the resource.
+	Operation PlanOperation `json:"operation"`
+	// ResourceVersion is the version of the resource in the cluster when the
+	// plan was made, or empty if it did not exist.
+	ResourceVersion string `json:"resource_version,omitempty"`
+}
+
+// Plan describes what installing or upgrading a release would do.
+type Plan struct {
+	// Name is the name of the release.
+	Name string `json:"name"`
+	// Namespace is the namespace of the release.
+	Namespace string `json:"namespace"`
+	// Revision is the revision the operation would create.
+	Revision int `json:"revision"`
+	// Chart is the name and version of the chart.
+	Chart string `json:"chart"`
+	// ManifestDigest is the digest of the rendered manifest and hooks.
+	ManifestDigest string `json:"manifest_digest"`
+	// Changes lists the resources that would be created, updated, adopted
+	// or deleted, in order.
+	Changes []PlannedChange `json:"changes"`
+	// Orphaned lists the resources removed from the chart that would be kept
+	// because of their helm.sh/resource-policy annotation.
+	Orphaned []PlannedResource `json:"orphaned"`
+	// Hooks lists the hooks that would run, in execution order.
+	Hooks []PlannedHook `json:"hooks"`
+	// Namespaces lists the namespaces of the resources and hooks that would
+	// be changed or run.
+	Namespaces []string `json:"namespaces"`
+}
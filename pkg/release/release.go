@@ -37,6 +37,9 @@ type Release struct {
 	Version int `json:"version,omitempty"`
 	// Namespace is the kubernetes namespace of the release.
 	Namespace string `json:"namespace,omitempty"`
+	// Waves are the waves the resources of the release are applied in, if
+	// there is more than one.
+	Waves []*Wave `json:"waves,omitempty"`
 	// Labels of the release.
 	// Disabled encoding into Json cause labels are stored in storage driver metadata field.
 	Labels map[string]string `json:"-"`
@@ -21,4 +21,6 @@ type UninstallReleaseResponse struct {
 	Release *Release `json:"release,omitempty"`
 	// Info is an uninstall message
 	Info string `json:"info,omitempty"`
+	// Plan describes what the uninstall would do. It is only set on dry runs.
+	Plan *UninstallPlan `json:"plan,omitempty"`
 }
@@ -0,0 +1,30 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package release
+
+// WaveAnnotation assigns a resource of a release to a wave. Resources without
+// it are in wave 0.
+const WaveAnnotation = "helm.sh/wave"
+
+// Wave is a group of resources of a release that are applied together. Waves
+// are applied in increasing order, each one after the previous one.
+type Wave struct {
+	// Wave is the number of the wave.
+	Wave int `json:"wave"`
+	// Resources are the resources in the wave.
+	Resources []PlannedResource `json:"resources"`
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package repo // import "helm.sh/helm/v3/pkg/repo"
 
 import (
+	"bytes"
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/json"
@@ -48,6 +49,34 @@ type Entry struct {
 	CAFile                string `json:"caFile"`
 	InsecureSkipTLSverify bool   `json:"insecure_skip_tls_verify"`
 	PassCredentialsAll    bool   `json:"pass_credentials_all"`
+	// Mirrors are URLs serving the same repository, tried in order when
+	// the URL cannot be reached. The credentials of the repository are only
+	// sent to them if PassCredentialsAll is set.
+	Mirrors []string `json:"mirrors,omitempty"`
+	// CredentialHelper is a command providing the credentials of the
+	// repository when they are needed, see getter.CredentialHelper.
+	CredentialHelper string `json:"credentialHelper,omitempty"`
+	// RegistryCredentials is the server whose credentials in the registry
+	// credentials file authenticate the repository.
+	RegistryCredentials string `json:"registryCredentials,omitempty"`
+	// BearerToken is a token sent in the Authorization header of requests.
+	BearerToken string `json:"bearerToken,omitempty"`
+	// Headers are static headers sent with requests, such as API keys.
+	Headers map[string]string `json:"headers,omitempty"`
+	// OAuth2 authenticates requests with a token from the OAuth2 client
+	// credentials flow.
+	OAuth2 *OAuth2Config `json:"oauth2,omitempty"`
+	// JSONIndex makes updates ask the repository for its compact JSON
+	// index, index.json, before index.yaml.
+	JSONIndex bool `json:"jsonIndex,omitempty"`
+}
+
+// OAuth2Config configures the OAuth2 client credentials flow of a repository.
+type OAuth2Config struct {
+	TokenURL     string   `json:"tokenURL"`
+	ClientID     string   `json:"clientID"`
+	ClientSecret string   `json:"clientSecret"`
+	Scopes       []string `json:"scopes,omitempty"`
 }
 
 // ChartRepository represents a chart repository
@@ -57,6 +86,12 @@ type ChartRepository struct {
 	IndexFile  *IndexFile
 	Client     getter.Getter
 	CachePath  string
+	// Rewrites are applied to the URL and the mirrors of the repository
+	// before the index is downloaded.
+	Rewrites Rewrites
+	// RegistryConfig is the registry credentials file, used when the
+	// repository references registry credentials.
+	RegistryConfig string
 }
 
 // NewChartRepository constructs ChartRepository
@@ -115,30 +150,112 @@ func (r *ChartRepository) Load() error {
 
 // DownloadIndexFile fetches the index from a repository.
 func (r *ChartRepository) DownloadIndexFile() (string, error) {
-	indexURL, err := ResolveReferenceURL(r.Config.URL, "index.yaml")
-	if err != nil {
-		return "", err
-	}
+	fname, _, err := r.UpdateIndexFile()
+	return fname, err
+}
 
-	resp, err := r.Client.Get(indexURL,
-		getter.WithURL(r.Config.URL),
-		getter.WithInsecureSkipVerifyTLS(r.Config.InsecureSkipTLSverify),
-		getter.WithTLSClientConfig(r.Config.CertFile, r.Config.KeyFile, r.Config.CAFile),
-		getter.WithBasicAuth(r.Config.Username, r.Config.Password),
-		getter.WithPassCredentialsAll(r.Config.PassCredentialsAll),
-	)
-	if err != nil {
-		return "", err
+// indexValidators are the cache validators of a downloaded index.
+type indexValidators struct {
+	URL string `json:"url"`
+	// JSON records that the index was downloaded from index.json.
+	JSON bool `json:"json,omitempty"`
+	getter.CacheValidators
+}
+
+// UpdateIndexFile fetches the index from a repository if it changed since it
+// was last downloaded, and reports whether it did.
+//
+// The HTTP cache validators of the index are kept next to it in the cache, so
+// that an unchanged index is not downloaded again. The index is stored in the
+// cache as compact JSON, which is faster to load than YAML.
+//
+// An HTTP repository may serve a compact JSON index as index.json. It is asked
+// for it first when the entry sets JSONIndex, or when it served index.json the
+// last time, and index.yaml is only downloaded when index.json is not found.
+//
+// When the repository URL cannot be reached, the index is downloaded from the
+// mirrors of the repository, in order.
+func (r *ChartRepository) UpdateIndexFile() (string, bool, error) {
+	fname := filepath.Join(r.CachePath, helmpath.CacheIndexFile(r.Config.Name))
+	validatorsFile := filepath.Join(r.CachePath, helmpath.CacheIndexValidatorsFile(r.Config.Name))
+
+	var bases []string
+	for _, u := range r.Config.URLs() {
+		bases = append(bases, r.Rewrites.Apply(u))
 	}
 
-	index, err := io.ReadAll(resp)
-	if err != nil {
-		return "", err
+	var cached indexValidators
+	if _, err := os.Stat(fname); err == nil {
+		if b, err := os.ReadFile(validatorsFile); err == nil {
+			json.Unmarshal(b, &cached)
+		}
 	}
 
-	indexFile, err := loadIndex(index, r.Config.URL)
+	var (
+		index       []byte
+		indexFile   *IndexFile
+		validators  *indexValidators
+		notModified bool
+	)
+	fetch := func(indexURL string, isJSON bool) error {
+		validators = &indexValidators{URL: indexURL, JSON: isJSON}
+		if cached.URL == indexURL {
+			v := cached
+			validators = &v
+		}
+
+		// The credentials are scoped to the repository URL, so that they are
+		// not sent to a mirror or a rewritten URL on another host.
+		opts := []getter.Option{
+			getter.WithURL(r.Config.URL),
+			getter.WithInsecureSkipVerifyTLS(r.Config.InsecureSkipTLSverify),
+			getter.WithTLSClientConfig(r.Config.CertFile, r.Config.KeyFile, r.Config.CAFile),
+			getter.WithBasicAuth(r.Config.Username, r.Config.Password),
+			getter.WithPassCredentialsAll(r.Config.PassCredentialsAll),
+			getter.WithCredentials(r.Config.Credentials(r.RegistryConfig)),
+			getter.WithCacheValidators(&validators.CacheValidators),
+			getter.WithAcceptGzip(),
+		}
+		opts = append(opts, r.Config.AuthOptions()...)
+		resp, err := r.Client.Get(indexURL, opts...)
+		if errors.Is(err, getter.ErrNotModified) {
+			notModified = true
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if index, err = io.ReadAll(resp); err != nil {
+			return err
+		}
+		indexFile, err = loadIndex(index, r.Config.URL)
+		return err
+	}
+	_, err := TryMirrors(bases, func(base string) error {
+		// Only HTTP repositories serve index.json, and only those known to
+		// serve it are asked for it, to spare the others a request.
+		if u, err := url.Parse(base); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+			jsonURL, err := ResolveReferenceURL(base, "index.json")
+			if err != nil {
+				return err
+			}
+			if r.Config.JSONIndex || (cached.JSON && cached.URL == jsonURL) {
+				if err := fetch(jsonURL, true); !errors.Is(err, getter.ErrNotFound) {
+					return err
+				}
+			}
+		}
+		yamlURL, err := ResolveReferenceURL(base, "index.yaml")
+		if err != nil {
+			return err
+		}
+		return fetch(yamlURL, false)
+	})
 	if err != nil {
-		return "", err
+		return "", false, err
+	}
+	if notModified {
+		return fname, false, nil
 	}
 
 	// Create the chart list file in the cache directory
@@ -150,10 +267,26 @@ func (r *ChartRepository) DownloadIndexFile() (string, error) {
 	os.MkdirAll(filepath.Dir(chartsFile), 0755)
 	os.WriteFile(chartsFile, []byte(charts.String()), 0644)
 
-	// Create the index file in the cache directory
-	fname := filepath.Join(r.CachePath, helmpath.CacheIndexFile(r.Config.Name))
+	// Create the index file in the cache directory. JSON is a subset of YAML,
+	// so the file can be read by anything that reads an index.
+	if compact := new(bytes.Buffer); json.Compact(compact, index) == nil {
+		index = compact.Bytes()
+	} else if index, err = json.Marshal(indexFile); err != nil {
+		return "", false, err
+	}
 	os.MkdirAll(filepath.Dir(fname), 0755)
-	return fname, os.WriteFile(fname, index, 0644)
+	// Remove the validators before replacing the index, so that a partially
+	// written index is never considered unchanged.
+	os.Remove(validatorsFile)
+	if err := os.WriteFile(fname, index, 0644); err != nil {
+		return "", false, err
+	}
+	if validators.ETag != "" || validators.LastModified != "" || validators.JSON {
+		if b, err := json.Marshal(validators); err == nil {
+			os.WriteFile(validatorsFile, b, 0644)
+		}
+	}
+	return fname, true, nil
 }
 
 // Index generates an index for the chart repository and writes an index.yaml file.
@@ -251,6 +384,7 @@ func FindChartInAuthAndTLSAndPassRepoURL(repoURL, username, password, chartName,
 	defer func() {
 		os.RemoveAll(filepath.Join(r.CachePath, helmpath.CacheChartsFile(r.Config.Name)))
 		os.RemoveAll(filepath.Join(r.CachePath, helmpath.CacheIndexFile(r.Config.Name)))
+		os.RemoveAll(filepath.Join(r.CachePath, helmpath.CacheIndexValidatorsFile(r.Config.Name)))
 	}()
 
 	// Read the index file for the repository to get chart information and return chart URL
@@ -308,6 +442,35 @@ func ResolveReferenceURL(baseURL, refURL string) (string, error) {
 	return resolvedURL.String(), nil
 }
 
+// Credentials returns the function providing the credentials of the
+// repository from its credential helper or from the registry credentials
+// file, or nil if the repository uses neither.
+func (e *Entry) Credentials(registryConfig string) getter.CredentialsFunc {
+	switch {
+	case e.CredentialHelper != "":
+		return getter.CredentialHelper(e.CredentialHelper, e.URL)
+	case e.RegistryCredentials != "":
+		return getter.RegistryCredentials(registryConfig, e.RegistryCredentials)
+	}
+	return nil
+}
+
+// AuthOptions returns the getter options sending the bearer token, the
+// headers and the OAuth2 token of the repository.
+func (e *Entry) AuthOptions() []getter.Option {
+	var opts []getter.Option
+	if e.BearerToken != "" {
+		opts = append(opts, getter.WithBearerToken(e.BearerToken))
+	}
+	if len(e.Headers) > 0 {
+		opts = append(opts, getter.WithHeaders(e.Headers))
+	}
+	if o := e.OAuth2; o != nil {
+		opts = append(opts, getter.WithOAuth2ClientCredentials(o.TokenURL, o.ClientID, o.ClientSecret, o.Scopes))
+	}
+	return opts
+}
+
 func (e *Entry) String() string {
 	buf, err := json.Marshal(e)
 	if err != nil {
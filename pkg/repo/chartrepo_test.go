@@ -400,3 +400,42 @@ func TestResolveReferenceURL(t *testing.T) {
 		}
 	}
 }
+
+func TestEntryCredentials(t *testing.T) {
+	if f := (&Entry{URL: testURL}).Credentials(""); f != nil {
+		t.Error("expected no credentials function for a repository without credential helper")
+	}
+
+	index, err := os.ReadFile("testdata/local-index.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if username, password, ok := r.BasicAuth(); !ok || username != "user" || password != "pass" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write(index)
+	}))
+	defer srv.Close()
+
+	credentialsFile := filepath.Join(t.TempDir(), "config.json")
+	config := `{"auths":{"charts.example.com":{"username":"user","password":"pass"}}}`
+	if err := os.WriteFile(credentialsFile, []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewChartRepository(&Entry{
+		Name:                "test",
+		URL:                 srv.URL,
+		RegistryCredentials: "charts.example.com",
+	}, getter.All(&cli.EnvSettings{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.CachePath = t.TempDir()
+	r.RegistryConfig = credentialsFile
+	if _, err := r.DownloadIndexFile(); err != nil {
+		t.Fatalf("expected the index to be downloaded with the registry credentials: %s", err)
+	}
+}
@@ -23,8 +23,10 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"runtime"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/Masterminds/semver/v3"
@@ -118,6 +120,10 @@ func LoadIndexFile(path string) (*IndexFile, error) {
 // MustAdd adds a file to the index
 // This can leave the index in an unsorted state
 func (i IndexFile) MustAdd(md *chart.Metadata, filename, baseURL, digest string) error {
+	return i.add(md, filename, baseURL, digest, time.Now())
+}
+
+func (i IndexFile) add(md *chart.Metadata, filename, baseURL, digest string, created time.Time) error {
 	if i.Entries == nil {
 		return errors.New("entries not initialized")
 	}
@@ -129,26 +135,30 @@ func (i IndexFile) MustAdd(md *chart.Metadata, filename, baseURL, digest string)
 		return errors.Wrapf(err, "validate failed for %s", filename)
 	}
 
-	u := filename
-	if baseURL != "" {
-		_, file := filepath.Split(filename)
-		var err error
-		u, err = urlutil.URLJoin(baseURL, file)
-		if err != nil {
-			u = path.Join(baseURL, file)
-		}
-	}
 	cr := &ChartVersion{
-		URLs:     []string{u},
+		URLs:     []string{chartURL(filename, baseURL)},
 		Metadata: md,
 		Digest:   digest,
-		Created:  time.Now(),
+		Created:  created,
 	}
 	ee := i.Entries[md.Name]
 	i.Entries[md.Name] = append(ee, cr)
 	return nil
 }
 
+// chartURL returns the URL of a chart archive in an index.
+func chartURL(filename, baseURL string) string {
+	if baseURL == "" {
+		return filename
+	}
+	_, file := filepath.Split(filename)
+	u, err := urlutil.URLJoin(baseURL, file)
+	if err != nil {
+		u = path.Join(baseURL, file)
+	}
+	return u
+}
+
 // Add adds a file to the index and logs an error.
 //
 // Deprecated: Use index.MustAdd instead.
@@ -292,26 +302,132 @@ type ChartVersion struct {
 
 // IndexDirectory reads a (flat) directory and generates an index.
 //
-// It indexes only charts that have been packaged (*.tgz).
+// It indexes only charts that have been packaged (*.tgz). The creation time
+// of an entry is the modification time of its archive.
 //
 // The index returned will be in an unsorted state
 func IndexDirectory(dir, baseURL string) (*IndexFile, error) {
-	archives, err := filepath.Glob(filepath.Join(dir, "*.tgz"))
+	index, _, err := IndexDirectoryIncremental(dir, baseURL, nil, nil)
+	return index, err
+}
+
+// ArchiveState is the size and modification time of an indexed chart archive.
+type ArchiveState struct {
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+}
+
+// IndexState records the chart archives of an index, by their slash-separated
+// path relative to the indexed directory. It tells which archives changed
+// since the index was generated.
+type IndexState map[string]ArchiveState
+
+// LoadIndexState reads an index state file. A missing file is an empty state.
+func LoadIndexState(path string) (IndexState, error) {
+	s := IndexState{}
+	b, err := os.ReadFile(path)
 	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
 		return nil, err
 	}
+	if err := json.Unmarshal(b, &s); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse index state file %s", path)
+	}
+	return s, nil
+}
+
+// WriteFile writes an index state file to the given destination path.
+//
+// The mode on the file is set to 'mode'.
+func (s IndexState) WriteFile(dest string, mode os.FileMode) error {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return fileutil.AtomicWriteFile(dest, bytes.NewReader(b), mode)
+}
+
+// indexedArchive is a chart archive found while indexing a directory.
+type indexedArchive struct {
+	path      string
+	key       string
+	fname     string
+	parentURL string
+	state     ArchiveState
+
+	// entry is the entry of the previous index reused for the archive.
+	entry *ChartVersion
+	// metadata and digest are set when the archive is loaded. An archive
+	// that is not a chart has no metadata.
+	metadata *chart.Metadata
+	digest   string
+	err      error
+}
+
+func (a *indexedArchive) load() {
+	c, err := loader.Load(a.path)
+	if err != nil {
+		// Assume this is not a chart.
+		return
+	}
+	if a.digest, a.err = provenance.DigestFile(a.path); a.err == nil {
+		a.metadata = c.Metadata
+	}
+}
+
+// IndexDirectoryIncremental generates an index of a directory like
+// IndexDirectory, reusing the entries of a previous index for the archives
+// whose size and modification time did not change since the given state was
+// recorded. Only new and modified archives are loaded, in parallel. The
+// entries of deleted archives are dropped.
+//
+// The index is identical to the one IndexDirectory generates. The state of
+// the indexed archives is returned along with it.
+func IndexDirectoryIncremental(dir, baseURL string, previous *IndexFile, state IndexState) (*IndexFile, IndexState, error) {
+	archives, err := filepath.Glob(filepath.Join(dir, "*.tgz"))
+	if err != nil {
+		return nil, nil, err
+	}
 	moreArchives, err := filepath.Glob(filepath.Join(dir, "**/*.tgz"))
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	archives = append(archives, moreArchives...)
 
-	index := NewIndexFile()
+	// Entries are reused by URL, so that a changed base URL rebuilds them.
+	reusable := map[string]*ChartVersion{}
+	if previous != nil {
+		for _, cvs := range previous.Entries {
+			for _, cv := range cvs {
+				if cv != nil && len(cv.URLs) > 0 {
+					reusable[cv.URLs[0]] = cv
+				}
+			}
+		}
+	}
+
+	items := make([]*indexedArchive, 0, len(archives))
+	work := make(chan *indexedArchive)
+	var wg sync.WaitGroup
+	for n := 0; n < runtime.NumCPU(); n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for a := range work {
+				a.load()
+			}
+		}()
+	}
 	for _, arch := range archives {
 		fname, err := filepath.Rel(dir, arch)
 		if err != nil {
-			return index, err
+			close(work)
+			wg.Wait()
+			return nil, nil, err
 		}
+		key := filepath.ToSlash(fname)
 
 		var parentDir string
 		parentDir, fname = filepath.Split(fname)
@@ -322,20 +438,53 @@ func IndexDirectory(dir, baseURL string) (*IndexFile, error) {
 			parentURL = path.Join(baseURL, parentDir)
 		}
 
-		c, err := loader.Load(arch)
+		fi, err := os.Stat(arch)
 		if err != nil {
-			// Assume this is not a chart.
-			continue
+			close(work)
+			wg.Wait()
+			return nil, nil, err
 		}
-		hash, err := provenance.DigestFile(arch)
-		if err != nil {
-			return index, err
+		a := &indexedArchive{
+			path:      arch,
+			key:       key,
+			fname:     fname,
+			parentURL: parentURL,
+			state:     ArchiveState{Size: fi.Size(), ModTime: fi.ModTime()},
 		}
-		if err := index.MustAdd(c.Metadata, fname, parentURL, hash); err != nil {
-			return index, errors.Wrapf(err, "failed adding to %s to index", fname)
+		if prev, ok := state[key]; ok && prev.Size == a.state.Size && prev.ModTime.Equal(a.state.ModTime) {
+			a.entry = reusable[chartURL(fname, parentURL)]
+		}
+		items = append(items, a)
+		if a.entry == nil {
+			work <- a
+		}
+	}
+	close(work)
+	wg.Wait()
+
+	index := NewIndexFile()
+	newState := IndexState{}
+	for _, a := range items {
+		switch {
+		case a.err != nil:
+			return index, newState, a.err
+		case a.entry != nil:
+			// The creation time is set as IndexDirectory sets it, in case the
+			// previous index was generated differently.
+			cv := *a.entry
+			cv.Created = a.state.ModTime
+			index.Entries[cv.Name] = append(index.Entries[cv.Name], &cv)
+		case a.metadata == nil:
+			// Not a chart.
+			continue
+		default:
+			if err := index.add(a.metadata, a.fname, a.parentURL, a.digest, a.state.ModTime); err != nil {
+				return index, newState, errors.Wrapf(err, "failed adding to %s to index", a.fname)
+			}
 		}
+		newState[a.key] = a.state
 	}
-	return index, nil
+	return index, newState, nil
 }
 
 // loadIndex loads an index file and does minimal validity checking.
@@ -22,11 +22,16 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"reflect"
 	"sort"
 	"strings"
 	"testing"
+	"time"
+
+	"sigs.k8s.io/yaml"
 
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/cli"
@@ -349,6 +354,250 @@ func TestDownloadIndexFile(t *testing.T) {
 		}
 		verifyLocalChartsFile(t, b, i)
 	})
+
+	t.Run("should download the index from a mirror", func(t *testing.T) {
+		srv, err := startLocalServerForTests(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer srv.Close()
+		down := httptest.NewServer(http.NotFoundHandler())
+		defer down.Close()
+
+		for _, entry := range []*Entry{
+			{Name: testRepo, URL: down.URL, Mirrors: []string{"http://127.0.0.1:1", srv.URL}},
+			{Name: testRepo, URL: "https://charts.example.invalid"},
+		} {
+			r, err := NewChartRepository(entry, getter.All(&cli.EnvSettings{}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			r.CachePath = t.TempDir()
+			r.Rewrites = Rewrites{{From: "https://charts.example.invalid", To: srv.URL}}
+
+			idx, err := r.DownloadIndexFile()
+			if err != nil {
+				t.Fatalf("Failed to download index file from %s: %s", entry.URL, err)
+			}
+			i, err := LoadIndexFile(idx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			verifyLocalIndex(t, i)
+		}
+
+		// Every failure is reported when no mirror works.
+		r, err := NewChartRepository(&Entry{Name: testRepo, URL: down.URL, Mirrors: []string{down.URL + "/mirror"}}, getter.All(&cli.EnvSettings{}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.CachePath = t.TempDir()
+		_, err = r.DownloadIndexFile()
+		if err == nil || !strings.Contains(err.Error(), "all mirrors failed") || !strings.Contains(err.Error(), down.URL+"/mirror/index.yaml") {
+			t.Errorf("Expected the failures of every mirror, got %v", err)
+		}
+	})
+
+	t.Run("should only send the credentials to a mirror when passing them to all domains", func(t *testing.T) {
+		fileBytes, err := os.ReadFile("testdata/local-index.yaml")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var authorization string
+		mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authorization = r.Header.Get("Authorization")
+			w.Write(fileBytes)
+		}))
+		defer mirror.Close()
+		down := httptest.NewServer(http.NotFoundHandler())
+		defer down.Close()
+
+		for _, passCredentialsAll := range []bool{false, true} {
+			authorization = ""
+			r, err := NewChartRepository(&Entry{
+				Name:               testRepo,
+				URL:                down.URL,
+				Mirrors:            []string{mirror.URL},
+				Username:           "user",
+				Password:           "secret",
+				PassCredentialsAll: passCredentialsAll,
+			}, getter.All(&cli.EnvSettings{}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			r.CachePath = t.TempDir()
+			if _, err := r.DownloadIndexFile(); err != nil {
+				t.Fatal(err)
+			}
+			if sent := authorization != ""; sent != passCredentialsAll {
+				t.Errorf("pass credentials to all domains %t: expected credentials sent to the mirror to be %t", passCredentialsAll, sent)
+			}
+		}
+	})
+
+	t.Run("should download the JSON index when the repository opts in", func(t *testing.T) {
+		fileBytes, err := os.ReadFile("testdata/local-index.yaml")
+		if err != nil {
+			t.Fatal(err)
+		}
+		index, err := loadIndex(fileBytes, "testdata/local-index.yaml")
+		if err != nil {
+			t.Fatal(err)
+		}
+		jsonBytes, err := json.Marshal(index)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var requested []string
+		jsonStatus := http.StatusOK
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requested = append(requested, r.URL.Path)
+			switch r.URL.Path {
+			case "/index.json":
+				if jsonStatus != http.StatusOK {
+					w.WriteHeader(jsonStatus)
+					return
+				}
+				w.Write(jsonBytes)
+			case "/index.yaml":
+				w.Write(fileBytes)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		})
+		srv, err := startLocalServerForTests(handler)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer srv.Close()
+
+		cache := t.TempDir()
+		update := func(jsonIndex bool) error {
+			requested = nil
+			r, err := NewChartRepository(&Entry{Name: testRepo, URL: srv.URL, JSONIndex: jsonIndex}, getter.All(&cli.EnvSettings{}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			r.CachePath = cache
+			idx, err := r.DownloadIndexFile()
+			if err != nil {
+				return err
+			}
+			i, err := LoadIndexFile(idx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			verifyLocalIndex(t, i)
+			return nil
+		}
+
+		if err := update(false); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(requested, []string{"/index.yaml"}) {
+			t.Errorf("Expected only index.yaml to be requested without opting in, got %v", requested)
+		}
+		if err := update(true); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(requested, []string{"/index.json"}) {
+			t.Errorf("Expected only index.json to be requested, got %v", requested)
+		}
+		if err := update(false); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(requested, []string{"/index.json"}) {
+			t.Errorf("Expected index.json to be requested again once it was served, got %v", requested)
+		}
+
+		jsonStatus = http.StatusNotFound
+		if err := update(true); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(requested, []string{"/index.json", "/index.yaml"}) {
+			t.Errorf("Expected index.yaml to be requested when index.json is not found, got %v", requested)
+		}
+
+		jsonStatus = http.StatusUnauthorized
+		if err := update(true); err == nil {
+			t.Error("Expected an error when index.json cannot be downloaded")
+		}
+		if !reflect.DeepEqual(requested, []string{"/index.json"}) {
+			t.Errorf("Expected no fallback to index.yaml on an error other than not found, got %v", requested)
+		}
+	})
+
+	t.Run("should skip downloading an unchanged index", func(t *testing.T) {
+		fileBytes, err := os.ReadFile("testdata/local-index.yaml")
+		if err != nil {
+			t.Fatal(err)
+		}
+		etag := `"1"`
+		downloads := 0
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("If-None-Match") == etag {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			downloads++
+			w.Header().Set("ETag", etag)
+			w.Write(fileBytes)
+		})
+		srv, err := startLocalServerForTests(handler)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer srv.Close()
+
+		r, err := NewChartRepository(&Entry{
+			Name: testRepo,
+			URL:  srv.URL,
+		}, getter.All(&cli.EnvSettings{}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.CachePath = t.TempDir()
+
+		for i, expectChanged := range []bool{true, false} {
+			idx, changed, err := r.UpdateIndexFile()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if changed != expectChanged {
+				t.Errorf("update %d: expected changed to be %t", i, expectChanged)
+			}
+			b, err := os.ReadFile(idx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !json.Valid(b) || bytes.Contains(b, []byte("\n")) {
+				t.Errorf("update %d: expected the cached index to be compact JSON", i)
+			}
+			index, err := LoadIndexFile(idx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			verifyLocalIndex(t, index)
+		}
+		if downloads != 1 {
+			t.Errorf("Expected the index to be downloaded once, got %d", downloads)
+		}
+
+		// A changed index is downloaded again.
+		etag = `"2"`
+		if _, changed, err := r.UpdateIndexFile(); err != nil || !changed {
+			t.Errorf("Expected a changed index to be downloaded, got %t, %v", changed, err)
+		}
+
+		// Without the cached index, the index is downloaded unconditionally.
+		os.Remove(filepath.Join(r.CachePath, helmpath.CacheIndexFile(testRepo)))
+		if _, changed, err := r.UpdateIndexFile(); err != nil || !changed {
+			t.Errorf("Expected a missing index to be downloaded, got %t, %v", changed, err)
+		}
+		if downloads != 3 {
+			t.Errorf("Expected the index to be downloaded 3 times, got %d", downloads)
+		}
+	})
 }
 
 func verifyLocalIndex(t *testing.T, i *IndexFile) {
@@ -506,6 +755,132 @@ func TestIndexDirectory(t *testing.T) {
 	}
 }
 
+func TestIndexDirectoryIncremental(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"frobnitz-1.2.3.tgz", "sprocket-1.1.0.tgz", "universe/zarthal-1.0.0.tgz"} {
+		copyTestFile(t, filepath.Join("testdata/repository", name), filepath.Join(dir, name))
+	}
+	const baseURL = "http://localhost:8080"
+
+	index, state, err := IndexDirectoryIncremental(dir, baseURL, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(state) != 3 {
+		t.Fatalf("Expected the state of 3 archives, got %v", state)
+	}
+	for key := range state {
+		if strings.Contains(key, "\\") {
+			t.Errorf("Expected slash-separated paths in the state, got %s", key)
+		}
+	}
+
+	// Delete an archive, add another, and modify a third.
+	os.Remove(filepath.Join(dir, "frobnitz-1.2.3.tgz"))
+	copyTestFile(t, "testdata/repository/sprocket-1.2.0.tgz", filepath.Join(dir, "sprocket-1.2.0.tgz"))
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, "universe/zarthal-1.0.0.tgz"), later, later); err != nil {
+		t.Fatal(err)
+	}
+
+	incremental, state, err := IndexDirectoryIncremental(dir, baseURL, index, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	full, err := IndexDirectory(dir, baseURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertSameIndex(t, full, incremental)
+	if len(state) != 3 || state["universe/zarthal-1.0.0.tgz"].ModTime.Unix() != later.Unix() {
+		t.Errorf("Unexpected state %v", state)
+	}
+	if created := incremental.Entries["zarthal"][0].Created; created.Unix() != later.Unix() {
+		t.Errorf("Expected the creation time of the modified archive, got %s", created)
+	}
+
+	// Reused entries get the creation time a full rebuild gives them, even
+	// when the previous index recorded another one.
+	previous := *incremental
+	previous.Entries = map[string]ChartVersions{}
+	for name, cvs := range incremental.Entries {
+		for _, cv := range cvs {
+			stale := *cv
+			stale.Created = time.Now().Add(-24 * time.Hour)
+			previous.Entries[name] = append(previous.Entries[name], &stale)
+		}
+	}
+	again, _, err := IndexDirectoryIncremental(dir, baseURL, &previous, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertSameIndex(t, full, again)
+
+	// An archive whose size and modification time did not change is not
+	// loaded again, even if its content did.
+	sprocket := filepath.Join(dir, "sprocket-1.1.0.tgz")
+	fi, err := os.Stat(sprocket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(sprocket, make([]byte, fi.Size()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(sprocket, fi.ModTime(), fi.ModTime()); err != nil {
+		t.Fatal(err)
+	}
+	reused, _, err := IndexDirectoryIncremental(dir, baseURL, incremental, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reused.Has("sprocket", "1.1.0") {
+		t.Error("Expected the entry of the unchanged archive to be reused")
+	}
+
+	// A different base URL rebuilds every entry.
+	rebuilt, _, err := IndexDirectoryIncremental(dir, "http://localhost:9090", incremental, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rebuilt.Has("sprocket", "1.1.0") {
+		t.Error("Expected the entries to be rebuilt for a different base URL")
+	}
+}
+
+// assertSameIndex checks that two indexes are written identically, apart from
+// their generation time.
+func assertSameIndex(t *testing.T, expected, actual *IndexFile) {
+	t.Helper()
+	actual.Generated = expected.Generated
+	expected.SortEntries()
+	actual.SortEntries()
+	e, err := yaml.Marshal(expected)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := yaml.Marshal(actual)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(e, a) {
+		t.Errorf("Expected index:\n%s\ngot:\n%s", e, a)
+	}
+}
+
+func copyTestFile(t *testing.T, src, dst string) {
+	t.Helper()
+	data, err := os.ReadFile(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dst, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func TestIndexAdd(t *testing.T) {
 	i := NewIndexFile()
 
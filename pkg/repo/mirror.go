@@ -0,0 +1,150 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo // import "helm.sh/helm/v3/pkg/repo"
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Rewrite is a rule that rewrites the URLs of chart repositories, for example
+// to send the requests for a public repository to an internal proxy.
+//
+// A From ending with "*" matches every URL starting with the rest of it, and
+// the part of the URL matched by "*" is appended to To. Otherwise, From must
+// be equal to the URL.
+type Rewrite struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Rewrites is an ordered list of rewrite rules. The first matching rule
+// applies.
+type Rewrites []Rewrite
+
+// Apply returns the URL rewritten by the first matching rule, or the URL
+// itself if no rule matches.
+func (rs Rewrites) Apply(u string) string {
+	for _, r := range rs {
+		if prefix, ok := strings.CutSuffix(r.From, "*"); ok {
+			if rest, ok := strings.CutPrefix(u, prefix); ok {
+				return strings.TrimSuffix(r.To, "*") + rest
+			}
+			continue
+		}
+		if strings.TrimSuffix(u, "/") == strings.TrimSuffix(r.From, "/") {
+			return r.To
+		}
+	}
+	return u
+}
+
+// URLs returns the URL of the repository followed by its mirrors.
+func (e *Entry) URLs() []string {
+	return append([]string{e.URL}, e.Mirrors...)
+}
+
+// MirrorURLs returns the locations of a file of the repository on the
+// repository URL and on each of its mirrors, in order. If u is not under the
+// URL or a mirror of the repository, only u is returned.
+func (e *Entry) MirrorURLs(u string) []string {
+	bases := e.URLs()
+	for _, base := range bases {
+		prefix := strings.TrimSuffix(base, "/") + "/"
+		rest, ok := strings.CutPrefix(u, prefix)
+		if !ok {
+			continue
+		}
+		urls := make([]string, 0, len(bases))
+		for _, b := range bases {
+			urls = append(urls, strings.TrimSuffix(b, "/")+"/"+rest)
+		}
+		return urls
+	}
+	return []string{u}
+}
+
+// unhealthyPeriod is how long a host that failed a request is tried after
+// the others.
+const unhealthyPeriod = 5 * time.Minute
+
+// hostHealth remembers the hosts that recently failed a request.
+var hostHealth = struct {
+	sync.Mutex
+	failures map[string]time.Time
+}{failures: map[string]time.Time{}}
+
+func hostOf(u string) string {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return u
+	}
+	return parsed.Scheme + "://" + parsed.Host
+}
+
+// TryMirrors calls fetch with each URL in turn until one succeeds, and returns
+// that URL. URLs on hosts that recently failed are tried last, so that a mirror
+// that is down does not slow down every request.
+//
+// Duplicate URLs are only tried once. If every URL fails, the error lists the
+// error of each of them.
+func TryMirrors(urls []string, fetch func(u string) error) (string, error) {
+	var ordered []string
+	seen := map[string]bool{}
+	for _, u := range urls {
+		if !seen[u] {
+			seen[u] = true
+			ordered = append(ordered, u)
+		}
+	}
+
+	hostHealth.Lock()
+	now := time.Now()
+	unhealthy := func(u string) bool {
+		failed, ok := hostHealth.failures[hostOf(u)]
+		return ok && now.Sub(failed) < unhealthyPeriod
+	}
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return !unhealthy(ordered[i]) && unhealthy(ordered[j])
+	})
+	hostHealth.Unlock()
+
+	var msgs []string
+	for _, u := range ordered {
+		err := fetch(u)
+		hostHealth.Lock()
+		if err == nil {
+			delete(hostHealth.failures, hostOf(u))
+		} else {
+			hostHealth.failures[hostOf(u)] = time.Now()
+		}
+		hostHealth.Unlock()
+		if err == nil {
+			return u, nil
+		}
+		if len(ordered) == 1 {
+			return "", err
+		}
+		msgs = append(msgs, err.Error())
+	}
+	return "", errors.Errorf("all mirrors failed:\n\t%s", strings.Join(msgs, "\n\t"))
+}
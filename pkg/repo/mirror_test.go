@@ -0,0 +1,106 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestRewritesApply(t *testing.T) {
+	rules := Rewrites{
+		{From: "https://charts.bitnami.com/*", To: "https://proxy.example.com/bitnami/*"},
+		{From: "https://charts.example.com/stable/", To: "https://mirror.example.com/stable"},
+		{From: "https://charts.example.com/*", To: "https://other-proxy.example.com/*"},
+	}
+
+	tests := []struct {
+		url    string
+		expect string
+	}{
+		{"https://charts.bitnami.com/bitnami", "https://proxy.example.com/bitnami/bitnami"},
+		{"https://charts.bitnami.com/bitnami/nginx-1.0.0.tgz", "https://proxy.example.com/bitnami/bitnami/nginx-1.0.0.tgz"},
+		{"https://charts.example.com/stable", "https://mirror.example.com/stable"},
+		{"https://charts.example.com/stable/alpine-0.1.0.tgz", "https://other-proxy.example.com/stable/alpine-0.1.0.tgz"},
+		{"https://other.example.com/charts", "https://other.example.com/charts"},
+	}
+	for _, tt := range tests {
+		if got := rules.Apply(tt.url); got != tt.expect {
+			t.Errorf("expected %s to be rewritten to %s, got %s", tt.url, tt.expect, got)
+		}
+	}
+}
+
+func TestEntryMirrorURLs(t *testing.T) {
+	e := &Entry{URL: "https://charts.example.com/stable/", Mirrors: []string{"https://mirror-a.example.com", "https://mirror-b.example.com/charts"}}
+
+	expect := []string{
+		"https://charts.example.com/stable/alpine-0.1.0.tgz",
+		"https://mirror-a.example.com/alpine-0.1.0.tgz",
+		"https://mirror-b.example.com/charts/alpine-0.1.0.tgz",
+	}
+	for _, u := range expect {
+		if got := e.MirrorURLs(u); !reflect.DeepEqual(got, expect) {
+			t.Errorf("expected the mirror URLs of %s to be %v, got %v", u, expect, got)
+		}
+	}
+
+	other := "https://other.example.com/alpine-0.1.0.tgz"
+	if got := e.MirrorURLs(other); !reflect.DeepEqual(got, []string{other}) {
+		t.Errorf("expected only %s, got %v", other, got)
+	}
+}
+
+func TestTryMirrors(t *testing.T) {
+	urls := []string{"https://down.mirror.test/index.yaml", "https://up.mirror.test/index.yaml", "https://up.mirror.test/index.yaml"}
+
+	var tried []string
+	fetch := func(u string) error {
+		tried = append(tried, u)
+		if u == urls[0] {
+			return errors.New("connection refused")
+		}
+		return nil
+	}
+
+	got, err := TryMirrors(urls, fetch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != urls[1] {
+		t.Errorf("expected %s, got %s", urls[1], got)
+	}
+	if !reflect.DeepEqual(tried, urls[:2]) {
+		t.Errorf("expected to try %v, tried %v", urls[:2], tried)
+	}
+
+	// The host that failed is now tried last.
+	tried = nil
+	if _, err := TryMirrors(urls, fetch); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(tried, urls[1:2]) {
+		t.Errorf("expected to only try %v, tried %v", urls[1:2], tried)
+	}
+
+	// A single URL fails with its own error.
+	_, err = TryMirrors(urls[:1], fetch)
+	if err == nil || err.Error() != "connection refused" {
+		t.Errorf("expected the error of the URL, got %v", err)
+	}
+}
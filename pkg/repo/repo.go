@@ -30,6 +30,9 @@ type File struct {
 	APIVersion   string    `json:"apiVersion"`
 	Generated    time.Time `json:"generated"`
 	Repositories []*Entry  `json:"repositories"`
+	// Rewrites are rules rewriting the URLs of chart repositories, including
+	// the repositories of chart dependencies.
+	Rewrites Rewrites `json:"rewrites,omitempty"`
 }
 
 // NewFile generates an empty repositories file.
@@ -16,12 +16,17 @@ limitations under the License.
 package repotest
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path"
 	"path/filepath"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -147,6 +152,16 @@ func (srv *OCIServer) Run(t *testing.T, opts ...OCIServerOpt) {
 
 	go srv.ListenAndServe()
 
+	// Wait for the registry to accept connections before logging in.
+	for i := 0; i < 50; i++ {
+		conn, err := net.Dial("tcp", srv.RegistryURL)
+		if err == nil {
+			conn.Close()
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
 	credentialsFile := filepath.Join(srv.Dir, "config.json")
 
 	// init test client
@@ -293,14 +308,62 @@ type Server struct {
 	docroot    string
 	srv        *httptest.Server
 	middleware http.HandlerFunc
+
+	requiredHeaders map[string]string
+	oauth2          *oauth2Endpoint
 }
 
+// oauth2Endpoint is an OAuth2 token endpoint issuing a token to a client with
+// the client credentials flow.
+type oauth2Endpoint struct {
+	clientID     string
+	clientSecret string
+	token        string
+	expiresIn    time.Duration
+	issued       atomic.Int32
+}
+
+// oauth2TokenPath is the path of the OAuth2 token endpoint of the server.
+const oauth2TokenPath = "/oauth2/token"
+
 // WithMiddleware injects middleware in front of the server. This can be used to inject
 // additional functionality like layering in an authentication frontend.
 func (s *Server) WithMiddleware(middleware http.HandlerFunc) {
 	s.middleware = middleware
 }
 
+// RequireHeader makes the server reject requests that do not have a header
+// with the given value, such as an Authorization header or an API key.
+func (s *Server) RequireHeader(name, value string) {
+	if s.requiredHeaders == nil {
+		s.requiredHeaders = map[string]string{}
+	}
+	s.requiredHeaders[name] = value
+}
+
+// WithOAuth2TokenEndpoint serves an OAuth2 token endpoint issuing the token to
+// the client with the given credentials, and returns its URL. The token expires
+// after the given duration. Requests to the token endpoint do not require the
+// headers set with RequireHeader.
+func (s *Server) WithOAuth2TokenEndpoint(clientID, clientSecret, token string, expiresIn time.Duration) string {
+	s.oauth2 = &oauth2Endpoint{
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		token:        token,
+		expiresIn:    expiresIn,
+	}
+	return s.URL() + oauth2TokenPath
+}
+
+// OAuth2TokensIssued returns the number of tokens issued by the OAuth2 token
+// endpoint.
+func (s *Server) OAuth2TokensIssued() int {
+	if s.oauth2 == nil {
+		return 0
+	}
+	return int(s.oauth2.issued.Load())
+}
+
 // Root gets the docroot for the server.
 func (s *Server) Root() string {
 	return s.docroot
@@ -348,12 +411,7 @@ func (s *Server) CreateIndex() error {
 }
 
 func (s *Server) Start() {
-	s.srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if s.middleware != nil {
-			s.middleware.ServeHTTP(w, r)
-		}
-		http.FileServer(http.Dir(s.docroot)).ServeHTTP(w, r)
-	}))
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
 }
 
 func (s *Server) StartTLS() {
@@ -361,12 +419,7 @@ func (s *Server) StartTLS() {
 	ca, pub, priv := filepath.Join(cd, "rootca.crt"), filepath.Join(cd, "crt.pem"), filepath.Join(cd, "key.pem")
 	insecure := false
 
-	s.srv = httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if s.middleware != nil {
-			s.middleware.ServeHTTP(w, r)
-		}
-		http.FileServer(http.Dir(s.Root())).ServeHTTP(w, r)
-	}))
+	s.srv = httptest.NewUnstartedServer(http.HandlerFunc(s.serveHTTP))
 	tlsConf, err := tlsutil.NewClientTLS(pub, priv, ca, insecure)
 	if err != nil {
 		panic(err)
@@ -390,6 +443,132 @@ func (s *Server) StartTLS() {
 	}
 }
 
+// serveHTTP serves files off of the docroot. Like a ChartMuseum server, it
+// also accepts charts uploaded with a POST to /api/charts, and it accepts
+// files uploaded with a PUT to their path. Uploads update the index.
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.oauth2 != nil && r.URL.Path == oauth2TokenPath {
+		s.oauth2.serveHTTP(w, r)
+		return
+	}
+	for name, value := range s.requiredHeaders {
+		if r.Header.Get(name) != value {
+			http.Error(w, fmt.Sprintf("missing or invalid %s header", name), http.StatusUnauthorized)
+			return
+		}
+	}
+	if s.middleware != nil {
+		s.middleware.ServeHTTP(w, r)
+	}
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/api/charts":
+		s.uploadCharts(w, r)
+	case r.Method == http.MethodPut:
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.saveUpload(w, []string{path.Base(r.URL.Path)}, [][]byte{data}, true)
+	default:
+		http.FileServer(http.Dir(s.docroot)).ServeHTTP(w, r)
+	}
+}
+
+// serveHTTP issues a token to a client authenticated with its credentials,
+// sent either with basic authentication or in the form.
+func (e *oauth2Endpoint) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.FormValue("grant_type") != "client_credentials" {
+		http.Error(w, `{"error":"unsupported_grant_type"}`, http.StatusBadRequest)
+		return
+	}
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID, clientSecret = r.FormValue("client_id"), r.FormValue("client_secret")
+	}
+	if clientID != e.clientID || clientSecret != e.clientSecret {
+		http.Error(w, `{"error":"invalid_client"}`, http.StatusUnauthorized)
+		return
+	}
+	e.issued.Add(1)
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprintf(w, `{"access_token":%q,"token_type":"Bearer","expires_in":%d}`, e.token, int(e.expiresIn.Seconds()))
+}
+
+// uploadCharts saves the chart and provenance file of a multipart upload, or
+// the chart archive in the body of a plain upload.
+func (s *Server) uploadCharts(w http.ResponseWriter, r *http.Request) {
+	var names []string
+	var files [][]byte
+	if err := r.ParseMultipartForm(32 << 20); err == nil {
+		for _, field := range []string{"chart", "prov"} {
+			f, header, err := r.FormFile(field)
+			if err == http.ErrMissingFile {
+				continue
+			}
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			data, err := io.ReadAll(f)
+			f.Close()
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			names = append(names, filepath.Base(header.Filename))
+			files = append(files, data)
+		}
+	} else {
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		ch, err := loader.LoadArchive(bytes.NewReader(data))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		names = append(names, fmt.Sprintf("%s-%s.tgz", ch.Name(), ch.Metadata.Version))
+		files = append(files, data)
+	}
+	if len(files) == 0 {
+		writeAPIError(w, http.StatusBadRequest, "no chart uploaded")
+		return
+	}
+	force := r.URL.Query().Get("force") != "" && r.URL.Query().Get("force") != "false"
+	s.saveUpload(w, names, files, force)
+}
+
+func (s *Server) saveUpload(w http.ResponseWriter, names []string, files [][]byte, overwrite bool) {
+	for _, name := range names {
+		if _, err := os.Stat(filepath.Join(s.docroot, name)); err == nil && !overwrite {
+			writeAPIError(w, http.StatusConflict, "file already exists")
+			return
+		}
+	}
+	for i, name := range names {
+		if err := os.WriteFile(filepath.Join(s.docroot, name), files[i], 0644); err != nil {
+			writeAPIError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+	if err := s.CreateIndex(); err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	fmt.Fprint(w, `{"saved":true}`)
+}
+
+func writeAPIError(w http.ResponseWriter, code int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	fmt.Fprintf(w, `{"error":%q}`, msg)
+}
+
 // Stop stops the server and closes all connections.
 //
 // It should be called explicitly.
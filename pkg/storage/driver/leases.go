@@ -0,0 +1,183 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver // import "helm.sh/helm/v3/pkg/storage/driver"
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	coordinationclientv1 "k8s.io/client-go/kubernetes/typed/coordination/v1"
+)
+
+var _ Locker = (*Leases)(nil)
+
+// leasePrefix is the prefix of the name of the Lease locking a release.
+const leasePrefix = "sh.helm.lock.v1."
+
+// Leases locks releases with a coordination.k8s.io Lease per release, for
+// the drivers storing releases in Kubernetes.
+type Leases struct {
+	impl coordinationclientv1.LeaseInterface
+	Log  func(string, ...interface{})
+}
+
+// NewLeases initializes a new Leases wrapping an implementation of the
+// kubernetes LeaseInterface.
+func NewLeases(impl coordinationclientv1.LeaseInterface) *Leases {
+	return &Leases{
+		impl: impl,
+		Log:  func(_ string, _ ...interface{}) {},
+	}
+}
+
+// AcquireLock acquires the Lease of the named release for holder.
+func (l *Leases) AcquireLock(name, holder string, duration time.Duration) error {
+	ctx := context.Background()
+	now := metav1.NewMicroTime(time.Now())
+	seconds := int32(duration / time.Second)
+
+	lease, err := l.impl.Get(ctx, leasePrefix+name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		lease = &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   leasePrefix + name,
+				Labels: map[string]string{"owner": "helm", "name": name},
+			},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       &holder,
+				LeaseDurationSeconds: &seconds,
+				AcquireTime:          &now,
+				RenewTime:            &now,
+			},
+		}
+		_, err = l.impl.Create(ctx, lease, metav1.CreateOptions{})
+		if apierrors.IsAlreadyExists(err) {
+			// Another holder created the Lease in the meantime.
+			return &LockedError{ReleaseName: name, Holder: "another operation"}
+		}
+		return l.wrap(err, "create", name)
+	}
+	if err != nil {
+		return l.wrap(err, "get", name)
+	}
+
+	spec := &lease.Spec
+	if current := stringValue(spec.HolderIdentity); current != "" && current != holder && !leaseExpired(spec) {
+		locked := &LockedError{ReleaseName: name, Holder: current}
+		if spec.AcquireTime != nil {
+			locked.Acquired = spec.AcquireTime.Time
+		}
+		return locked
+	}
+
+	if stringValue(spec.HolderIdentity) != holder {
+		l.Log("acquiring lock of release %s released or expired by %q", name, stringValue(spec.HolderIdentity))
+		spec.AcquireTime = &now
+	}
+	spec.HolderIdentity = &holder
+	spec.LeaseDurationSeconds = &seconds
+	spec.RenewTime = &now
+	// The resource version of the Lease makes the update fail if another
+	// holder updated it first.
+	_, err = l.impl.Update(ctx, lease, metav1.UpdateOptions{})
+	if apierrors.IsConflict(err) {
+		return &LockedError{ReleaseName: name, Holder: "another operation"}
+	}
+	return l.wrap(err, "update", name)
+}
+
+// RenewLock extends the Lease of the named release held by holder.
+func (l *Leases) RenewLock(name, holder string, duration time.Duration) error {
+	ctx := context.Background()
+	lease, err := l.impl.Get(ctx, leasePrefix+name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return ErrLockNotHeld
+	}
+	if err != nil {
+		return l.wrap(err, "get", name)
+	}
+	if stringValue(lease.Spec.HolderIdentity) != holder {
+		return ErrLockNotHeld
+	}
+
+	now := metav1.NewMicroTime(time.Now())
+	seconds := int32(duration / time.Second)
+	lease.Spec.RenewTime = &now
+	lease.Spec.LeaseDurationSeconds = &seconds
+	if _, err := l.impl.Update(ctx, lease, metav1.UpdateOptions{}); err != nil {
+		if apierrors.IsConflict(err) {
+			return ErrLockNotHeld
+		}
+		return l.wrap(err, "update", name)
+	}
+	return nil
+}
+
+// ReleaseLock deletes the Lease of the named release if it is held by holder.
+func (l *Leases) ReleaseLock(name, holder string) error {
+	ctx := context.Background()
+	lease, err := l.impl.Get(ctx, leasePrefix+name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return l.wrap(err, "get", name)
+	}
+	if stringValue(lease.Spec.HolderIdentity) != holder {
+		return nil
+	}
+	// Only delete the Lease if no other holder acquired it since it was read.
+	err = l.impl.Delete(ctx, lease.Name, metav1.DeleteOptions{
+		Preconditions: &metav1.Preconditions{ResourceVersion: &lease.ResourceVersion},
+	})
+	if apierrors.IsNotFound(err) || apierrors.IsConflict(err) {
+		return nil
+	}
+	return l.wrap(err, "delete", name)
+}
+
+// wrap reports the errors meaning that Leases cannot be used, such as a
+// missing permission, as ErrLockingUnsupported.
+func (l *Leases) wrap(err error, op, name string) error {
+	if err == nil {
+		return nil
+	}
+	if apierrors.IsForbidden(err) || apierrors.IsMethodNotSupported(err) || (apierrors.IsNotFound(err) && op != "get") {
+		l.Log("%s: unable to lock release %s: %v", op, name, err)
+		return errors.Wrapf(ErrLockingUnsupported, "%s: %v", op, err)
+	}
+	return errors.Wrapf(err, "%s: failed to lock release %q", op, name)
+}
+
+func leaseExpired(spec *coordinationv1.LeaseSpec) bool {
+	if spec.RenewTime == nil || spec.LeaseDurationSeconds == nil {
+		return true
+	}
+	expiry := spec.RenewTime.Add(time.Duration(*spec.LeaseDurationSeconds) * time.Second)
+	return time.Now().After(expiry)
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,121 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestLeases(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	leases := NewLeases(client.CoordinationV1().Leases("default"))
+
+	if err := leases.AcquireLock("smug-pigeon", "alice", time.Minute); err != nil {
+		t.Fatalf("failed to acquire lock: %s", err)
+	}
+	lease, err := client.CoordinationV1().Leases("default").Get(context.Background(), "sh.helm.lock.v1.smug-pigeon", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get lease: %s", err)
+	}
+	if *lease.Spec.HolderIdentity != "alice" || *lease.Spec.LeaseDurationSeconds != 60 {
+		t.Errorf("unexpected lease spec: %+v", lease.Spec)
+	}
+	if lease.Labels["owner"] != "helm" || lease.Labels["name"] != "smug-pigeon" {
+		t.Errorf("unexpected lease labels: %v", lease.Labels)
+	}
+
+	// The holder can acquire its lock again, but not another holder.
+	if err := leases.AcquireLock("smug-pigeon", "alice", time.Minute); err != nil {
+		t.Errorf("failed to acquire lock again: %s", err)
+	}
+	err = leases.AcquireLock("smug-pigeon", "bob", time.Minute)
+	var locked *LockedError
+	if !errors.As(err, &locked) || locked.Holder != "alice" || locked.Acquired.IsZero() {
+		t.Fatalf("expected a lock held by alice, got %v", err)
+	}
+	if !errors.Is(err, ErrReleaseLocked) {
+		t.Errorf("expected ErrReleaseLocked, got %v", err)
+	}
+	if err := leases.RenewLock("smug-pigeon", "bob", time.Minute); !errors.Is(err, ErrLockNotHeld) {
+		t.Errorf("expected ErrLockNotHeld, got %v", err)
+	}
+	if err := leases.RenewLock("smug-pigeon", "alice", time.Minute); err != nil {
+		t.Errorf("failed to renew lock: %s", err)
+	}
+
+	// Only the holder can release its lock.
+	if err := leases.ReleaseLock("smug-pigeon", "bob"); err != nil {
+		t.Errorf("failed to release lock: %s", err)
+	}
+	if err := leases.AcquireLock("smug-pigeon", "bob", time.Minute); !errors.Is(err, ErrReleaseLocked) {
+		t.Errorf("expected ErrReleaseLocked, got %v", err)
+	}
+	if err := leases.ReleaseLock("smug-pigeon", "alice"); err != nil {
+		t.Errorf("failed to release lock: %s", err)
+	}
+	if _, err := client.CoordinationV1().Leases("default").Get(context.Background(), "sh.helm.lock.v1.smug-pigeon", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected the lease to be deleted, got %v", err)
+	}
+	if err := leases.AcquireLock("smug-pigeon", "bob", time.Minute); err != nil {
+		t.Errorf("failed to acquire released lock: %s", err)
+	}
+}
+
+func TestLeasesExpired(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	leases := NewLeases(client.CoordinationV1().Leases("default"))
+
+	// A lock that is not renewed expires.
+	if err := leases.AcquireLock("smug-pigeon", "alice", 0); err != nil {
+		t.Fatalf("failed to acquire lock: %s", err)
+	}
+	if err := leases.AcquireLock("smug-pigeon", "bob", time.Minute); err != nil {
+		t.Fatalf("failed to acquire expired lock: %s", err)
+	}
+	lease, err := client.CoordinationV1().Leases("default").Get(context.Background(), "sh.helm.lock.v1.smug-pigeon", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get lease: %s", err)
+	}
+	if *lease.Spec.HolderIdentity != "bob" {
+		t.Errorf("expected the lease to be held by bob, got %s", *lease.Spec.HolderIdentity)
+	}
+	if err := leases.RenewLock("smug-pigeon", "alice", time.Minute); !errors.Is(err, ErrLockNotHeld) {
+		t.Errorf("expected ErrLockNotHeld, got %v", err)
+	}
+}
+
+func TestLeasesForbidden(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("*", "leases", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(schema.GroupResource{Group: "coordination.k8s.io", Resource: "leases"}, "", errors.New("denied"))
+	})
+	leases := NewLeases(client.CoordinationV1().Leases("default"))
+
+	if err := leases.AcquireLock("smug-pigeon", "alice", time.Minute); !errors.Is(err, ErrLockingUnsupported) {
+		t.Errorf("expected ErrLockingUnsupported, got %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver // import "helm.sh/helm/v3/pkg/storage/driver"
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrReleaseLocked indicates that a release is locked by another operation.
+	ErrReleaseLocked = errors.New("release: locked by another operation")
+	// ErrLockNotHeld indicates that a lock is not held by the given holder,
+	// for example because it expired and was acquired by another holder.
+	ErrLockNotHeld = errors.New("release: lock not held")
+	// ErrLockingUnsupported indicates that the storage backend does not allow
+	// locking releases, for example because the user is not allowed to
+	// manage leases.
+	ErrLockingUnsupported = errors.New("release: locking unsupported")
+)
+
+// LockedError records the holder of the lock of a release.
+type LockedError struct {
+	ReleaseName string
+	Holder      string
+	Acquired    time.Time
+}
+
+func (e *LockedError) Error() string {
+	if e.Acquired.IsZero() {
+		return fmt.Sprintf("release %q is locked by %s", e.ReleaseName, e.Holder)
+	}
+	return fmt.Sprintf("release %q is locked by %s since %s", e.ReleaseName, e.Holder, e.Acquired.Format(time.RFC3339))
+}
+
+func (e *LockedError) Unwrap() error { return ErrReleaseLocked }
+
+// Locker is the interface that wraps the AcquireLock, RenewLock and
+// ReleaseLock methods.
+//
+// A lock is held by a holder, which identifies the operation that acquired
+// it, for a duration. A lock that is not renewed within its duration expires
+// and can be acquired by another holder, so that the lock of an operation
+// that was killed does not block the release forever.
+//
+// AcquireLock acquires the lock of the named release without waiting, or returns
+// a *LockedError if another holder holds a lock that has not expired.
+//
+// RenewLock extends the lock held by holder, or returns ErrLockNotHeld if the
+// holder lost it.
+//
+// ReleaseLock releases the lock held by holder. It does nothing if the holder
+// does not hold the lock.
+type Locker interface {
+	AcquireLock(name, holder string, duration time.Duration) error
+	RenewLock(name, holder string, duration time.Duration) error
+	ReleaseLock(name, holder string) error
+}
@@ -20,11 +20,13 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	rspb "helm.sh/helm/v3/pkg/release"
 )
 
 var _ Driver = (*Memory)(nil)
+var _ Locker = (*Memory)(nil)
 
 const (
 	// MemoryDriverName is the string name of this driver.
@@ -42,11 +44,20 @@ type Memory struct {
 	namespace string
 	// A map of namespaces to releases
 	cache map[string]memReleases
+	// A map of namespaces to the locks of releases
+	locks map[string]map[string]memLock
+}
+
+// memLock is the lock of a release held by a holder until it expires.
+type memLock struct {
+	holder   string
+	acquired time.Time
+	expires  time.Time
 }
 
 // NewMemory initializes a new memory driver.
 func NewMemory() *Memory {
-	return &Memory{cache: map[string]memReleases{}, namespace: "default"}
+	return &Memory{cache: map[string]memReleases{}, locks: map[string]map[string]memLock{}, namespace: "default"}
 }
 
 // SetNamespace sets a specific namespace in which releases will be accessed.
@@ -222,6 +233,49 @@ func (mem *Memory) Delete(key string) (*rspb.Release, error) {
 	return nil, ErrReleaseNotFound
 }
 
+// AcquireLock acquires the lock of the named release for holder.
+func (mem *Memory) AcquireLock(name, holder string, duration time.Duration) error {
+	defer unlock(mem.wlock())
+
+	now := time.Now()
+	l, ok := mem.locks[mem.namespace][name]
+	if ok && l.holder != holder && now.Before(l.expires) {
+		return &LockedError{ReleaseName: name, Holder: l.holder, Acquired: l.acquired}
+	}
+	if !ok || l.holder != holder {
+		l = memLock{holder: holder, acquired: now}
+	}
+	l.expires = now.Add(duration)
+	if _, ok := mem.locks[mem.namespace]; !ok {
+		mem.locks[mem.namespace] = map[string]memLock{}
+	}
+	mem.locks[mem.namespace][name] = l
+	return nil
+}
+
+// RenewLock extends the lock of the named release held by holder.
+func (mem *Memory) RenewLock(name, holder string, duration time.Duration) error {
+	defer unlock(mem.wlock())
+
+	l, ok := mem.locks[mem.namespace][name]
+	if !ok || l.holder != holder {
+		return ErrLockNotHeld
+	}
+	l.expires = time.Now().Add(duration)
+	mem.locks[mem.namespace][name] = l
+	return nil
+}
+
+// ReleaseLock releases the lock of the named release held by holder.
+func (mem *Memory) ReleaseLock(name, holder string) error {
+	defer unlock(mem.wlock())
+
+	if l, ok := mem.locks[mem.namespace][name]; ok && l.holder == holder {
+		delete(mem.locks[mem.namespace], name)
+	}
+	return nil
+}
+
 // wlock locks mem for writing
 func (mem *Memory) wlock() func() {
 	mem.Lock()
@@ -17,9 +17,11 @@ limitations under the License.
 package driver
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
 	rspb "helm.sh/helm/v3/pkg/release"
 )
@@ -287,3 +289,49 @@ func TestMemoryDelete(t *testing.T) {
 	}
 
 }
+
+func TestMemoryLock(t *testing.T) {
+	mem := NewMemory()
+
+	if err := mem.AcquireLock("smug-pigeon", "alice", time.Minute); err != nil {
+		t.Fatalf("failed to acquire lock: %s", err)
+	}
+	if err := mem.AcquireLock("smug-pigeon", "alice", time.Minute); err != nil {
+		t.Errorf("failed to acquire lock again: %s", err)
+	}
+	err := mem.AcquireLock("smug-pigeon", "bob", time.Minute)
+	var locked *LockedError
+	if !errors.As(err, &locked) || locked.Holder != "alice" {
+		t.Fatalf("expected a lock held by alice, got %v", err)
+	}
+
+	// Locks are namespaced like releases.
+	mem.SetNamespace("other")
+	if err := mem.AcquireLock("smug-pigeon", "bob", time.Minute); err != nil {
+		t.Errorf("failed to acquire lock in another namespace: %s", err)
+	}
+	mem.SetNamespace("default")
+
+	if err := mem.RenewLock("smug-pigeon", "bob", time.Minute); !errors.Is(err, ErrLockNotHeld) {
+		t.Errorf("expected ErrLockNotHeld, got %v", err)
+	}
+	if err := mem.RenewLock("smug-pigeon", "alice", 0); err != nil {
+		t.Errorf("failed to renew lock: %s", err)
+	}
+	// The lock renewed for no time expired.
+	if err := mem.AcquireLock("smug-pigeon", "bob", time.Minute); err != nil {
+		t.Errorf("failed to acquire expired lock: %s", err)
+	}
+	if err := mem.ReleaseLock("smug-pigeon", "alice"); err != nil {
+		t.Errorf("failed to release lock: %s", err)
+	}
+	if err := mem.AcquireLock("smug-pigeon", "alice", time.Minute); !errors.Is(err, ErrReleaseLocked) {
+		t.Errorf("expected ErrReleaseLocked, got %v", err)
+	}
+	if err := mem.ReleaseLock("smug-pigeon", "bob"); err != nil {
+		t.Errorf("failed to release lock: %s", err)
+	}
+	if err := mem.AcquireLock("smug-pigeon", "alice", time.Minute); err != nil {
+		t.Errorf("failed to acquire released lock: %s", err)
+	}
+}
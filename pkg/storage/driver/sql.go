@@ -34,6 +34,7 @@ import (
 )
 
 var _ Driver = (*SQL)(nil)
+var _ Locker = (*SQL)(nil)
 
 var labelMap = map[string]struct{}{
 	"modifiedAt": {},
@@ -51,6 +52,7 @@ const SQLDriverName = "SQL"
 
 const sqlReleaseTableName = "releases_v1"
 const sqlCustomLabelsTableName = "custom_labels_v1"
+const sqlLocksTableName = "release_locks_v1"
 
 const (
 	sqlReleaseTableKeyColumn        = "key"
@@ -68,6 +70,12 @@ const (
 	sqlCustomLabelsTableReleaseNamespaceColumn = "releaseNamespace"
 	sqlCustomLabelsTableKeyColumn              = "key"
 	sqlCustomLabelsTableValueColumn            = "value"
+
+	sqlLocksTableNameColumn       = "name"
+	sqlLocksTableNamespaceColumn  = "namespace"
+	sqlLocksTableHolderColumn     = "holder"
+	sqlLocksTableAcquiredAtColumn = "acquiredAt"
+	sqlLocksTableExpiresAtColumn  = "expiresAt"
 )
 
 // Following limits based on k8s labels limits - https://kubernetes.io/docs/concepts/overview/working-with-objects/labels/#syntax-and-character-set
@@ -232,6 +240,40 @@ func (s *SQL) ensureDBSetup() error {
 					`, sqlCustomLabelsTableName),
 				},
 			},
+			{
+				Id: "locks",
+				Up: []string{
+					fmt.Sprintf(`
+						CREATE TABLE %s (
+							%s VARCHAR(64),
+							%s VARCHAR(64),
+							%s TEXT NOT NULL,
+							%s INTEGER NOT NULL,
+							%s INTEGER NOT NULL,
+							PRIMARY KEY(%s, %s)
+						);
+
+						GRANT ALL ON %s TO PUBLIC;
+						ALTER TABLE %s ENABLE ROW LEVEL SECURITY;
+					`,
+						sqlLocksTableName,
+						sqlLocksTableNameColumn,
+						sqlLocksTableNamespaceColumn,
+						sqlLocksTableHolderColumn,
+						sqlLocksTableAcquiredAtColumn,
+						sqlLocksTableExpiresAtColumn,
+						sqlLocksTableNameColumn,
+						sqlLocksTableNamespaceColumn,
+						sqlLocksTableName,
+						sqlLocksTableName,
+					),
+				},
+				Down: []string{
+					fmt.Sprintf(`
+						DROP TABLE %s;
+					`, sqlLocksTableName),
+				},
+			},
 		},
 	}
 
@@ -661,6 +703,115 @@ func (s *SQL) Delete(key string) (*rspb.Release, error) {
 	return release, err
 }
 
+// AcquireLock acquires the lock of the named release for holder. The lock is
+// inserted, or taken over if it is held by holder or expired, in a single
+// statement so that concurrent operations cannot both acquire it.
+func (s *SQL) AcquireLock(name, holder string, duration time.Duration) error {
+	now := time.Now()
+	query, args, err := s.statementBuilder.
+		Insert(sqlLocksTableName).
+		Columns(
+			sqlLocksTableNameColumn,
+			sqlLocksTableNamespaceColumn,
+			sqlLocksTableHolderColumn,
+			sqlLocksTableAcquiredAtColumn,
+			sqlLocksTableExpiresAtColumn,
+		).
+		Values(name, s.namespace, holder, int(now.Unix()), int(now.Add(duration).Unix())).
+		Suffix(fmt.Sprintf(
+			"ON CONFLICT (%[2]s, %[3]s) DO UPDATE SET %[4]s = EXCLUDED.%[4]s, "+
+				"%[5]s = CASE WHEN %[1]s.%[4]s = EXCLUDED.%[4]s THEN %[1]s.%[5]s ELSE EXCLUDED.%[5]s END, "+
+				"%[6]s = EXCLUDED.%[6]s "+
+				"WHERE %[1]s.%[4]s = EXCLUDED.%[4]s OR %[1]s.%[6]s < EXCLUDED.%[5]s",
+			sqlLocksTableName,
+			sqlLocksTableNameColumn,
+			sqlLocksTableNamespaceColumn,
+			sqlLocksTableHolderColumn,
+			sqlLocksTableAcquiredAtColumn,
+			sqlLocksTableExpiresAtColumn,
+		)).
+		ToSql()
+	if err != nil {
+		s.Log("failed to build lock query: %v", err)
+		return err
+	}
+
+	result, err := s.db.Exec(query, args...)
+	if err != nil {
+		s.Log("failed to lock release %s: %v", name, err)
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil || n != 0 {
+		return err
+	}
+
+	// The lock is held by another holder: find out which one.
+	selectQuery, args, err := s.statementBuilder.
+		Select(sqlLocksTableHolderColumn, sqlLocksTableAcquiredAtColumn).
+		From(sqlLocksTableName).
+		Where(sq.Eq{sqlLocksTableNameColumn: name}).
+		Where(sq.Eq{sqlLocksTableNamespaceColumn: s.namespace}).
+		ToSql()
+	if err != nil {
+		s.Log("failed to build select query: %v", err)
+		return err
+	}
+	var lockHolder string
+	var acquiredAt int64
+	if err := s.db.QueryRow(selectQuery, args...).Scan(&lockHolder, &acquiredAt); err != nil {
+		s.Log("failed to get the lock of release %s: %v", name, err)
+		return &LockedError{ReleaseName: name, Holder: "another operation"}
+	}
+	return &LockedError{ReleaseName: name, Holder: lockHolder, Acquired: time.Unix(acquiredAt, 0)}
+}
+
+// RenewLock extends the lock of the named release held by holder.
+func (s *SQL) RenewLock(name, holder string, duration time.Duration) error {
+	query, args, err := s.statementBuilder.
+		Update(sqlLocksTableName).
+		Set(sqlLocksTableExpiresAtColumn, int(time.Now().Add(duration).Unix())).
+		Where(sq.Eq{sqlLocksTableNameColumn: name}).
+		Where(sq.Eq{sqlLocksTableNamespaceColumn: s.namespace}).
+		Where(sq.Eq{sqlLocksTableHolderColumn: holder}).
+		ToSql()
+	if err != nil {
+		s.Log("failed to build update query: %v", err)
+		return err
+	}
+
+	result, err := s.db.Exec(query, args...)
+	if err != nil {
+		s.Log("failed to renew the lock of release %s: %v", name, err)
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrLockNotHeld
+	}
+	return nil
+}
+
+// ReleaseLock releases the lock of the named release held by holder.
+func (s *SQL) ReleaseLock(name, holder string) error {
+	query, args, err := s.statementBuilder.
+		Delete(sqlLocksTableName).
+		Where(sq.Eq{sqlLocksTableNameColumn: name}).
+		Where(sq.Eq{sqlLocksTableNamespaceColumn: s.namespace}).
+		Where(sq.Eq{sqlLocksTableHolderColumn: holder}).
+		ToSql()
+	if err != nil {
+		s.Log("failed to build delete query: %v", err)
+		return err
+	}
+
+	if _, err := s.db.Exec(query, args...); err != nil {
+		s.Log("failed to release the lock of release %s: %v", name, err)
+		return err
+	}
+	return nil
+}
+
 // Get release custom labels from database
 func (s *SQL) getReleaseCustomLabels(key string, _ string) (map[string]string, error) {
 	query, args, err := s.statementBuilder.
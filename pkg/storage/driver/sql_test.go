@@ -14,6 +14,7 @@ limitations under the License.
 package driver
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 	"regexp"
@@ -543,6 +544,86 @@ func mockGetReleaseCustomLabels(mock sqlmock.Sqlmock, key string, namespace stri
 	eq.WillReturnRows(returnRows).RowsWillBeClosed()
 }
 
+func TestSqlLock(t *testing.T) {
+	name := "smug-pigeon"
+	sqlDriver, mock := newTestFixtureSQL(t)
+
+	lockQuery := regexp.QuoteMeta(fmt.Sprintf(
+		"INSERT INTO %s (%s,%s,%s,%s,%s) VALUES ($1,$2,$3,$4,$5) ON CONFLICT",
+		sqlLocksTableName,
+		sqlLocksTableNameColumn,
+		sqlLocksTableNamespaceColumn,
+		sqlLocksTableHolderColumn,
+		sqlLocksTableAcquiredAtColumn,
+		sqlLocksTableExpiresAtColumn,
+	))
+	now := int(time.Now().Unix())
+
+	mock.
+		ExpectExec(lockQuery).
+		WithArgs(name, "default", "alice", now, now+60).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	if err := sqlDriver.AcquireLock(name, "alice", time.Minute); err != nil {
+		t.Fatalf("failed to acquire lock: %v", err)
+	}
+
+	// The lock is held by another holder: nothing is inserted or updated.
+	mock.
+		ExpectExec(lockQuery).
+		WithArgs(name, "default", "bob", now, now+60).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.
+		ExpectQuery(regexp.QuoteMeta(fmt.Sprintf(
+			"SELECT %s, %s FROM %s WHERE %s = $1 AND %s = $2",
+			sqlLocksTableHolderColumn,
+			sqlLocksTableAcquiredAtColumn,
+			sqlLocksTableName,
+			sqlLocksTableNameColumn,
+			sqlLocksTableNamespaceColumn,
+		))).
+		WithArgs(name, "default").
+		WillReturnRows(mock.NewRows([]string{sqlLocksTableHolderColumn, sqlLocksTableAcquiredAtColumn}).AddRow("alice", now))
+	err := sqlDriver.AcquireLock(name, "bob", time.Minute)
+	var locked *LockedError
+	if !errors.As(err, &locked) || locked.Holder != "alice" || locked.Acquired.Unix() != int64(now) {
+		t.Fatalf("expected a lock held by alice, got %v", err)
+	}
+
+	renewQuery := regexp.QuoteMeta(fmt.Sprintf(
+		"UPDATE %s SET %s = $1 WHERE %s = $2 AND %s = $3 AND %s = $4",
+		sqlLocksTableName,
+		sqlLocksTableExpiresAtColumn,
+		sqlLocksTableNameColumn,
+		sqlLocksTableNamespaceColumn,
+		sqlLocksTableHolderColumn,
+	))
+	mock.
+		ExpectExec(renewQuery).
+		WithArgs(now+60, name, "default", "bob").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	if err := sqlDriver.RenewLock(name, "bob", time.Minute); !errors.Is(err, ErrLockNotHeld) {
+		t.Errorf("expected ErrLockNotHeld, got %v", err)
+	}
+
+	mock.
+		ExpectExec(regexp.QuoteMeta(fmt.Sprintf(
+			"DELETE FROM %s WHERE %s = $1 AND %s = $2 AND %s = $3",
+			sqlLocksTableName,
+			sqlLocksTableNameColumn,
+			sqlLocksTableNamespaceColumn,
+			sqlLocksTableHolderColumn,
+		))).
+		WithArgs(name, "default", "alice").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	if err := sqlDriver.ReleaseLock(name, "alice"); err != nil {
+		t.Errorf("failed to release lock: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("sql expectations weren't met: %v", err)
+	}
+}
+
 func TestSqlCheckAppliedMigrations(t *testing.T) {
 	cases := []struct {
 		migrationsToApply    []*migrate.Migration
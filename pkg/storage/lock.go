@@ -0,0 +1,171 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage // import "helm.sh/helm/v3/pkg/storage"
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/pkg/storage/driver"
+)
+
+// LockDuration is how long the lock of a release stays valid without being
+// renewed. Locks are renewed every third of it while they are held, so the
+// lock of an operation that was killed expires after at most LockDuration.
+var LockDuration = 60 * time.Second
+
+// lockRetryInterval is how often Lock tries to acquire a lock held by
+// another operation.
+var lockRetryInterval = time.Second
+
+// heldLock is a lock held by the storage, renewed in the background until it
+// is released.
+type heldLock struct {
+	holder   string
+	acquired time.Time
+	stop     chan struct{}
+	done     chan struct{}
+
+	mu sync.Mutex
+	// lost is set once the lock could not be renewed before it expired, or
+	// was no longer held.
+	lost error
+}
+
+// Lock acquires the lock of the named release for holder, waiting for up to
+// LockTimeout if it is held by another operation, and returns the function
+// that releases it. The lock is renewed in the background until then. If it
+// could not be kept, because it expired before it could be renewed or was
+// no longer held, the release function returns an error.
+//
+// A lock is not re-entrant: while a caller of the storage holds it, other
+// callers wait for it like any other operation, even with the same holder.
+// An operation that runs another one on the same release, such as the
+// rollback of a failed atomic upgrade, must not lock it again.
+//
+// If the storage has no Locker, or the backend does not allow locking,
+// nothing is locked. A warning is logged in the latter case.
+func (s *Storage) Lock(name, holder string) (func() error, error) {
+	if s.Locker == nil {
+		return func() error { return nil }, nil
+	}
+
+	deadline := time.Now().Add(s.LockTimeout)
+	for waiting := false; ; waiting = true {
+		l, err := s.acquireLock(name, holder)
+		if err == nil {
+			s.Log("locked release %s for %s", name, holder)
+			return s.unlockFunc(name, l), nil
+		}
+		if errors.Is(err, driver.ErrLockingUnsupported) {
+			s.Log("warning: release %s is not locked, concurrent operations on it are not prevented: %v", name, err)
+			return func() error { return nil }, nil
+		}
+		var locked *driver.LockedError
+		if !errors.As(err, &locked) {
+			return nil, err
+		}
+		if !time.Now().Before(deadline) {
+			if waiting {
+				return nil, errors.Wrapf(err, "timed out after %s waiting for the lock", s.LockTimeout)
+			}
+			return nil, err
+		}
+		if !waiting {
+			s.Log("%s, waiting for up to %s", err, s.LockTimeout)
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+// acquireLock acquires the lock of the named release and starts renewing it.
+// A lock the storage already holds is locked for the other callers, as the
+// Locker cannot tell apart two callers with the same holder.
+func (s *Storage) acquireLock(name, holder string) (*heldLock, error) {
+	s.locksMu.Lock()
+	defer s.locksMu.Unlock()
+	if l, ok := s.locks[name]; ok {
+		return nil, &driver.LockedError{ReleaseName: name, Holder: l.holder, Acquired: l.acquired}
+	}
+	if err := s.Locker.AcquireLock(name, holder, LockDuration); err != nil {
+		return nil, err
+	}
+	l := &heldLock{holder: holder, acquired: time.Now(), stop: make(chan struct{}), done: make(chan struct{})}
+	if s.locks == nil {
+		s.locks = map[string]*heldLock{}
+	}
+	s.locks[name] = l
+	go s.renewLock(name, l)
+	return l, nil
+}
+
+// renewLock renews a held lock until it is released. A failed renewal is
+// retried at the next interval, until the lock expires or is no longer held,
+// after which the lock is lost.
+func (s *Storage) renewLock(name string, l *heldLock) {
+	defer close(l.done)
+	ticker := time.NewTicker(LockDuration / 3)
+	defer ticker.Stop()
+	renewed := time.Now()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			err := s.Locker.RenewLock(name, l.holder, LockDuration)
+			if err == nil {
+				renewed = time.Now()
+				continue
+			}
+			s.Log("failed to renew the lock of release %s: %v", name, err)
+			if errors.Is(err, driver.ErrLockNotHeld) || time.Since(renewed) >= LockDuration {
+				l.mu.Lock()
+				l.lost = errors.Wrapf(err, "lost the lock of release %s, another operation may have modified it", name)
+				l.mu.Unlock()
+				return
+			}
+		}
+	}
+}
+
+func (s *Storage) unlockFunc(name string, l *heldLock) func() error {
+	released := false
+	return func() error {
+		s.locksMu.Lock()
+		defer s.locksMu.Unlock()
+		if released {
+			return nil
+		}
+		released = true
+		delete(s.locks, name)
+		close(l.stop)
+		<-l.done
+		if err := s.Locker.ReleaseLock(name, l.holder); err != nil {
+			s.Log("failed to release the lock of release %s: %v", name, err)
+		}
+		s.Log("unlocked release %s", name)
+		return l.lostErr()
+	}
+}
+
+func (l *heldLock) lostErr() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.lost
+}
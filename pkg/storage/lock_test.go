@@ -0,0 +1,149 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage // import "helm.sh/helm/v3/pkg/storage"
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/pkg/storage/driver"
+)
+
+func TestStorageLock(t *testing.T) {
+	mem := driver.NewMemory()
+	storage := Init(mem)
+
+	unlock, err := storage.Lock("angry-beaver", "alice")
+	assertErrNil(t.Fatal, err, "Lock")
+
+	// Other callers of the storage wait for the lock, even with the same
+	// holder.
+	if _, err := storage.Lock("angry-beaver", "alice"); !errors.Is(err, driver.ErrReleaseLocked) {
+		t.Fatalf("expected ErrReleaseLocked for another caller of the storage, got %v", err)
+	}
+
+	// Another storage does not wait for the lock without a timeout.
+	other := Init(mem)
+	if _, err := other.Lock("angry-beaver", "bob"); !errors.Is(err, driver.ErrReleaseLocked) {
+		t.Fatalf("expected ErrReleaseLocked, got %v", err)
+	}
+
+	unlock()
+	otherUnlock, err := other.Lock("angry-beaver", "bob")
+	assertErrNil(t.Fatal, err, "Lock released lock")
+	otherUnlock()
+}
+
+func TestStorageLockWait(t *testing.T) {
+	defer func(interval time.Duration) { lockRetryInterval = interval }(lockRetryInterval)
+	lockRetryInterval = 10 * time.Millisecond
+
+	mem := driver.NewMemory()
+	storage := Init(mem)
+	storage.LockTimeout = time.Minute
+	if err := mem.AcquireLock("angry-beaver", "alice", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		mem.ReleaseLock("angry-beaver", "alice")
+	}()
+	unlock, err := storage.Lock("angry-beaver", "bob")
+	assertErrNil(t.Fatal, err, "Lock after waiting")
+	unlock()
+
+	if err := mem.AcquireLock("angry-beaver", "alice", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	storage.LockTimeout = 50 * time.Millisecond
+	_, err = storage.Lock("angry-beaver", "bob")
+	if !errors.Is(err, driver.ErrReleaseLocked) {
+		t.Fatalf("expected ErrReleaseLocked, got %v", err)
+	}
+}
+
+func TestStorageLockWaitSameHolder(t *testing.T) {
+	defer func(interval time.Duration) { lockRetryInterval = interval }(lockRetryInterval)
+	lockRetryInterval = 10 * time.Millisecond
+
+	storage := Init(driver.NewMemory())
+	storage.LockTimeout = time.Minute
+	unlock, err := storage.Lock("angry-beaver", "alice")
+	assertErrNil(t.Fatal, err, "Lock")
+
+	released := make(chan struct{})
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		close(released)
+		unlock()
+	}()
+	unlockAgain, err := storage.Lock("angry-beaver", "alice")
+	assertErrNil(t.Fatal, err, "Lock after waiting")
+	select {
+	case <-released:
+	default:
+		t.Fatal("expected the lock to be acquired only once released by the other caller")
+	}
+	unlockAgain()
+}
+
+func TestStorageLockUnsupported(t *testing.T) {
+	storage := Init(driver.NewMemory())
+	storage.Locker = unsupportedLocker{}
+
+	unlock, err := storage.Lock("angry-beaver", "alice")
+	assertErrNil(t.Fatal, err, "Lock")
+	unlock()
+
+	storage.Locker = nil
+	unlock, err = storage.Lock("angry-beaver", "alice")
+	assertErrNil(t.Fatal, err, "Lock")
+	unlock()
+}
+
+func TestStorageLockLost(t *testing.T) {
+	defer func(d time.Duration) { LockDuration = d }(LockDuration)
+	LockDuration = 30 * time.Millisecond
+
+	mem := driver.NewMemory()
+	storage := Init(mem)
+	unlock, err := storage.Lock("angry-beaver", "alice")
+	assertErrNil(t.Fatal, err, "Lock")
+
+	// The lock expires and is taken by another operation.
+	mem.ReleaseLock("angry-beaver", "alice")
+	if err := mem.AcquireLock("angry-beaver", "bob", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(3 * LockDuration)
+	if err := unlock(); !errors.Is(err, driver.ErrLockNotHeld) {
+		t.Fatalf("expected the lost lock to be reported, got %v", err)
+	}
+}
+
+type unsupportedLocker struct{}
+
+func (unsupportedLocker) AcquireLock(_, _ string, _ time.Duration) error {
+	return errors.Wrap(driver.ErrLockingUnsupported, "forbidden")
+}
+
+func (unsupportedLocker) RenewLock(_, _ string, _ time.Duration) error { return nil }
+
+func (unsupportedLocker) ReleaseLock(_, _ string) error { return nil }
@@ -19,6 +19,8 @@ package storage // import "helm.sh/helm/v3/pkg/storage"
 import (
 	"fmt"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -43,7 +45,17 @@ type Storage struct {
 	// ignored (meaning no limits are imposed).
 	MaxHistory int
 
+	// Locker locks releases for the duration of an operation. Releases are
+	// not locked if it is nil.
+	Locker driver.Locker
+	// LockTimeout is how long Lock waits for the lock of a release held by
+	// another operation. Lock fails at once if it is 0.
+	LockTimeout time.Duration
+
 	Log func(string, ...interface{})
+
+	locksMu sync.Mutex
+	locks   map[string]*heldLock
 }
 
 // Get retrieves the release from storage. An error is returned
@@ -259,8 +271,13 @@ func Init(d driver.Driver) *Storage {
 	if d == nil {
 		d = driver.NewMemory()
 	}
-	return &Storage{
+	s := &Storage{
 		Driver: d,
 		Log:    func(_ string, _ ...interface{}) {},
 	}
+	// Drivers storing releases outside of Kubernetes lock releases themselves.
+	if l, ok := d.(driver.Locker); ok {
+		s.Locker = l
+	}
+	return s
 }